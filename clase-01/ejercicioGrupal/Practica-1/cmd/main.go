@@ -3,15 +3,35 @@ package main
 import (
 	"app/internal/application"
 	"fmt"
+	"os"
+	"time"
 )
 
 func main() {
 	// env
-	// ...
+	// - REQUEST_TIMEOUT: deadline of each request, as a duration (30s, 1m)
+	var requestTimeout time.Duration
+	if v := os.Getenv("REQUEST_TIMEOUT"); v != "" {
+		var err error
+		requestTimeout, err = time.ParseDuration(v)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	// - STORAGE: where the products are kept (mysql, json), mysql by default
+	storage := os.Getenv("STORAGE")
+	if storage == "" {
+		storage = application.StorageMySQL
+	}
+	// - LEGACY_ROUTES: keep the routes before /api/v1 (true, false)
+	legacyRoutes := os.Getenv("LEGACY_ROUTES") == "true"
+	// - REQUIRE_MIGRATIONS: refuse to start when the mysql schema has pending migrations (true, false)
+	requireMigrations := os.Getenv("REQUIRE_MIGRATIONS") == "true"
 
 	// app
 	// - config
-	app := application.NewApplicationDefault("", "./docs/db/json/products.json")
+	app := application.NewApplicationDefault("", storage, "./docs/db/json/products.json", requestTimeout, legacyRoutes, requireMigrations)
 	// - tear down
 	defer app.TearDown()
 	// - set up
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"app/docs/db/migrations"
+	"app/platform/migrate"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// migrate manages the versioned schema of the mysql database.
+//
+//	go run ./cmd/migrate up [n]     applies the pending migrations (n of them if given)
+//	go run ./cmd/migrate down [n]   rolls back the last applied migration (n of them if given)
+//	go run ./cmd/migrate status     lists the migrations and whether they are applied
+//
+// The database is set by DB_USER, DB_PASSWORD, DB_HOST and DB_NAME.
+func main() {
+	// env
+	// - args
+	if len(os.Args) < 2 {
+		fmt.Println("usage: migrate up|down|status [n]")
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	var steps int
+	if len(os.Args) > 2 {
+		var err error
+		steps, err = strconv.Atoi(os.Args[2])
+		if err != nil || steps < 0 {
+			fmt.Println("invalid number of migrations:", os.Args[2])
+			os.Exit(2)
+		}
+	}
+
+	// dependencies
+	// - data base
+	configDB := mysql.Config{
+		User:      os.Getenv("DB_USER"),
+		Passwd:    os.Getenv("DB_PASSWORD"),
+		Net:       "tcp",
+		Addr:      os.Getenv("DB_HOST"),
+		DBName:    os.Getenv("DB_NAME"),
+		ParseTime: true,
+	}
+	db, err := sql.Open("mysql", configDB.FormatDSN())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer db.Close()
+	// - migrations
+	fsys, err := migrations.FS()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	ms, err := migrate.Load(fsys)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	mg := migrate.NewMigrator(db, ms)
+
+	// run
+	switch command {
+	case "up":
+		var done []migrate.Migration
+		done, err = mg.Up(steps)
+		for _, m := range done {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+	case "down":
+		var done []migrate.Migration
+		done, err = mg.Down(steps)
+		for _, m := range done {
+			fmt.Printf("rolled back %04d_%s\n", m.Version, m.Name)
+		}
+	case "status":
+		var st []migrate.Status
+		st, err = mg.Status()
+		for _, s := range st {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format(time.DateTime)
+			}
+			if s.Modified {
+				state += " (modified after being applied)"
+			}
+			fmt.Printf("%04d_%s: %s\n", s.Version, s.Name, state)
+		}
+	default:
+		fmt.Println("usage: migrate up|down|status [n]")
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/seed"
+	"app/internal/store"
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// seed moves the product catalogue between the json file store and the mysql database.
+//
+//	go run ./cmd/seed [-file path] [-batch size] [-dry-run] [-reverse] [-reindex]
+//
+// By default the json file is seeded into mysql, matching products by code value.
+// The database must have the schema of docs/db/migrations (go run ./cmd/migrate up).
+// With -reverse the mysql products are dumped into the json file.
+// With -reindex the search terms of the mysql products and their typo keys are written again, for the ones stored
+// before migrations 0004 and 0005.
+func main() {
+	// env
+	// - flags
+	filePath := flag.String("file", "./docs/db/json/products.json", "path of the json file store")
+	batchSize := flag.Int("batch", 100, "products written per transaction")
+	dryRun := flag.Bool("dry-run", false, "run without committing any change")
+	reverse := flag.Bool("reverse", false, "dump the mysql products into the json file")
+	reindex := flag.Bool("reindex", false, "write again the search terms of the mysql products")
+	flag.Parse()
+
+	// dependencies
+	// - store
+	st := store.NewStoreProductJSON(*filePath)
+	// - data base
+	configDB := mysql.Config{
+		User:   os.Getenv("DB_USER"),
+		Passwd: os.Getenv("DB_PASSWORD"),
+		Net:    "tcp",
+		Addr:   os.Getenv("DB_HOST"),
+		DBName: os.Getenv("DB_NAME"),
+	}
+	db, err := sql.Open("mysql", configDB.FormatDSN())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer db.Close()
+	if err = db.Ping(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// run
+	switch {
+	case *reverse:
+		err = dump(db, st, *dryRun)
+	case *reindex:
+		err = index(db, *dryRun)
+	default:
+		err = load(db, st, *batchSize, *dryRun)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// load seeds the products of the json file store into mysql.
+func load(db *sql.DB, st *store.StoreProductJSON, batchSize int, dryRun bool) (err error) {
+	// read products
+	ps, err := st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// seed
+	sd := seed.NewSeederMySQL(db, batchSize, dryRun)
+	r, err := sd.Seed(context.Background(), ps)
+	if err != nil {
+		return
+	}
+
+	fmt.Printf("seeded %d products in %d batches: %d inserted, %d updated (dry run: %t)\n", len(ps), r.Batches, r.Inserted, r.Updated, dryRun)
+	return
+}
+
+// dump writes the mysql products into the json file store.
+func dump(db *sql.DB, st *store.StoreProductJSON, dryRun bool) (err error) {
+	// read products
+	rp := repository.NewRepositoryProductMySql(db)
+	ps, err := rp.FindAll(context.Background())
+	if err != nil {
+		return
+	}
+
+	// write products
+	if !dryRun {
+		p := make(map[int]internal.Product, len(ps))
+		for _, v := range ps {
+			p[v.Id] = v
+		}
+		if err = st.WriteAll(p); err != nil {
+			return
+		}
+	}
+
+	fmt.Printf("dumped %d products into %s (dry run: %t)\n", len(ps), st.Path, dryRun)
+	return
+}
+
+// index writes again the search terms of the mysql products.
+func index(db *sql.DB, dryRun bool) (err error) {
+	rp := repository.NewRepositoryProductMySql(db)
+
+	var n int
+	switch dryRun {
+	case true:
+		var ps []internal.Product
+		ps, err = rp.FindAll(context.Background())
+		n = len(ps)
+	default:
+		n, err = rp.Reindex(context.Background())
+	}
+	if err != nil {
+		return
+	}
+
+	fmt.Printf("indexed %d products for search (dry run: %t)\n", n, dryRun)
+	return
+}
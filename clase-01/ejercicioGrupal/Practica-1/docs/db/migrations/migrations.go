@@ -0,0 +1,17 @@
+// Package migrations embeds the versioned schema migrations of the mysql database.
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed mysql
+var files embed.FS
+
+// FS returns the migrations of the mysql database.
+func FS() (fsys fs.FS, err error) {
+	fsys, err = fs.Sub(files, "mysql")
+	return
+}
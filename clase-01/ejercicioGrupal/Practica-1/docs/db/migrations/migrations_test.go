@@ -0,0 +1,60 @@
+package migrations_test
+
+import (
+	"app/docs/db/migrations"
+	"app/platform/migrate"
+	"app/platform/mysqltest"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for the embedded migrations
+func TestFS(t *testing.T) {
+	t.Run("migrations are reversible", func(t *testing.T) {
+		// arrange
+		fsys, err := migrations.FS()
+		require.NoError(t, err)
+
+		// act
+		ms, err := migrate.Load(fsys)
+
+		// assert
+		require.NoError(t, err)
+		require.NotEmpty(t, ms)
+		for i, m := range ms {
+			require.Equal(t, i+1, m.Version)
+			require.NotEmpty(t, m.Down, "migration %d has no down file", m.Version)
+		}
+	})
+
+	t.Run("migrations apply and roll back", func(t *testing.T) {
+		// arrange
+		s, err := mysqltest.NewServer()
+		require.NoError(t, err)
+		defer s.Close()
+		cfg, err := s.NewDatabase()
+		require.NoError(t, err)
+		db, err := sql.Open("mysql", cfg.FormatDSN())
+		require.NoError(t, err)
+		defer db.Close()
+		fsys, err := migrations.FS()
+		require.NoError(t, err)
+		ms, err := migrate.Load(fsys)
+		require.NoError(t, err)
+		mg := migrate.NewMigrator(db, ms)
+
+		// act
+		up, errUp := mg.Up(0)
+		errCheck := mg.CheckUpToDate()
+		down, errDown := mg.Down(len(ms))
+
+		// assert
+		require.NoError(t, errUp)
+		require.Len(t, up, len(ms))
+		require.NoError(t, errCheck)
+		require.NoError(t, errDown)
+		require.Len(t, down, len(ms))
+	})
+}
@@ -0,0 +1,11 @@
+-- Crear tabla products donde se almacenan los productos, la de build_database.sql con su clave primaria
+CREATE TABLE `products` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `quantity` int NOT NULL,
+  `code_value` varchar(255) NOT NULL,
+  `is_published` boolean NOT NULL,
+  `expiration` date NOT NULL,
+  `price` decimal(10, 2) NOT NULL,
+  PRIMARY KEY (`id`)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE `products` DROP FOREIGN KEY `fk_products_warehouses`;
+ALTER TABLE `products` DROP COLUMN `id_warehouse`;
+DROP TABLE `warehouses`;
@@ -0,0 +1,19 @@
+-- Crear tabla warehouses donde se almacenan datos de los almacenes de products
+CREATE TABLE `warehouses` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `adress` varchar(150) NOT NULL,
+  `telephone` varchar(150) NOT NULL,
+  `capacity` int NOT NULL,
+  PRIMARY KEY (`id`)
+);
+
+-- Almacen por defecto
+INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES
+(1, 'Main Warehouse', '221 Baker Street', '4555666', 100);
+
+-- Se agrega una columna para el id del warehouse a la tabla products, asignada al almacen por defecto
+ALTER TABLE `products` ADD `id_warehouse` int NOT NULL DEFAULT 1 AFTER `price`;
+ALTER TABLE `products` ALTER `id_warehouse` DROP DEFAULT;
+ALTER TABLE `products`
+  ADD CONSTRAINT `fk_products_warehouses` FOREIGN KEY (`id_warehouse`) REFERENCES `warehouses` (`id`);
@@ -0,0 +1,2 @@
+-- El code_value identifica a cada producto (importaciones y seeds hacen upsert por code_value)
+ALTER TABLE `products` ADD UNIQUE KEY `uq_products_code_value` (`code_value`);
@@ -0,0 +1,10 @@
+-- Crear tabla product_search_terms con los terminos del nombre y el code_value de cada producto,
+-- el indice de busqueda de GET /products/search: sus terminos en minuscula y sin acentos (platform/search)
+-- Los escribe el repositorio con cada producto, los productos guardados antes se indexan con go run ./cmd/seed -reindex
+CREATE TABLE `product_search_terms` (
+  `term` varchar(255) COLLATE utf8mb4_bin NOT NULL,
+  `id_product` int NOT NULL,
+  PRIMARY KEY (`term`, `id_product`),
+  KEY `idx_product_search_terms_product` (`id_product`),
+  CONSTRAINT `fk_product_search_terms_products` FOREIGN KEY (`id_product`) REFERENCES `products` (`id`) ON DELETE CASCADE
+);
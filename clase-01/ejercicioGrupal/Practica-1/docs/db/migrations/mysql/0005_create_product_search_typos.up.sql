@@ -0,0 +1,13 @@
+-- Crear tabla product_search_typos con las variantes de cada termino de product_search_terms: el termino sin una letra,
+-- o sin dos desde 6 letras (search.TypoKeys). Una busqueda con un error de tipeo encuentra sus terminos por el indice
+-- de la variante en vez de recorrer todos los terminos
+-- Las escribe el repositorio con los terminos de cada producto, en la misma transaccion; los productos guardados antes
+-- se indexan con go run ./cmd/seed -reindex
+CREATE TABLE `product_search_typos` (
+  `variant` varchar(255) COLLATE utf8mb4_bin NOT NULL,
+  `term` varchar(255) COLLATE utf8mb4_bin NOT NULL,
+  `id_product` int NOT NULL,
+  PRIMARY KEY (`variant`, `term`, `id_product`),
+  KEY `idx_product_search_typos_product` (`id_product`),
+  CONSTRAINT `fk_product_search_typos_products` FOREIGN KEY (`id_product`) REFERENCES `products` (`id`) ON DELETE CASCADE
+);
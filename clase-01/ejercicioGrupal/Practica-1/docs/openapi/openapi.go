@@ -0,0 +1,42 @@
+// Package openapi embeds the OpenAPI 3 document of the api and the page that renders it.
+// The page has no external assets, so the docs work offline.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+var (
+	// Spec is the OpenAPI document of the api.
+	//go:embed openapi.json
+	Spec []byte
+	// Page is the docs page, it renders the document served at /openapi.json.
+	//go:embed index.html
+	Page []byte
+)
+
+// Operations returns the methods of each path of the document (e.g. /products/{id}: [GET PATCH]).
+func Operations() (ops map[string][]string, err error) {
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	err = json.Unmarshal(Spec, &doc)
+	if err != nil {
+		return
+	}
+
+	ops = make(map[string][]string)
+	for path, item := range doc.Paths {
+		for method := range item {
+			switch method {
+			case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+				ops[path] = append(ops[path], strings.ToUpper(method))
+			}
+		}
+		sort.Strings(ops[path])
+	}
+	return
+}
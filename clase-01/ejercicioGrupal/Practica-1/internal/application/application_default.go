@@ -1,31 +1,59 @@
 package application
 
 import (
+	"app/docs/db/migrations"
+	"app/internal"
 	"app/internal/handler"
 	"app/internal/repository"
+	"app/internal/service"
+	"app/internal/store"
+	"app/platform/migrate"
+	"app/platform/web/request"
+	"app/platform/web/response"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-sql-driver/mysql"
 )
 
+const (
+	// StorageMySQL keeps the products in the mysql database set by the DB_* environment variables.
+	StorageMySQL = "mysql"
+	// StorageJSON keeps the products in the json file store.
+	StorageJSON = "json"
+)
+
 // NewApplicationDefault creates a new default application.
-func NewApplicationDefault(addr, filePathStore string) (a *ApplicationDefault) {
+// storage is where the products are kept, StorageMySQL or StorageJSON at filePathStore.
+// A zero requestTimeout defaults to 30 seconds, a negative one sets no deadline to the requests.
+// legacyRoutes keeps the routes before /api/v1 at the root, with their former bodies.
+// requireMigrations makes SetUp fail when the mysql schema has pending or modified migrations.
+func NewApplicationDefault(addr, storage, filePathStore string, requestTimeout time.Duration, legacyRoutes, requireMigrations bool) (a *ApplicationDefault) {
 	// default config
 	defaultRouter := chi.NewRouter()
 	defaultAddr := ":8080"
 	if addr != "" {
 		defaultAddr = addr
 	}
+	defaultRequestTimeout := 30 * time.Second
+	if requestTimeout != 0 {
+		defaultRequestTimeout = requestTimeout
+	}
 
 	a = &ApplicationDefault{
-		rt:            defaultRouter,
-		addr:          defaultAddr,
-		filePathStore: filePathStore,
+		rt:                defaultRouter,
+		addr:              defaultAddr,
+		storage:           storage,
+		filePathStore:     filePathStore,
+		requestTimeout:    defaultRequestTimeout,
+		legacyRoutes:      legacyRoutes,
+		requireMigrations: requireMigrations,
 	}
 	return
 }
@@ -36,8 +64,16 @@ type ApplicationDefault struct {
 	rt *chi.Mux
 	// addr is the address to listen.
 	addr string
+	// storage is where the products are kept: StorageMySQL or StorageJSON.
+	storage string
 	// filePathStore is the file path to store.
 	filePathStore string
+	// requestTimeout is the deadline of each request, past it its queries are canceled and it responds 504.
+	requestTimeout time.Duration
+	// legacyRoutes tells if the routes before /api/v1 are kept.
+	legacyRoutes bool
+	// requireMigrations tells if the mysql schema must be up to date with docs/db/migrations to set up.
+	requireMigrations bool
 }
 
 // TearDown tears down the application.
@@ -48,40 +84,118 @@ func (a *ApplicationDefault) TearDown() (err error) {
 // SetUp sets up the application.
 func (a *ApplicationDefault) SetUp() (err error) {
 	// dependencies
-	// - store
-	//st := store.NewStoreProductJSON(a.filePathStore)
-	// - data base
-	// - repository
-	// rp := repository.NewRepositoryProductStore(st)
-	configDB := mysql.Config{
-		User:   os.Getenv("DB_USER"),
-		Passwd: os.Getenv("DB_PASSWORD"),
-		Net:    "tcp",
-		Addr:   os.Getenv("DB_HOST"),
-		DBName: os.Getenv("DB_NAME"),
-	}
+	// - unit of work of the repository: the storage chosen, never a fallback to another one
+	var uow internal.UnitOfWork
+	switch a.storage {
+	case StorageJSON:
+		// - store
+		if a.filePathStore == "" {
+			err = errors.New("application: json storage without a file path")
+			return
+		}
+		st := store.NewStoreProductJSON(a.filePathStore)
+		uow = repository.NewUnitOfWorkStore(st)
+	case StorageMySQL:
+		if os.Getenv("DB_HOST") == "" {
+			err = errors.New("application: mysql storage without a database host (DB_HOST)")
+			return
+		}
 
-	db, err := sql.Open("mysql", configDB.FormatDSN())
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	// Ping to check if the database is up
-	if err = db.Ping(); err != nil {
-		fmt.Println(err)
+		// - data base
+		configDB := mysql.Config{
+			User:   os.Getenv("DB_USER"),
+			Passwd: os.Getenv("DB_PASSWORD"),
+			Net:    "tcp",
+			Addr:   os.Getenv("DB_HOST"),
+			DBName: os.Getenv("DB_NAME"),
+			// - the default of the driver, lost by a config literal
+			AllowNativePasswords: true,
+		}
+
+		var db *sql.DB
+		db, err = sql.Open("mysql", configDB.FormatDSN())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		// Ping to check if the database is up
+		if err = db.Ping(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		// - schema
+		if a.requireMigrations {
+			if err = checkMigrations(configDB); err != nil {
+				return
+			}
+		}
+		uow = repository.NewUnitOfWorkMySql(db)
+	default:
+		err = fmt.Errorf("application: unknown storage %q, it must be %s or %s", a.storage, StorageMySQL, StorageJSON)
 		return
 	}
-	// repository
-	rp := repository.NewRepositoryProductMySql(db)
+	// - service
+	sv := service.NewServiceProductDefault(uow)
 	// - handler
-	hd := handler.NewHandlerProduct(rp)
+	hd := handler.NewHandlerProduct(sv)
 
 	// router
 	// - middlewares
+	a.rt.Use(request.ID)
+	a.rt.Use(request.Language)
 	a.rt.Use(middleware.Logger)
 	a.rt.Use(middleware.Recoverer)
-	// - endpoints
-	a.rt.Route("/products", func(r chi.Router) {
+	a.rt.Use(request.Deadline(a.requestTimeout))
+	a.rt.Use(response.Negotiated)
+	// - endpoints: api v1, every response in an envelope
+	a.rt.Route("/api/v1", func(r chi.Router) {
+		r.Use(response.Enveloped)
+		routesProduct(r, hd)
+	})
+	// - endpoints: legacy, the routes before the api v1
+	if a.legacyRoutes {
+		routesProduct(a.rt, hd)
+	}
+	// - docs
+	// GET /openapi.json
+	a.rt.Get("/openapi.json", handler.OpenAPI())
+	// GET /docs
+	a.rt.Get("/docs", handler.Docs())
+
+	return
+}
+
+// checkMigrations returns an error when the schema of the database of cfg is not up to date with the embedded migrations.
+// It opens its own connection, since the migrator reads times the products connection leaves as text.
+func checkMigrations(cfg mysql.Config) (err error) {
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		return
+	}
+	defer db.Close()
+
+	fsys, err := migrations.FS()
+	if err != nil {
+		return
+	}
+	ms, err := migrate.Load(fsys)
+	if err != nil {
+		return
+	}
+	err = migrate.NewMigrator(db, ms).CheckUpToDate()
+	return
+}
+
+// routesProduct registers the endpoints of the products.
+func routesProduct(rt chi.Router, hd *handler.HandlerProduct) {
+	rt.Route("/products", func(r chi.Router) {
+		// GET /products/export
+		r.Get("/export", hd.Export())
+		// POST /products/import
+		r.Post("/import", hd.Import())
+		// GET /products/search
+		r.Get("/search", hd.Search())
 		// GET /products/{id}
 		r.Get("/{id}", hd.GetById())
 		// POST /products
@@ -93,8 +207,6 @@ func (a *ApplicationDefault) SetUp() (err error) {
 		// DELETE /products/{id}
 		r.Delete("/{id}", hd.Delete())
 	})
-
-	return
 }
 
 // Run runs the application.
@@ -0,0 +1,351 @@
+package application
+
+import (
+	"app/docs/db/migrations"
+	"app/docs/openapi"
+	"app/platform/migrate"
+	"app/platform/mysqltest"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// setUp returns an application set up with an empty json file store.
+func setUp(t *testing.T, legacyRoutes bool) (a *ApplicationDefault) {
+	path := filepath.Join(t.TempDir(), "products.json")
+	require.NoError(t, os.WriteFile(path, []byte("[]"), 0644))
+	a = NewApplicationDefault("", StorageJSON, path, 0, legacyRoutes, false)
+	require.NoError(t, a.SetUp())
+	return
+}
+
+// registered returns the methods of each route of a router, in the path format of the OpenAPI document.
+func registered(t *testing.T, rt chi.Routes) (ops map[string][]string) {
+	ops = make(map[string][]string)
+	err := chi.Walk(rt, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		ops[route] = append(ops[route], method)
+		return nil
+	})
+	require.NoError(t, err)
+	for route := range ops {
+		sort.Strings(ops[route])
+	}
+	return
+}
+
+// Tests for SetUp function
+func TestApplicationDefault_SetUp(t *testing.T) {
+	t.Run("failure - the storage is not chosen, none is used instead", func(t *testing.T) {
+		// arrange
+		a := NewApplicationDefault("", "", filepath.Join(t.TempDir(), "products.json"), 0, false, false)
+
+		// act
+		err := a.SetUp()
+
+		// assert
+		require.ErrorContains(t, err, "unknown storage")
+	})
+
+	t.Run("failure - the mysql storage without a database host", func(t *testing.T) {
+		// arrange
+		t.Setenv("DB_HOST", "")
+		a := NewApplicationDefault("", StorageMySQL, "", 0, false, false)
+
+		// act
+		err := a.SetUp()
+
+		// assert
+		require.ErrorContains(t, err, "DB_HOST")
+	})
+
+	t.Run("failure - the mysql schema has pending migrations and they are required", func(t *testing.T) {
+		// arrange
+		s, err := mysqltest.NewServer()
+		require.NoError(t, err)
+		defer s.Close()
+		cfg, err := s.NewDatabase()
+		require.NoError(t, err)
+		t.Setenv("DB_HOST", cfg.Addr)
+		t.Setenv("DB_USER", cfg.User)
+		t.Setenv("DB_PASSWORD", cfg.Passwd)
+		t.Setenv("DB_NAME", cfg.DBName)
+		required := NewApplicationDefault("", StorageMySQL, "", 0, false, true)
+		notRequired := NewApplicationDefault("", StorageMySQL, "", 0, false, false)
+
+		// act
+		err = required.SetUp()
+		errNotRequired := notRequired.SetUp()
+
+		// assert
+		require.ErrorIs(t, err, migrate.ErrSchemaBehind)
+		require.NoError(t, errNotRequired)
+	})
+
+	t.Run("success - the mysql schema is up to date with the migrations required", func(t *testing.T) {
+		// arrange
+		s, err := mysqltest.NewServer()
+		require.NoError(t, err)
+		defer s.Close()
+		cfg, err := s.NewDatabase()
+		require.NoError(t, err)
+		db, err := sql.Open("mysql", cfg.FormatDSN())
+		require.NoError(t, err)
+		defer db.Close()
+		fsys, err := migrations.FS()
+		require.NoError(t, err)
+		ms, err := migrate.Load(fsys)
+		require.NoError(t, err)
+		_, err = migrate.NewMigrator(db, ms).Up(0)
+		require.NoError(t, err)
+		t.Setenv("DB_HOST", cfg.Addr)
+		t.Setenv("DB_USER", cfg.User)
+		t.Setenv("DB_PASSWORD", cfg.Passwd)
+		t.Setenv("DB_NAME", cfg.DBName)
+		a := NewApplicationDefault("", StorageMySQL, "", 0, false, true)
+
+		// act
+		err = a.SetUp()
+
+		// assert
+		require.NoError(t, err)
+	})
+
+	t.Run("success - every registered route is documented", func(t *testing.T) {
+		// arrange
+		a := setUp(t, true)
+		documented, err := openapi.Operations()
+		require.NoError(t, err)
+
+		// act
+		ops := registered(t, a.rt)
+
+		// assert
+		for route, methods := range ops {
+			for _, method := range methods {
+				require.Contains(t, documented[route], method, "%s %s is missing from docs/openapi/openapi.json", method, route)
+			}
+		}
+	})
+
+	t.Run("success - every documented operation is registered", func(t *testing.T) {
+		// arrange
+		a := setUp(t, true)
+
+		// act
+		documented, err := openapi.Operations()
+		require.NoError(t, err)
+
+		// assert
+		ops := registered(t, a.rt)
+		for route, methods := range documented {
+			for _, method := range methods {
+				require.Contains(t, ops[route], method, "%s %s is documented but not registered", method, route)
+			}
+		}
+	})
+
+	t.Run("success - the document and the docs page are served", func(t *testing.T) {
+		// arrange
+		a := setUp(t, true)
+
+		// act
+		resSpec := httptest.NewRecorder()
+		a.rt.ServeHTTP(resSpec, httptest.NewRequest("GET", "/openapi.json", nil))
+		resDocs := httptest.NewRecorder()
+		a.rt.ServeHTTP(resDocs, httptest.NewRequest("GET", "/docs", nil))
+
+		// assert
+		require.Equal(t, http.StatusOK, resSpec.Code)
+		require.Equal(t, "application/json", resSpec.Header().Get("Content-Type"))
+		require.JSONEq(t, string(openapi.Spec), resSpec.Body.String())
+		require.Equal(t, http.StatusOK, resDocs.Code)
+		require.Contains(t, resDocs.Body.String(), `fetch("openapi.json")`)
+	})
+
+	t.Run("success - the legacy routes are registered only behind their flag", func(t *testing.T) {
+		// arrange
+		a := setUp(t, false)
+
+		// act
+		ops := registered(t, a.rt)
+
+		// assert
+		require.Contains(t, ops, "/api/v1/products/{id}")
+		require.NotContains(t, ops, "/products/{id}")
+	})
+
+	t.Run("400 - the api v1 writes its errors in an envelope, the legacy routes as problems", func(t *testing.T) {
+		// arrange
+		a := setUp(t, true)
+
+		// act
+		resV1 := httptest.NewRecorder()
+		reqV1 := httptest.NewRequest("GET", "/api/v1/products/abc", nil)
+		reqV1.Header.Set("X-Request-Id", "id")
+		a.rt.ServeHTTP(resV1, reqV1)
+		resLegacy := httptest.NewRecorder()
+		reqLegacy := httptest.NewRequest("GET", "/products/abc", nil)
+		reqLegacy.Header.Set("X-Request-Id", "id")
+		a.rt.ServeHTTP(resLegacy, reqLegacy)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, resV1.Code)
+		require.Equal(t, "application/json", resV1.Header().Get("Content-Type"))
+		require.JSONEq(t, `{"data":null,"meta":{"request_id":"id"},"errors":[{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid id","code":"bad_request"}]}`, resV1.Body.String())
+		require.Equal(t, http.StatusBadRequest, resLegacy.Code)
+		require.Equal(t, "application/problem+json", resLegacy.Header().Get("Content-Type"))
+		require.JSONEq(t, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid id","code":"bad_request","request_id":"id"}`, resLegacy.Body.String())
+	})
+
+	t.Run("201 - the api v1 writes the created product in an envelope", func(t *testing.T) {
+		// arrange
+		a := setUp(t, false)
+		body := `{"name":"product","quantity":1,"code_value":"A1","is_published":true,"expiration":"2999-01-01","price":1.5}`
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/v1/products", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-Id", "id")
+		a.rt.ServeHTTP(res, req)
+
+		// assert
+		require.Equal(t, http.StatusCreated, res.Code)
+		require.JSONEq(t, `{
+			"data": {"id":1,"name":"product","quantity":1,"code_value":"A1","is_published":true,"expiration":"2999-01-01","price":1.5},
+			"meta": {"message":"success","request_id":"id"},
+			"links": {"self":"/api/v1/products/1"}
+		}`, res.Body.String())
+	})
+	t.Run("200 - the api v1 searches the products written, highlighting the parts matched", func(t *testing.T) {
+		// arrange
+		a := setUp(t, false)
+		for _, body := range []string{
+			`{"name":"Chocolate & Bar","quantity":1,"code_value":"42957-002","expiration":"2999-01-01","price":1.5}`,
+			`{"name":"Cookies - Oreo","quantity":1,"code_value":"54868-6276","expiration":"2999-01-01","price":1.5}`,
+		} {
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/v1/products", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			a.rt.ServeHTTP(res, req)
+			require.Equal(t, http.StatusCreated, res.Code)
+		}
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/products/search?q=choclate+42957", nil)
+		a.rt.ServeHTTP(res, req)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		var body struct {
+			Data []struct {
+				Id        int     `json:"id"`
+				Score     float64 `json:"score"`
+				Highlight struct {
+					Name      string `json:"name"`
+					CodeValue string `json:"code_value"`
+				} `json:"highlight"`
+			} `json:"data"`
+			Meta struct {
+				Count int `json:"count"`
+			} `json:"meta"`
+		}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+		require.Equal(t, 1, body.Meta.Count)
+		require.Len(t, body.Data, 1)
+		require.Equal(t, 1, body.Data[0].Id)
+		require.Positive(t, body.Data[0].Score)
+		require.Equal(t, "<mark>Chocolate</mark> &amp; Bar", body.Data[0].Highlight.Name)
+		require.Equal(t, "<mark>42957</mark>-002", body.Data[0].Highlight.CodeValue)
+	})
+
+	t.Run("400 - the search query is missing", func(t *testing.T) {
+		// arrange
+		a := setUp(t, false)
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/products/search?q=+", nil)
+		a.rt.ServeHTTP(res, req)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Contains(t, res.Body.String(), "missing search query")
+	})
+}
+
+// Tests for the preconditions of the writes of a product
+func TestApplicationDefault_IfMatch(t *testing.T) {
+	// create creates a product and returns its entity tag.
+	create := func(t *testing.T, a *ApplicationDefault) (etag string) {
+		body := `{"name":"product","quantity":1,"code_value":"A1","is_published":true,"expiration":"2999-01-01","price":1.5}`
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/v1/products", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		a.rt.ServeHTTP(res, req)
+		require.Equal(t, http.StatusCreated, res.Code)
+
+		res = httptest.NewRecorder()
+		a.rt.ServeHTTP(res, httptest.NewRequest("GET", "/api/v1/products/1", nil))
+		require.Equal(t, http.StatusOK, res.Code)
+		etag = res.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+		return
+	}
+
+	t.Run("412 - a patch with the entity tag of a former product leaves it as it is", func(t *testing.T) {
+		// arrange
+		a := setUp(t, false)
+		etag := create(t, a)
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("PATCH", "/api/v1/products/1", strings.NewReader(`{"quantity":2}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", etag)
+		a.rt.ServeHTTP(res, req)
+		require.Equal(t, http.StatusOK, res.Code)
+
+		// act
+		res = httptest.NewRecorder()
+		req = httptest.NewRequest("PATCH", "/api/v1/products/1", strings.NewReader(`{"quantity":3}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", etag)
+		a.rt.ServeHTTP(res, req)
+
+		// assert
+		require.Equal(t, http.StatusPreconditionFailed, res.Code)
+		res = httptest.NewRecorder()
+		a.rt.ServeHTTP(res, httptest.NewRequest("GET", "/api/v1/products/1", nil))
+		require.Contains(t, res.Body.String(), `"quantity":2`)
+	})
+
+	t.Run("204 - a delete with the entity tag of the current product deletes it", func(t *testing.T) {
+		// arrange
+		a := setUp(t, false)
+		etag := create(t, a)
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("DELETE", "/api/v1/products/1", nil)
+		req.Header.Set("If-Match", etag)
+		a.rt.ServeHTTP(res, req)
+
+		// assert
+		require.Equal(t, http.StatusNoContent, res.Code)
+		res = httptest.NewRecorder()
+		a.rt.ServeHTTP(res, httptest.NewRequest("GET", "/api/v1/products/1", nil))
+		require.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
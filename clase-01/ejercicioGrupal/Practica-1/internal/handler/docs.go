@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"app/docs/openapi"
+	"net/http"
+)
+
+// OpenAPI returns the OpenAPI document of the api.
+func OpenAPI() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(openapi.Spec)
+	}
+}
+
+// Docs returns the docs page of the api, it renders the OpenAPI document.
+func Docs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write(openapi.Page)
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/validate"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	// errProductModified is returned when the product of a request does not match the version the client has.
+	errProductModified = errors.New("handler: product has been modified")
+)
+
+// problemKind is the kind of problem of the response of an error.
+type problemKind struct {
+	err    error
+	status int
+	code   string
+	title  string
+}
+
+// problemKinds maps the domain errors to the kind of problem of their response, the first one matched with errors.Is wins.
+var problemKinds = []problemKind{
+	// - product
+	{internal.ErrServiceProductInvalid, http.StatusUnprocessableEntity, "product_invalid", "Product breaks a validation rule"},
+	{internal.ErrRepositoryProductNotFound, http.StatusNotFound, "product_not_found", "Product not found"},
+	{internal.ErrRepositoryProductNotUnique, http.StatusConflict, "product_not_unique", "Product not unique"},
+	{errProductModified, http.StatusPreconditionFailed, "product_version_conflict", "Product has been modified"},
+	{internal.ErrRepositoryProductModified, http.StatusPreconditionFailed, "product_version_conflict", "Product has been modified"},
+	// - request
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, "request_timeout", "Request timed out"},
+	{context.Canceled, response.StatusClientClosedRequest, "client_closed_request", "Client closed request"},
+}
+
+// problemOf returns the problem of an error: the kind of its domain error, with the fields that break a rule in the language of the response when it is invalid.
+// Any other error is an internal error without details, as they could leak the internals of the storage.
+func problemOf(w http.ResponseWriter, err error) (p response.Problem) {
+	// request body that breaks a rule
+	var errs validate.Errors
+	if errors.As(err, &errs) {
+		p = response.Problem{Status: http.StatusUnprocessableEntity, Code: "invalid_body", Title: "Request body breaks a validation rule", Errors: fieldErrors(w, err)}
+		return
+	}
+
+	// domain error
+	for _, k := range problemKinds {
+		if errors.Is(err, k.err) {
+			p = response.Problem{Status: k.status, Code: k.code, Title: k.title}
+			var fieldErr *internal.FieldError
+			if errors.As(err, &fieldErr) {
+				p.Detail, p.Errors = fieldMessage(w, err), fieldErrors(w, err)
+			}
+			return
+		}
+	}
+
+	// internal error
+	p = response.Problem{Status: http.StatusInternalServerError, Code: "internal_error", Title: "Internal server error"}
+	return
+}
+
+// writeError writes the problem of an error.
+func writeError(w http.ResponseWriter, err error) {
+	response.WriteProblem(w, problemOf(w, err))
+}
+
+// writeBodyError writes the problem of a request body that can not be decoded: 415 when its content type is not supported, otherwise 400.
+func writeBodyError(w http.ResponseWriter, err error) {
+	if errors.Is(err, request.ErrRequestContentTypeNotSupported) {
+		response.Error(w, http.StatusUnsupportedMediaType, "unsupported content type")
+		return
+	}
+	response.Error(w, http.StatusBadRequest, "invalid body")
+}
+
+// fieldMessage returns the message of a validation error in the language of the response: the field and the rule it breaks.
+func fieldMessage(w http.ResponseWriter, err error) (message string) {
+	var fieldErr *internal.FieldError
+	if errors.As(err, &fieldErr) {
+		message = response.Localize(w, "%[1]s "+fieldErr.Message, fieldErr.Field)
+		return
+	}
+	message = err.Error()
+	return
+}
+
+// fieldErrors returns the field errors of a validation error in the language of the response,
+// either the rules broken by a request body or the one broken by a product in the service.
+func fieldErrors(w http.ResponseWriter, err error) (errs validate.Errors) {
+	var bodyErrs validate.Errors
+	var fieldErr *internal.FieldError
+	switch {
+	case errors.As(err, &bodyErrs):
+		errs = make(validate.Errors, len(bodyErrs))
+		for i, e := range bodyErrs {
+			errs[i] = e.Localize(func(format string, args ...any) string { return response.Localize(w, format, args...) })
+		}
+	case errors.As(err, &fieldErr):
+		errs = validate.Errors{{Field: fieldErr.Field, Code: fieldErr.Code, Message: fieldMessage(w, err)}}
+	default:
+		errs = validate.Errors{{Message: err.Error()}}
+	}
+	return
+}
@@ -0,0 +1,50 @@
+package handler
+
+import "app/platform/i18n"
+
+// init registers the Spanish messages of the handlers.
+func init() {
+	i18n.Register(i18n.Spanish, messagesES)
+}
+
+// messagesES are the Spanish translations of the messages of the handlers, keyed by the message in English.
+var messagesES = map[string]string{
+	// problems
+	"Product breaks a validation rule":      "El producto no cumple una regla de validación",
+	"Product not found":                     "Producto no encontrado",
+	"Product not unique":                    "El producto no es único",
+	"Product has been modified":             "El producto fue modificado",
+	"Request body breaks a validation rule": "El cuerpo de la solicitud no cumple una regla de validación",
+	"Request timed out":                     "Se agotó el tiempo de la solicitud",
+	"Client closed request":                 "El cliente cerró la solicitud",
+	"Internal server error":                 "Error interno del servidor",
+	// rules of the service
+	"%[1]s is required":          "%[1]s es obligatorio",
+	"%[1]s must not be negative": "%[1]s no debe ser negativo",
+	"%[1]s has passed, an expired product can not be published": "%[1]s ya pasó, un producto vencido no puede publicarse",
+	// request
+	"invalid id":               "id inválido",
+	"invalid body":             "cuerpo inválido",
+	"invalid expiration":       "vencimiento inválido",
+	"unsupported patch format": "formato de patch no soportado",
+	"patch test failed":        "falló la prueba del patch",
+	"unsupported format":       "formato no soportado",
+	"unsupported content type": "tipo de contenido no soportado",
+	"missing search query":     "falta la consulta de búsqueda",
+	"invalid limit":            "límite inválido",
+	// products
+	"success":               "éxito",
+	"internal server error": "error interno del servidor",
+	"product not unique":    "el producto no es único",
+	// csv
+	"csv file missing":                       "falta el archivo csv",
+	"csv header missing":                     "falta el encabezado del csv",
+	"csv column %s missing":                  "falta la columna %s del csv",
+	"csv file too large":                     "archivo csv demasiado grande",
+	"invalid csv file":                       "archivo csv inválido",
+	"invalid csv row":                        "fila del csv inválida",
+	"quantity must be an integer":            "quantity debe ser un entero",
+	"is_published must be a boolean":         "is_published debe ser un booleano",
+	"expiration must be a date (YYYY-MM-DD)": "expiration debe ser una fecha (AAAA-MM-DD)",
+	"price must be a number":                 "price debe ser un número",
+}
@@ -2,10 +2,15 @@ package handler
 
 import (
 	"app/internal"
+	"app/platform/validate"
 	"app/platform/web/request"
 	"app/platform/web/response"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"path"
 	"strconv"
 	"time"
 
@@ -13,17 +18,17 @@ import (
 )
 
 // NewHandlerProduct creates a new handler for products.
-func NewHandlerProduct(rp internal.RepositoryProduct) (h *HandlerProduct) {
+func NewHandlerProduct(sv internal.ServiceProduct) (h *HandlerProduct) {
 	h = &HandlerProduct{
-		rp: rp,
+		sv: sv,
 	}
 	return
 }
 
 // HandlerProduct is a handler for products.
 type HandlerProduct struct {
-	// rp is the repository for products.
-	rp internal.RepositoryProduct
+	// sv is the service for products.
+	sv internal.ServiceProduct
 }
 
 // ProductJSON is a product in JSON format.
@@ -37,6 +42,57 @@ type ProductJSON struct {
 	Price       float64 `json:"price"`
 }
 
+// productETag returns the entity tag of a product, a hash of its JSON representation.
+func productETag(p internal.Product) (etag string) {
+	bytes, _ := json.Marshal(ProductJSON{
+		Id:          p.Id,
+		Name:        p.Name,
+		Quantity:    p.Quantity,
+		CodeValue:   p.CodeValue,
+		IsPublished: p.IsPublished,
+		Expiration:  p.Expiration.Format(time.DateOnly),
+		Price:       p.Price,
+	})
+	etag = fmt.Sprintf("\"%x\"", sha256.Sum256(bytes))
+	return
+}
+
+// checkIfMatch checks the If-Match precondition of the request against the current product.
+// It returns the product the precondition matched, for the write to fail if it changes meanwhile,
+// or nil when the request has no precondition or one matching any product (*).
+// It writes the error response and returns false when the precondition fails.
+func (h *HandlerProduct) checkIfMatch(w http.ResponseWriter, r *http.Request, id int) (expected *internal.Product, ok bool) {
+	// no precondition
+	if r.Header.Get("If-Match") == "" {
+		ok = true
+		return
+	}
+
+	// find current product
+	p, err := h.sv.FindById(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrRepositoryProductNotFound):
+			writeError(w, errProductModified)
+		default:
+			writeError(w, err)
+		}
+		return
+	}
+
+	// compare
+	if !request.IfMatch(r, productETag(p)) {
+		writeError(w, errProductModified)
+		return
+	}
+	if r.Header.Get("If-Match") != "*" {
+		expected = &p
+	}
+
+	ok = true
+	return
+}
+
 // GetById gets a product by id.
 func (h *HandlerProduct) GetById() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -44,20 +100,21 @@ func (h *HandlerProduct) GetById() http.HandlerFunc {
 		// - path parameter: id
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid id")
+			response.Error(w, http.StatusBadRequest, "invalid id")
 			return
 		}
 
 		// process
 		// - find product by id
-		p, err := h.rp.FindById(id)
+		p, err := h.sv.FindById(r.Context(), id)
 		if err != nil {
-			switch {
-			case errors.Is(err, internal.ErrRepositoryProductNotFound):
-				response.JSON(w, http.StatusNotFound, "product not found")
-			default:
-				response.JSON(w, http.StatusInternalServerError, "internal server error")
-			}
+			writeError(w, err)
+			return
+		}
+		// - check the client cached version
+		etag := productETag(p)
+		if request.IfNoneMatch(r, etag) {
+			response.NotModified(w, etag)
 			return
 		}
 
@@ -72,21 +129,34 @@ func (h *HandlerProduct) GetById() http.HandlerFunc {
 			Expiration:  p.Expiration.Format(time.DateOnly),
 			Price:       p.Price,
 		}
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "success",
-			"data":    data,
+		response.ETag(w, etag)
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "success"),
+			Data:    data,
+			Links:   map[string]string{"self": r.URL.Path},
 		})
 	}
 }
 
 // RequestBodyProductCreate is a request body for creating a product.
 type RequestBodyProductCreate struct {
-	Name        string  `json:"name"`
-	Quantity    int     `json:"quantity"`
-	CodeValue   string  `json:"code_value"`
+	Name        string  `json:"name" validate:"required"`
+	Quantity    int     `json:"quantity" validate:"min=0"`
+	CodeValue   string  `json:"code_value" validate:"required"`
 	IsPublished bool    `json:"is_published"`
-	Expiration  string  `json:"expiration"`
-	Price       float64 `json:"price"`
+	Expiration  string  `json:"expiration" validate:"required,date,notpast"`
+	Price       float64 `json:"price" validate:"min=0"`
+}
+
+// RequestBodyProductUpdate is the request body of a product to update or replace.
+// Unlike a new product, an existing one may keep an expiration that has passed.
+type RequestBodyProductUpdate struct {
+	Name        string  `json:"name" validate:"required"`
+	Quantity    int     `json:"quantity" validate:"min=0"`
+	CodeValue   string  `json:"code_value" validate:"required"`
+	IsPublished bool    `json:"is_published"`
+	Expiration  string  `json:"expiration" validate:"required,date"`
+	Price       float64 `json:"price" validate:"min=0"`
 }
 
 // Create creates a product.
@@ -95,15 +165,20 @@ func (h *HandlerProduct) Create() http.HandlerFunc {
 		// request
 		// - body
 		var body RequestBodyProductCreate
-		err := request.JSON(r, &body)
+		err := request.Body(r, &body)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid body")
+			writeBodyError(w, err)
+			return
+		}
+		err = validate.Struct(body)
+		if err != nil {
+			writeError(w, err)
 			return
 		}
 		// - expiration
 		exp, err := time.Parse(time.DateOnly, body.Expiration)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid expiration")
+			response.Error(w, http.StatusBadRequest, "invalid expiration")
 			return
 		}
 
@@ -119,9 +194,9 @@ func (h *HandlerProduct) Create() http.HandlerFunc {
 				Price:       body.Price,
 			},
 		}
-		err = h.rp.Save(&p)
+		err = h.sv.Create(r.Context(), &p)
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, "internal server error")
+			writeError(w, err)
 			return
 		}
 
@@ -136,9 +211,11 @@ func (h *HandlerProduct) Create() http.HandlerFunc {
 			Expiration:  p.Expiration.Format(time.DateOnly),
 			Price:       p.Price,
 		}
-		response.JSON(w, http.StatusCreated, map[string]any{
-			"message": "success",
-			"data":    data,
+		response.ETag(w, productETag(p))
+		response.Success(w, http.StatusCreated, response.Body{
+			Message: response.Localize(w, "success"),
+			Data:    data,
+			Links:   map[string]string{"self": path.Join(r.URL.Path, strconv.Itoa(p.Id))},
 		})
 	}
 }
@@ -150,20 +227,30 @@ func (h *HandlerProduct) UpdateOrCreate() http.HandlerFunc {
 		// - path parameter: id
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid id")
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - precondition
+		expected, ok := h.checkIfMatch(w, r, id)
+		if !ok {
 			return
 		}
 		// - body
-		var body RequestBodyProductCreate
-		err = request.JSON(r, &body)
+		var body RequestBodyProductUpdate
+		err = request.Body(r, &body)
+		if err != nil {
+			writeBodyError(w, err)
+			return
+		}
+		err = validate.Struct(body)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid body")
+			writeError(w, err)
 			return
 		}
 		// - expiration
 		exp, err := time.Parse(time.DateOnly, body.Expiration)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid expiration")
+			response.Error(w, http.StatusBadRequest, "invalid expiration")
 			return
 		}
 
@@ -180,9 +267,14 @@ func (h *HandlerProduct) UpdateOrCreate() http.HandlerFunc {
 				Price:       body.Price,
 			},
 		}
-		err = h.rp.UpdateOrSave(&p)
+		switch expected {
+		case nil:
+			err = h.sv.UpdateOrCreate(r.Context(), &p)
+		default:
+			err = h.sv.UpdateIf(r.Context(), &p, *expected)
+		}
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, "internal server error")
+			writeError(w, err)
 			return
 		}
 
@@ -197,9 +289,11 @@ func (h *HandlerProduct) UpdateOrCreate() http.HandlerFunc {
 			Expiration:  p.Expiration.Format(time.DateOnly),
 			Price:       p.Price,
 		}
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "success",
-			"data":    data,
+		response.ETag(w, productETag(p))
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "success"),
+			Data:    data,
+			Links:   map[string]string{"self": r.URL.Path},
 		})
 	}
 }
@@ -211,24 +305,26 @@ func (h *HandlerProduct) Update() http.HandlerFunc {
 		// - path parameter: id
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid id")
+			response.Error(w, http.StatusBadRequest, "invalid id")
 			return
 		}
 
 		// process
 		// - find product by id
-		p, err := h.rp.FindById(id)
+		p, err := h.sv.FindById(r.Context(), id)
 		if err != nil {
-			switch {
-			case errors.Is(err, internal.ErrRepositoryProductNotFound):
-				response.JSON(w, http.StatusNotFound, "product not found")
-			default:
-				response.JSON(w, http.StatusInternalServerError, "internal server error")
-			}
+			writeError(w, err)
+			return
+		}
+		// - check the client has the current version, the update fails if it changes meanwhile
+		if !request.IfMatch(r, productETag(p)) {
+			writeError(w, errProductModified)
 			return
 		}
+		expected := p
+		conditional := r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != "*"
 		// - patch product
-		body := RequestBodyProductCreate{
+		body := RequestBodyProductUpdate{
 			Name:        p.Name,
 			Quantity:    p.Quantity,
 			CodeValue:   p.CodeValue,
@@ -236,15 +332,28 @@ func (h *HandlerProduct) Update() http.HandlerFunc {
 			Expiration:  p.Expiration.Format(time.DateOnly),
 			Price:       p.Price,
 		}
-		err = request.JSON(r, &body)
+		err = request.Patch(r, &body)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid body")
+			switch {
+			case errors.Is(err, request.ErrRequestContentTypeNotPatch):
+				w.Header().Set("Accept-Patch", request.ContentTypeMergePatch+", "+request.ContentTypeJSONPatch)
+				response.Error(w, http.StatusUnsupportedMediaType, "unsupported patch format")
+			case errors.Is(err, request.ErrRequestPatchTestFailed):
+				response.Error(w, http.StatusConflict, "patch test failed")
+			default:
+				response.Error(w, http.StatusBadRequest, "invalid body")
+			}
+			return
+		}
+		err = validate.Struct(body)
+		if err != nil {
+			writeError(w, err)
 			return
 		}
 		// - expiration
 		exp, err := time.Parse(time.DateOnly, body.Expiration)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid expiration")
+			response.Error(w, http.StatusBadRequest, "invalid expiration")
 			return
 		}
 		// - update product
@@ -254,9 +363,14 @@ func (h *HandlerProduct) Update() http.HandlerFunc {
 		p.IsPublished = body.IsPublished
 		p.Expiration = exp
 		p.Price = body.Price
-		err = h.rp.Update(&p)
+		switch conditional {
+		case true:
+			err = h.sv.UpdateIf(r.Context(), &p, expected)
+		default:
+			err = h.sv.Update(r.Context(), &p)
+		}
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, "internal server error")
+			writeError(w, err)
 			return
 		}
 
@@ -271,9 +385,11 @@ func (h *HandlerProduct) Update() http.HandlerFunc {
 			Expiration:  p.Expiration.Format(time.DateOnly),
 			Price:       p.Price,
 		}
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "success",
-			"data":    data,
+		response.ETag(w, productETag(p))
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "success"),
+			Data:    data,
+			Links:   map[string]string{"self": r.URL.Path},
 		})
 	}
 }
@@ -285,20 +401,25 @@ func (h *HandlerProduct) Delete() http.HandlerFunc {
 		// - path parameter: id
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "invalid id")
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		// - precondition
+		expected, ok := h.checkIfMatch(w, r, id)
+		if !ok {
 			return
 		}
 
 		// process
 		// - delete product by id
-		err = h.rp.Delete(id)
+		switch expected {
+		case nil:
+			err = h.sv.Delete(r.Context(), id)
+		default:
+			err = h.sv.DeleteIf(r.Context(), *expected)
+		}
 		if err != nil {
-			switch {
-			case errors.Is(err, internal.ErrRepositoryProductNotFound):
-				response.JSON(w, http.StatusNotFound, "product not found")
-			default:
-				response.JSON(w, http.StatusInternalServerError, "internal server error")
-			}
+			writeError(w, err)
 			return
 		}
 
@@ -0,0 +1,196 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/repository"
+	"app/internal/service"
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newHandler returns a product handler over a memory repository holding ps.
+func newHandler(ps ...internal.Product) (hd *handler.HandlerProduct, rp *repository.RepositoryProductMemory) {
+	db := make(map[int]internal.Product)
+	for _, p := range ps {
+		db[p.Id] = p
+	}
+	rp = repository.NewRepositoryProductMemory(db)
+	hd = handler.NewHandlerProduct(service.NewServiceProductDefault(repository.NewUnitOfWorkMemory(rp)))
+	return
+}
+
+// newProduct returns a product with an id.
+func newProduct(id int, code string) internal.Product {
+	return internal.Product{
+		Id: id,
+		ProductAttributes: internal.ProductAttributes{
+			Name:        "product " + code,
+			Quantity:    10,
+			CodeValue:   code,
+			IsPublished: true,
+			Expiration:  time.Date(2999, 12, 31, 0, 0, 0, 0, time.UTC),
+			Price:       9.5,
+		},
+	}
+}
+
+// failingService is a product service whose reads fail.
+type failingService struct {
+	internal.ServiceProduct
+}
+
+// FindEach fails before any product.
+func (s *failingService) FindEach(ctx context.Context, fn func(p internal.Product) (err error)) (err error) {
+	err = errors.New("connection refused")
+	return
+}
+
+// Tests for HandlerProduct.Export
+func TestHandlerProduct_Export(t *testing.T) {
+	t.Run("200 - the products written as csv by id", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandler(newProduct(2, "A2"), newProduct(1, "A1"))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Export()(res, httptest.NewRequest("GET", "/products/export?format=csv", nil))
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, "text/csv; charset=utf-8", res.Header().Get("Content-Type"))
+		require.Equal(t, "attachment; filename=products.csv", res.Header().Get("Content-Disposition"))
+		require.Equal(t, "name,quantity,code_value,is_published,expiration,price\n"+
+			"product A1,10,A1,true,2999-12-31,9.5\n"+
+			"product A2,10,A2,true,2999-12-31,9.5\n", res.Body.String())
+	})
+
+	t.Run("200 - only the header without products", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandler()
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Export()(res, httptest.NewRequest("GET", "/products/export", nil))
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, "name,quantity,code_value,is_published,expiration,price\n", res.Body.String())
+	})
+
+	t.Run("400 - unsupported format", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandler()
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Export()(res, httptest.NewRequest("GET", "/products/export?format=xlsx", nil))
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("500 - the products can not be read, a problem instead of the file", func(t *testing.T) {
+		// arrange
+		hd := handler.NewHandlerProduct(&failingService{})
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Export()(res, httptest.NewRequest("GET", "/products/export", nil))
+
+		// assert
+		require.Equal(t, http.StatusInternalServerError, res.Code)
+		require.Equal(t, "application/problem+json", res.Header().Get("Content-Type"))
+	})
+}
+
+// Tests for HandlerProduct.Import
+func TestHandlerProduct_Import(t *testing.T) {
+	t.Run("200 - rows created, updated by code value and failed", func(t *testing.T) {
+		// arrange
+		hd, rp := newHandler(newProduct(1, "A1"))
+		body := "name,quantity,code_value,is_published,expiration,price,warehouse_id\n" +
+			"updated,20,A1,true,2999-12-31,1.5,1\n" +
+			"created,5,A2,false,2999-12-31,2,1\n" +
+			"invalid,five,A3,false,2999-12-31,2,1\n" +
+			",5,A4,false,2999-12-31,2,1\n"
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/products/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		hd.Import()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, `{"message":"success", "data":{"created":1, "updated":1, "failed":2, "errors":[
+			{"row":4, "column":"quantity", "message":"quantity must be an integer"},
+			{"row":5, "column":"name", "message":"name is required"}
+		]}}`, res.Body.String())
+		ps, err := rp.FindAll(context.Background())
+		require.NoError(t, err)
+		require.Len(t, ps, 2)
+		require.Equal(t, "updated", ps[0].Name)
+		require.Equal(t, "created", ps[1].Name)
+	})
+
+	t.Run("200 - the file field of a multipart form", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandler()
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, err := mw.CreateFormFile("file", "products.csv")
+		require.NoError(t, err)
+		_, err = fw.Write([]byte("name,quantity,code_value,is_published,expiration,price\ncreated,5,A1,false,2999-12-31,2\n"))
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/products/import", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		hd.Import()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, `{"message":"success", "data":{"created":1, "updated":0, "failed":0, "errors":[]}}`, res.Body.String())
+	})
+
+	t.Run("400 - a column missing", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandler()
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/products/import", strings.NewReader("name,quantity\nproduct,5\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		hd.Import()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Contains(t, res.Body.String(), "csv column code_value missing")
+	})
+
+	t.Run("415 - not a csv", func(t *testing.T) {
+		// arrange
+		hd, _ := newHandler()
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/products/import", strings.NewReader(`[]`))
+		req.Header.Set("Content-Type", "application/json")
+		hd.Import()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+	})
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/search"
+	"app/platform/web/response"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProductHighlightJSON is the name and code value of a product found by a search in JSON format,
+// HTML escaped and with the parts matched by the query wrapped in <mark> tags.
+type ProductHighlightJSON struct {
+	Name      string `json:"name"`
+	CodeValue string `json:"code_value"`
+}
+
+// ProductMatchJSON is a product found by a search in JSON format.
+type ProductMatchJSON struct {
+	ProductJSON
+	Score     float64              `json:"score"`
+	Highlight ProductHighlightJSON `json:"highlight"`
+}
+
+// highlight returns a text HTML escaped with the parts matched by a search wrapped in <mark> tags.
+func highlight(text string, matched map[string]int) string {
+	var b strings.Builder
+	last := 0
+	for _, s := range search.Highlight(text, matched) {
+		b.WriteString(html.EscapeString(text[last:s.Start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[s.Start:s.End]))
+		b.WriteString("</mark>")
+		last = s.End
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// Search finds the products by name and code value, the most relevant first.
+func (h *HandlerProduct) Search() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - query parameter: q
+		query := r.URL.Query().Get("q")
+		if strings.TrimSpace(query) == "" {
+			response.Error(w, http.StatusBadRequest, "missing search query")
+			return
+		}
+		// - query parameter: limit
+		limit := internal.ProductSearchLimit
+		if l := r.URL.Query().Get("limit"); l != "" {
+			var err error
+			limit, err = strconv.Atoi(l)
+			if err != nil || limit <= 0 {
+				response.Error(w, http.StatusBadRequest, "invalid limit")
+				return
+			}
+		}
+
+		// process
+		// - search products
+		ms, err := h.sv.Search(r.Context(), query, limit)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// response
+		// - serialize matches to JSON
+		data := make([]ProductMatchJSON, 0, len(ms))
+		for _, m := range ms {
+			data = append(data, ProductMatchJSON{
+				ProductJSON: ProductJSON{
+					Id:          m.Id,
+					Name:        m.Name,
+					Quantity:    m.Quantity,
+					CodeValue:   m.CodeValue,
+					IsPublished: m.IsPublished,
+					Expiration:  m.Expiration.Format(time.DateOnly),
+					Price:       m.Price,
+				},
+				Score: m.Score,
+				Highlight: ProductHighlightJSON{
+					Name:      highlight(m.Name, m.Matched),
+					CodeValue: highlight(m.CodeValue, m.Matched),
+				},
+			})
+		}
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "success"),
+			Data:    data,
+			Meta:    map[string]any{"count": len(data)},
+			Links:   map[string]string{"self": r.URL.RequestURI()},
+		})
+	}
+}
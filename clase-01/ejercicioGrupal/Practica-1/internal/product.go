@@ -25,3 +25,13 @@ type Product struct {
 	// ProductAttributes is the attributes of the product
 	ProductAttributes
 }
+
+// ProductMatch is a product found by a search.
+type ProductMatch struct {
+	// Product is the product found.
+	Product
+	// Score is the relevance of the product to the query, the higher the more relevant.
+	Score float64
+	// Matched are the terms of the name and the code value matched by the query, with the number of their leading letters matched.
+	Matched map[string]int
+}
@@ -1,22 +1,45 @@
 package internal
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 var (
 	// ErrRepositoryProductNotFound is returned when a product is not found.
 	ErrRepositoryProductNotFound = errors.New("repository: product not found")
+	// ErrRepositoryProductNotUnique is returned when the code value of a product is already used.
+	ErrRepositoryProductNotUnique = errors.New("repository: product not unique")
+	// ErrRepositoryProductModified is returned when a conditional write finds the product changed since it was read.
+	ErrRepositoryProductModified = errors.New("repository: product modified")
 )
 
-// RepositoryProduct is an interface that contains the methods for a product repository
+// RepositoryProduct is an interface that contains the methods for a product repository.
+// Its methods stop and return the error of ctx when it is canceled or its deadline passes.
 type RepositoryProduct interface {
+	// FindAll returns all products
+	FindAll(ctx context.Context) (p []Product, err error)
+	// FindEach calls fn with each product by id, reading them one at a time where the storage allows it.
+	// It stops and returns the first error of fn
+	FindEach(ctx context.Context, fn func(p Product) (err error)) (err error)
 	// FindById returns a product by its id
-	FindById(id int) (p Product, err error)
+	FindById(ctx context.Context, id int) (p Product, err error)
+	// FindByCodeValue returns a product by its code value
+	FindByCodeValue(ctx context.Context, code string) (p Product, err error)
 	// Save saves a product
-	Save(p *Product) (err error)
+	Save(ctx context.Context, p *Product) (err error)
 	// UpdateOrSave updates or saves a product
-	UpdateOrSave(p *Product) (err error)
+	UpdateOrSave(ctx context.Context, p *Product) (err error)
 	// Update updates a product
-	Update(p *Product) (err error)
+	Update(ctx context.Context, p *Product) (err error)
+	// UpdateIf updates a product only if the stored one still equals expected, the product as it was read,
+	// otherwise it returns ErrRepositoryProductModified
+	UpdateIf(ctx context.Context, p *Product, expected Product) (err error)
 	// Delete deletes a product
-	Delete(id int) (err error)
-}
\ No newline at end of file
+	Delete(ctx context.Context, id int) (err error)
+	// DeleteIf deletes a product only if the stored one still equals expected, otherwise it returns ErrRepositoryProductModified
+	DeleteIf(ctx context.Context, expected Product) (err error)
+	// Search finds the products whose name or code value match the terms of a query, also by a prefix of them or with a typo,
+	// the most relevant first and up to limit, all of them when it is zero or less
+	Search(ctx context.Context, query string, limit int) (ms []ProductMatch, err error)
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrServiceProductInvalid is returned, as a *FieldError, when a product breaks a validation rule.
+	ErrServiceProductInvalid = errors.New("service: product invalid")
+)
+
+const (
+	// ProductSearchLimit is the number of products a search returns when its limit is not set.
+	ProductSearchLimit = 20
+	// ProductSearchMaxLimit is the most products a search returns.
+	ProductSearchMaxLimit = 100
+)
+
+// ServiceProduct is an interface that contains the business rules of products.
+// Writes validate the product, keep its code value unique and do not publish expired products.
+type ServiceProduct interface {
+	// FindAll returns all products
+	FindAll(ctx context.Context) (p []Product, err error)
+	// FindEach calls fn with each product by id, without holding them all in memory where the storage allows it
+	FindEach(ctx context.Context, fn func(p Product) (err error)) (err error)
+	// FindById returns a product by its id
+	FindById(ctx context.Context, id int) (p Product, err error)
+	// Create creates a product
+	Create(ctx context.Context, p *Product) (err error)
+	// UpdateOrCreate updates a product or creates it with its id
+	UpdateOrCreate(ctx context.Context, p *Product) (err error)
+	// Update updates a product
+	Update(ctx context.Context, p *Product) (err error)
+	// UpdateIf updates a product only if the stored one still equals expected, the product its changes were made on
+	UpdateIf(ctx context.Context, p *Product, expected Product) (err error)
+	// Delete deletes a product
+	Delete(ctx context.Context, id int) (err error)
+	// DeleteIf deletes a product only if the stored one still equals expected
+	DeleteIf(ctx context.Context, expected Product) (err error)
+	// Import creates a product or updates the one with its code value, created reports which one happened
+	Import(ctx context.Context, p *Product) (created bool, err error)
+	// Search finds the products whose name or code value match a query, the most relevant first.
+	// It returns up to limit of them: ProductSearchLimit when it is zero or less, at most ProductSearchMaxLimit
+	Search(ctx context.Context, query string, limit int) (ms []ProductMatch, err error)
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"app/internal"
+	"app/platform/search"
+	"context"
+	"sort"
+	"sync"
+)
+
+// NewRepositoryProductMemory creates a new in-memory repository for products, starting with a copy of db.
+func NewRepositoryProductMemory(db map[int]internal.Product) (r *RepositoryProductMemory) {
+	index := newProductIndex()
+	syncProductIndex(index, db)
+	r = newRepositoryProductMemory(db, index)
+	return
+}
+
+// newRepositoryProductMemory creates a new in-memory repository for products, starting with a copy of db,
+// whose full-text index is index, already up to date with db.
+func newRepositoryProductMemory(db map[int]internal.Product, index *search.Index) (r *RepositoryProductMemory) {
+	r = &RepositoryProductMemory{
+		db:    make(map[int]internal.Product),
+		index: index,
+	}
+	for k, v := range db {
+		r.db[k] = v
+		if k > r.lastId {
+			r.lastId = k
+		}
+	}
+	return
+}
+
+// RepositoryProductMemory is an in-memory repository for products, safe for concurrent use.
+type RepositoryProductMemory struct {
+	// mu guards db, lastId and index.
+	mu sync.RWMutex
+	// db is the products by id.
+	db map[int]internal.Product
+	// lastId is the last id assigned to a product.
+	lastId int
+	// index is the full-text index of the products, updated on every write.
+	index *search.Index
+}
+
+// FindAll finds all products sorted by id.
+func (r *RepositoryProductMemory) FindAll(ctx context.Context) (p []internal.Product, err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range r.db {
+		p = append(p, v)
+	}
+	sort.Slice(p, func(i, j int) bool { return p[i].Id < p[j].Id })
+
+	return
+}
+
+// FindEach calls fn with each product by id, from a copy of them so fn may take its time.
+func (r *RepositoryProductMemory) FindEach(ctx context.Context, fn func(p internal.Product) (err error)) (err error) {
+	// find all products
+	ps, err := r.FindAll(ctx)
+	if err != nil {
+		return
+	}
+
+	// call fn
+	for _, p := range ps {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		if err = fn(p); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// FindById finds a product by id.
+func (r *RepositoryProductMemory) FindById(ctx context.Context, id int) (p internal.Product, err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.db[id]
+	if !ok {
+		err = internal.ErrRepositoryProductNotFound
+		return
+	}
+
+	return
+}
+
+// FindByCodeValue finds a product by code value.
+func (r *RepositoryProductMemory) FindByCodeValue(ctx context.Context, code string) (p internal.Product, err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range r.db {
+		if v.CodeValue == code {
+			p = v
+			return
+		}
+	}
+
+	err = internal.ErrRepositoryProductNotFound
+	return
+}
+
+// Save saves a product.
+func (r *RepositoryProductMemory) Save(ctx context.Context, p *internal.Product) (err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err = r.save(p)
+	return
+}
+
+// UpdateOrSave updates or saves a product.
+func (r *RepositoryProductMemory) UpdateOrSave(ctx context.Context, p *internal.Product) (err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// save product
+	if _, ok := r.db[p.Id]; !ok {
+		err = r.save(p)
+		return
+	}
+
+	// update product
+	err = r.update(p)
+	return
+}
+
+// Update updates a product.
+func (r *RepositoryProductMemory) Update(ctx context.Context, p *internal.Product) (err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err = r.update(p)
+	return
+}
+
+// UpdateIf updates a product if the stored one still equals expected.
+func (r *RepositoryProductMemory) UpdateIf(ctx context.Context, p *internal.Product, expected internal.Product) (err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// check product
+	if err = productExpected(r.db, expected); err != nil {
+		return
+	}
+
+	// update product
+	err = r.update(p)
+	return
+}
+
+// DeleteIf deletes a product if the stored one still equals expected.
+func (r *RepositoryProductMemory) DeleteIf(ctx context.Context, expected internal.Product) (err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// check product
+	if err = productExpected(r.db, expected); err != nil {
+		return
+	}
+
+	// delete product
+	delete(r.db, expected.Id)
+	r.index.Remove(expected.Id)
+
+	return
+}
+
+// Delete deletes a product.
+func (r *RepositoryProductMemory) Delete(ctx context.Context, id int) (err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// delete product
+	if _, ok := r.db[id]; !ok {
+		err = internal.ErrRepositoryProductNotFound
+		return
+	}
+	delete(r.db, id)
+	r.index.Remove(id)
+
+	return
+}
+
+// Search finds the products matched by a query in the full-text index.
+func (r *RepositoryProductMemory) Search(ctx context.Context, query string, limit int) (ms []internal.ProductMatch, err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	// a search sorts the terms of the index when they changed, so it writes
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ms = searchProducts(r.index, r.db, query, limit)
+	return
+}
+
+// save saves a product with a new id, the lock must be held.
+func (r *RepositoryProductMemory) save(p *internal.Product) (err error) {
+	// check code value
+	if codeValueUsed(r.db, 0, p.CodeValue) {
+		err = internal.ErrRepositoryProductNotUnique
+		return
+	}
+
+	// set id
+	r.lastId++
+	(*p).Id = r.lastId
+
+	// add product
+	r.db[p.Id] = *p
+	indexProduct(r.index, *p)
+
+	return
+}
+
+// update updates a product, the lock must be held.
+func (r *RepositoryProductMemory) update(p *internal.Product) (err error) {
+	// find product
+	if _, ok := r.db[p.Id]; !ok {
+		err = internal.ErrRepositoryProductNotFound
+		return
+	}
+
+	// check code value
+	if codeValueUsed(r.db, p.Id, p.CodeValue) {
+		err = internal.ErrRepositoryProductNotUnique
+		return
+	}
+
+	// update product
+	r.db[p.Id] = *p
+	indexProduct(r.index, *p)
+
+	return
+}
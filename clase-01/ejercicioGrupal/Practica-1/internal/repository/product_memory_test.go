@@ -0,0 +1,55 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/repository/repositorytest"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for RepositoryProductMemory
+func TestRepositoryProductMemory(t *testing.T) {
+	repositorytest.Products(t, func(t *testing.T) internal.RepositoryProduct {
+		return repository.NewRepositoryProductMemory(nil)
+	})
+	repositorytest.Search(t, func(t *testing.T) internal.RepositoryProduct {
+		return repository.NewRepositoryProductMemory(nil)
+	})
+
+	t.Run("concurrent saves get distinct ids", func(t *testing.T) {
+		// arrange
+		rp := repository.NewRepositoryProductMemory(nil)
+
+		// act
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				p := internal.Product{ProductAttributes: internal.ProductAttributes{CodeValue: fmt.Sprintf("C%d", i)}}
+				rp.Save(context.Background(), &p)
+			}(i)
+		}
+		wg.Wait()
+
+		// assert
+		ps, err := rp.FindAll(context.Background())
+		require.NoError(t, err)
+		require.Len(t, ps, 50)
+		for i, p := range ps {
+			require.Equal(t, i+1, p.Id)
+		}
+	})
+}
+
+// Tests for UnitOfWorkMemory
+func TestUnitOfWorkMemory(t *testing.T) {
+	repositorytest.UnitOfWork(t, func(t *testing.T) internal.UnitOfWork {
+		return repository.NewUnitOfWorkMemory(repository.NewRepositoryProductMemory(nil))
+	})
+}
@@ -2,17 +2,26 @@ package repository
 
 import (
 	"app/internal"
+	"app/platform/search"
+	"context"
 	"database/sql"
 	"errors"
-	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
 )
 
+// conn is the subset of *sql.DB and *sql.Tx used by the mysql repository.
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type RepositoryProductMySql struct {
-	// db is the underlying database.
-	db *sql.DB
+	// db is the underlying database, or the transaction of a unit of work.
+	db conn
 }
 
 func NewRepositoryProductMySql(db *sql.DB) (r *RepositoryProductMySql) {
@@ -22,10 +31,112 @@ func NewRepositoryProductMySql(db *sql.DB) (r *RepositoryProductMySql) {
 	return
 }
 
-func (r *RepositoryProductMySql) FindById(id int) (p internal.Product, err error) {
+// productErrorMySql maps a mysql error into a product repository error.
+func productErrorMySql(err error) error {
+	var mySqlErr *mysql.MySQLError
+	if errors.As(err, &mySqlErr) && mySqlErr.Number == 1062 {
+		return internal.ErrRepositoryProductNotUnique
+	}
+	return err
+}
+
+// atomic runs fn with a repository on a transaction, so a product and its search terms are written together:
+// r itself when it already runs on the transaction of a unit of work.
+func (r *RepositoryProductMySql) atomic(ctx context.Context, fn func(r *RepositoryProductMySql) error) (err error) {
+	db, ok := r.db.(*sql.DB)
+	if !ok {
+		err = fn(r)
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	if err = fn(&RepositoryProductMySql{db: tx}); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+func (r *RepositoryProductMySql) FindAll(ctx context.Context) (p []internal.Product, err error) {
+	query := "SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `price`,`expiration` FROM `products` ORDER BY `id`"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pr internal.Product
+		var timeString string
+		err = rows.Scan(&pr.Id, &pr.Name, &pr.Quantity, &pr.CodeValue, &pr.IsPublished, &pr.Price, &timeString)
+		if err != nil {
+			return
+		}
+		pr.Expiration, err = time.Parse(time.DateOnly, timeString)
+		if err != nil {
+			return
+		}
+		p = append(p, pr)
+	}
+	err = rows.Err()
+	return
+}
+
+// FindEach calls fn with each product by id as its row is read, so the products are never all in memory.
+func (r *RepositoryProductMySql) FindEach(ctx context.Context, fn func(p internal.Product) (err error)) (err error) {
+	query := "SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `price`,`expiration` FROM `products` ORDER BY `id`"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pr internal.Product
+		var timeString string
+		err = rows.Scan(&pr.Id, &pr.Name, &pr.Quantity, &pr.CodeValue, &pr.IsPublished, &pr.Price, &timeString)
+		if err != nil {
+			return
+		}
+		pr.Expiration, err = time.Parse(time.DateOnly, timeString)
+		if err != nil {
+			return
+		}
+		if err = fn(pr); err != nil {
+			return
+		}
+	}
+	err = rows.Err()
+	return
+}
+
+func (r *RepositoryProductMySql) FindByCodeValue(ctx context.Context, code string) (p internal.Product, err error) {
+	query := "SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `price`,`expiration` FROM `products` WHERE `code_value` = ?"
+
+	var timeString string
+	err = r.db.QueryRowContext(ctx, query, code).Scan(&p.Id, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Price, &timeString)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrRepositoryProductNotFound
+		}
+		return
+	}
+
+	p.Expiration, err = time.Parse(time.DateOnly, timeString)
+	return
+}
+
+func (r *RepositoryProductMySql) FindById(ctx context.Context, id int) (p internal.Product, err error) {
 	query := "SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `price`,`expiration` FROM `products` WHERE `id` = ?"
 
-	result := r.db.QueryRow(query, id)
+	result := r.db.QueryRowContext(ctx, query, id)
 	if result.Err() != nil {
 		if errors.Is(result.Err(), sql.ErrNoRows) {
 			err = internal.ErrRepositoryProductNotFound
@@ -38,6 +149,9 @@ func (r *RepositoryProductMySql) FindById(id int) (p internal.Product, err error
 	var timeString string
 	err = result.Scan(&p.Id, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Price, &timeString)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrRepositoryProductNotFound
+		}
 		return
 	}
 
@@ -49,40 +163,119 @@ func (r *RepositoryProductMySql) FindById(id int) (p internal.Product, err error
 	return
 }
 
-func (r *RepositoryProductMySql) Save(p *internal.Product) (err error) {
-	query := "INSERT INTO `products` (`name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`) VALUES (?, ?, ?, ?, ?, ?,1)"
+func (r *RepositoryProductMySql) Save(ctx context.Context, p *internal.Product) (err error) {
+	err = r.atomic(ctx, func(r *RepositoryProductMySql) (err error) {
+		query := "INSERT INTO `products` (`name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`) VALUES (?, ?, ?, ?, ?, ?,1)"
 
-	result, err := r.db.Exec(query, p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price)
-	if err != nil {
-		return
-	}
+		result, err := r.db.ExecContext(ctx, query, p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price)
+		if err != nil {
+			err = productErrorMySql(err)
+			return
+		}
 
-	lastId, err := result.LastInsertId()
+		lastId, err := result.LastInsertId()
+		if err != nil {
+			return
+		}
 
-	(*p).Id = int(lastId)
+		(*p).Id = int(lastId)
+		err = r.indexProduct(ctx, *p)
+		return
+	})
 	return
 }
 
-func (r *RepositoryProductMySql) UpdateOrSave(p *internal.Product) (err error) {
+// UpdateOrSave updates the product with the id of p, or saves it with a new id when it does not exist.
+func (r *RepositoryProductMySql) UpdateOrSave(ctx context.Context, p *internal.Product) (err error) {
+	err = r.Update(ctx, p)
+	if errors.Is(err, internal.ErrRepositoryProductNotFound) {
+		err = r.Save(ctx, p)
+	}
 	return
 }
 
-func (r *RepositoryProductMySql) Update(p *internal.Product) (err error) {
-	query := "UPDATE `products` SET `name` = ?, `quantity` = ?, `code_value` = ?, `is_published` = ?, `expiration` = ?, `price` = ? WHERE `id` = ?"
+func (r *RepositoryProductMySql) Update(ctx context.Context, p *internal.Product) (err error) {
+	err = r.atomic(ctx, func(r *RepositoryProductMySql) (err error) {
+		query := "UPDATE `products` SET `name` = ?, `quantity` = ?, `code_value` = ?, `is_published` = ?, `expiration` = ?, `price` = ? WHERE `id` = ?"
 
-	result, err := r.db.Exec(query, p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.Id)
-	if err != nil {
-		var mySqlErr *mysql.MySQLError
-		if errors.As(err, &mySqlErr) {
-			switch mySqlErr.Number {
-			case 1062:
-				err = fmt.Errorf("product already exists")
+		result, err := r.db.ExecContext(ctx, query, p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.Id)
+		if err != nil {
+			err = productErrorMySql(err)
+			return
+		}
+
+		rowAffected, err := result.RowsAffected()
+		if err != nil {
+			return
+		}
+
+		if rowAffected == 0 {
+			// mysql does not count the rows whose values did not change
+			var exists bool
+			err = r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM `products` WHERE `id` = ?)", p.Id).Scan(&exists)
+			if err != nil {
 				return
-			default:
-				err = fmt.Errorf("unknown error")
+			}
+			if !exists {
+				err = internal.ErrRepositoryProductNotFound
+			}
+			return
+		}
+
+		err = r.indexProduct(ctx, *p)
+		return
+	})
+	return
+}
+
+// productExpectedMySql is the condition of a conditional write: the product still has the id and the columns of the one expected.
+const productExpectedMySql = "`id` = ? AND `name` = ? AND `quantity` = ? AND `code_value` = ? AND `is_published` = ? AND `expiration` = ? AND `price` = ?"
+
+// productExpectedArgsMySql returns the arguments of productExpectedMySql for the product expected.
+func productExpectedArgsMySql(expected internal.Product) []any {
+	return []any{expected.Id, expected.Name, expected.Quantity, expected.CodeValue, expected.IsPublished, expected.Expiration.Format(time.DateOnly), expected.Price}
+}
+
+// UpdateIf updates a product if the stored one still equals expected, checked by the condition of the update itself.
+func (r *RepositoryProductMySql) UpdateIf(ctx context.Context, p *internal.Product, expected internal.Product) (err error) {
+	err = r.atomic(ctx, func(r *RepositoryProductMySql) (err error) {
+		query := "UPDATE `products` SET `name` = ?, `quantity` = ?, `code_value` = ?, `is_published` = ?, `expiration` = ?, `price` = ? WHERE " + productExpectedMySql
+		args := append([]any{p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price}, productExpectedArgsMySql(expected)...)
+
+		result, err := r.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			err = productErrorMySql(err)
+			return
+		}
+
+		rowAffected, err := result.RowsAffected()
+		if err != nil {
+			return
+		}
+
+		if rowAffected == 0 {
+			// mysql does not count the rows whose values did not change, so the product may already be the one updated
+			var current internal.Product
+			current, err = r.FindById(ctx, p.Id)
+			if err != nil {
 				return
 			}
+			if !sameProduct(current, *p) {
+				err = internal.ErrRepositoryProductModified
+			}
+			return
 		}
+
+		err = r.indexProduct(ctx, *p)
+		return
+	})
+	return
+}
+
+func (r *RepositoryProductMySql) Delete(ctx context.Context, id int) (err error) {
+	query := "DELETE FROM `products` WHERE `id` = ?"
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
 		return
 	}
 
@@ -95,13 +288,13 @@ func (r *RepositoryProductMySql) Update(p *internal.Product) (err error) {
 		err = internal.ErrRepositoryProductNotFound
 		return
 	}
-
 	return
 }
 
-func (r *RepositoryProductMySql) Delete(id int) (err error) {
-	query := "DELETE FROM `products` WHERE `id` = ?"
-	result, err := r.db.Exec(query, id)
+// DeleteIf deletes a product if the stored one still equals expected, checked by the condition of the delete itself.
+func (r *RepositoryProductMySql) DeleteIf(ctx context.Context, expected internal.Product) (err error) {
+	query := "DELETE FROM `products` WHERE " + productExpectedMySql
+	result, err := r.db.ExecContext(ctx, query, productExpectedArgsMySql(expected)...)
 	if err != nil {
 		return
 	}
@@ -112,8 +305,181 @@ func (r *RepositoryProductMySql) Delete(id int) (err error) {
 	}
 
 	if rowAffected == 0 {
-		err = internal.ErrRepositoryProductNotFound
+		// the product was either deleted or modified by someone else
+		var exists bool
+		err = r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM `products` WHERE `id` = ?)", expected.Id).Scan(&exists)
+		if err != nil {
+			return
+		}
+		err = internal.ErrRepositoryProductModified
+		if !exists {
+			err = internal.ErrRepositoryProductNotFound
+		}
+		return
+	}
+	return
+}
+
+// indexProduct writes the search terms of a product (product_search_terms) and their typo keys (product_search_typos),
+// replacing the ones it had. The ones of a deleted product are deleted with it by their foreign keys.
+func (r *RepositoryProductMySql) indexProduct(ctx context.Context, p internal.Product) (err error) {
+	for _, table := range []string{"`product_search_terms`", "`product_search_typos`"} {
+		_, err = r.db.ExecContext(ctx, "DELETE FROM "+table+" WHERE `id_product` = ?", p.Id)
+		if err != nil {
+			return
+		}
+	}
+
+	terms := search.Terms(p.Name + " " + p.CodeValue)
+	if len(terms) == 0 {
+		return
+	}
+	var values []string
+	var args []any
+	for _, t := range terms {
+		values = append(values, "(?, ?)")
+		args = append(args, t, p.Id)
+	}
+	_, err = r.db.ExecContext(ctx, "INSERT INTO `product_search_terms` (`term`, `id_product`) VALUES "+strings.Join(values, ", "), args...)
+	if err != nil {
+		return
+	}
+
+	values, args = nil, nil
+	for _, t := range terms {
+		for _, k := range search.TypoKeys(t) {
+			values = append(values, "(?, ?, ?)")
+			args = append(args, k, t, p.Id)
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+	_, err = r.db.ExecContext(ctx, "INSERT INTO `product_search_typos` (`variant`, `term`, `id_product`) VALUES "+strings.Join(values, ", "), args...)
+	return
+}
+
+// Reindex writes again the search terms of every product, for the ones stored before the search tables
+// or by other means than the repository. It returns the number of products indexed.
+func (r *RepositoryProductMySql) Reindex(ctx context.Context) (n int, err error) {
+	ps, err := r.FindAll(ctx)
+	if err != nil {
+		return
+	}
+
+	err = r.atomic(ctx, func(r *RepositoryProductMySql) (err error) {
+		for _, p := range ps {
+			if err = r.indexProduct(ctx, p); err != nil {
+				return
+			}
+		}
+		return
+	})
+	if err != nil {
+		return
+	}
+	n = len(ps)
+	return
+}
+
+// inMySql returns the placeholders of a list of values of an IN condition and its arguments.
+func inMySql(values []string) (placeholders string, args []any) {
+	ps := make([]string, len(values))
+	args = make([]any, len(values))
+	for i, v := range values {
+		ps[i] = "?"
+		args[i] = v
+	}
+	placeholders = strings.Join(ps, ", ")
+	return
+}
+
+// searchTerms returns the terms of the search terms table matched by a query, each term of the query looked up by an index:
+// the terms it starts by the prefix of the primary key of product_search_terms, and its typos by their typo keys,
+// the ones it is or has among its own keys (search.QueryTypoKeys) in product_search_terms and product_search_typos.
+func (r *RepositoryProductMySql) searchTerms(ctx context.Context, query string) (terms []string, err error) {
+	seen := make(map[string]bool)
+	for _, q := range search.Terms(query) {
+		// - the terms of the query are letters and digits only, so they have no wildcard of LIKE
+		lookup := "SELECT `term` FROM `product_search_terms` WHERE `term` LIKE ?"
+		args := []any{q + "%"}
+		if keys := search.QueryTypoKeys(q); len(keys) > 0 {
+			in, keyArgs := inMySql(keys)
+			lookup += " UNION SELECT `term` FROM `product_search_terms` WHERE `term` IN (" + in + ")" +
+				" UNION SELECT `term` FROM `product_search_typos` WHERE `variant` IN (" + in + ")"
+			args = append(append(args, keyArgs...), keyArgs...)
+		}
+
+		var rows *sql.Rows
+		rows, err = r.db.QueryContext(ctx, lookup, args...)
+		if err != nil {
+			return
+		}
+		for rows.Next() {
+			var term string
+			if err = rows.Scan(&term); err != nil {
+				rows.Close()
+				return
+			}
+			if _, ok := search.Match(q, term); ok && !seen[term] {
+				seen[term] = true
+				terms = append(terms, term)
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Search finds the products matched by a query through their search terms (product_search_terms), ranked as the other
+// repositories do: the candidates are every product with a term matched, so an index of them weighs the rarity
+// of a term as an index of all the products does.
+func (r *RepositoryProductMySql) Search(ctx context.Context, query string, limit int) (ms []internal.ProductMatch, err error) {
+	// terms matched
+	terms, err := r.searchTerms(ctx, query)
+	if err != nil || len(terms) == 0 {
+		return
+	}
+
+	// candidates
+	in, args := inMySql(terms)
+	q := "SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `price`, `expiration` FROM `products` " +
+		"WHERE `id` IN (SELECT `id_product` FROM `product_search_terms` WHERE `term` IN (" + in + "))"
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	db := make(map[int]internal.Product)
+	x := newProductIndex()
+	for rows.Next() {
+		var p internal.Product
+		var timeString string
+		err = rows.Scan(&p.Id, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Price, &timeString)
+		if err != nil {
+			return
+		}
+		p.Expiration, err = time.Parse(time.DateOnly, timeString)
+		if err != nil {
+			return
+		}
+		db[p.Id] = p
+		indexProduct(x, p)
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	// rank
+	var total int
+	if err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM `products`").Scan(&total); err != nil {
 		return
 	}
+	x.SetCorpus(total)
+	ms = searchProducts(x, db, query, limit)
 	return
 }
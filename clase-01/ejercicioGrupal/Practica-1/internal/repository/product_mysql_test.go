@@ -0,0 +1,151 @@
+package repository_test
+
+import (
+	"app/docs/db/migrations"
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/repository/repositorytest"
+	"app/platform/migrate"
+	"app/platform/mysqltest"
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mysqlServer is the embedded mysql compatible server shared by the tests of the package, started on first use
+var mysqlServer = sync.OnceValues(mysqltest.NewServer)
+
+// mysqlDB returns a new database of the embedded server with the migrations applied
+func mysqlDB(t *testing.T) (db *sql.DB) {
+	s, err := mysqlServer()
+	require.NoError(t, err)
+	cfg, err := s.NewDatabase()
+	require.NoError(t, err)
+	// - the repository reads the dates as text, as the connection of the application does
+	cfg.ParseTime = false
+	db, err = sql.Open("mysql", cfg.FormatDSN())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	fsys, err := migrations.FS()
+	require.NoError(t, err)
+	ms, err := migrate.Load(fsys)
+	require.NoError(t, err)
+	_, err = migrate.NewMigrator(db, ms).Up(0)
+	require.NoError(t, err)
+	return
+}
+
+// Tests for RepositoryProductMySql, each one on a new database of the embedded server
+func TestRepositoryProductMySql(t *testing.T) {
+	repositorytest.Products(t, func(t *testing.T) internal.RepositoryProduct {
+		return repository.NewRepositoryProductMySql(mysqlDB(t))
+	})
+	repositorytest.Search(t, func(t *testing.T) internal.RepositoryProduct {
+		return repository.NewRepositoryProductMySql(mysqlDB(t))
+	})
+
+	t.Run("search ranks as the other repositories", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		rp := repository.NewRepositoryProductMySql(mysqlDB(t))
+		rm := repository.NewRepositoryProductMemory(nil)
+		names := []string{"Chocolate - Dark", "Chocolate Bar", "Chocolate Bar - Smarties", "Cookies - Oreo", "Chocolat Milk", "Cocoa Powder", "Chicken Wings"}
+		for i, name := range names {
+			p := internal.Product{ProductAttributes: internal.ProductAttributes{Name: name, CodeValue: "C" + string(rune('A'+i)), Expiration: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}}
+			require.NoError(t, rp.Save(ctx, &p))
+			require.NoError(t, rm.Save(ctx, &p))
+		}
+
+		// act
+		ms, err := rp.Search(ctx, "chocolate bar", 2)
+
+		// assert
+		require.NoError(t, err)
+		expected, err := rm.Search(ctx, "chocolate bar", 2)
+		require.NoError(t, err)
+		require.Len(t, ms, 2)
+		for i := range expected {
+			require.Equal(t, expected[i].Id, ms[i].Id)
+			require.InDelta(t, expected[i].Score, ms[i].Score, 1e-9)
+			require.Equal(t, expected[i].Matched, ms[i].Matched)
+		}
+	})
+
+	t.Run("search finds the typos of a term by their keys", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		rp := repository.NewRepositoryProductMySql(mysqlDB(t))
+		p1 := internal.Product{ProductAttributes: internal.ProductAttributes{Name: "Chocolate Bar", CodeValue: "A1", Expiration: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}}
+		p2 := internal.Product{ProductAttributes: internal.ProductAttributes{Name: "Cookies", CodeValue: "A2", Expiration: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}}
+		require.NoError(t, rp.Save(ctx, &p1))
+		require.NoError(t, rp.Save(ctx, &p2))
+
+		// act
+		twoTypos, errTwo := rp.Search(ctx, "chcolatte", 0)
+		oneTypo, errOne := rp.Search(ctx, "cokies", 0)
+		tooMany, errMany := rp.Search(ctx, "cakis", 0)
+
+		// assert
+		require.NoError(t, errTwo)
+		require.Len(t, twoTypos, 1)
+		require.Equal(t, p1.Id, twoTypos[0].Id)
+		require.NoError(t, errOne)
+		require.Len(t, oneTypo, 1)
+		require.Equal(t, p2.Id, oneTypo[0].Id)
+		require.NoError(t, errMany)
+		require.Empty(t, tooMany)
+	})
+
+	t.Run("a product is not saved when its search terms are not", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		db := mysqlDB(t)
+		rp := repository.NewRepositoryProductMySql(db)
+		_, err := db.Exec("DROP TABLE `product_search_typos`")
+		require.NoError(t, err)
+		p := internal.Product{ProductAttributes: internal.ProductAttributes{Name: "Chocolate Bar", CodeValue: "A1", Expiration: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}}
+
+		// act
+		err = rp.Save(ctx, &p)
+
+		// assert
+		require.Error(t, err)
+		ps, err := rp.FindAll(ctx)
+		require.NoError(t, err)
+		require.Empty(t, ps)
+	})
+
+	t.Run("reindex indexes the products stored by other means", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		db := mysqlDB(t)
+		rp := repository.NewRepositoryProductMySql(db)
+		_, err := db.Exec("INSERT INTO `products` (`name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`) VALUES ('Chocolate Bar', 1, 'A1', true, '2024-01-01', 1, 1)")
+		require.NoError(t, err)
+		before, err := rp.Search(ctx, "chocolate", 0)
+		require.NoError(t, err)
+
+		// act
+		n, err := rp.Reindex(ctx)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Empty(t, before)
+		after, err := rp.Search(ctx, "chocolate", 0)
+		require.NoError(t, err)
+		require.Len(t, after, 1)
+	})
+}
+
+// Tests for UnitOfWorkMySql, each one on a new database of the embedded server
+func TestUnitOfWorkMySql(t *testing.T) {
+	repositorytest.UnitOfWork(t, func(t *testing.T) internal.UnitOfWork {
+		return repository.NewUnitOfWorkMySql(mysqlDB(t))
+	})
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"app/internal"
+	"app/platform/search"
+)
+
+const (
+	// productNameWeight is the weight of the name of a product in the score of a search.
+	productNameWeight = 1.0
+	// productCodeValueWeight is the weight of the code value of a product in the score of a search,
+	// a code value matched tells more about the product looked for than a name.
+	productCodeValueWeight = 2.0
+)
+
+// newProductIndex returns a new full-text index of products by name and code value.
+func newProductIndex() *search.Index {
+	return search.NewIndex(productNameWeight, productCodeValueWeight)
+}
+
+// indexProduct adds a product to an index, or replaces its texts.
+func indexProduct(x *search.Index, p internal.Product) {
+	x.Put(p.Id, p.Name, p.CodeValue)
+}
+
+// syncProductIndex brings an index up to date with the products of db: only the ones changed are indexed again
+// and the ones missing are removed.
+func syncProductIndex(x *search.Index, db map[int]internal.Product) {
+	for _, p := range db {
+		indexProduct(x, p)
+	}
+	x.Retain(func(id int) bool {
+		_, ok := db[id]
+		return ok
+	})
+}
+
+// searchProducts returns the products of db matched by a query, searched in an index of them.
+func searchProducts(x *search.Index, db map[int]internal.Product, query string, limit int) (ms []internal.ProductMatch) {
+	for _, h := range x.Search(query, limit) {
+		ms = append(ms, internal.ProductMatch{Product: db[h.ID], Score: h.Score, Matched: h.Matched})
+	}
+	return
+}
@@ -1,11 +1,19 @@
 package repository
 
-import "app/internal"
+import (
+	"app/internal"
+	"app/platform/search"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
 
 // NewRepositoryProductStore creates a new repository for products.
 func NewRepositoryProductStore(st internal.StoreProduct) (r *RepositoryProductStore) {
 	r = &RepositoryProductStore{
-		st: st,
+		st:    st,
+		index: newProductIndex(),
 	}
 	return
 }
@@ -14,10 +22,74 @@ func NewRepositoryProductStore(st internal.StoreProduct) (r *RepositoryProductSt
 type RepositoryProductStore struct {
 	// st is the underlying store.
 	st internal.StoreProduct
+	// mu guards index and indexed.
+	mu sync.Mutex
+	// index is the full-text index of the products: the first search builds it from the store and the writes
+	// of the repository update it, the ones made through other repositories of the store are not seen by it.
+	index *search.Index
+	// indexed is whether index was built.
+	indexed bool
+}
+
+// updateIndex applies a write of the repository to the index, once a search built it.
+func (r *RepositoryProductStore) updateIndex(fn func(x *search.Index)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexed {
+		fn(r.index)
+	}
+}
+
+// FindAll finds all products sorted by id.
+func (r *RepositoryProductStore) FindAll(ctx context.Context) (p []internal.Product, err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	// read all products
+	ps, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// sort products
+	for _, v := range ps {
+		p = append(p, v)
+	}
+	sort.Slice(p, func(i, j int) bool { return p[i].Id < p[j].Id })
+
+	return
+}
+
+// FindEach calls fn with each product by id, from a copy of them so fn may take its time.
+func (r *RepositoryProductStore) FindEach(ctx context.Context, fn func(p internal.Product) (err error)) (err error) {
+	// find all products
+	ps, err := r.FindAll(ctx)
+	if err != nil {
+		return
+	}
+
+	// call fn
+	for _, p := range ps {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		if err = fn(p); err != nil {
+			return
+		}
+	}
+	return
 }
 
 // FindById finds a product by id.
-func (r *RepositoryProductStore) FindById(id int) (p internal.Product, err error) {
+func (r *RepositoryProductStore) FindById(ctx context.Context, id int) (p internal.Product, err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	// read all products
 	ps, err := r.st.ReadAll()
 	if err != nil {
@@ -34,14 +106,50 @@ func (r *RepositoryProductStore) FindById(id int) (p internal.Product, err error
 	return
 }
 
+// FindByCodeValue finds a product by code value.
+func (r *RepositoryProductStore) FindByCodeValue(ctx context.Context, code string) (p internal.Product, err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	// read all products
+	ps, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// find product
+	for _, v := range ps {
+		if v.CodeValue == code {
+			p = v
+			return
+		}
+	}
+
+	err = internal.ErrRepositoryProductNotFound
+	return
+}
+
 // Save saves a product.
-func (r *RepositoryProductStore) Save(p *internal.Product) (err error) {
+func (r *RepositoryProductStore) Save(ctx context.Context, p *internal.Product) (err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	// read all products
 	ps, err := r.st.ReadAll()
 	if err != nil {
 		return
 	}
 
+	// check code value
+	if codeValueUsed(ps, 0, p.CodeValue) {
+		err = internal.ErrRepositoryProductNotUnique
+		return
+	}
+
 	// find max id
 	var maxId int
 	var cc int
@@ -68,19 +176,37 @@ func (r *RepositoryProductStore) Save(p *internal.Product) (err error) {
 		return
 	}
 
+	// index product
+	r.updateIndex(func(x *search.Index) { indexProduct(x, *p) })
+
 	return
 }
 
 // UpdateOrSave updates or saves a product.
-func (r *RepositoryProductStore) UpdateOrSave(p *internal.Product) (err error) {
+func (r *RepositoryProductStore) UpdateOrSave(ctx context.Context, p *internal.Product) (err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	// read all products
 	ps, err := r.st.ReadAll()
 	if err != nil {
 		return
 	}
 
-	// update product
+	// check code value
 	_, ok := ps[p.Id]
+	id := p.Id
+	if !ok {
+		id = 0
+	}
+	if codeValueUsed(ps, id, p.CodeValue) {
+		err = internal.ErrRepositoryProductNotUnique
+		return
+	}
+
+	// update product
 	switch ok {
 	case true:
 		ps[p.Id] = *p
@@ -112,11 +238,19 @@ func (r *RepositoryProductStore) UpdateOrSave(p *internal.Product) (err error) {
 		return
 	}
 
+	// index product
+	r.updateIndex(func(x *search.Index) { indexProduct(x, *p) })
+
 	return
 }
 
 // Update updates a product.
-func (r *RepositoryProductStore) Update(p *internal.Product) (err error) {
+func (r *RepositoryProductStore) Update(ctx context.Context, p *internal.Product) (err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	// read all products
 	ps, err := r.st.ReadAll()
 	if err != nil {
@@ -130,6 +264,12 @@ func (r *RepositoryProductStore) Update(p *internal.Product) (err error) {
 		return
 	}
 
+	// check code value
+	if codeValueUsed(ps, p.Id, p.CodeValue) {
+		err = internal.ErrRepositoryProductNotUnique
+		return
+	}
+
 	// update product
 	ps[p.Id] = *p
 
@@ -139,11 +279,19 @@ func (r *RepositoryProductStore) Update(p *internal.Product) (err error) {
 		return
 	}
 
+	// index product
+	r.updateIndex(func(x *search.Index) { indexProduct(x, *p) })
+
 	return
 }
 
 // Delete deletes a product.
-func (r *RepositoryProductStore) Delete(id int) (err error) {
+func (r *RepositoryProductStore) Delete(ctx context.Context, id int) (err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	// read all products
 	ps, err := r.st.ReadAll()
 	if err != nil {
@@ -166,5 +314,132 @@ func (r *RepositoryProductStore) Delete(id int) (err error) {
 		return
 	}
 
+	// unindex product
+	r.updateIndex(func(x *search.Index) { x.Remove(id) })
+
+	return
+}
+
+// UpdateIf updates a product if the stored one still equals expected.
+func (r *RepositoryProductStore) UpdateIf(ctx context.Context, p *internal.Product, expected internal.Product) (err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	// read all products
+	ps, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// check product
+	if err = productExpected(ps, expected); err != nil {
+		return
+	}
+
+	// check code value
+	if codeValueUsed(ps, p.Id, p.CodeValue) {
+		err = internal.ErrRepositoryProductNotUnique
+		return
+	}
+
+	// update product
+	ps[p.Id] = *p
+
+	// write all products
+	if err = r.st.WriteAll(ps); err != nil {
+		return
+	}
+
+	// index product
+	r.updateIndex(func(x *search.Index) { indexProduct(x, *p) })
+	return
+}
+
+// DeleteIf deletes a product if the stored one still equals expected.
+func (r *RepositoryProductStore) DeleteIf(ctx context.Context, expected internal.Product) (err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	// read all products
+	ps, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// check product
+	if err = productExpected(ps, expected); err != nil {
+		return
+	}
+
+	// delete product
+	delete(ps, expected.Id)
+
+	// write all products
+	if err = r.st.WriteAll(ps); err != nil {
+		return
+	}
+
+	// unindex product
+	r.updateIndex(func(x *search.Index) { x.Remove(expected.Id) })
 	return
-}
\ No newline at end of file
+}
+
+// Search finds the products matched by a query in the full-text index, built from the store by the first one.
+func (r *RepositoryProductStore) Search(ctx context.Context, query string, limit int) (ms []internal.ProductMatch, err error) {
+	// check context
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	// read all products
+	ps, err := r.st.ReadAll()
+	if err != nil {
+		return
+	}
+
+	// search products
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.indexed {
+		syncProductIndex(r.index, ps)
+		r.indexed = true
+	}
+	ms = searchProducts(r.index, ps, query, limit)
+
+	return
+}
+
+// productExpected checks the product with the id of expected is stored and still equals it.
+func productExpected(ps map[int]internal.Product, expected internal.Product) (err error) {
+	current, ok := ps[expected.Id]
+	switch {
+	case !ok:
+		err = internal.ErrRepositoryProductNotFound
+	case !sameProduct(current, expected):
+		err = internal.ErrRepositoryProductModified
+	}
+	return
+}
+
+// sameProduct returns whether two products have the same id and attributes, comparing their expiration by date.
+func sameProduct(a, b internal.Product) bool {
+	if a.Expiration.Format(time.DateOnly) != b.Expiration.Format(time.DateOnly) {
+		return false
+	}
+	a.Expiration, b.Expiration = time.Time{}, time.Time{}
+	return a == b
+}
+
+// codeValueUsed returns whether a product other than the one with id has the code value.
+func codeValueUsed(ps map[int]internal.Product, id int, code string) bool {
+	for _, v := range ps {
+		if v.Id != id && v.CodeValue == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/repository/repositorytest"
+	"app/internal/store"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newStoreJSON returns a json file store without products.
+func newStoreJSON(t *testing.T) *store.StoreProductJSON {
+	path := filepath.Join(t.TempDir(), "products.json")
+	require.NoError(t, os.WriteFile(path, []byte("[]"), 0644))
+	return store.NewStoreProductJSON(path)
+}
+
+// Tests for RepositoryProductStore with a json file store
+func TestRepositoryProductStore(t *testing.T) {
+	repositorytest.Products(t, func(t *testing.T) internal.RepositoryProduct {
+		return repository.NewRepositoryProductStore(newStoreJSON(t))
+	})
+	repositorytest.Search(t, func(t *testing.T) internal.RepositoryProduct {
+		return repository.NewRepositoryProductStore(newStoreJSON(t))
+	})
+}
+
+// Tests for UnitOfWorkStore with a json file store
+func TestUnitOfWorkStore(t *testing.T) {
+	repositorytest.UnitOfWork(t, func(t *testing.T) internal.UnitOfWork {
+		return repository.NewUnitOfWorkStore(newStoreJSON(t))
+	})
+
+	t.Run("commit writes the store once", func(t *testing.T) {
+		// arrange
+		st := newStoreJSON(t)
+		uow := repository.NewUnitOfWorkStore(st)
+		p1 := internal.Product{ProductAttributes: internal.ProductAttributes{CodeValue: "A1"}}
+		p2 := internal.Product{ProductAttributes: internal.ProductAttributes{CodeValue: "A2"}}
+
+		// act
+		err := uow.Do(context.Background(), func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+			require.NoError(t, rp.Save(context.Background(), &p1))
+			require.NoError(t, rp.Save(context.Background(), &p2))
+
+			// - the store is not written before the commit
+			ps, err := st.ReadAll()
+			require.NoError(t, err)
+			require.Empty(t, ps)
+			return
+		})
+
+		// assert
+		require.NoError(t, err)
+		ps, err := st.ReadAll()
+		require.NoError(t, err)
+		require.Len(t, ps, 2)
+	})
+}
+
+// Tests for the search of UnitOfWorkStore, whose index is kept across the units of work
+func TestUnitOfWorkStore_Search(t *testing.T) {
+	t.Run("rollback restores the index", func(t *testing.T) {
+		// arrange
+		uow := repository.NewUnitOfWorkStore(newStoreJSON(t))
+		p1 := internal.Product{ProductAttributes: internal.ProductAttributes{Name: "Chocolate Bar", CodeValue: "A1"}}
+		require.NoError(t, uow.Do(context.Background(), func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+			return rp.Save(ctx, &p1)
+		}))
+		errRollback := errors.New("rollback")
+
+		// act
+		err := uow.Do(context.Background(), func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+			p2 := internal.Product{ProductAttributes: internal.ProductAttributes{Name: "Chocolate - Dark", CodeValue: "A2"}}
+			require.NoError(t, rp.Save(ctx, &p2))
+			require.NoError(t, rp.Delete(ctx, p1.Id))
+			return errRollback
+		})
+
+		// assert
+		require.ErrorIs(t, err, errRollback)
+		var ms []internal.ProductMatch
+		require.NoError(t, uow.Do(context.Background(), func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+			ms, err = rp.Search(ctx, "chocolate", 0)
+			return
+		}))
+		require.Len(t, ms, 1)
+		require.Equal(t, p1.Id, ms[0].Id)
+	})
+}
@@ -0,0 +1,419 @@
+// Package repositorytest holds the contract every implementation of internal.RepositoryProduct must satisfy.
+// Each backend runs it from its own tests with a factory of empty repositories.
+package repositorytest
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns a new repository without products.
+type Factory func(t *testing.T) (rp internal.RepositoryProduct)
+
+// newProduct returns a product, not saved yet.
+func newProduct(code string) internal.Product {
+	return internal.Product{
+		ProductAttributes: internal.ProductAttributes{
+			Name:        "product " + code,
+			Quantity:    10,
+			CodeValue:   code,
+			IsPublished: true,
+			Expiration:  time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC),
+			Price:       9.5,
+		},
+	}
+}
+
+// requireProduct asserts two products are equal, comparing the expiration by date.
+func requireProduct(t *testing.T, expected, actual internal.Product) {
+	t.Helper()
+	require.Equal(t, expected.Expiration.Format(time.DateOnly), actual.Expiration.Format(time.DateOnly))
+	expected.Expiration, actual.Expiration = time.Time{}, time.Time{}
+	require.Equal(t, expected, actual)
+}
+
+// Products runs the contract of internal.RepositoryProduct.
+func Products(t *testing.T, factory Factory) {
+	t.Run("save assigns id", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p1, p2 := newProduct("A1"), newProduct("A2")
+
+		// act
+		err1 := rp.Save(context.Background(), &p1)
+		err2 := rp.Save(context.Background(), &p2)
+
+		// assert
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		require.Positive(t, p1.Id)
+		require.Positive(t, p2.Id)
+		require.NotEqual(t, p1.Id, p2.Id)
+		stored, err := rp.FindById(context.Background(), p1.Id)
+		require.NoError(t, err)
+		requireProduct(t, p1, stored)
+	})
+
+	t.Run("save not unique code value", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p, duplicate := newProduct("A1"), newProduct("A1")
+		require.NoError(t, rp.Save(context.Background(), &p))
+
+		// act
+		err := rp.Save(context.Background(), &duplicate)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrRepositoryProductNotUnique)
+	})
+
+	t.Run("find by id not found", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+
+		// act
+		_, err := rp.FindById(context.Background(), 1)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrRepositoryProductNotFound)
+	})
+
+	t.Run("find by code value", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p := newProduct("A1")
+		require.NoError(t, rp.Save(context.Background(), &p))
+
+		// act
+		found, errFound := rp.FindByCodeValue(context.Background(), "A1")
+		_, errNotFound := rp.FindByCodeValue(context.Background(), "B1")
+
+		// assert
+		require.NoError(t, errFound)
+		requireProduct(t, p, found)
+		require.ErrorIs(t, errNotFound, internal.ErrRepositoryProductNotFound)
+	})
+
+	t.Run("find all sorted by id", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p1, p2 := newProduct("A1"), newProduct("A2")
+		require.NoError(t, rp.Save(context.Background(), &p1))
+		require.NoError(t, rp.Save(context.Background(), &p2))
+
+		// act
+		ps, err := rp.FindAll(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, ps, 2)
+		requireProduct(t, p1, ps[0])
+		requireProduct(t, p2, ps[1])
+	})
+
+	t.Run("find each sorted by id until fn fails", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p1, p2, p3 := newProduct("A1"), newProduct("A2"), newProduct("A3")
+		require.NoError(t, rp.Save(context.Background(), &p1))
+		require.NoError(t, rp.Save(context.Background(), &p2))
+		require.NoError(t, rp.Save(context.Background(), &p3))
+		errStop := errors.New("stop")
+
+		// act
+		var ps []internal.Product
+		err := rp.FindEach(context.Background(), func(p internal.Product) (err error) {
+			ps = append(ps, p)
+			if len(ps) == 2 {
+				err = errStop
+			}
+			return
+		})
+
+		// assert
+		require.ErrorIs(t, err, errStop)
+		require.Len(t, ps, 2)
+		requireProduct(t, p1, ps[0])
+		requireProduct(t, p2, ps[1])
+	})
+
+	t.Run("update", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p := newProduct("A1")
+		require.NoError(t, rp.Save(context.Background(), &p))
+		p.Name, p.Quantity = "updated", 20
+
+		// act
+		err := rp.Update(context.Background(), &p)
+
+		// assert
+		require.NoError(t, err)
+		stored, err := rp.FindById(context.Background(), p.Id)
+		require.NoError(t, err)
+		requireProduct(t, p, stored)
+	})
+
+	t.Run("update with the same values", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p := newProduct("A1")
+		require.NoError(t, rp.Save(context.Background(), &p))
+
+		// act
+		err := rp.Update(context.Background(), &p)
+
+		// assert
+		require.NoError(t, err)
+	})
+
+	t.Run("update not found", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p := newProduct("A1")
+		p.Id = 1
+
+		// act
+		err := rp.Update(context.Background(), &p)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrRepositoryProductNotFound)
+	})
+
+	t.Run("update not unique code value", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p1, p2 := newProduct("A1"), newProduct("A2")
+		require.NoError(t, rp.Save(context.Background(), &p1))
+		require.NoError(t, rp.Save(context.Background(), &p2))
+		p2.CodeValue = "A1"
+
+		// act
+		err := rp.Update(context.Background(), &p2)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrRepositoryProductNotUnique)
+	})
+
+	t.Run("update or save updates an existing product", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p := newProduct("A1")
+		require.NoError(t, rp.Save(context.Background(), &p))
+		id := p.Id
+		p.Name = "updated"
+
+		// act
+		err := rp.UpdateOrSave(context.Background(), &p)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, id, p.Id)
+		stored, err := rp.FindById(context.Background(), id)
+		require.NoError(t, err)
+		requireProduct(t, p, stored)
+	})
+
+	t.Run("update or save saves a missing product", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p := newProduct("A1")
+
+		// act
+		err := rp.UpdateOrSave(context.Background(), &p)
+
+		// assert
+		require.NoError(t, err)
+		require.Positive(t, p.Id)
+		stored, err := rp.FindById(context.Background(), p.Id)
+		require.NoError(t, err)
+		requireProduct(t, p, stored)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p := newProduct("A1")
+		require.NoError(t, rp.Save(context.Background(), &p))
+
+		// act
+		err := rp.Delete(context.Background(), p.Id)
+		errNotFound := rp.Delete(context.Background(), p.Id)
+
+		// assert
+		require.NoError(t, err)
+		require.ErrorIs(t, errNotFound, internal.ErrRepositoryProductNotFound)
+		_, err = rp.FindById(context.Background(), p.Id)
+		require.ErrorIs(t, err, internal.ErrRepositoryProductNotFound)
+	})
+
+	t.Run("update if the product is the one expected", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p := newProduct("A1")
+		require.NoError(t, rp.Save(context.Background(), &p))
+		expected := p
+		p.Name = "updated"
+
+		// act
+		err := rp.UpdateIf(context.Background(), &p, expected)
+
+		// assert
+		require.NoError(t, err)
+		stored, err := rp.FindById(context.Background(), p.Id)
+		require.NoError(t, err)
+		requireProduct(t, p, stored)
+	})
+
+	t.Run("update if the product was modified", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p := newProduct("A1")
+		require.NoError(t, rp.Save(context.Background(), &p))
+		expected := p
+		other := p
+		other.Quantity = 20
+		require.NoError(t, rp.Update(context.Background(), &other))
+		p.Name = "updated"
+
+		// act
+		err := rp.UpdateIf(context.Background(), &p, expected)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrRepositoryProductModified)
+		stored, err := rp.FindById(context.Background(), p.Id)
+		require.NoError(t, err)
+		requireProduct(t, other, stored)
+	})
+
+	t.Run("delete if the product is the one expected", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p := newProduct("A1")
+		require.NoError(t, rp.Save(context.Background(), &p))
+
+		// act
+		err := rp.DeleteIf(context.Background(), p)
+		errNotFound := rp.DeleteIf(context.Background(), p)
+
+		// assert
+		require.NoError(t, err)
+		require.ErrorIs(t, errNotFound, internal.ErrRepositoryProductNotFound)
+	})
+
+	t.Run("delete if the product was modified", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p := newProduct("A1")
+		require.NoError(t, rp.Save(context.Background(), &p))
+		expected := p
+		p.Price = 10.25
+		require.NoError(t, rp.Update(context.Background(), &p))
+
+		// act
+		err := rp.DeleteIf(context.Background(), expected)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrRepositoryProductModified)
+		_, err = rp.FindById(context.Background(), p.Id)
+		require.NoError(t, err)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		p := newProduct("A1")
+
+		// act
+		_, errFind := rp.FindAll(ctx)
+		errSave := rp.Save(ctx, &p)
+
+		// assert
+		require.ErrorIs(t, errFind, context.Canceled)
+		require.ErrorIs(t, errSave, context.Canceled)
+		ps, err := rp.FindAll(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, ps)
+	})
+}
+
+// Search runs the contract of the search of internal.RepositoryProduct, apart from Products so a repository
+// can be tested without its full-text index.
+func Search(t *testing.T, factory Factory) {
+	t.Run("search ranks every term matched first", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p1, p2, p3 := newProduct("A1"), newProduct("A2"), newProduct("A3")
+		p1.Name, p2.Name, p3.Name = "Chocolate - Dark", "Chocolate Bar", "Cookies - Oreo"
+		for _, p := range []*internal.Product{&p1, &p2, &p3} {
+			require.NoError(t, rp.Save(context.Background(), p))
+		}
+
+		// act
+		ms, err := rp.Search(context.Background(), "dark chocolate", 0)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, ms, 2)
+		requireProduct(t, p1, ms[0].Product)
+		requireProduct(t, p2, ms[1].Product)
+		require.Greater(t, ms[0].Score, ms[1].Score)
+		require.Equal(t, map[string]int{"dark": 4, "chocolate": 9}, ms[0].Matched)
+	})
+
+	t.Run("search by prefix, typo and code value", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p1, p2 := newProduct("42957-002"), newProduct("54868-6276")
+		p1.Name, p2.Name = "Chocolate Bar", "Cookies - Oreo"
+		require.NoError(t, rp.Save(context.Background(), &p1))
+		require.NoError(t, rp.Save(context.Background(), &p2))
+
+		// act
+		prefix, errPrefix := rp.Search(context.Background(), "choc", 0)
+		typo, errTypo := rp.Search(context.Background(), "chocolte", 0)
+		code, errCode := rp.Search(context.Background(), "54868", 0)
+
+		// assert
+		require.NoError(t, errPrefix)
+		require.NoError(t, errTypo)
+		require.NoError(t, errCode)
+		require.Len(t, prefix, 1)
+		require.Equal(t, p1.Id, prefix[0].Id)
+		require.Equal(t, map[string]int{"chocolate": 4}, prefix[0].Matched)
+		require.Len(t, typo, 1)
+		require.Equal(t, p1.Id, typo[0].Id)
+		require.Len(t, code, 1)
+		require.Equal(t, p2.Id, code[0].Id)
+	})
+
+	t.Run("search follows updates and deletes", func(t *testing.T) {
+		// arrange
+		rp := factory(t)
+		p1, p2 := newProduct("A1"), newProduct("A2")
+		p1.Name, p2.Name = "Chocolate Bar", "Chocolate - Dark"
+		require.NoError(t, rp.Save(context.Background(), &p1))
+		require.NoError(t, rp.Save(context.Background(), &p2))
+
+		// act
+		p1.Name = "Vanilla Bar"
+		require.NoError(t, rp.Update(context.Background(), &p1))
+		require.NoError(t, rp.Delete(context.Background(), p2.Id))
+		chocolate, errChocolate := rp.Search(context.Background(), "chocolate", 0)
+		vanilla, errVanilla := rp.Search(context.Background(), "vanilla", 1)
+
+		// assert
+		require.NoError(t, errChocolate)
+		require.NoError(t, errVanilla)
+		require.Empty(t, chocolate)
+		require.Len(t, vanilla, 1)
+		requireProduct(t, p1, vanilla[0].Product)
+	})
+}
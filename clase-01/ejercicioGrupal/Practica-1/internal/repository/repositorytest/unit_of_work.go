@@ -0,0 +1,153 @@
+package repositorytest
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// UnitOfWorkFactory returns a new unit of work over a repository without products.
+type UnitOfWorkFactory func(t *testing.T) (uow internal.UnitOfWork)
+
+// requireEmpty asserts a unit of work sees no products.
+func requireEmpty(t *testing.T, uow internal.UnitOfWork) {
+	t.Helper()
+	err := uow.Do(context.Background(), func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		ps, err := rp.FindAll(ctx)
+		require.NoError(t, err)
+		require.Empty(t, ps)
+		return
+	})
+	require.NoError(t, err)
+}
+
+// UnitOfWork runs the contract of internal.UnitOfWork.
+func UnitOfWork(t *testing.T, factory UnitOfWorkFactory) {
+	t.Run("commit", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		p1, p2 := newProduct("A1"), newProduct("A2")
+
+		// act
+		err := uow.Do(context.Background(), func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+			if err = rp.Save(ctx, &p1); err != nil {
+				return
+			}
+			err = rp.Save(ctx, &p2)
+			return
+		})
+
+		// assert
+		require.NoError(t, err)
+		err = uow.Do(context.Background(), func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+			ps, err := rp.FindAll(ctx)
+			require.NoError(t, err)
+			require.Len(t, ps, 2)
+			requireProduct(t, p1, ps[0])
+			requireProduct(t, p2, ps[1])
+			return
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		errFail := errors.New("fail")
+
+		// act
+		err := uow.Do(context.Background(), func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+			p := newProduct("A1")
+			require.NoError(t, rp.Save(ctx, &p))
+			err = errFail
+			return
+		})
+
+		// assert
+		require.ErrorIs(t, err, errFail)
+		requireEmpty(t, uow)
+	})
+
+	t.Run("rollback on panic", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+
+		// act
+		require.Panics(t, func() {
+			uow.Do(context.Background(), func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+				p := newProduct("A1")
+				require.NoError(t, rp.Save(ctx, &p))
+				panic("fail")
+			})
+		})
+
+		// assert
+		requireEmpty(t, uow)
+	})
+
+	t.Run("nested call joins the transaction", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		errFail := errors.New("fail")
+
+		// act
+		err := uow.Do(context.Background(), func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+			err = uow.Do(ctx, func(ctx context.Context, nested internal.RepositoryProduct) (err error) {
+				p := newProduct("A1")
+				err = nested.Save(ctx, &p)
+				return
+			})
+			require.NoError(t, err)
+
+			// - the outer call sees the nested write before the commit
+			_, err = rp.FindByCodeValue(ctx, "A1")
+			require.NoError(t, err)
+			err = errFail
+			return
+		})
+
+		// assert
+		require.ErrorIs(t, err, errFail)
+		requireEmpty(t, uow)
+	})
+
+	t.Run("canceled context does not begin", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var called bool
+
+		// act
+		err := uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+			called = true
+			return
+		})
+
+		// assert
+		require.ErrorIs(t, err, context.Canceled)
+		require.False(t, called)
+	})
+
+	t.Run("context canceled during the transaction rolls back", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// act
+		err := uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+			p := newProduct("A1")
+			err = rp.Save(ctx, &p)
+			cancel()
+			return
+		})
+
+		// assert
+		require.Error(t, err)
+		requireEmpty(t, uow)
+	})
+}
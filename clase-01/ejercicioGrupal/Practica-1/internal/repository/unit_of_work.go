@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+)
+
+// unitOfWorkKey is the context key of the unit of work in progress.
+type unitOfWorkKey struct{}
+
+// unitOfWorkTx is the unit of work in progress of a context.
+type unitOfWorkTx struct {
+	// owner is the unit of work.
+	owner any
+	// rp is the repository of its transaction.
+	rp internal.RepositoryProduct
+}
+
+// withUnitOfWork returns a context carrying the transaction of a unit of work, for nested calls to join it.
+func withUnitOfWork(ctx context.Context, owner any, rp internal.RepositoryProduct) context.Context {
+	return context.WithValue(ctx, unitOfWorkKey{}, &unitOfWorkTx{owner: owner, rp: rp})
+}
+
+// joinUnitOfWork returns the repository of the transaction of owner in progress in ctx, if any.
+func joinUnitOfWork(ctx context.Context, owner any) (rp internal.RepositoryProduct, ok bool) {
+	tx, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWorkTx)
+	if !ok || tx.owner != owner {
+		ok = false
+		return
+	}
+	rp = tx.rp
+	return
+}
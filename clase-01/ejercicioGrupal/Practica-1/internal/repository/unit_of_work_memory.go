@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"maps"
+	"sync"
+)
+
+// NewUnitOfWorkMemory creates a new unit of work over an in-memory repository.
+func NewUnitOfWorkMemory(rp *RepositoryProductMemory) (u *UnitOfWorkMemory) {
+	u = &UnitOfWorkMemory{
+		rp: rp,
+	}
+	return
+}
+
+// UnitOfWorkMemory is a unit of work over an in-memory repository.
+// Units of work run one at a time and a rollback restores the products as they were when it began,
+// so writes made meanwhile through the repository outside a unit of work are not isolated from it.
+type UnitOfWorkMemory struct {
+	// mu serializes the units of work.
+	mu sync.Mutex
+	// rp is the repository.
+	rp *RepositoryProductMemory
+}
+
+// Do runs fn as a transaction.
+func (u *UnitOfWorkMemory) Do(ctx context.Context, fn func(ctx context.Context, rp internal.RepositoryProduct) error) (err error) {
+	// nested: join the transaction
+	if rp, ok := joinUnitOfWork(ctx, u); ok {
+		err = fn(ctx, rp)
+		return
+	}
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	// begin: snapshot the products
+	u.rp.mu.RLock()
+	db, lastId := maps.Clone(u.rp.db), u.rp.lastId
+	u.rp.mu.RUnlock()
+
+	// roll back unless committed, also on panic
+	var committed bool
+	defer func() {
+		if !committed {
+			u.rp.mu.Lock()
+			u.rp.db, u.rp.lastId = db, lastId
+			syncProductIndex(u.rp.index, db)
+			u.rp.mu.Unlock()
+		}
+	}()
+
+	if err = fn(withUnitOfWork(ctx, u, u.rp), u.rp); err != nil {
+		return
+	}
+	// - a done context rolls back, as it does a sql transaction
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	committed = true
+	return
+}
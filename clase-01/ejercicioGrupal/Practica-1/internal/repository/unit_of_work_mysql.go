@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"database/sql"
+)
+
+// NewUnitOfWorkMySql creates a new unit of work over a mysql database.
+func NewUnitOfWorkMySql(db *sql.DB) (u *UnitOfWorkMySql) {
+	u = &UnitOfWorkMySql{
+		db: db,
+	}
+	return
+}
+
+// UnitOfWorkMySql is a unit of work over a mysql database, its repository runs on a *sql.Tx.
+type UnitOfWorkMySql struct {
+	// db is the underlying database.
+	db *sql.DB
+}
+
+// Do runs fn within a transaction.
+func (u *UnitOfWorkMySql) Do(ctx context.Context, fn func(ctx context.Context, rp internal.RepositoryProduct) error) (err error) {
+	// nested: join the transaction
+	if rp, ok := joinUnitOfWork(ctx, u); ok {
+		err = fn(ctx, rp)
+		return
+	}
+
+	// begin, a canceled context rolls the transaction back
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	rp := &RepositoryProductMySql{db: tx}
+	if err = fn(withUnitOfWork(ctx, u, rp), rp); err != nil {
+		return
+	}
+
+	// commit
+	err = tx.Commit()
+	return
+}
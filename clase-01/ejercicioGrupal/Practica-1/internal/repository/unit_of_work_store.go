@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"app/internal"
+	"app/platform/search"
+	"context"
+	"sync"
+)
+
+// NewUnitOfWorkStore creates a new unit of work over a product store.
+func NewUnitOfWorkStore(st internal.StoreProduct) (u *UnitOfWorkStore) {
+	u = &UnitOfWorkStore{
+		st:    st,
+		index: newProductIndex(),
+	}
+	return
+}
+
+// UnitOfWorkStore is a unit of work over a product store.
+// It reads the products once, works on them in memory and writes them back in a single WriteAll on commit,
+// so a rollback leaves the store untouched. Units of work run one at a time, writes made meanwhile
+// through other repositories of the store are overwritten by the commit and not seen by the index.
+type UnitOfWorkStore struct {
+	// mu serializes the units of work.
+	mu sync.Mutex
+	// st is the underlying store.
+	st internal.StoreProduct
+	// index is the full-text index of the products, kept across the units of work: the first one builds it
+	// with the products it reads, the writes update it and a rollback brings it back to the products read.
+	index *search.Index
+	// indexed is whether index was built.
+	indexed bool
+}
+
+// Do runs fn as a transaction.
+func (u *UnitOfWorkStore) Do(ctx context.Context, fn func(ctx context.Context, rp internal.RepositoryProduct) error) (err error) {
+	// nested: join the transaction
+	if rp, ok := joinUnitOfWork(ctx, u); ok {
+		err = fn(ctx, rp)
+		return
+	}
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	// begin: read the products, indexed by the first unit of work
+	ps, err := u.st.ReadAll()
+	if err != nil {
+		return
+	}
+	if !u.indexed {
+		syncProductIndex(u.index, ps)
+		u.indexed = true
+	}
+	rp := newRepositoryProductMemory(ps, u.index)
+
+	// roll back the index unless committed, a panic leaves the store untouched
+	var committed bool
+	defer func() {
+		if !committed {
+			syncProductIndex(u.index, ps)
+		}
+	}()
+
+	if err = fn(withUnitOfWork(ctx, u, rp), rp); err != nil {
+		return
+	}
+	// - a done context rolls back, as it does a sql transaction
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	// commit: write the products
+	if err = u.st.WriteAll(rp.db); err != nil {
+		return
+	}
+	committed = true
+	return
+}
@@ -0,0 +1,116 @@
+package seed
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+)
+
+// errDryRun is returned within the transaction of a batch of a dry run, to roll it back.
+var errDryRun = errors.New("seed: dry run")
+
+// NewSeederMySQL creates a new seeder of products into a MySQL database.
+func NewSeederMySQL(db *sql.DB, batchSize int, dryRun bool) (s *SeederMySQL) {
+	// default config
+	defaultBatchSize := 100
+	if batchSize > 0 {
+		defaultBatchSize = batchSize
+	}
+
+	s = &SeederMySQL{
+		uow:       repository.NewUnitOfWorkMySql(db),
+		batchSize: defaultBatchSize,
+		dryRun:    dryRun,
+	}
+	return
+}
+
+// SeederMySQL seeds products through the mysql product repository, into the schema of docs/db/migrations.
+// Products are matched by code value, so running it several times updates instead of duplicating them.
+// New products are stored in the default warehouse, as the repository does with every product it saves.
+type SeederMySQL struct {
+	// uow is the unit of work each batch runs in.
+	uow *repository.UnitOfWorkMySql
+	// batchSize is the number of products written per transaction.
+	batchSize int
+	// dryRun rolls back every batch instead of committing it.
+	dryRun bool
+}
+
+// Report is the result of a seed.
+type Report struct {
+	// Inserted is the number of products inserted.
+	Inserted int
+	// Updated is the number of products updated.
+	Updated int
+	// Batches is the number of batches written.
+	Batches int
+}
+
+// Seed writes the products, sorted by id, in batches.
+// A batch that fails is rolled back and stops the seed, the batches before it stay written.
+func (s *SeederMySQL) Seed(ctx context.Context, p map[int]internal.Product) (r Report, err error) {
+	// sort products
+	ps := make([]internal.Product, 0, len(p))
+	for _, v := range p {
+		ps = append(ps, v)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].Id < ps[j].Id })
+
+	// write batches
+	for start := 0; start < len(ps); start += s.batchSize {
+		end := min(start+s.batchSize, len(ps))
+
+		var inserted, updated int
+		inserted, updated, err = s.seedBatch(ctx, ps[start:end])
+		if err != nil {
+			return
+		}
+		r.Inserted += inserted
+		r.Updated += updated
+		r.Batches++
+	}
+
+	return
+}
+
+// seedBatch writes a batch of products in a single transaction.
+func (s *SeederMySQL) seedBatch(ctx context.Context, ps []internal.Product) (inserted, updated int, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		inserted, updated = 0, 0
+		for _, p := range ps {
+			// update the product stored with the code value, or save it with a new id
+			var stored internal.Product
+			stored, err = rp.FindByCodeValue(ctx, p.CodeValue)
+			switch {
+			case err == nil:
+				p.Id = stored.Id
+				if err = rp.Update(ctx, &p); err != nil {
+					return
+				}
+				updated++
+			case errors.Is(err, internal.ErrRepositoryProductNotFound):
+				p.Id = 0
+				if err = rp.Save(ctx, &p); err != nil {
+					return
+				}
+				inserted++
+			default:
+				return
+			}
+		}
+
+		// rollback
+		if s.dryRun {
+			err = errDryRun
+		}
+		return
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	return
+}
@@ -0,0 +1,144 @@
+package seed_test
+
+import (
+	"app/docs/db/migrations"
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/seed"
+	"app/platform/migrate"
+	"app/platform/mysqltest"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mysqlServer is the embedded mysql compatible server shared by the tests of the package, started on first use
+var mysqlServer = sync.OnceValues(mysqltest.NewServer)
+
+// mysqlDB returns a new database of the embedded server with the migrations applied
+func mysqlDB(t *testing.T) (db *sql.DB) {
+	s, err := mysqlServer()
+	require.NoError(t, err)
+	cfg, err := s.NewDatabase()
+	require.NoError(t, err)
+	// - the repository reads the dates as text, as the connection of the application does
+	cfg.ParseTime = false
+	db, err = sql.Open("mysql", cfg.FormatDSN())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	fsys, err := migrations.FS()
+	require.NoError(t, err)
+	ms, err := migrate.Load(fsys)
+	require.NoError(t, err)
+	_, err = migrate.NewMigrator(db, ms).Up(0)
+	require.NoError(t, err)
+	return
+}
+
+// products returns the products of the json store to seed, by id
+func products(ps ...internal.Product) (p map[int]internal.Product) {
+	p = make(map[int]internal.Product, len(ps))
+	for _, v := range ps {
+		p[v.Id] = v
+	}
+	return
+}
+
+// newProduct returns a product of the json store
+func newProduct(id int, name, code string, quantity int) internal.Product {
+	return internal.Product{
+		Id: id,
+		ProductAttributes: internal.ProductAttributes{
+			Name:        name,
+			Quantity:    quantity,
+			CodeValue:   code,
+			IsPublished: true,
+			Expiration:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Price:       1.5,
+		},
+	}
+}
+
+// Tests for SeederMySQL.Seed function
+func TestSeederMySQL_Seed(t *testing.T) {
+	p1 := newProduct(1, "Water", "W001", 10)
+	p2 := newProduct(2, "Bread", "B001", 5)
+	p3 := newProduct(3, "Milk", "M001", 8)
+
+	t.Run("success - inserts the new products and updates the ones stored by code value", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		db := mysqlDB(t)
+		rp := repository.NewRepositoryProductMySql(db)
+		stored := newProduct(0, "Old water", "W001", 1)
+		require.NoError(t, rp.Save(ctx, &stored))
+		sd := seed.NewSeederMySQL(db, 2, false)
+
+		// act
+		r, err := sd.Seed(ctx, products(p1, p2, p3))
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, seed.Report{Inserted: 2, Updated: 1, Batches: 2}, r)
+		ps, err := rp.FindAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, ps, 3)
+		water, err := rp.FindByCodeValue(ctx, "W001")
+		require.NoError(t, err)
+		require.Equal(t, stored.Id, water.Id)
+		require.Equal(t, "Water", water.Name)
+		require.Equal(t, 10, water.Quantity)
+		var warehouse int
+		require.NoError(t, db.QueryRow("SELECT `id_warehouse` FROM `products` WHERE `code_value` = 'M001'").Scan(&warehouse))
+		require.Equal(t, 1, warehouse)
+	})
+
+	t.Run("success - dry run rolls back every batch", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		db := mysqlDB(t)
+		rp := repository.NewRepositoryProductMySql(db)
+		stored := newProduct(0, "Old water", "W001", 1)
+		require.NoError(t, rp.Save(ctx, &stored))
+		sd := seed.NewSeederMySQL(db, 2, true)
+
+		// act
+		r, err := sd.Seed(ctx, products(p1, p2, p3))
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, seed.Report{Inserted: 2, Updated: 1, Batches: 2}, r)
+		ps, err := rp.FindAll(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []internal.Product{stored}, ps)
+	})
+
+	t.Run("failure - a batch error rolls back the batch and keeps the ones before", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		db := mysqlDB(t)
+		rp := repository.NewRepositoryProductMySql(db)
+		invalid := p3
+		invalid.Name = strings.Repeat("a", 256)
+		p4 := newProduct(4, "Tea", "T001", 2)
+		sd := seed.NewSeederMySQL(db, 2, false)
+
+		// act
+		r, err := sd.Seed(ctx, products(p1, p2, p4, invalid))
+
+		// assert
+		require.Error(t, err)
+		require.Equal(t, seed.Report{Inserted: 2, Batches: 1}, r)
+		ps, err := rp.FindAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, ps, 2)
+		require.Equal(t, "W001", ps[0].CodeValue)
+		require.Equal(t, "B001", ps[1].CodeValue)
+	})
+}
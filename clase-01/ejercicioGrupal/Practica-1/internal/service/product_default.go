@@ -0,0 +1,222 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// NewServiceProductDefault creates a new default service for products.
+func NewServiceProductDefault(uow internal.UnitOfWork) (s *ServiceProductDefault) {
+	s = &ServiceProductDefault{
+		uow: uow,
+	}
+	return
+}
+
+// ServiceProductDefault is the default service for products.
+// Each operation runs in a unit of work, so its checks and writes commit or roll back together.
+type ServiceProductDefault struct {
+	// uow is the unit of work of the repository for products.
+	uow internal.UnitOfWork
+}
+
+// FindAll finds all products.
+func (s *ServiceProductDefault) FindAll(ctx context.Context) (p []internal.Product, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		p, err = rp.FindAll(ctx)
+		return
+	})
+	return
+}
+
+// FindEach calls fn with each product by id.
+func (s *ServiceProductDefault) FindEach(ctx context.Context, fn func(p internal.Product) (err error)) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		err = rp.FindEach(ctx, fn)
+		return
+	})
+	return
+}
+
+// FindById finds a product by id.
+func (s *ServiceProductDefault) FindById(ctx context.Context, id int) (p internal.Product, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		p, err = rp.FindById(ctx, id)
+		return
+	})
+	return
+}
+
+// Create validates and saves a product.
+func (s *ServiceProductDefault) Create(ctx context.Context, p *internal.Product) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		// check rules
+		if err = s.check(ctx, rp, *p); err != nil {
+			return
+		}
+
+		// save product
+		err = rp.Save(ctx, p)
+		return
+	})
+	return
+}
+
+// UpdateOrCreate validates and updates or saves a product.
+func (s *ServiceProductDefault) UpdateOrCreate(ctx context.Context, p *internal.Product) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		// check rules
+		if err = s.check(ctx, rp, *p); err != nil {
+			return
+		}
+
+		// update or save product
+		err = rp.UpdateOrSave(ctx, p)
+		return
+	})
+	return
+}
+
+// Update validates and updates a product.
+func (s *ServiceProductDefault) Update(ctx context.Context, p *internal.Product) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		err = s.update(ctx, rp, p)
+		return
+	})
+	return
+}
+
+// UpdateIf validates and updates a product if the stored one is still the expected one.
+func (s *ServiceProductDefault) UpdateIf(ctx context.Context, p *internal.Product, expected internal.Product) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		// check rules
+		if err = s.check(ctx, rp, *p); err != nil {
+			return
+		}
+
+		// update product
+		err = rp.UpdateIf(ctx, p, expected)
+		return
+	})
+	return
+}
+
+// Delete deletes a product.
+func (s *ServiceProductDefault) Delete(ctx context.Context, id int) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		err = rp.Delete(ctx, id)
+		return
+	})
+	return
+}
+
+// DeleteIf deletes a product if the stored one is still the expected one.
+func (s *ServiceProductDefault) DeleteIf(ctx context.Context, expected internal.Product) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		err = rp.DeleteIf(ctx, expected)
+		return
+	})
+	return
+}
+
+// Import creates a product or updates the one with its code value.
+func (s *ServiceProductDefault) Import(ctx context.Context, p *internal.Product) (created bool, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		// find product by code value
+		current, err := rp.FindByCodeValue(ctx, p.CodeValue)
+		switch {
+		case err == nil:
+			p.Id = current.Id
+			err = s.update(ctx, rp, p)
+		case errors.Is(err, internal.ErrRepositoryProductNotFound):
+			if err = s.check(ctx, rp, *p); err != nil {
+				return
+			}
+			err = rp.Save(ctx, p)
+			created = err == nil
+		}
+		return
+	})
+	return
+}
+
+// Search finds the products matched by a query, a blank one matches none.
+func (s *ServiceProductDefault) Search(ctx context.Context, query string, limit int) (ms []internal.ProductMatch, err error) {
+	// check query
+	if strings.TrimSpace(query) == "" {
+		return
+	}
+
+	// check limit
+	switch {
+	case limit <= 0:
+		limit = internal.ProductSearchLimit
+	case limit > internal.ProductSearchMaxLimit:
+		limit = internal.ProductSearchMaxLimit
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context, rp internal.RepositoryProduct) (err error) {
+		ms, err = rp.Search(ctx, query, limit)
+		return
+	})
+	return
+}
+
+// update validates and updates a product within a unit of work.
+func (s *ServiceProductDefault) update(ctx context.Context, rp internal.RepositoryProduct, p *internal.Product) (err error) {
+	// check rules
+	if err = s.check(ctx, rp, *p); err != nil {
+		return
+	}
+
+	// update product
+	err = rp.Update(ctx, p)
+	return
+}
+
+// check validates a product and checks its code value is not used by another product.
+func (s *ServiceProductDefault) check(ctx context.Context, rp internal.RepositoryProduct, p internal.Product) (err error) {
+	// validate fields
+	if err = validateProduct(p); err != nil {
+		return
+	}
+
+	// check code value
+	other, err := rp.FindByCodeValue(ctx, p.CodeValue)
+	switch {
+	case errors.Is(err, internal.ErrRepositoryProductNotFound):
+		err = nil
+	case err != nil:
+	case other.Id != p.Id:
+		err = internal.ErrRepositoryProductNotUnique
+	}
+	return
+}
+
+// validateProduct returns a *internal.FieldError for the first field of a product that breaks a rule.
+func validateProduct(p internal.Product) (err error) {
+	fail := func(field, code, message string) error {
+		return &internal.FieldError{Field: field, Code: code, Message: message, Err: internal.ErrServiceProductInvalid}
+	}
+
+	y, m, d := time.Now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	switch {
+	case strings.TrimSpace(p.Name) == "":
+		err = fail("name", "required", "is required")
+	case p.Quantity < 0:
+		err = fail("quantity", "min", "must not be negative")
+	case strings.TrimSpace(p.CodeValue) == "":
+		err = fail("code_value", "required", "is required")
+	case p.Expiration.IsZero():
+		err = fail("expiration", "required", "is required")
+	case p.IsPublished && p.Expiration.Before(today):
+		err = fail("expiration", "expired", "has passed, an expired product can not be published")
+	case p.Price < 0:
+		err = fail("price", "min", "must not be negative")
+	}
+	return
+}
@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newProduct returns a valid product, not saved yet.
+func newProduct(code string) internal.Product {
+	return internal.Product{
+		ProductAttributes: internal.ProductAttributes{
+			Name:        "product " + code,
+			Quantity:    10,
+			CodeValue:   code,
+			IsPublished: true,
+			Expiration:  time.Now().AddDate(1, 0, 0),
+			Price:       9.5,
+		},
+	}
+}
+
+// Tests for ServiceProductDefault.Create
+func TestServiceProductDefault_Create(t *testing.T) {
+	t.Run("success - product created", func(t *testing.T) {
+		// arrange
+		rp := repository.NewRepositoryProductMemory(nil)
+		sv := service.NewServiceProductDefault(repository.NewUnitOfWorkMemory(rp))
+		p := newProduct("A1")
+
+		// act
+		err := sv.Create(context.Background(), &p)
+
+		// assert
+		require.NoError(t, err)
+		require.Positive(t, p.Id)
+		_, err = rp.FindById(context.Background(), p.Id)
+		require.NoError(t, err)
+	})
+
+	t.Run("failure - invalid fields", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceProductDefault(repository.NewUnitOfWorkMemory(repository.NewRepositoryProductMemory(nil)))
+		cases := map[string]func(p *internal.Product){
+			"name":       func(p *internal.Product) { p.Name = " " },
+			"quantity":   func(p *internal.Product) { p.Quantity = -1 },
+			"code_value": func(p *internal.Product) { p.CodeValue = "" },
+			"expiration": func(p *internal.Product) { p.Expiration = time.Now().AddDate(0, 0, -2) },
+			"price":      func(p *internal.Product) { p.Price = -0.5 },
+		}
+
+		for field, invalidate := range cases {
+			p := newProduct("A1")
+			invalidate(&p)
+
+			// act
+			err := sv.Create(context.Background(), &p)
+
+			// assert
+			require.ErrorIs(t, err, internal.ErrServiceProductInvalid, field)
+			var fieldErr *internal.FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			require.Equal(t, field, fieldErr.Field)
+		}
+	})
+
+	t.Run("failure - code value not unique", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceProductDefault(repository.NewUnitOfWorkMemory(repository.NewRepositoryProductMemory(nil)))
+		p, duplicate := newProduct("A1"), newProduct("A1")
+		require.NoError(t, sv.Create(context.Background(), &p))
+
+		// act
+		err := sv.Create(context.Background(), &duplicate)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrRepositoryProductNotUnique)
+	})
+}
+
+// Tests for ServiceProductDefault.Update
+func TestServiceProductDefault_Update(t *testing.T) {
+	t.Run("success - expired product unpublished", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceProductDefault(repository.NewUnitOfWorkMemory(repository.NewRepositoryProductMemory(nil)))
+		p := newProduct("A1")
+		require.NoError(t, sv.Create(context.Background(), &p))
+		p.IsPublished = false
+		p.Expiration = time.Now().AddDate(0, 0, -2)
+
+		// act
+		err := sv.Update(context.Background(), &p)
+
+		// assert
+		require.NoError(t, err)
+	})
+
+	t.Run("failure - code value of another product", func(t *testing.T) {
+		// arrange
+		sv := service.NewServiceProductDefault(repository.NewUnitOfWorkMemory(repository.NewRepositoryProductMemory(nil)))
+		p1, p2 := newProduct("A1"), newProduct("A2")
+		require.NoError(t, sv.Create(context.Background(), &p1))
+		require.NoError(t, sv.Create(context.Background(), &p2))
+		p2.CodeValue = "A1"
+
+		// act
+		err := sv.Update(context.Background(), &p2)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrRepositoryProductNotUnique)
+	})
+}
+
+// Tests for ServiceProductDefault.Import
+func TestServiceProductDefault_Import(t *testing.T) {
+	t.Run("success - created then updated by code value", func(t *testing.T) {
+		// arrange
+		rp := repository.NewRepositoryProductMemory(nil)
+		sv := service.NewServiceProductDefault(repository.NewUnitOfWorkMemory(rp))
+		p, again := newProduct("A1"), newProduct("A1")
+		again.Name = "updated"
+
+		// act
+		created, err := sv.Import(context.Background(), &p)
+		createdAgain, errAgain := sv.Import(context.Background(), &again)
+
+		// assert
+		require.NoError(t, err)
+		require.True(t, created)
+		require.NoError(t, errAgain)
+		require.False(t, createdAgain)
+		require.Equal(t, p.Id, again.Id)
+		stored, err := rp.FindById(context.Background(), p.Id)
+		require.NoError(t, err)
+		require.Equal(t, "updated", stored.Name)
+	})
+}
+
+// Tests for ServiceProductDefault.Search
+func TestServiceProductDefault_Search(t *testing.T) {
+	t.Run("success - limited to the max limit", func(t *testing.T) {
+		// arrange
+		rp := repository.NewRepositoryProductMemory(nil)
+		sv := service.NewServiceProductDefault(repository.NewUnitOfWorkMemory(rp))
+		for i := 0; i < internal.ProductSearchMaxLimit+1; i++ {
+			p := newProduct(fmt.Sprintf("A%d", i))
+			require.NoError(t, rp.Save(context.Background(), &p))
+		}
+
+		// act
+		ms, err := sv.Search(context.Background(), "product", 0)
+		msMax, errMax := sv.Search(context.Background(), "product", internal.ProductSearchMaxLimit+1)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, ms, internal.ProductSearchLimit)
+		require.NoError(t, errMax)
+		require.Len(t, msMax, internal.ProductSearchMaxLimit)
+	})
+
+	t.Run("success - blank query matches none", func(t *testing.T) {
+		// arrange
+		rp := repository.NewRepositoryProductMemory(nil)
+		sv := service.NewServiceProductDefault(repository.NewUnitOfWorkMemory(rp))
+		p := newProduct("A1")
+		require.NoError(t, rp.Save(context.Background(), &p))
+
+		// act
+		ms, err := sv.Search(context.Background(), "  ", 0)
+
+		// assert
+		require.NoError(t, err)
+		require.Empty(t, ms)
+	})
+}
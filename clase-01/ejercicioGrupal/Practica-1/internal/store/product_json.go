@@ -4,6 +4,7 @@ import (
 	"app/internal"
 	"encoding/json"
 	"os"
+	"sort"
 	"time"
 )
 
@@ -75,9 +76,15 @@ func (s *StoreProductJSON) ReadAll() (p map[int]internal.Product, err error) {
 
 // WriteAll writes all products to the store.
 func (s *StoreProductJSON) WriteAll(p map[int]internal.Product) (err error) {
-	// serialize
+	// serialize, sorted by id
+	ids := make([]int, 0, len(p))
+	for k := range p {
+		ids = append(ids, k)
+	}
+	sort.Ints(ids)
 	var pr []ProductJSON
-	for _, v := range p {
+	for _, k := range ids {
+		v := p[k]
 		pr = append(pr, ProductJSON{
 			Id:          v.Id,
 			Name:        v.Name,
@@ -0,0 +1,11 @@
+package internal
+
+import "context"
+
+// UnitOfWork is an interface for a unit of work: repository calls that commit or roll back together.
+type UnitOfWork interface {
+	// Do runs fn with a repository bound to a transaction, begun with ctx, that commits when fn returns nil
+	// and rolls back when it returns an error, panics or ctx is done.
+	// A Do called with the context fn receives joins the same transaction, which the outermost Do ends.
+	Do(ctx context.Context, fn func(ctx context.Context, rp RepositoryProduct) error) (err error)
+}
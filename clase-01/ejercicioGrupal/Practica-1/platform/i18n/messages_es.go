@@ -0,0 +1,28 @@
+package i18n
+
+// messagesES are the Spanish translations of the messages shared by every api: status texts and validation rules.
+var messagesES = map[string]string{
+	// status texts
+	"Bad Request":              "Solicitud incorrecta",
+	"Not Found":                "No encontrado",
+	"Method Not Allowed":       "Método no permitido",
+	"Not Acceptable":           "No aceptable",
+	"Conflict":                 "Conflicto",
+	"Precondition Failed":      "Precondición fallida",
+	"Request Entity Too Large": "Entidad de solicitud demasiado grande",
+	"Unsupported Media Type":   "Tipo de contenido no soportado",
+	"Unprocessable Entity":     "Entidad no procesable",
+	"Precondition Required":    "Precondición requerida",
+	"Client Closed Request":    "El cliente cerró la solicitud",
+	"Internal Server Error":    "Error interno del servidor",
+	"Gateway Timeout":          "Tiempo de espera agotado",
+	// validation rules
+	"%[1]s is required":                         "%[1]s es obligatorio",
+	"%[1]s must be at least %[2]s":              "%[1]s debe ser al menos %[2]s",
+	"%[1]s must be at most %[2]s":               "%[1]s debe ser como máximo %[2]s",
+	"%[1]s must have at least %[2]s characters": "%[1]s debe tener al menos %[2]s caracteres",
+	"%[1]s must have at most %[2]s characters":  "%[1]s debe tener como máximo %[2]s caracteres",
+	"%[1]s must be greater than %[2]s":          "%[1]s debe ser mayor que %[2]s",
+	"%[1]s must be a date (yyyy-mm-dd)":         "%[1]s debe ser una fecha (aaaa-mm-dd)",
+	"%[1]s must not be in the past":             "%[1]s no debe estar en el pasado",
+}
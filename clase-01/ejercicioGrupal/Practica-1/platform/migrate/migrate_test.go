@@ -0,0 +1,78 @@
+package migrate_test
+
+import (
+	"app/platform/migrate"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Load function
+func TestLoad(t *testing.T) {
+	t.Run("success - sorted by version", func(t *testing.T) {
+		// arrange
+		fsys := fstest.MapFS{
+			"0002_second.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+			"0001_first.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+			"0001_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+			"readme.md":            {Data: []byte("ignored")},
+			"0003_invalid-name.up": {Data: []byte("ignored")},
+		}
+
+		// act
+		ms, err := migrate.Load(fsys)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, ms, 2)
+		require.Equal(t, 1, ms[0].Version)
+		require.Equal(t, "first", ms[0].Name)
+		require.Equal(t, "DROP TABLE a;", ms[0].Down)
+		require.Len(t, ms[0].Checksum, 64)
+		require.Equal(t, 2, ms[1].Version)
+		require.Equal(t, "", ms[1].Down)
+	})
+
+	t.Run("error - down without up", func(t *testing.T) {
+		// arrange
+		fsys := fstest.MapFS{
+			"0001_first.down.sql": {Data: []byte("DROP TABLE a;")},
+		}
+
+		// act
+		_, err := migrate.Load(fsys)
+
+		// assert
+		require.ErrorIs(t, err, migrate.ErrMigrationInvalid)
+	})
+
+	t.Run("error - same version with two names", func(t *testing.T) {
+		// arrange
+		fsys := fstest.MapFS{
+			"0001_first.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+			"0001_other.up.sql": {Data: []byte("CREATE TABLE b (id INT);")},
+		}
+
+		// act
+		_, err := migrate.Load(fsys)
+
+		// assert
+		require.ErrorIs(t, err, migrate.ErrMigrationInvalid)
+	})
+}
+
+// Tests for Statements function
+func TestStatements(t *testing.T) {
+	t.Run("multiple statements and comments", func(t *testing.T) {
+		// arrange
+		script := "-- comment\nCREATE TABLE `a` (\n  `id` int\n);\n\nINSERT INTO `a` VALUES\n(1);\nDROP TABLE `b`"
+
+		// act
+		stmts := migrate.Statements(script)
+
+		// assert
+		expected := []string{"CREATE TABLE `a` (\n  `id` int\n);", "INSERT INTO `a` VALUES\n(1);", "DROP TABLE `b`"}
+		require.Equal(t, expected, stmts)
+	})
+}
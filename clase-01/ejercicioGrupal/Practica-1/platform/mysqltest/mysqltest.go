@@ -0,0 +1,100 @@
+// Package mysqltest runs an in-process mysql compatible server backed by memory, so tests exercise
+// the mysql driver and sql, including its error codes, without an external database.
+//
+// The server supports transactions but not savepoints.
+package mysqltest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"sync/atomic"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/server"
+	gmssql "github.com/dolthub/go-mysql-server/sql"
+	vitess "github.com/dolthub/vitess/go/mysql"
+	"github.com/go-sql-driver/mysql"
+	"github.com/sirupsen/logrus"
+)
+
+// NewServer starts a server without databases, listening on a free local port.
+// The server logs through the standard logrus logger, which is limited to errors.
+func NewServer() (s *Server, err error) {
+	logrus.SetLevel(logrus.ErrorLevel)
+
+	// engine
+	// - primary keys are indexes, so foreign keys can reference them
+	pro := memory.NewDBProviderWithOpts(memory.NativeIndexProvider(true)).(*memory.DbProvider)
+	engine := sqle.NewDefault(pro)
+
+	// server
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return
+	}
+	sb := func(ctx context.Context, conn *vitess.Conn, addr string) (gmssql.Session, error) {
+		client := gmssql.Client{Address: "localhost", User: "root", Capabilities: conn.Capabilities}
+		return memory.NewSession(gmssql.NewBaseSessionWithClientServer(addr, client, conn.ConnectionID), pro), nil
+	}
+	srv, err := server.NewServer(server.Config{Protocol: "tcp", Listener: l}, engine, sb, nil)
+	if err != nil {
+		l.Close()
+		return
+	}
+	go srv.Start()
+
+	// admin connection
+	cfg := mysql.Config{
+		User:                 "root",
+		Net:                  "tcp",
+		Addr:                 l.Addr().String(),
+		ParseTime:            true,
+		AllowNativePasswords: true,
+	}
+	admin, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		srv.Close()
+		return
+	}
+
+	s = &Server{
+		srv:   srv,
+		admin: admin,
+		cfg:   cfg,
+	}
+	return
+}
+
+// Server is an in-process mysql compatible server.
+type Server struct {
+	// srv is the server.
+	srv *server.Server
+	// admin is the connection used to create databases.
+	admin *sql.DB
+	// cfg is the connection configuration of the server.
+	cfg mysql.Config
+	// databases is the number of databases created.
+	databases atomic.Int64
+}
+
+// NewDatabase creates a new empty database and returns its connection configuration.
+func (s *Server) NewDatabase() (cfg mysql.Config, err error) {
+	name := fmt.Sprintf("test_%d", s.databases.Add(1))
+	if _, err = s.admin.Exec("CREATE DATABASE `" + name + "`"); err != nil {
+		return
+	}
+
+	cfg = s.cfg
+	cfg.DBName = name
+	return
+}
+
+// Close stops the server.
+func (s *Server) Close() (err error) {
+	s.admin.Close()
+	err = s.srv.Close()
+	return
+}
@@ -0,0 +1,218 @@
+// Package validate checks structs against the rules declared in the validate tag of their fields,
+// reporting every field that breaks a rule at once.
+//
+//	type Body struct {
+//		Name       string  `json:"name" validate:"required"`
+//		Price      float64 `json:"price" validate:"min=0"`
+//		Expiration string  `json:"expiration" validate:"required,date,notpast"`
+//	}
+//
+// The rules of a field are checked in order and stop at the first one it breaks:
+//   - required: not the zero value, a string not blank, a slice or map not empty, a pointer not nil
+//   - min=n, max=n: a number at least or at most n, the length of a string, slice or map (reported as min_length and max_length)
+//   - gt=n: a number greater than n
+//   - date: a string with a yyyy-mm-dd date, an empty one is left to required
+//   - notpast: a yyyy-mm-dd date that is today or later
+//
+// Fields are named after their json tag, embedded structs are checked as part of the outer one
+// and pointers are checked by the value they point to. The messages of the errors are in English,
+// Error.Localize formats them in another language.
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error is an error of a field that breaks a rule.
+type Error struct {
+	// Field is the name of the field, as its json tag.
+	Field string `json:"field"`
+	// Code is the rule it breaks (e.g. required).
+	Code string `json:"code"`
+	// Message describes the broken rule (e.g. name is required).
+	Message string `json:"message"`
+	// Param is the argument of the rule (e.g. 0 for min=0).
+	Param string `json:"-"`
+}
+
+// Messages are the formats of the messages of each code, with the field and the argument of the rule as operands.
+var Messages = map[string]string{
+	"required":   "%[1]s is required",
+	"min":        "%[1]s must be at least %[2]s",
+	"max":        "%[1]s must be at most %[2]s",
+	"min_length": "%[1]s must have at least %[2]s characters",
+	"max_length": "%[1]s must have at most %[2]s characters",
+	"gt":         "%[1]s must be greater than %[2]s",
+	"date":       "%[1]s must be a date (yyyy-mm-dd)",
+	"notpast":    "%[1]s must not be in the past",
+}
+
+// Localize returns the error with its message formatted by translate, a printf like function that receives the format of its code.
+func (e Error) Localize(translate func(format string, args ...any) string) Error {
+	e.Message = translate(Messages[e.Code], e.Field, e.Param)
+	return e
+}
+
+// Errors is the list of errors of the fields of a struct that break a rule.
+type Errors []Error
+
+// Error returns the messages of the errors.
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return "validate: " + strings.Join(messages, ", ")
+}
+
+// Struct checks the fields of a struct, or a pointer to one, against their rules.
+// It returns Errors when a field breaks a rule, and panics on a malformed rule.
+func Struct(v any) (err error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: %T is not a struct", v))
+	}
+
+	var errs Errors
+	checkStruct(rv, &errs)
+	if len(errs) > 0 {
+		err = errs
+	}
+	return
+}
+
+// checkStruct appends the errors of the fields of a struct to errs.
+func checkStruct(rv reflect.Value, errs *Errors) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		// - embedded struct, exported or not as encoding/json does
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			checkStruct(rv.Field(i), errs)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("validate")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		name := fieldName(f)
+		for _, rule := range strings.Split(tag, ",") {
+			rule, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			if code, ok := check(rv.Field(i), rule, arg); !ok {
+				*errs = append(*errs, Error{Field: name, Code: code, Message: fmt.Sprintf(Messages[code], name, arg), Param: arg})
+				break
+			}
+		}
+	}
+}
+
+// fieldName returns the name of a field in its json tag, or its go name.
+func fieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
+
+// check returns whether a value passes a rule, and the code of the failure when it does not.
+func check(v reflect.Value, rule, arg string) (code string, ok bool) {
+	code = rule
+	// a nil pointer only breaks required
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "required", rule != "required"
+		}
+		v = v.Elem()
+	}
+
+	switch rule {
+	case "required":
+		switch v.Kind() {
+		case reflect.String:
+			ok = strings.TrimSpace(v.String()) != ""
+		case reflect.Slice, reflect.Map:
+			ok = v.Len() > 0
+		default:
+			ok = !v.IsZero()
+		}
+	case "min":
+		ok = number(v, rule, arg) >= parse(rule, arg)
+		if isLen(v) {
+			code = "min_length"
+		}
+	case "max":
+		ok = number(v, rule, arg) <= parse(rule, arg)
+		if isLen(v) {
+			code = "max_length"
+		}
+	case "gt":
+		ok = number(v, rule, arg) > parse(rule, arg)
+	case "date":
+		ok = true
+		if s := str(v, rule); s != "" {
+			_, err := time.Parse(time.DateOnly, s)
+			ok = err == nil
+		}
+	case "notpast":
+		ok = true
+		if d, err := time.Parse(time.DateOnly, str(v, rule)); err == nil {
+			y, m, day := time.Now().Date()
+			ok = !d.Before(time.Date(y, m, day, 0, 0, 0, 0, time.UTC))
+		}
+	default:
+		panic("validate: unknown rule " + rule)
+	}
+	return
+}
+
+// isLen returns whether the size of a value is its length.
+func isLen(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// number returns a value as a number to compare, its length for strings, slices and maps.
+func number(v reflect.Value, code, arg string) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return float64(len([]rune(v.String())))
+	case reflect.Slice, reflect.Map:
+		return float64(v.Len())
+	}
+	panic(fmt.Sprintf("validate: rule %s=%s on a %s", code, arg, v.Kind()))
+}
+
+// str returns a string value, panicking for other kinds.
+func str(v reflect.Value, code string) string {
+	if v.Kind() != reflect.String {
+		panic(fmt.Sprintf("validate: rule %s on a %s", code, v.Kind()))
+	}
+	return v.String()
+}
+
+// parse returns the number argument of a rule.
+func parse(code, arg string) float64 {
+	n, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: rule %s=%s needs a number", code, arg))
+	}
+	return n
+}
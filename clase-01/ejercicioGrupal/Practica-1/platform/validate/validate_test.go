@@ -0,0 +1,114 @@
+package validate_test
+
+import (
+	"app/platform/validate"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// body is a struct with rules of every kind.
+type body struct {
+	Name       string   `json:"name" validate:"required,max=5"`
+	Quantity   int      `json:"quantity" validate:"min=0"`
+	Price      *float64 `json:"price" validate:"gt=0"`
+	Expiration string   `json:"expiration" validate:"required,date,notpast"`
+	Tags       []string `json:"tags,omitempty" validate:"required"`
+	NoRules    string   `json:"no_rules"`
+	embedded
+}
+
+// embedded is a struct embedded into body.
+type embedded struct {
+	Capacity int `json:"capacity" validate:"gt=0"`
+}
+
+// Tests for Struct function
+func TestStruct(t *testing.T) {
+	t.Run("success - valid struct", func(t *testing.T) {
+		// arrange
+		price := 1.5
+		b := body{
+			Name:       "name",
+			Price:      &price,
+			Expiration: time.Now().AddDate(0, 0, 1).Format(time.DateOnly),
+			Tags:       []string{"a"},
+			embedded:   embedded{Capacity: 1},
+		}
+
+		// act
+		err := validate.Struct(&b)
+
+		// assert
+		require.NoError(t, err)
+	})
+
+	t.Run("error - every field breaks a rule", func(t *testing.T) {
+		// arrange
+		price := 0.0
+		b := body{
+			Name:       "long name",
+			Quantity:   -1,
+			Price:      &price,
+			Expiration: "2020-01-01",
+		}
+
+		// act
+		err := validate.Struct(b)
+
+		// assert
+		expected := validate.Errors{
+			{Field: "name", Code: "max_length", Message: "name must have at most 5 characters", Param: "5"},
+			{Field: "quantity", Code: "min", Message: "quantity must be at least 0", Param: "0"},
+			{Field: "price", Code: "gt", Message: "price must be greater than 0", Param: "0"},
+			{Field: "expiration", Code: "notpast", Message: "expiration must not be in the past"},
+			{Field: "tags", Code: "required", Message: "tags is required"},
+			{Field: "capacity", Code: "gt", Message: "capacity must be greater than 0", Param: "0"},
+		}
+		require.Equal(t, expected, err)
+	})
+
+	t.Run("error - rules stop at the first broken one", func(t *testing.T) {
+		// arrange
+		b := body{Name: " ", Expiration: "31/12/2030", Tags: []string{"a"}, embedded: embedded{Capacity: 1}}
+
+		// act
+		err := validate.Struct(b)
+
+		// assert
+		expected := validate.Errors{
+			{Field: "name", Code: "required", Message: "name is required"},
+			{Field: "expiration", Code: "date", Message: "expiration must be a date (yyyy-mm-dd)"},
+		}
+		require.Equal(t, expected, err)
+	})
+
+	t.Run("panic - unknown rule", func(t *testing.T) {
+		// arrange
+		b := struct {
+			Name string `validate:"unknown"`
+		}{}
+
+		// act & assert
+		require.Panics(t, func() { validate.Struct(b) })
+	})
+}
+
+// Tests for Error.Localize method
+func TestError_Localize(t *testing.T) {
+	t.Run("success - message formatted in another language", func(t *testing.T) {
+		// arrange
+		e := validate.Error{Field: "price", Code: "gt", Message: "price must be greater than 0", Param: "0"}
+		es := map[string]string{"%[1]s must be greater than %[2]s": "%[1]s debe ser mayor que %[2]s"}
+
+		// act
+		e = e.Localize(func(format string, args ...any) string {
+			return fmt.Sprintf(es[format], args...)
+		})
+
+		// assert
+		require.Equal(t, "price debe ser mayor que 0", e.Message)
+	})
+}
@@ -0,0 +1,124 @@
+package codec_test
+
+import (
+	"app/platform/web/codec"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// item is the struct of the tests.
+type item struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Price float64  `json:"price"`
+	Stock *int     `json:"stock"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// Tests for MediaType.
+func TestMediaType(t *testing.T) {
+	t.Run("case 1: should return the media type without its parameters", func(t *testing.T) {
+		// act
+		mediaType, ok := codec.MediaType("application/json; charset=utf-8")
+
+		// assert
+		require.True(t, ok)
+		require.Equal(t, codec.JSON, mediaType)
+	})
+
+	t.Run("case 2: should resolve an alias", func(t *testing.T) {
+		// act
+		mediaType, ok := codec.MediaType("application/x-msgpack")
+
+		// assert
+		require.True(t, ok)
+		require.Equal(t, codec.MessagePack, mediaType)
+	})
+
+	t.Run("case 3: should not support other media types", func(t *testing.T) {
+		// act
+		_, ok := codec.MediaType("application/yaml")
+
+		// assert
+		require.False(t, ok)
+	})
+}
+
+// Tests for Encode and Decode.
+func TestCodec(t *testing.T) {
+	stock := 5
+	items := []item{
+		{ID: 1, Name: "a & b", Price: 1.5, Stock: &stock, Tags: []string{"x", "y"}},
+		{ID: 2, Name: "c", Price: 2},
+	}
+
+	for _, mediaType := range []string{codec.JSON, codec.XML, codec.MessagePack} {
+		t.Run("case 1: should decode what it encodes in "+mediaType, func(t *testing.T) {
+			// act
+			var buf bytes.Buffer
+			err := codec.Encode(&buf, mediaType, items)
+			require.NoError(t, err)
+			var decoded []item
+			err = codec.Decode(&buf, mediaType, &decoded)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, items, decoded)
+		})
+	}
+
+	t.Run("case 2: should write xml in the order of the json fields", func(t *testing.T) {
+		// act
+		var buf bytes.Buffer
+		err := codec.Encode(&buf, codec.XML, map[string]any{"data": items[1:], "message": "found"})
+
+		// assert
+		expected := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+			`<response><data><item><id>2</id><name>c</name><price>2</price><stock nil="true"></stock></item></data><message>found</message></response>`
+		require.NoError(t, err)
+		require.Equal(t, expected, buf.String())
+	})
+
+	t.Run("case 3: should write csv with a header row of the json fields", func(t *testing.T) {
+		// act
+		var buf bytes.Buffer
+		err := codec.Encode(&buf, codec.CSV, items)
+
+		// assert
+		expected := "id,name,price,stock,tags\n1,a & b,1.5,5,\"[\"\"x\"\",\"\"y\"\"]\"\n2,c,2,,null\n"
+		require.NoError(t, err)
+		require.Equal(t, expected, buf.String())
+	})
+
+	t.Run("case 4: should read csv by the header row, ignoring unknown columns", func(t *testing.T) {
+		// act
+		var decoded []item
+		err := codec.Decode(strings.NewReader("name,other,stock\nc,z,\nd,z,7\n"), codec.CSV, &decoded)
+
+		// assert
+		seven := 7
+		expected := []item{{Name: "c"}, {Name: "d", Stock: &seven}}
+		require.NoError(t, err)
+		require.Equal(t, expected, decoded)
+	})
+
+	t.Run("case 5: should not write a single struct in csv", func(t *testing.T) {
+		// act
+		err := codec.Encode(&bytes.Buffer{}, codec.CSV, items[0])
+
+		// assert
+		require.ErrorIs(t, err, codec.ErrUnsupported)
+	})
+
+	t.Run("case 6: should not decode an invalid body", func(t *testing.T) {
+		// act
+		var decoded item
+		err := codec.Decode(strings.NewReader("<response><id>one</id></response>"), codec.XML, &decoded)
+
+		// assert
+		require.Error(t, err)
+	})
+}
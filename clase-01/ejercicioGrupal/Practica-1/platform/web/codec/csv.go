@@ -0,0 +1,236 @@
+package codec
+
+import (
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// textMarshalerType is the type of the values that encode themselves as text.
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// csvColumn is a column of a csv body: a field of the structs of a list.
+type csvColumn struct {
+	// name is the name of the field in json, the header of the column.
+	name string
+	// index is the index of the field in the struct.
+	index []int
+}
+
+// csvColumns returns the columns of a list of structs of type t, in the order of their fields.
+func csvColumns(t reflect.Type) (columns []csvColumn) {
+	fields := jsonFields(t)
+	for _, f := range reflect.VisibleFields(t) {
+		name, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+		if index, ok := fields[name]; ok && reflect.DeepEqual(index, f.Index) {
+			columns = append(columns, csvColumn{name: name, index: index})
+		}
+	}
+	return
+}
+
+// structOf returns the struct type of the items of a list, following pointers.
+func structOf(t reflect.Type) reflect.Type {
+	t = t.Elem()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// encodeCSV writes a list of structs as csv: a header row with the json name of the fields and a row per item.
+func encodeCSV(w io.Writer, v any) (err error) {
+	if !Tabular(v) {
+		err = ErrUnsupported
+		return
+	}
+	rv := reflect.ValueOf(v)
+	columns := csvColumns(structOf(rv.Type()))
+
+	cw := csv.NewWriter(w)
+	// header
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		row[i] = c.name
+	}
+	if err = cw.Write(row); err != nil {
+		return
+	}
+	// rows
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i)
+		if item.Kind() == reflect.Pointer {
+			if item.IsNil() {
+				continue
+			}
+			item = item.Elem()
+		}
+		for j, c := range columns {
+			f, ok := fieldOf(item, c.index)
+			if !ok {
+				row[j] = ""
+				continue
+			}
+			if row[j], err = formatCell(f); err != nil {
+				return
+			}
+		}
+		if err = cw.Write(row); err != nil {
+			return
+		}
+	}
+	cw.Flush()
+	err = cw.Error()
+	return
+}
+
+// fieldOf returns the field of a struct by its index, ok is false when an embedded pointer on its way is nil.
+func fieldOf(v reflect.Value, index []int) (f reflect.Value, ok bool) {
+	f = v
+	for i, x := range index {
+		if i > 0 && f.Kind() == reflect.Pointer {
+			if f.IsNil() {
+				return
+			}
+			f = f.Elem()
+		}
+		f = f.Field(x)
+	}
+	ok = true
+	return
+}
+
+// formatCell returns the cell of a value: scalars as text, null as an empty cell and the rest as json.
+func formatCell(v reflect.Value) (cell string, err error) {
+	// null
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return
+	}
+	// values that encode themselves
+	if v.Type().Implements(textMarshalerType) {
+		var b []byte
+		b, err = v.Interface().(encoding.TextMarshaler).MarshalText()
+		cell = string(b)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		cell, err = formatCell(v.Elem())
+	case reflect.String:
+		cell = v.String()
+	case reflect.Bool:
+		cell = strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		cell = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		cell = strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		cell = strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	default:
+		var b []byte
+		b, err = json.Marshal(v.Interface())
+		cell = string(b)
+	}
+	return
+}
+
+// decodeCSV reads a csv body into the list of structs pointed by ptr: the header row names the fields by their json name
+// and each row is an item. Unknown columns are ignored and an empty cell is null.
+func decodeCSV(r io.Reader, ptr any) (err error) {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || !Tabular(rv.Elem().Interface()) || rv.Elem().Kind() != reflect.Slice {
+		err = ErrUnsupported
+		return
+	}
+	list := rv.Elem()
+	t := structOf(list.Type())
+	fields := jsonFields(t)
+
+	cr := csv.NewReader(r)
+	// header
+	header, err := cr.Read()
+	if err != nil {
+		return
+	}
+	index := make([][]int, len(header))
+	for i, name := range header {
+		index[i] = fields[name]
+	}
+	// rows
+	s := reflect.MakeSlice(list.Type(), 0, 0)
+	for line := 2; ; line++ {
+		var row []string
+		row, err = cr.Read()
+		if err == io.EOF {
+			err = nil
+			break
+		}
+		if err != nil {
+			return
+		}
+
+		item := reflect.New(t).Elem()
+		for i, cell := range row {
+			if index[i] == nil {
+				continue
+			}
+			if err = parseCell(fieldByIndex(item, index[i]), cell); err != nil {
+				err = fmt.Errorf("csv: line %d, column %s: %w", line, header[i], err)
+				return
+			}
+		}
+		if list.Type().Elem().Kind() == reflect.Pointer {
+			item = item.Addr()
+		}
+		s = reflect.Append(s, item)
+	}
+	list.Set(s)
+	return
+}
+
+// parseCell sets v to the value of a cell: an empty cell is null, scalars are parsed by their kind and the rest as json.
+func parseCell(v reflect.Value, cell string) (err error) {
+	// null
+	if cell == "" {
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+	}
+	// values that decode themselves
+	if v.Kind() != reflect.Pointer && v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		err = v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(cell))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		err = parseCell(v.Elem(), cell)
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			err = fmt.Errorf("can not decode into %s", v.Type())
+			return
+		}
+		v.Set(reflect.ValueOf(cell))
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		err = json.Unmarshal([]byte(cell), v.Addr().Interface())
+	default:
+		if cell == "" && v.Kind() != reflect.String {
+			return
+		}
+		err = setText(v, cell)
+	}
+	return
+}
@@ -0,0 +1,24 @@
+package codec
+
+import (
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// encodeMsgpack writes a value as msgpack, its fields named by their json tag.
+func encodeMsgpack(w io.Writer, v any) (err error) {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	err = enc.Encode(v)
+	return
+}
+
+// decodeMsgpack reads a msgpack body into the value pointed by ptr, its fields named by their json tag.
+func decodeMsgpack(r io.Reader, ptr any) (err error) {
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag("json")
+	err = dec.Decode(ptr)
+	return
+}
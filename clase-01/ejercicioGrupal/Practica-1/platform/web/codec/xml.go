@@ -0,0 +1,367 @@
+package codec
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// xmlRoot is the root element of an xml body.
+	xmlRoot = "response"
+	// xmlItem is the element of each item of a list.
+	xmlItem = "item"
+	// xmlField is the element of a field whose name is not a valid element name, named by its name attribute.
+	xmlField = "field"
+)
+
+// xmlNil is the attribute of an element whose value is null.
+var xmlNil = xml.Attr{Name: xml.Name{Local: "nil"}, Value: "true"}
+
+// encodeXML writes a value as xml: it is marshaled as json and each json token is written as its element,
+// so the fields keep the names and order of json.
+func encodeXML(w io.Writer, v any) (err error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	enc := xml.NewEncoder(w)
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return
+	}
+	if err = encodeXMLValue(enc, dec, xml.StartElement{Name: xml.Name{Local: xmlRoot}}); err != nil {
+		return
+	}
+	err = enc.Flush()
+	return
+}
+
+// encodeXMLValue writes the next json value of dec as the element start.
+func encodeXMLValue(enc *xml.Encoder, dec *json.Decoder, start xml.StartElement) (err error) {
+	tk, err := dec.Token()
+	if err != nil {
+		return
+	}
+
+	switch t := tk.(type) {
+	case json.Delim:
+		if err = enc.EncodeToken(start); err != nil {
+			return
+		}
+		for dec.More() {
+			child := xml.StartElement{Name: xml.Name{Local: xmlItem}}
+			// - the fields of an object are named by its keys
+			if t == '{' {
+				var key json.Token
+				if key, err = dec.Token(); err != nil {
+					return
+				}
+				child = xmlElement(key.(string))
+			}
+			if err = encodeXMLValue(enc, dec, child); err != nil {
+				return
+			}
+		}
+		// - closing delimiter
+		if _, err = dec.Token(); err != nil {
+			return
+		}
+		err = enc.EncodeToken(start.End())
+	case nil:
+		start.Attr = append(start.Attr, xmlNil)
+		err = enc.EncodeElement("", start)
+	case json.Number:
+		err = enc.EncodeElement(t.String(), start)
+	default:
+		err = enc.EncodeElement(fmt.Sprint(t), start)
+	}
+	return
+}
+
+// xmlElement returns the element of a field: named by it when it is a valid element name, otherwise a field element with it as its name attribute.
+func xmlElement(name string) (start xml.StartElement) {
+	valid := name != "" && !strings.HasPrefix(strings.ToLower(name), "xml")
+	for i, r := range name {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case i > 0 && (r == '-' || r == '.' || r >= '0' && r <= '9'):
+		default:
+			valid = false
+		}
+	}
+	if valid {
+		start.Name.Local = name
+		return
+	}
+	start.Name.Local = xmlField
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "name"}, Value: name}}
+	return
+}
+
+// xmlNode is an element of an xml body.
+type xmlNode struct {
+	// name is the name of the field of the element.
+	name string
+	// null is true when the element has the nil attribute.
+	null bool
+	// text is the character data of the element.
+	text string
+	// children are the child elements of the element.
+	children []*xmlNode
+}
+
+// decodeXML reads an xml body into the value pointed by ptr.
+func decodeXML(r io.Reader, ptr any) (err error) {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		err = fmt.Errorf("xml: decode into a non-pointer %T", ptr)
+		return
+	}
+
+	root, err := parseXML(xml.NewDecoder(r))
+	if err != nil {
+		return
+	}
+	err = setXML(rv.Elem(), root)
+	return
+}
+
+// parseXML reads the root element of an xml body and its children.
+func parseXML(dec *xml.Decoder) (root *xmlNode, err error) {
+	var stack []*xmlNode
+	for {
+		var tk xml.Token
+		tk, err = dec.Token()
+		if err == io.EOF {
+			if root == nil || len(stack) > 0 {
+				err = io.ErrUnexpectedEOF
+				return
+			}
+			err = nil
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		switch t := tk.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name.Local}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Local == xmlNil.Name.Local:
+					n.null = a.Value == xmlNil.Value
+				case a.Name.Local == "name" && t.Name.Local == xmlField:
+					n.name = a.Value
+				}
+			}
+			switch {
+			case len(stack) > 0:
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			case root != nil:
+				err = fmt.Errorf("xml: more than one root element")
+				return
+			default:
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+}
+
+// textUnmarshalerType is the type of the values that decode themselves from text.
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// setXML sets v to the value of an element.
+func setXML(v reflect.Value, n *xmlNode) (err error) {
+	// null
+	if n.null {
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return
+	}
+
+	// values that decode themselves
+	if v.Kind() != reflect.Pointer && v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		err = v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(strings.TrimSpace(n.text)))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		err = setXML(v.Elem(), n)
+	case reflect.Struct:
+		fields := jsonFields(v.Type())
+		for _, c := range n.children {
+			i, ok := fields[c.name]
+			if !ok {
+				continue
+			}
+			if err = setXML(fieldByIndex(v, i), c); err != nil {
+				return
+			}
+		}
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), len(n.children), len(n.children))
+		for i, c := range n.children {
+			if err = setXML(s.Index(i), c); err != nil {
+				return
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			err = fmt.Errorf("xml: can not decode into %s", v.Type())
+			return
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for _, c := range n.children {
+			e := reflect.New(v.Type().Elem()).Elem()
+			if err = setXML(e, c); err != nil {
+				return
+			}
+			v.SetMapIndex(reflect.ValueOf(c.name).Convert(v.Type().Key()), e)
+		}
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			err = fmt.Errorf("xml: can not decode into %s", v.Type())
+			return
+		}
+		v.Set(reflect.ValueOf(xmlAny(n)))
+	default:
+		err = setText(v, strings.TrimSpace(n.text))
+	}
+	return
+}
+
+// xmlAny returns the value of an element without a type: a list when all its children are items,
+// an object when it has children, otherwise its text.
+func xmlAny(n *xmlNode) any {
+	if n.null {
+		return nil
+	}
+	if len(n.children) == 0 {
+		return strings.TrimSpace(n.text)
+	}
+
+	list := true
+	for _, c := range n.children {
+		list = list && c.name == xmlItem
+	}
+	if list {
+		s := make([]any, len(n.children))
+		for i, c := range n.children {
+			s[i] = xmlAny(c)
+		}
+		return s
+	}
+	m := make(map[string]any, len(n.children))
+	for _, c := range n.children {
+		m[c.name] = xmlAny(c)
+	}
+	return m
+}
+
+// setText sets a scalar v to the value of a text, parsed by its kind.
+func setText(v reflect.Value, s string) (err error) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, v.Type().Bits()); err == nil {
+			v.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	default:
+		err = fmt.Errorf("can not decode %q into %s", s, v.Type())
+	}
+	return
+}
+
+// jsonFields returns the index of the fields of a struct by their json name, including the ones of its embedded structs.
+func jsonFields(t reflect.Type) (fields map[string][]int) {
+	fields = make(map[string][]int)
+	for _, f := range reflect.VisibleFields(t) {
+		t := f.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if !f.IsExported() || f.Anonymous && t.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+			continue
+		}
+		name, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+		if _, dup := fields[name]; !dup || len(f.Index) < len(fields[name]) {
+			fields[name] = f.Index
+		}
+	}
+	return
+}
+
+// jsonName returns the name of a field in json, ok is false when it is not written.
+func jsonName(f reflect.StructField) (name string, ok bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	ok = true
+	return
+}
+
+// fieldByIndex returns the field of a struct by its index, allocating the embedded pointers on its way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
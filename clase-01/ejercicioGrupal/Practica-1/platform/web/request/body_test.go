@@ -0,0 +1,102 @@
+package request_test
+
+import (
+	"app/platform/web/request"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Body function
+func TestRequestBody(t *testing.T) {
+	type schema struct {
+		Name     string `json:"name"`
+		Quantity int    `json:"quantity"`
+	}
+
+	t.Run("success - json without content-type", func(t *testing.T) {
+		// arrange
+		inputSchema := schema{}
+		inputRequest := http.Request{
+			Header: http.Header{},
+			Body:   io.NopCloser(strings.NewReader(`{"name":"test","quantity":1}`)),
+		}
+
+		// act
+		err := request.Body(&inputRequest, &inputSchema)
+
+		// assert
+		expectedSchema := schema{Name: "test", Quantity: 1}
+		require.NoError(t, err)
+		require.Equal(t, expectedSchema, inputSchema)
+	})
+
+	t.Run("success - xml", func(t *testing.T) {
+		// arrange
+		inputSchema := schema{}
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"text/xml; charset=utf-8"}},
+			Body:   io.NopCloser(strings.NewReader(`<?xml version="1.0"?><request><name>test</name><quantity>1</quantity></request>`)),
+		}
+
+		// act
+		err := request.Body(&inputRequest, &inputSchema)
+
+		// assert
+		expectedSchema := schema{Name: "test", Quantity: 1}
+		require.NoError(t, err)
+		require.Equal(t, expectedSchema, inputSchema)
+	})
+
+	t.Run("success - csv list", func(t *testing.T) {
+		// arrange
+		var inputSchema []schema
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"text/csv"}},
+			Body:   io.NopCloser(strings.NewReader("name,quantity\na,1\nb,2\n")),
+		}
+
+		// act
+		err := request.Body(&inputRequest, &inputSchema)
+
+		// assert
+		expectedSchema := []schema{{Name: "a", Quantity: 1}, {Name: "b", Quantity: 2}}
+		require.NoError(t, err)
+		require.Equal(t, expectedSchema, inputSchema)
+	})
+
+	t.Run("error - content-type", func(t *testing.T) {
+		// arrange
+		inputSchema := schema{}
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"text/csv"}},
+			Body:   io.NopCloser(strings.NewReader("name,quantity\na,1\n")),
+		}
+
+		// act
+		err := request.Body(&inputRequest, &inputSchema)
+
+		// assert
+		require.ErrorIs(t, err, request.ErrRequestContentTypeNotSupported)
+		require.Equal(t, schema{}, inputSchema)
+	})
+
+	t.Run("error - body", func(t *testing.T) {
+		// arrange
+		inputSchema := schema{}
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"application/json"}},
+			Body:   io.NopCloser(strings.NewReader(`{"name":"test"`)),
+		}
+
+		// act
+		err := request.Body(&inputRequest, &inputSchema)
+
+		// assert
+		require.ErrorIs(t, err, request.ErrRequestBodyInvalid)
+		require.EqualError(t, err, "request body invalid. unexpected EOF")
+	})
+}
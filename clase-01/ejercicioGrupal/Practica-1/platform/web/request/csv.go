@@ -0,0 +1,56 @@
+package request
+
+import (
+	"encoding/csv"
+	"errors"
+	"mime"
+	"net/http"
+)
+
+var (
+	// ErrRequestContentTypeNotCSV is used when the request content type is neither text/csv nor multipart/form-data.
+	ErrRequestContentTypeNotCSV = errors.New("request content type is not text/csv or multipart/form-data")
+	// ErrRequestCSVFileMissing is used when a multipart request has no file field.
+	ErrRequestCSVFileMissing = errors.New("request csv file missing")
+)
+
+// CSV returns a csv reader of the request body: either a text/csv body or the file field of a multipart/form-data body
+func CSV(r *http.Request) (rd *csv.Reader, err error) {
+	// check content type
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		err = ErrRequestContentTypeNotCSV
+		return
+	}
+
+	switch mediaType {
+	case "text/csv":
+		rd = csv.NewReader(r.Body)
+	case "multipart/form-data":
+		// stream the parts until the file field
+		multipart, e := r.MultipartReader()
+		if e != nil {
+			err = ErrRequestContentTypeNotCSV
+			return
+		}
+		for {
+			part, e := multipart.NextPart()
+			if e != nil {
+				err = ErrRequestCSVFileMissing
+				return
+			}
+			if part.FormName() == "file" {
+				rd = csv.NewReader(part)
+				break
+			}
+		}
+	default:
+		err = ErrRequestContentTypeNotCSV
+		return
+	}
+
+	// allow rows with a different number of fields, they are reported by the caller
+	rd.FieldsPerRecord = -1
+	rd.TrimLeadingSpace = true
+	return
+}
@@ -0,0 +1,90 @@
+package request_test
+
+import (
+	"app/platform/web/request"
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for CSV function
+func TestRequestCSV(t *testing.T) {
+	t.Run("success - text/csv", func(t *testing.T) {
+		// arrange
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"text/csv; charset=utf-8"}},
+			Body:   io.NopCloser(strings.NewReader("name,price\ntest,10\n")),
+		}
+
+		// act
+		rd, err := request.CSV(&inputRequest)
+		require.NoError(t, err)
+		records, err := rd.ReadAll()
+
+		// assert
+		expectedRecords := [][]string{{"name", "price"}, {"test", "10"}}
+		require.NoError(t, err)
+		require.Equal(t, expectedRecords, records)
+	})
+
+	t.Run("success - multipart", func(t *testing.T) {
+		// arrange
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		require.NoError(t, mw.WriteField("comment", "ignored"))
+		fw, err := mw.CreateFormFile("file", "products.csv")
+		require.NoError(t, err)
+		_, err = fw.Write([]byte("name,price\ntest,10\n"))
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+		inputRequest, err := http.NewRequest(http.MethodPost, "/", &body)
+		require.NoError(t, err)
+		inputRequest.Header.Set("Content-Type", mw.FormDataContentType())
+
+		// act
+		rd, err := request.CSV(inputRequest)
+		require.NoError(t, err)
+		records, err := rd.ReadAll()
+
+		// assert
+		expectedRecords := [][]string{{"name", "price"}, {"test", "10"}}
+		require.NoError(t, err)
+		require.Equal(t, expectedRecords, records)
+	})
+
+	t.Run("error - multipart without file", func(t *testing.T) {
+		// arrange
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		require.NoError(t, mw.WriteField("comment", "ignored"))
+		require.NoError(t, mw.Close())
+		inputRequest, err := http.NewRequest(http.MethodPost, "/", &body)
+		require.NoError(t, err)
+		inputRequest.Header.Set("Content-Type", mw.FormDataContentType())
+
+		// act
+		_, err = request.CSV(inputRequest)
+
+		// assert
+		require.ErrorIs(t, err, request.ErrRequestCSVFileMissing)
+	})
+
+	t.Run("error - content-type", func(t *testing.T) {
+		// arrange
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"application/json"}},
+			Body:   io.NopCloser(strings.NewReader(`{}`)),
+		}
+
+		// act
+		_, err := request.CSV(&inputRequest)
+
+		// assert
+		require.ErrorIs(t, err, request.ErrRequestContentTypeNotCSV)
+	})
+}
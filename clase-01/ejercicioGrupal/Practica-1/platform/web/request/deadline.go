@@ -0,0 +1,23 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Deadline returns a middleware that cancels the context of each request once d has passed since it started.
+// The handlers are expected to stop on the error of the context, a d of zero or less sets no deadline.
+func Deadline(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
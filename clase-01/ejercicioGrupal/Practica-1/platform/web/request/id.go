@@ -0,0 +1,56 @@
+package request
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// HeaderRequestID is the header with the id of a request, sent by the client or set in the response.
+const HeaderRequestID = "X-Request-Id"
+
+// idKey is the key of the id of a request in its context.
+type idKey struct{}
+
+// ID is a middleware that identifies each request with the id sent by the client in the X-Request-Id header,
+// or a random one when it is missing or malformed. The id is set in the context and in the header of the response.
+func ID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderRequestID)
+		if !validID(id) {
+			id = newID()
+		}
+
+		w.Header().Set(HeaderRequestID, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), idKey{}, id)))
+	})
+}
+
+// IDFrom returns the id of a request set by the ID middleware, empty when it is not set.
+func IDFrom(ctx context.Context) (id string) {
+	id, _ = ctx.Value(idKey{}).(string)
+	return
+}
+
+// validID returns whether an id sent by a client can be echoed back: up to 64 letters, digits, dashes, dots or underscores.
+func validID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '.', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newID returns a random id of 32 hexadecimal characters.
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
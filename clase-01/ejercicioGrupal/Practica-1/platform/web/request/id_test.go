@@ -0,0 +1,49 @@
+package request_test
+
+import (
+	"app/platform/web/request"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ID function
+func TestID(t *testing.T) {
+	t.Run("success - id sent by the client", func(t *testing.T) {
+		// arrange
+		var id string
+		hd := request.ID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id = request.IDFrom(r.Context())
+		}))
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-Id", "client-id_1")
+
+		// act
+		res := httptest.NewRecorder()
+		hd.ServeHTTP(res, req)
+
+		// assert
+		require.Equal(t, "client-id_1", id)
+		require.Equal(t, "client-id_1", res.Header().Get("X-Request-Id"))
+	})
+
+	t.Run("success - malformed id replaced by a random one", func(t *testing.T) {
+		// arrange
+		var id string
+		hd := request.ID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id = request.IDFrom(r.Context())
+		}))
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-Id", "id\r\nSet-Cookie: a=b")
+
+		// act
+		res := httptest.NewRecorder()
+		hd.ServeHTTP(res, req)
+
+		// assert
+		require.Len(t, id, 32)
+		require.Equal(t, id, res.Header().Get("X-Request-Id"))
+	})
+}
@@ -0,0 +1,17 @@
+package request
+
+import (
+	"app/platform/i18n"
+	"net/http"
+)
+
+// Language is a middleware that negotiates the language of the response with the Accept-Language header of the request.
+// The language is set in the Content-Language header of the response, where the response helpers read it from.
+func Language(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Language", i18n.Negotiate(r.Header.Get("Accept-Language")))
+		w.Header().Add("Vary", "Accept-Language")
+
+		next.ServeHTTP(w, r)
+	})
+}
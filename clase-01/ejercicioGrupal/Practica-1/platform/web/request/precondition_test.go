@@ -0,0 +1,103 @@
+package request_test
+
+import (
+	"app/platform/web/request"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for IfMatch function
+func TestRequestIfMatch(t *testing.T) {
+	t.Run("success - header missing", func(t *testing.T) {
+		// arrange
+		inputRequest := http.Request{Header: http.Header{}}
+
+		// act
+		ok := request.IfMatch(&inputRequest, `"1"`)
+
+		// assert
+		require.True(t, ok)
+	})
+
+	t.Run("success - etag listed", func(t *testing.T) {
+		// arrange
+		inputRequest := http.Request{Header: http.Header{"If-Match": []string{`"2", "1"`}}}
+
+		// act
+		ok := request.IfMatch(&inputRequest, `"1"`)
+
+		// assert
+		require.True(t, ok)
+	})
+
+	t.Run("success - wildcard", func(t *testing.T) {
+		// arrange
+		inputRequest := http.Request{Header: http.Header{"If-Match": []string{`*`}}}
+
+		// act
+		ok := request.IfMatch(&inputRequest, `"1"`)
+
+		// assert
+		require.True(t, ok)
+	})
+
+	t.Run("failure - etag not listed", func(t *testing.T) {
+		// arrange
+		inputRequest := http.Request{Header: http.Header{"If-Match": []string{`"2"`}}}
+
+		// act
+		ok := request.IfMatch(&inputRequest, `"1"`)
+
+		// assert
+		require.False(t, ok)
+	})
+
+	t.Run("failure - weak etag", func(t *testing.T) {
+		// arrange
+		inputRequest := http.Request{Header: http.Header{"If-Match": []string{`W/"1"`}}}
+
+		// act
+		ok := request.IfMatch(&inputRequest, `"1"`)
+
+		// assert
+		require.False(t, ok)
+	})
+}
+
+// Tests for IfNoneMatch function
+func TestRequestIfNoneMatch(t *testing.T) {
+	t.Run("no match - header missing", func(t *testing.T) {
+		// arrange
+		inputRequest := http.Request{Header: http.Header{}}
+
+		// act
+		match := request.IfNoneMatch(&inputRequest, `"1"`)
+
+		// assert
+		require.False(t, match)
+	})
+
+	t.Run("match - weak etag listed", func(t *testing.T) {
+		// arrange
+		inputRequest := http.Request{Header: http.Header{"If-None-Match": []string{`"2", W/"1"`}}}
+
+		// act
+		match := request.IfNoneMatch(&inputRequest, `"1"`)
+
+		// assert
+		require.True(t, match)
+	})
+
+	t.Run("no match - etag not listed", func(t *testing.T) {
+		// arrange
+		inputRequest := http.Request{Header: http.Header{"If-None-Match": []string{`"2"`}}}
+
+		// act
+		match := request.IfNoneMatch(&inputRequest, `"1"`)
+
+		// assert
+		require.False(t, match)
+	})
+}
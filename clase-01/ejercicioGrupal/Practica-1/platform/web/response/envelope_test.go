@@ -0,0 +1,119 @@
+package response_test
+
+import (
+	"app/platform/web/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Success function
+func TestSuccess(t *testing.T) {
+	t.Run("200 - message and data under its key", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		rr.Header().Set("X-Request-Id", "id")
+		response.Success(rr, http.StatusOK, response.Body{
+			Message: "product found",
+			Key:     "product",
+			Data:    map[string]any{"id": 1},
+			Links:   map[string]string{"self": "/products/1"},
+		})
+
+		// assert
+		expectedCode := http.StatusOK
+		expectedBody := `{"message":"product found","product":{"id":1}}`
+		require.Equal(t, expectedCode, rr.Code)
+		require.JSONEq(t, expectedBody, rr.Body.String())
+	})
+
+	t.Run("200 - envelope of an enveloped response", func(t *testing.T) {
+		// arrange
+		hd := response.Enveloped(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response.Success(w, http.StatusOK, response.Body{
+				Message: "products found",
+				Key:     "products",
+				Data:    []int{1},
+				Meta:    map[string]any{"count": 1},
+				Links:   map[string]string{"self": "/api/v1/products"},
+			})
+		}))
+
+		// act
+		rr := httptest.NewRecorder()
+		rr.Header().Set("X-Request-Id", "id")
+		hd.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/products", nil))
+
+		// assert
+		expectedCode := http.StatusOK
+		expectedBody := `{"data":[1],"meta":{"count":1,"message":"products found","request_id":"id"},"links":{"self":"/api/v1/products"}}`
+		require.Equal(t, expectedCode, rr.Code)
+		require.JSONEq(t, expectedBody, rr.Body.String())
+	})
+
+	t.Run("200 - envelope of a wrapped enveloped writer", func(t *testing.T) {
+		// arrange
+		hd := response.Enveloped(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			response.Success(ww, http.StatusOK, response.Body{Data: 1})
+		}))
+
+		// act
+		rr := httptest.NewRecorder()
+		hd.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+		// assert
+		expectedBody := `{"data":1,"meta":{}}`
+		require.JSONEq(t, expectedBody, rr.Body.String())
+	})
+}
+
+// Tests for WriteProblem function in an envelope
+func TestWriteProblem_Enveloped(t *testing.T) {
+	t.Run("404 - problem as the errors of an envelope", func(t *testing.T) {
+		// arrange
+		hd := response.Enveloped(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response.WriteProblem(w, response.Problem{Status: http.StatusNotFound, Code: "product_not_found", Title: "Product not found"})
+		}))
+
+		// act
+		rr := httptest.NewRecorder()
+		rr.Header().Set("X-Request-Id", "id")
+		hd.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/products/1", nil))
+
+		// assert
+		expectedCode := http.StatusNotFound
+		expectedBody := `{"data":null,"meta":{"request_id":"id"},"errors":[{"type":"/problems/product_not_found","title":"Product not found","status":404,"code":"product_not_found"}]}`
+		require.Equal(t, expectedCode, rr.Code)
+		require.JSONEq(t, expectedBody, rr.Body.String())
+		require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	})
+
+	t.Run("404 - envelope of a problem in the negotiated media type", func(t *testing.T) {
+		// arrange
+		hd := response.Negotiated(response.Enveloped(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response.WriteProblem(w, response.Problem{Status: http.StatusNotFound, Code: "product_not_found", Title: "Product not found"})
+		})))
+
+		// act
+		rr := httptest.NewRecorder()
+		rr.Header().Set("X-Request-Id", "id")
+		req := httptest.NewRequest("GET", "/api/v1/products/1", nil)
+		req.Header.Set("Accept", "application/xml")
+		hd.ServeHTTP(rr, req)
+
+		// assert
+		expectedCode := http.StatusNotFound
+		expectedBody := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+			`<response><data nil="true"></data><meta><request_id>id</request_id></meta><errors><item><type>/problems/product_not_found</type><title>Product not found</title><status>404</status><code>product_not_found</code></item></errors></response>`
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+		require.Equal(t, "application/xml; charset=utf-8", rr.Header().Get("Content-Type"))
+	})
+}
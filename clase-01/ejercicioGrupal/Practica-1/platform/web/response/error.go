@@ -1,42 +1,33 @@
 package response
 
 import (
-	"encoding/json"
-	"fmt"
 	"net/http"
 )
 
-type errorResponse struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
-}
+// StatusClientClosedRequest is the non-standard status code of a request whose client closed the connection
+// before the response, as logged by nginx.
+const StatusClientClosedRequest = 499
 
-func Error(w http.ResponseWriter, statusCode int, message string) {
-	// default status code
-	defaultStatusCode := http.StatusInternalServerError
-	// check if status code is valid
-	if statusCode > 299 && statusCode < 600 {
-		defaultStatusCode = statusCode
+// statusText returns the text of a status code, including StatusClientClosedRequest.
+func statusText(code int) string {
+	if code == StatusClientClosedRequest {
+		return "Client Closed Request"
 	}
+	return http.StatusText(code)
+}
 
-	// response
-	body := errorResponse{
-		Status:  http.StatusText(defaultStatusCode),
-		Message: message,
-	}
-	bytes, err := json.Marshal(body)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
+// Error writes a problem described by its status, with the message as its detail.
+func Error(w http.ResponseWriter, statusCode int, message string) {
+	WriteProblem(w, Problem{Status: statusCode, Detail: message})
+}
 
-	// write response
-	w.WriteHeader(defaultStatusCode)
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(bytes)
+// ErrorList writes a problem described by its status with the list of errors that caused it (e.g. the fields of an invalid body).
+func ErrorList(w http.ResponseWriter, statusCode int, message string, errs any) {
+	WriteProblem(w, Problem{Status: statusCode, Detail: message, Errors: errs})
 }
 
+// Errorf writes a problem described by its status, with the formatted message as its detail.
 func Errorf(w http.ResponseWriter, statusCode int, format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
+	message := Localize(w, format, args...)
 	Error(w, statusCode, message)
-}
\ No newline at end of file
+}
@@ -0,0 +1,17 @@
+package response
+
+import "net/http"
+
+// ETag sets the etag header of the response
+func ETag(w http.ResponseWriter, etag string) {
+	w.Header().Set("ETag", etag)
+}
+
+// NotModified writes a not modified response for etag
+func NotModified(w http.ResponseWriter, etag string) {
+	// set header
+	ETag(w, etag)
+
+	// set status code
+	w.WriteHeader(http.StatusNotModified)
+}
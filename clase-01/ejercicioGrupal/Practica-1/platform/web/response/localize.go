@@ -0,0 +1,16 @@
+package response
+
+import (
+	"app/platform/i18n"
+	"net/http"
+)
+
+// Localize returns a message translated to the language of the response, set in its Content-Language header by the request.Language middleware,
+// and formatted with args as fmt.Sprintf does. The message is kept in English when the response has no language.
+func Localize(w http.ResponseWriter, message string, args ...any) string {
+	lang := w.Header().Get("Content-Language")
+	if lang == "" {
+		lang = i18n.Default
+	}
+	return i18n.Translate(lang, message, args...)
+}
@@ -0,0 +1,48 @@
+package response_test
+
+import (
+	"app/platform/web/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for WriteProblem function
+func TestWriteProblem(t *testing.T) {
+	t.Run("404 - problem with its type and the request id", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		rr.Header().Set("X-Request-Id", "id")
+		p := response.Problem{Status: http.StatusNotFound, Code: "product_not_found", Title: "Product not found"}
+		response.WriteProblem(rr, p)
+
+		// assert
+		expectedCode := http.StatusNotFound
+		expectedBody := `{"type":"/problems/product_not_found","title":"Product not found","status":404,"code":"product_not_found","request_id":"id"}`
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+		require.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	})
+
+	t.Run("400 - problem in the language of the response", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		rr.Header().Set("Content-Language", "es")
+		p := response.Problem{Status: http.StatusBadRequest, Detail: "invalid id"}
+		response.WriteProblem(rr, p)
+
+		// assert
+		expectedCode := http.StatusBadRequest
+		expectedBody := `{"type":"about:blank","title":"Solicitud incorrecta","status":400,"detail":"invalid id","code":"bad_request"}`
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+	})
+}
@@ -4,17 +4,60 @@ import (
 	"app/internal/handler/application"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 )
 
 func main() {
 	// env
-	// ...
+	// - REQUEST_TIMEOUT: deadline of each request, as a duration (30s, 1m)
+	var requestTimeout time.Duration
+	if v := os.Getenv("REQUEST_TIMEOUT"); v != "" {
+		var err error
+		requestTimeout, err = time.ParseDuration(v)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	// - WEBHOOK_ATTEMPTS: deliveries of an event to a webhook before it becomes a dead letter
+	var webhookAttempts int
+	if v := os.Getenv("WEBHOOK_ATTEMPTS"); v != "" {
+		var err error
+		webhookAttempts, err = strconv.Atoi(v)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	// - WEBHOOK_BACKOFF: delay before the second delivery to a webhook, as a duration (1s, 500ms)
+	var webhookBackoff time.Duration
+	if v := os.Getenv("WEBHOOK_BACKOFF"); v != "" {
+		var err error
+		webhookBackoff, err = time.ParseDuration(v)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	// - OUTBOX_RELAY_INTERVAL: time between two passes of the relay of the outbox, as a duration (500ms, 2s)
+	var relayInterval time.Duration
+	if v := os.Getenv("OUTBOX_RELAY_INTERVAL"); v != "" {
+		var err error
+		relayInterval, err = time.ParseDuration(v)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
 
 	// application
 	// - config
 	cfg := &application.ConfigDefault{
+		Driver: os.Getenv("DB_DRIVER"),
 		Database: mysql.Config{
 			User:      os.Getenv("DB_USER"),
 			Passwd:    os.Getenv("DB_PASSWORD"),
@@ -23,7 +66,17 @@ func main() {
 			DBName:    os.Getenv("DB_NAME"),
 			ParseTime: true,
 		},
-		Address: "127.0.0.1:8080",
+		PostgresURL:       os.Getenv("DB_URL"),
+		SQLitePath:        os.Getenv("DB_PATH"),
+		Address:           "127.0.0.1:8080",
+		GRPCAddress:       os.Getenv("GRPC_ADDRESS"),
+		RequireMigrations: os.Getenv("REQUIRE_MIGRATIONS") == "true",
+		RequestTimeout:    requestTimeout,
+		LegacyRoutes:      os.Getenv("LEGACY_ROUTES") == "true",
+		WebhookAttempts:   webhookAttempts,
+		WebhookBackoff:    webhookBackoff,
+		RelayInterval:     relayInterval,
+		EventLogPath:      os.Getenv("EVENT_LOG_PATH"),
 	}
 	app := application.NewDefault(cfg)
 	// - run
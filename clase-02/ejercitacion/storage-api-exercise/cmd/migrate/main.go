@@ -0,0 +1,154 @@
+package main
+
+import (
+	"app/docs/db/migrations"
+	"app/internal/repository"
+	"app/platform/migrate"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// migrate manages the versioned schema of the database.
+//
+//	go run ./cmd/migrate up [n]     applies the pending migrations (n of them if given)
+//	go run ./cmd/migrate down [n]   rolls back the last applied migration (n of them if given)
+//	go run ./cmd/migrate status     lists the migrations and whether they are applied
+//	go run ./cmd/migrate reindex    writes the search terms of the products stored before 0007_create_product_search
+//
+// The database is selected by DB_DRIVER: mysql (default, DB_USER, DB_PASSWORD, DB_HOST, DB_NAME),
+// postgres (DB_URL) or sqlite (DB_PATH).
+func main() {
+	// env
+	// - args
+	if len(os.Args) < 2 {
+		fmt.Println("usage: migrate up|down|status|reindex [n]")
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	var steps int
+	if len(os.Args) > 2 {
+		var err error
+		steps, err = strconv.Atoi(os.Args[2])
+		if err != nil || steps < 0 {
+			fmt.Println("invalid number of migrations:", os.Args[2])
+			os.Exit(2)
+		}
+	}
+
+	// dependencies
+	// - database: connection
+	driver := os.Getenv("DB_DRIVER")
+	var dsn string
+	switch driver {
+	case "", "mysql":
+		driver = "mysql"
+		cfg := mysql.Config{
+			User:      os.Getenv("DB_USER"),
+			Passwd:    os.Getenv("DB_PASSWORD"),
+			Net:       "tcp",
+			Addr:      os.Getenv("DB_HOST"),
+			DBName:    os.Getenv("DB_NAME"),
+			ParseTime: true,
+		}
+		dsn = cfg.FormatDSN()
+	case "postgres":
+		driver = "pgx"
+		dsn = os.Getenv("DB_URL")
+	case "sqlite":
+		path := os.Getenv("DB_PATH")
+		if path == "" {
+			path = "storage_api_db.sqlite"
+		}
+		dsn = repository.DSNSQLite(path)
+	default:
+		fmt.Println("unknown database driver:", driver)
+		os.Exit(2)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer db.Close()
+	// - migrations
+	placeholder := migrate.PlaceholderQuestion
+	if driver == "pgx" {
+		driver, placeholder = "postgres", migrate.PlaceholderDollar
+	}
+	fsys, err := migrations.FS(driver)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	ms, err := migrate.Load(fsys)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	// - each migration in a transaction where ddl is transactional (postgres, sqlite)
+	mg := migrate.NewMigratorTransactional(db, ms, placeholder)
+	if driver == "mysql" {
+		mg = migrate.NewMigratorPlaceholder(db, ms, placeholder)
+	}
+
+	// run
+	switch command {
+	case "up":
+		var done []migrate.Migration
+		done, err = mg.Up(steps)
+		for _, m := range done {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+	case "down":
+		var done []migrate.Migration
+		done, err = mg.Down(steps)
+		for _, m := range done {
+			fmt.Printf("rolled back %04d_%s\n", m.Version, m.Name)
+		}
+	case "status":
+		var st []migrate.Status
+		st, err = mg.Status()
+		for _, s := range st {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format(time.DateTime)
+			}
+			if s.Modified {
+				state += " (modified after being applied)"
+			}
+			fmt.Printf("%04d_%s: %s\n", s.Version, s.Name, state)
+		}
+	case "reindex":
+		// - the products of the database, with their search terms written again
+		var rp interface {
+			Reindex(ctx context.Context) (n int, err error)
+		}
+		switch driver {
+		case "mysql":
+			rp = repository.NewProductsMySQL(db)
+		case "postgres":
+			rp = repository.NewProductsPostgres(db)
+		default:
+			rp = repository.NewProductsSQLite(db)
+		}
+		if err = mg.CheckUpToDate(); err != nil {
+			break
+		}
+		var n int
+		n, err = rp.Reindex(context.Background())
+		fmt.Printf("indexed %d products\n", n)
+	default:
+		fmt.Println("usage: migrate up|down|status|reindex [n]")
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
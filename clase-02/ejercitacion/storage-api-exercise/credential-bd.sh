@@ -1,6 +1,9 @@
+# DB_DRIVER=sqlite usa el archivo DB_PATH y DB_DRIVER=postgres la url DB_URL en lugar de MySQL
+export DB_DRIVER="mysql"
 export DB_USER="user1"
 export DB_PASSWORD="secret_password"
 export DB_HOST="localhost:3306"
-export DB_NAME="my_db"
+export DB_NAME="storage_api_db"
 export DB_NAME_TEST="my_db_test"
+export REQUIRE_MIGRATIONS="true"
 echo "Configurando credenciales de base de datos"
\ No newline at end of file
@@ -0,0 +1,17 @@
+// Package migrations embeds the versioned schema migrations of each database driver.
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed mysql postgres sqlite
+var files embed.FS
+
+// FS returns the migrations of a database driver
+func FS(driver string) (fsys fs.FS, err error) {
+	fsys, err = fs.Sub(files, driver)
+	return
+}
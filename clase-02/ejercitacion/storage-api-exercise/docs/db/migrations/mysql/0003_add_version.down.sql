@@ -0,0 +1,2 @@
+ALTER TABLE `warehouses` DROP COLUMN `version`;
+ALTER TABLE `products` DROP COLUMN `version`;
@@ -0,0 +1,12 @@
+-- Crear tabla outbox donde se escriben los eventos de los cambios de products y warehouses,
+-- en la misma transaccion que el cambio, pendientes hasta que el relay los entrega
+CREATE TABLE `outbox` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `entity` varchar(64) NOT NULL,
+  `type` varchar(64) NOT NULL,
+  `payload` text NOT NULL,
+  `created_at` datetime(6) NOT NULL,
+  `relayed_at` datetime(6) NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_outbox_relayed_at` (`relayed_at`, `id`)
+);
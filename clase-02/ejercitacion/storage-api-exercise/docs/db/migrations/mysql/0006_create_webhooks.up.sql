@@ -0,0 +1,24 @@
+-- Crear tablas webhooks, las suscripciones de urls a los eventos, y webhook_dead_letters,
+-- los eventos que no se pudieron entregar a un webhook, para que sobrevivan a un reinicio
+CREATE TABLE `webhooks` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `url` text NOT NULL,
+  `events` text NOT NULL,
+  `secret` text NOT NULL,
+  `version` int NOT NULL DEFAULT 1,
+  PRIMARY KEY (`id`)
+);
+CREATE TABLE `webhook_dead_letters` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `id_webhook` int NOT NULL,
+  `event_id` bigint NOT NULL,
+  `event_type` varchar(64) NOT NULL,
+  `event_payload` text NOT NULL,
+  `event_time` datetime(6) NOT NULL,
+  `attempts` int NOT NULL,
+  `error` text NOT NULL,
+  `failed_at` datetime(6) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_webhook_dead_letters_id_webhook` (`id_webhook`, `id`),
+  CONSTRAINT `fk_webhook_dead_letters_webhooks` FOREIGN KEY (`id_webhook`) REFERENCES `webhooks` (`id`) ON DELETE CASCADE
+);
@@ -0,0 +1,19 @@
+-- Crear tablas product_search_terms, los terminos del nombre y el code_value de cada producto en minuscula y sin acentos
+-- (platform/search), y product_search_typos, las variantes de cada termino sin una letra o sin dos desde 6 letras
+-- (search.TypoKeys): el indice de busqueda de GET /products/search. Una busqueda encuentra los terminos que empiezan
+-- por los suyos por el rango de la clave primaria, y los que tienen un error de tipeo por la clave de sus variantes
+-- Los escriben los repositorios con cada producto, en la misma transaccion. Los terminos se borran con el producto
+-- por la clave foranea; las variantes se guardan una vez por termino y quedan para los proximos productos que lo tengan
+-- Los productos guardados antes se indexan con go run ./cmd/migrate reindex
+CREATE TABLE `product_search_terms` (
+  `term` varchar(255) COLLATE utf8mb4_bin NOT NULL,
+  `id_product` int NOT NULL,
+  PRIMARY KEY (`term`, `id_product`),
+  KEY `idx_product_search_terms_id_product` (`id_product`),
+  CONSTRAINT `fk_product_search_terms_products` FOREIGN KEY (`id_product`) REFERENCES `products` (`id`) ON DELETE CASCADE
+);
+CREATE TABLE `product_search_typos` (
+  `variant` varchar(255) COLLATE utf8mb4_bin NOT NULL,
+  `term` varchar(255) COLLATE utf8mb4_bin NOT NULL,
+  PRIMARY KEY (`variant`, `term`)
+);
@@ -0,0 +1,10 @@
+-- Crear tabla products donde se almacenan los productos
+CREATE TABLE products (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  quantity INT NOT NULL,
+  code_value VARCHAR(255) NOT NULL,
+  is_published BOOLEAN NOT NULL,
+  expiration DATE NOT NULL,
+  price NUMERIC(10, 2) NOT NULL
+);
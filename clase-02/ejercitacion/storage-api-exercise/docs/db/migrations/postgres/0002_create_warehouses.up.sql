@@ -0,0 +1,19 @@
+-- Crear tabla warehouses donde se almacenan datos de los almacenes de products
+CREATE TABLE warehouses (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  adress VARCHAR(150) NOT NULL,
+  telephone VARCHAR(150) NOT NULL,
+  capacity INT NOT NULL
+);
+
+-- Almacen por defecto, la secuencia continua despues de su id
+INSERT INTO warehouses (id, name, adress, telephone, capacity) VALUES
+(1, 'Main Warehouse', '221 Baker Street', '4555666', 100);
+SELECT setval('warehouses_id_seq', (SELECT MAX(id) FROM warehouses));
+
+-- Se agrega una columna para el id del warehouse a la tabla products, asignada al almacen por defecto
+ALTER TABLE products ADD COLUMN id_warehouse INT NOT NULL DEFAULT 1;
+ALTER TABLE products ALTER COLUMN id_warehouse DROP DEFAULT;
+ALTER TABLE products
+  ADD CONSTRAINT fk_products_warehouses FOREIGN KEY (id_warehouse) REFERENCES warehouses (id);
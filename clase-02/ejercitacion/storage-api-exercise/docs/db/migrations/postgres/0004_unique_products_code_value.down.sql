@@ -0,0 +1 @@
+ALTER TABLE products DROP CONSTRAINT uq_products_code_value;
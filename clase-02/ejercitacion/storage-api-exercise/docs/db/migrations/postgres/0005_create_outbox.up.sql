@@ -0,0 +1,11 @@
+-- Crear tabla outbox donde se escriben los eventos de los cambios de products y warehouses,
+-- en la misma transaccion que el cambio, pendientes hasta que el relay los entrega
+CREATE TABLE outbox (
+  id BIGSERIAL PRIMARY KEY,
+  entity VARCHAR(64) NOT NULL,
+  type VARCHAR(64) NOT NULL,
+  payload TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  relayed_at TIMESTAMPTZ NULL
+);
+CREATE INDEX idx_outbox_relayed_at ON outbox (relayed_at, id);
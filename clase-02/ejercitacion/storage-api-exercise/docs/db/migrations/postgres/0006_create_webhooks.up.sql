@@ -0,0 +1,21 @@
+-- Crear tablas webhooks, las suscripciones de urls a los eventos, y webhook_dead_letters,
+-- los eventos que no se pudieron entregar a un webhook, para que sobrevivan a un reinicio
+CREATE TABLE webhooks (
+  id SERIAL PRIMARY KEY,
+  url TEXT NOT NULL,
+  events TEXT NOT NULL,
+  secret TEXT NOT NULL,
+  version INT NOT NULL DEFAULT 1
+);
+CREATE TABLE webhook_dead_letters (
+  id SERIAL PRIMARY KEY,
+  id_webhook INT NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+  event_id BIGINT NOT NULL,
+  event_type VARCHAR(64) NOT NULL,
+  event_payload TEXT NOT NULL,
+  event_time TIMESTAMPTZ NOT NULL,
+  attempts INT NOT NULL,
+  error TEXT NOT NULL,
+  failed_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_webhook_dead_letters_id_webhook ON webhook_dead_letters (id_webhook, id);
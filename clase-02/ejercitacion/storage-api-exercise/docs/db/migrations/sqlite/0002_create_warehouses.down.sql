@@ -0,0 +1,15 @@
+-- Se reconstruye la tabla products sin la columna id_warehouse
+CREATE TABLE `products_old` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `name` varchar(255) NOT NULL,
+  `quantity` int NOT NULL,
+  `code_value` varchar(255) NOT NULL,
+  `is_published` boolean NOT NULL,
+  `expiration` date NOT NULL,
+  `price` decimal(10, 2) NOT NULL
+);
+INSERT INTO `products_old` (`id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`)
+  SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price` FROM `products`;
+DROP TABLE `products`;
+ALTER TABLE `products_old` RENAME TO `products`;
+DROP TABLE `warehouses`;
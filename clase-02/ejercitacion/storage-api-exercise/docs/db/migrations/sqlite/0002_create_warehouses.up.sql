@@ -0,0 +1,30 @@
+-- Crear tabla warehouses donde se almacenan datos de los almacenes de products
+CREATE TABLE `warehouses` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `name` varchar(255) NOT NULL,
+  `adress` varchar(150) NOT NULL,
+  `telephone` varchar(150) NOT NULL,
+  `capacity` int NOT NULL
+);
+
+-- Almacen por defecto
+INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES
+(1, 'Main Warehouse', '221 Baker Street', '4555666', 100);
+
+-- SQLite no permite agregar una foreign key con ALTER TABLE, se reconstruye la tabla products
+-- con la columna id_warehouse asignada al almacen por defecto
+CREATE TABLE `products_new` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `name` varchar(255) NOT NULL,
+  `quantity` int NOT NULL,
+  `code_value` varchar(255) NOT NULL,
+  `is_published` boolean NOT NULL,
+  `expiration` date NOT NULL,
+  `price` decimal(10, 2) NOT NULL,
+  `id_warehouse` int NOT NULL,
+  CONSTRAINT `fk_products_warehouses` FOREIGN KEY (`id_warehouse`) REFERENCES `warehouses` (`id`)
+);
+INSERT INTO `products_new` (`id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`)
+  SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, 1 FROM `products`;
+DROP TABLE `products`;
+ALTER TABLE `products_new` RENAME TO `products`;
@@ -0,0 +1,3 @@
+-- Columnas de version para el control de concurrencia optimista
+ALTER TABLE `products` ADD `version` int NOT NULL DEFAULT 1;
+ALTER TABLE `warehouses` ADD `version` int NOT NULL DEFAULT 1;
@@ -0,0 +1 @@
+DROP INDEX `uq_products_code_value`;
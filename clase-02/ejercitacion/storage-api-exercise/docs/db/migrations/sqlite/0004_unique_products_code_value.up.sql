@@ -0,0 +1,2 @@
+-- El code_value identifica a cada producto (importaciones y seeds hacen upsert por code_value)
+CREATE UNIQUE INDEX `uq_products_code_value` ON `products` (`code_value`);
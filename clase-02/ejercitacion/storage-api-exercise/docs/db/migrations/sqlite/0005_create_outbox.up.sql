@@ -0,0 +1,11 @@
+-- Crear tabla outbox donde se escriben los eventos de los cambios de products y warehouses,
+-- en la misma transaccion que el cambio, pendientes hasta que el relay los entrega
+CREATE TABLE `outbox` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `entity` varchar(64) NOT NULL,
+  `type` varchar(64) NOT NULL,
+  `payload` text NOT NULL,
+  `created_at` datetime NOT NULL,
+  `relayed_at` datetime NULL
+);
+CREATE INDEX `idx_outbox_relayed_at` ON `outbox` (`relayed_at`, `id`);
@@ -0,0 +1,21 @@
+-- Crear tablas webhooks, las suscripciones de urls a los eventos, y webhook_dead_letters,
+-- los eventos que no se pudieron entregar a un webhook, para que sobrevivan a un reinicio
+CREATE TABLE `webhooks` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `url` text NOT NULL,
+  `events` text NOT NULL,
+  `secret` text NOT NULL,
+  `version` int NOT NULL DEFAULT 1
+);
+CREATE TABLE `webhook_dead_letters` (
+  `id` INTEGER PRIMARY KEY AUTOINCREMENT,
+  `id_webhook` int NOT NULL REFERENCES `webhooks` (`id`) ON DELETE CASCADE,
+  `event_id` bigint NOT NULL,
+  `event_type` varchar(64) NOT NULL,
+  `event_payload` text NOT NULL,
+  `event_time` datetime NOT NULL,
+  `attempts` int NOT NULL,
+  `error` text NOT NULL,
+  `failed_at` datetime NOT NULL
+);
+CREATE INDEX `idx_webhook_dead_letters_id_webhook` ON `webhook_dead_letters` (`id_webhook`, `id`);
@@ -0,0 +1,2 @@
+DROP TABLE `product_search_typos`;
+DROP TABLE `product_search_terms`;
@@ -1,17 +1,6 @@
 -- DDL: Data Definition Language
+-- Crea la base de datos vacia. Las tablas se crean con las migraciones versionadas de docs/db/migrations/mysql:
+--   go run ./cmd/migrate up
 DROP DATABASE IF EXISTS `storage_api_db`;
 
 CREATE DATABASE `storage_api_db`;
-
-USE `storage_api_db`;
-
-CREATE TABLE `products` (
-  `id` int NOT NULL AUTO_INCREMENT,
-  `name` varchar(255) NOT NULL,
-  `quantity` int NOT NULL,
-  `code_value` varchar(255) NOT NULL,
-  `is_published` boolean NOT NULL,
-  `expiration` date NOT NULL,
-  `price` decimal(10, 2) NOT NULL,
-  PRIMARY KEY (`id`)
-);
\ No newline at end of file
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+// EventType is the kind of change of an event, named after its resource (e.g. product.created)
+type EventType string
+
+const (
+	// EventProductCreated is the type of the event of a created product, its data is the Product
+	EventProductCreated EventType = "product.created"
+	// EventProductUpdated is the type of the event of an updated product, its data is the Product
+	EventProductUpdated EventType = "product.updated"
+	// EventProductDeleted is the type of the event of a deleted product, its data is the Product with only its id
+	EventProductDeleted EventType = "product.deleted"
+	// EventProductStockChanged is the type of the event of a product whose quantity changed, its data is the StockChange
+	EventProductStockChanged EventType = "product.stock_changed"
+	// EventWarehouseCreated is the type of the event of a created warehouse, its data is the Warehouse
+	EventWarehouseCreated EventType = "warehouse.created"
+	// EventWarehouseUpdated is the type of the event of an updated warehouse, its data is the Warehouse
+	EventWarehouseUpdated EventType = "warehouse.updated"
+)
+
+// EventTypes are the types of the events, in order of resource
+var EventTypes = []EventType{
+	EventProductCreated,
+	EventProductUpdated,
+	EventProductDeleted,
+	EventProductStockChanged,
+	EventWarehouseCreated,
+	EventWarehouseUpdated,
+}
+
+// Event is a struct that represents a change of a product or a warehouse
+type Event struct {
+	// ID is the number of the event: the one of its outbox message, kept by the bus of the event stream
+	ID int64
+	// Type is the kind of change
+	Type EventType
+	// Time is when the change was published
+	Time time.Time
+	// Data is the resource after the change: a Product, a Warehouse or a StockChange, as its type tells
+	Data any
+}
+
+// Number returns the number of the event, zero when it has none
+func (e Event) Number() int64 {
+	return e.ID
+}
+
+// WithID returns the event with its number
+func (e Event) WithID(id int64) Event {
+	e.ID = id
+	return e
+}
+
+// StockChange is a struct that represents the change of the quantity of a product
+type StockChange struct {
+	// ProductID is the id of the product
+	ProductID int
+	// WarehouseId is the id of the warehouse of the product
+	WarehouseId int
+	// Previous is the quantity before the change, zero for a created product
+	Previous int
+	// Quantity is the quantity after the change
+	Quantity int
+}
+
+// Events is an interface that represents the bus of the events of the changes of products and warehouses
+type Events interface {
+	// Publish sends an event to the current subscribers with its number, or numbered after the highest one when it has none.
+	// An event published again is not sent again while it is kept. It returns the event with its number
+	Publish(e Event) Event
+	// Subscribe returns the events published after the one numbered after, in order of publication, then the ones published from now on,
+	// until ctx is done or the subscriber falls behind, when the channel is closed. An after of zero receives only the events from now on.
+	// It returns an error when the events after it are no longer kept
+	Subscribe(ctx context.Context, after int64) (events <-chan Event, err error)
+}
@@ -0,0 +1,24 @@
+package internal
+
+// FieldError is an error of a field that breaks a validation rule.
+// It matches the error of its entity (e.g. ErrProductInvalid) with errors.Is
+type FieldError struct {
+	// Field is the name of the field, as in the api (e.g. code_value)
+	Field string
+	// Code is the rule it breaks (e.g. required)
+	Code string
+	// Message describes the broken rule (e.g. is required)
+	Message string
+	// Err is the error of the entity
+	Err error
+}
+
+// Error returns the error message
+func (e *FieldError) Error() string {
+	return e.Err.Error() + ": " + e.Field + " " + e.Message
+}
+
+// Unwrap returns the error of the entity
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
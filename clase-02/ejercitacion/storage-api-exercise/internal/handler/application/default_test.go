@@ -0,0 +1,126 @@
+package application
+
+import (
+	"app/docs/openapi"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// router returns the router of the application with every route, without the services and unit of work of its handlers
+func router() (rt *chi.Mux) {
+	d := NewDefault(&ConfigDefault{LegacyRoutes: true})
+	rt = d.router(nil, nil, nil, nil, nil, nil)
+	return
+}
+
+// registered returns the methods of each route of a router, in the path format of the OpenAPI document
+func registered(t *testing.T, rt chi.Routes) (ops map[string][]string) {
+	ops = make(map[string][]string)
+	err := chi.Walk(rt, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		ops[route] = append(ops[route], method)
+		return nil
+	})
+	require.NoError(t, err)
+	for route := range ops {
+		sort.Strings(ops[route])
+	}
+	return
+}
+
+// Tests for the routes of the application
+func TestRoutes(t *testing.T) {
+	t.Run("case 1: every registered route is documented", func(t *testing.T) {
+		// arrange
+		documented, err := openapi.Operations()
+		require.NoError(t, err)
+
+		// act
+		ops := registered(t, router())
+
+		// assert
+		for route, methods := range ops {
+			for _, method := range methods {
+				require.Contains(t, documented[route], method, "%s %s is missing from docs/openapi/openapi.json", method, route)
+			}
+		}
+	})
+
+	t.Run("case 2: every documented operation is registered", func(t *testing.T) {
+		// arrange
+		ops := registered(t, router())
+
+		// act
+		documented, err := openapi.Operations()
+		require.NoError(t, err)
+
+		// assert
+		for route, methods := range documented {
+			for _, method := range methods {
+				require.Contains(t, ops[route], method, "%s %s is documented but not registered", method, route)
+			}
+		}
+	})
+
+	t.Run("case 3: the document and the docs page are served", func(t *testing.T) {
+		// arrange
+		rt := router()
+
+		// act
+		resSpec := httptest.NewRecorder()
+		rt.ServeHTTP(resSpec, httptest.NewRequest("GET", "/openapi.json", nil))
+		resDocs := httptest.NewRecorder()
+		rt.ServeHTTP(resDocs, httptest.NewRequest("GET", "/docs", nil))
+
+		// assert
+		require.Equal(t, http.StatusOK, resSpec.Code)
+		require.Equal(t, "application/json", resSpec.Header().Get("Content-Type"))
+		require.JSONEq(t, string(openapi.Spec), resSpec.Body.String())
+		require.Equal(t, http.StatusOK, resDocs.Code)
+		require.Contains(t, resDocs.Body.String(), `fetch("openapi.json")`)
+	})
+
+	t.Run("case 4: the legacy routes are registered only behind their flag", func(t *testing.T) {
+		// arrange
+		d := NewDefault(nil)
+
+		// act
+		ops := registered(t, d.router(nil, nil, nil, nil, nil, nil))
+
+		// assert
+		require.Contains(t, ops, "/api/v1/products")
+		require.NotContains(t, ops, "/products")
+		require.NotContains(t, ops, "/warehouses")
+	})
+
+	t.Run("case 5: the api v1 writes its errors in an envelope, the legacy routes as problems", func(t *testing.T) {
+		// arrange
+		rt := router()
+
+		// act
+		resV1 := httptest.NewRecorder()
+		reqV1 := httptest.NewRequest("GET", "/api/v1/products/abc", nil)
+		reqV1.Header.Set("X-Request-Id", "id")
+		rt.ServeHTTP(resV1, reqV1)
+		resLegacy := httptest.NewRecorder()
+		reqLegacy := httptest.NewRequest("GET", "/products/abc", nil)
+		reqLegacy.Header.Set("X-Request-Id", "id")
+		rt.ServeHTTP(resLegacy, reqLegacy)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, resV1.Code)
+		require.Equal(t, "application/json", resV1.Header().Get("Content-Type"))
+		require.JSONEq(t, `{"data":null,"meta":{"request_id":"id"},"errors":[{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid id","code":"bad_request"}]}`, resV1.Body.String())
+		require.Equal(t, http.StatusBadRequest, resLegacy.Code)
+		require.Equal(t, "application/problem+json", resLegacy.Header().Get("Content-Type"))
+		require.JSONEq(t, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid id","code":"bad_request","request_id":"id"}`, resLegacy.Body.String())
+	})
+}
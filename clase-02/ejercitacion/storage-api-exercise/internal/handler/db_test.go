@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"app/docs/db/migrations"
+	"app/internal"
+	"app/internal/repository"
+	"app/platform/migrate"
+	"app/platform/mysqltest"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-txdb"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	txdb.Register("txdb_postgres", "pgx", os.Getenv("TEST_DB_URL"))
+}
+
+// mysqlServer is the embedded mysql compatible server shared by the tests of the package, started on first use
+var mysqlServer = sync.OnceValues(mysqltest.NewServer)
+
+// migrateTestDB applies the migrations of driver to db and removes the seeded rows
+func migrateTestDB(t *testing.T, db *sql.DB, driver string) {
+	t.Helper()
+
+	fsys, err := migrations.FS(driver)
+	require.NoError(t, err)
+	ms, err := migrate.Load(fsys)
+	require.NoError(t, err)
+	_, err = migrate.NewMigrator(db, ms).Up(0)
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM `warehouses`")
+	require.NoError(t, err)
+}
+
+// newTestUnitOfWork returns an empty database and the unit of work of its repositories for a handler test.
+// By default it is a temp-file sqlite database with the migrations applied,
+// TEST_DB_DRIVER=mysql uses a new migrated database of the embedded mysql compatible server instead,
+// and TEST_DB_DRIVER=postgres in one of the migrated postgres test database at TEST_DB_URL.
+func newTestUnitOfWork(t *testing.T) (db *sql.DB, uow internal.UnitOfWork) {
+	t.Helper()
+
+	var err error
+	switch os.Getenv("TEST_DB_DRIVER") {
+	case "mysql":
+		s, err := mysqlServer()
+		require.NoError(t, err)
+		cfg, err := s.NewDatabase()
+		require.NoError(t, err)
+		db, err = sql.Open("mysql", cfg.FormatDSN())
+		require.NoError(t, err)
+		migrateTestDB(t, db, "mysql")
+		uow = repository.NewUnitOfWorkMySQL(db)
+	case "postgres":
+		db, err = sql.Open("txdb_postgres", t.Name())
+		require.NoError(t, err)
+		uow = repository.NewUnitOfWorkPostgres(db)
+	default:
+		db, err = sql.Open("sqlite", repository.DSNSQLite(filepath.Join(t.TempDir(), "test.db")))
+		require.NoError(t, err)
+		migrateTestDB(t, db, "sqlite")
+		_, err = db.Exec("DELETE FROM `sqlite_sequence`")
+		require.NoError(t, err)
+		uow = repository.NewUnitOfWorkSQLite(db)
+	}
+	t.Cleanup(func() { db.Close() })
+	return
+}
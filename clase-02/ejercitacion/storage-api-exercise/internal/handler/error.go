@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/validate"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"context"
+	"errors"
+	"net/http"
+)
+
+// problemKind is the kind of problem of the response of an error
+type problemKind struct {
+	err    error
+	status int
+	code   string
+	title  string
+}
+
+// problemKinds maps the domain errors to the kind of problem of their response, the first one matched with errors.Is wins
+var problemKinds = []problemKind{
+	// - product
+	{internal.ErrProductInvalid, http.StatusUnprocessableEntity, "product_invalid", "Product breaks a validation rule"},
+	{internal.ErrProductNotFound, http.StatusNotFound, "product_not_found", "Product not found"},
+	{internal.ErrProductNotUnique, http.StatusConflict, "product_not_unique", "Product not unique"},
+	{internal.ErrProductRelation, http.StatusConflict, "product_relation", "Product relation error"},
+	{internal.ErrProductVersionConflict, http.StatusPreconditionFailed, "product_version_conflict", "Product has been modified"},
+	// - warehouse
+	{internal.ErrWarehouseInvalid, http.StatusUnprocessableEntity, "warehouse_invalid", "Warehouse breaks a validation rule"},
+	{internal.ErrWarehouseNotFound, http.StatusNotFound, "warehouse_not_found", "Warehouse not found"},
+	{internal.ErrWarehouseAlreadyExists, http.StatusConflict, "warehouse_already_exists", "Warehouse already exists"},
+	{internal.ErrWarehouseCapacityExceeded, http.StatusConflict, "warehouse_capacity_exceeded", "Warehouse capacity exceeded"},
+	{internal.ErrWarehouseVersionConflict, http.StatusPreconditionFailed, "warehouse_version_conflict", "Warehouse has been modified"},
+	// - webhook
+	{internal.ErrWebhookInvalid, http.StatusUnprocessableEntity, "webhook_invalid", "Webhook breaks a validation rule"},
+	{internal.ErrWebhookNotFound, http.StatusNotFound, "webhook_not_found", "Webhook not found"},
+	{internal.ErrWebhookVersionConflict, http.StatusPreconditionFailed, "webhook_version_conflict", "Webhook has been modified"},
+	{internal.ErrDeadLetterNotFound, http.StatusNotFound, "dead_letter_not_found", "Dead letter not found"},
+	// - request
+	{errInvalidCursor, http.StatusBadRequest, "invalid_cursor", "Invalid cursor"},
+	{errInvalidPage, http.StatusBadRequest, "invalid_page", "Invalid page size"},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, "request_timeout", "Request timed out"},
+	{context.Canceled, response.StatusClientClosedRequest, "client_closed_request", "Client closed request"},
+}
+
+// problemOf returns the problem of an error: the kind of its domain error, with the fields that break a rule in the language of the response when it is invalid.
+// Any other error is an internal error without details, as they could leak the internals of the storage
+func problemOf(w http.ResponseWriter, err error) (p response.Problem) {
+	// - request body that breaks a rule
+	var errs validate.Errors
+	if errors.As(err, &errs) {
+		p = response.Problem{Status: http.StatusUnprocessableEntity, Code: "invalid_body", Title: "Request body breaks a validation rule", Errors: fieldErrors(w, err)}
+		return
+	}
+
+	// - domain error
+	for _, k := range problemKinds {
+		if errors.Is(err, k.err) {
+			p = response.Problem{Status: k.status, Code: k.code, Title: k.title}
+			var fieldErr *internal.FieldError
+			if errors.As(err, &fieldErr) {
+				p.Detail, p.Errors = fieldMessage(w, err), fieldErrors(w, err)
+			}
+			return
+		}
+	}
+
+	// - internal error
+	p = response.Problem{Status: http.StatusInternalServerError, Code: "internal_error", Title: "Internal server error"}
+	return
+}
+
+// writeError writes the problem of an error
+func writeError(w http.ResponseWriter, err error) {
+	response.WriteProblem(w, problemOf(w, err))
+}
+
+// writeErrorDetail writes the problem of an error with a detail that explains it in the context of the request
+func writeErrorDetail(w http.ResponseWriter, err error, detail string) {
+	p := problemOf(w, err)
+	p.Detail = detail
+	response.WriteProblem(w, p)
+}
+
+// writeBodyError writes the problem of a request body that can not be decoded: 415 when its content type is not supported, otherwise 400
+func writeBodyError(w http.ResponseWriter, err error) {
+	if errors.Is(err, request.ErrRequestContentTypeNotSupported) {
+		response.Error(w, http.StatusUnsupportedMediaType, "unsupported content type")
+		return
+	}
+	response.Error(w, http.StatusBadRequest, "invalid request body")
+}
+
+// fieldMessage returns the message of a validation error in the language of the response: the field and the rule it breaks
+func fieldMessage(w http.ResponseWriter, err error) string {
+	var fieldErr *internal.FieldError
+	if errors.As(err, &fieldErr) {
+		return response.Localize(w, "%[1]s "+fieldErr.Message, fieldErr.Field)
+	}
+	return err.Error()
+}
+
+// fieldErrors returns the field errors of a validation error in the language of the response,
+// either the rules broken by a request body or the one broken by an entity in the service
+func fieldErrors(w http.ResponseWriter, err error) (errs validate.Errors) {
+	var bodyErrs validate.Errors
+	var fieldErr *internal.FieldError
+	switch {
+	case errors.As(err, &bodyErrs):
+		errs = make(validate.Errors, len(bodyErrs))
+		for i, e := range bodyErrs {
+			errs[i] = e.Localize(func(format string, args ...any) string { return response.Localize(w, format, args...) })
+		}
+	case errors.As(err, &fieldErr):
+		errs = validate.Errors{{Field: fieldErr.Field, Code: fieldErr.Code, Message: fieldMessage(w, err)}}
+	default:
+		errs = validate.Errors{{Message: err.Error()}}
+	}
+	return
+}
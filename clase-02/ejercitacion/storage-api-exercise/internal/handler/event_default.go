@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/broadcast"
+	"app/platform/web/response"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// eventsRetry is the delay a client of the event stream waits to reconnect
+	eventsRetry = 3 * time.Second
+	// eventsKeepAlive is the interval of the comments that keep an idle event stream open through proxies
+	eventsKeepAlive = 15 * time.Second
+)
+
+// NewEventsDefault returns a new instance of EventsDefault
+func NewEventsDefault(ev internal.Events) *EventsDefault {
+	return &EventsDefault{
+		ev:        ev,
+		keepAlive: eventsKeepAlive,
+	}
+}
+
+// EventsDefault is a struct that represents the default handler of the event stream
+type EventsDefault struct {
+	// ev is the event bus
+	ev internal.Events
+	// keepAlive is the interval of the keep-alive comments
+	keepAlive time.Duration
+}
+
+// EventJSON is a struct that represents an event in JSON
+type EventJSON struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+	Time string `json:"time"`
+	Data any    `json:"data"`
+}
+
+// ProductDeletedJSON is a struct that represents the data of the event of a deleted product in JSON
+type ProductDeletedJSON struct {
+	ID int `json:"id"`
+}
+
+// StockChangeJSON is a struct that represents the data of a stock event in JSON
+type StockChangeJSON struct {
+	ProductID   int `json:"product_id"`
+	WarehouseId int `json:"warehouse_id"`
+	Previous    int `json:"previous"`
+	Quantity    int `json:"quantity"`
+}
+
+// ExpiredJSON is a struct that represents the event sent when the stream can not resume from the last event of the client
+type ExpiredJSON struct {
+	LastEventID int64 `json:"last_event_id"`
+}
+
+// eventJSON returns the JSON of an event, its data as the api writes its resource
+func eventJSON(e internal.Event) (ej EventJSON) {
+	ej = EventJSON{ID: e.ID, Type: string(e.Type), Time: e.Time.UTC().Format(time.RFC3339Nano), Data: e.Data}
+
+	switch d := e.Data.(type) {
+	case internal.Product:
+		if e.Type == internal.EventProductDeleted {
+			ej.Data = ProductDeletedJSON{ID: d.ID}
+			return
+		}
+		ej.Data = ProductJSON{
+			ID:          d.ID,
+			Name:        d.Name,
+			Quantity:    d.Quantity,
+			CodeValue:   d.CodeValue,
+			IsPublished: d.IsPublished,
+			Expiration:  d.Expiration.Format(time.DateOnly),
+			Price:       d.Price,
+			WarehouseId: d.WarehouseId,
+		}
+	case internal.Warehouse:
+		ej.Data = WarehouseJSON{
+			Id:        d.Id,
+			Name:      d.Name,
+			Address:   d.Address,
+			Telephone: d.Telephone,
+			Capacity:  d.Capacity,
+		}
+	case internal.StockChange:
+		ej.Data = StockChangeJSON{
+			ProductID:   d.ProductID,
+			WarehouseId: d.WarehouseId,
+			Previous:    d.Previous,
+			Quantity:    d.Quantity,
+		}
+	}
+	return
+}
+
+// lastEventID returns the id of the last event received by the client, from the Last-Event-ID header a browser sends on reconnect
+// or the last_event_id query parameter. It is zero when the client has none
+func lastEventID(r *http.Request) (id int64, err error) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("last_event_id")
+	}
+	if v == "" {
+		return
+	}
+	id, err = strconv.ParseInt(v, 10, 64)
+	if err == nil && id < 0 {
+		err = strconv.ErrRange
+	}
+	return
+}
+
+// Stream streams the events of the bus as Server-Sent Events, until the client disconnects.
+// A client that reconnects receives the events it missed, or an expired event when they are no longer kept, followed by the new ones.
+// The stream ends when the client falls behind the bus, so it reconnects and resumes
+func (h *EventsDefault) Stream() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// requests
+		after, err := lastEventID(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid last event id")
+			return
+		}
+
+		// process
+		// - subscribe: from the last event of the client, or from now on when it expired
+		ctx := r.Context()
+		events, err := h.ev.Subscribe(ctx, after)
+		expired := errors.Is(err, broadcast.ErrExpired)
+		if expired {
+			events, err = h.ev.Subscribe(ctx, 0)
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// serialize response
+		s, err := response.NewStream(w, eventsRetry)
+		if err != nil {
+			return
+		}
+		if expired {
+			if err = s.Send("", "expired", ExpiredJSON{LastEventID: after}); err != nil {
+				return
+			}
+		}
+		tick := time.NewTicker(h.keepAlive)
+		defer tick.Stop()
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				if err = s.Send(strconv.FormatInt(e.ID, 10), string(e.Type), eventJSON(e)); err != nil {
+					return
+				}
+			case <-tick.C:
+				if err = s.Comment("keep-alive"); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
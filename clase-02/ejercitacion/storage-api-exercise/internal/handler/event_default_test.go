@@ -0,0 +1,131 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/platform/broadcast"
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// streamEvent is an event read from an event stream
+type streamEvent struct {
+	id, event, data string
+}
+
+// openEvents opens the event stream of a server with a Last-Event-ID, empty when the client has none
+func openEvents(t *testing.T, srv *httptest.Server, lastEventID string) (res *http.Response, rd *bufio.Reader) {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", srv.URL+"/events", nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err = srv.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { res.Body.Close() })
+	rd = bufio.NewReader(res.Body)
+	return
+}
+
+// readEvent reads the next event of an event stream, skipping the comments and the retry delay
+func readEvent(t *testing.T, rd *bufio.Reader) (e streamEvent) {
+	t.Helper()
+
+	for {
+		line, err := rd.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && e.data != "":
+			return
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestEventsDefault_Stream(t *testing.T) {
+	// created returns the event of a created warehouse
+	created := func(id int) internal.Event {
+		return internal.Event{
+			Type: internal.EventWarehouseCreated,
+			Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Data: internal.Warehouse{Id: id, Name: "warehouse", Address: "address", Telephone: "telephone", Capacity: 10},
+		}
+	}
+
+	t.Run("success 01 - events after the last one of the client, then the new ones", func(t *testing.T) {
+		// arrange
+		bus := broadcast.NewLog[internal.Event](broadcast.DefaultBuffer, broadcast.DefaultHistory)
+		for id := 1; id <= 3; id++ {
+			bus.Publish(created(id))
+		}
+		srv := httptest.NewServer(handler.NewEventsDefault(bus).Stream())
+		t.Cleanup(srv.Close)
+
+		// act
+		res, rd := openEvents(t, srv, "1")
+		missed := []streamEvent{readEvent(t, rd), readEvent(t, rd)}
+		bus.Publish(internal.Event{Type: internal.EventProductStockChanged, Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Data: internal.StockChange{ProductID: 1, WarehouseId: 3, Previous: 5, Quantity: 2}})
+		live := readEvent(t, rd)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+		require.Equal(t, "2", missed[0].id)
+		require.Equal(t, "warehouse.created", missed[0].event)
+		require.JSONEq(t, `{"id":2,"type":"warehouse.created","time":"2024-01-01T00:00:00Z","data":{"id":2,"name":"warehouse","address":"address","telephone":"telephone","capacity":10}}`, missed[0].data)
+		require.Equal(t, "3", missed[1].id)
+		require.Equal(t, "4", live.id)
+		require.Equal(t, "product.stock_changed", live.event)
+		require.JSONEq(t, `{"id":4,"type":"product.stock_changed","time":"2024-01-01T00:00:00Z","data":{"product_id":1,"warehouse_id":3,"previous":5,"quantity":2}}`, live.data)
+	})
+
+	t.Run("success 02 - last event no longer kept", func(t *testing.T) {
+		// arrange
+		bus := broadcast.NewLog[internal.Event](broadcast.DefaultBuffer, 1)
+		for id := 1; id <= 3; id++ {
+			bus.Publish(created(id))
+		}
+		srv := httptest.NewServer(handler.NewEventsDefault(bus).Stream())
+		t.Cleanup(srv.Close)
+
+		// act
+		_, rd := openEvents(t, srv, "1")
+		expired := readEvent(t, rd)
+		bus.Publish(created(4))
+		live := readEvent(t, rd)
+
+		// assert
+		require.Equal(t, streamEvent{event: "expired", data: `{"last_event_id":1}`}, expired)
+		require.Equal(t, "4", live.id)
+	})
+
+	t.Run("failure 01 - invalid last event id", func(t *testing.T) {
+		// arrange
+		bus := broadcast.NewLog[internal.Event](broadcast.DefaultBuffer, broadcast.DefaultHistory)
+		hd := handler.NewEventsDefault(bus)
+
+		// act
+		req := httptest.NewRequest("GET", "/events", nil)
+		req.Header.Set("Last-Event-ID", "abc")
+		res := httptest.NewRecorder()
+		hd.Stream()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.JSONEq(t, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid last event id","code":"bad_request"}`, res.Body.String())
+	})
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"app/internal"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// NewEventStreamSink returns a new instance of EventStreamSink
+func NewEventStreamSink(ev internal.Events) *EventStreamSink {
+	return &EventStreamSink{
+		ev: ev,
+	}
+}
+
+// EventStreamSink is a struct that represents the sink of the events relayed from the outbox that publishes them to the bus of the event stream,
+// so the id of an event of the stream is the one of its outbox message, and one relayed again is not streamed again
+type EventStreamSink struct {
+	// ev is the event bus
+	ev internal.Events
+}
+
+// Send publishes an event to the bus
+func (s *EventStreamSink) Send(ctx context.Context, e internal.Event) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	s.ev.Publish(e)
+	return
+}
+
+// NewProductWatchSink returns a new instance of ProductWatchSink
+func NewProductWatchSink(ev internal.ProductEvents) *ProductWatchSink {
+	return &ProductWatchSink{
+		ev: ev,
+	}
+}
+
+// ProductWatchSink is a struct that represents the sink of the events relayed from the outbox that publishes the changes of products
+// to the watchers of the gRPC server. The other events are skipped
+type ProductWatchSink struct {
+	// ev is the feed of the changes of products
+	ev internal.ProductEvents
+}
+
+// outboxProductEvents are the kinds of change of the product events of the outbox
+var outboxProductEvents = map[internal.EventType]internal.ProductEventType{
+	internal.EventProductCreated: internal.ProductCreated,
+	internal.EventProductUpdated: internal.ProductUpdated,
+	internal.EventProductDeleted: internal.ProductDeleted,
+}
+
+// Send publishes the change of a product to the feed
+func (s *ProductWatchSink) Send(ctx context.Context, e internal.Event) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	t, ok := outboxProductEvents[e.Type]
+	if !ok {
+		return
+	}
+	p, ok := e.Data.(internal.Product)
+	if !ok {
+		err = fmt.Errorf("handler: event %s without a product", e.Type)
+		return
+	}
+	s.ev.Publish(internal.ProductEvent{Type: t, Product: p})
+	return
+}
+
+// NewEventLogSink returns a new instance of EventLogSink, w is usually a file opened to append
+func NewEventLogSink(w io.Writer) *EventLogSink {
+	return &EventLogSink{
+		w: w,
+	}
+}
+
+// EventLogSink is a struct that represents the sink of the events relayed from the outbox that writes them as JSON lines,
+// as the event stream writes their data. An event sent again is written again, with the same id
+type EventLogSink struct {
+	// w is where the lines are written
+	w io.Writer
+	// mu makes the lines be written one after the other
+	mu sync.Mutex
+}
+
+// Send writes the line of an event
+func (s *EventLogSink) Send(ctx context.Context, e internal.Event) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	b, err := json.Marshal(eventJSON(e))
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(b, '\n'))
+	return
+}
@@ -0,0 +1,105 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/platform/broadcast"
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventLogSink_Send(t *testing.T) {
+	t.Run("success 01 - events written as json lines", func(t *testing.T) {
+		// arrange
+		var buf bytes.Buffer
+		sk := handler.NewEventLogSink(&buf)
+		at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		// act
+		err1 := sk.Send(context.Background(), internal.Event{ID: 7, Type: internal.EventProductDeleted, Time: at, Data: internal.Product{ID: 3}})
+		err2 := sk.Send(context.Background(), internal.Event{ID: 8, Type: internal.EventProductStockChanged, Time: at, Data: internal.StockChange{ProductID: 3, WarehouseId: 1, Previous: 5}})
+
+		// assert
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		expected := `{"id":7,"type":"product.deleted","time":"2024-01-01T00:00:00Z","data":{"id":3}}` + "\n" +
+			`{"id":8,"type":"product.stock_changed","time":"2024-01-01T00:00:00Z","data":{"product_id":3,"warehouse_id":1,"previous":5,"quantity":0}}` + "\n"
+		require.Equal(t, expected, buf.String())
+	})
+
+	t.Run("failure 01 - context done", func(t *testing.T) {
+		// arrange
+		var buf bytes.Buffer
+		sk := handler.NewEventLogSink(&buf)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// act
+		err := sk.Send(ctx, internal.Event{ID: 1, Type: internal.EventProductDeleted, Data: internal.Product{ID: 3}})
+
+		// assert
+		require.ErrorIs(t, err, context.Canceled)
+		require.Empty(t, buf.String())
+	})
+}
+
+func TestEventStreamSink_Send(t *testing.T) {
+	t.Run("success 01 - event published to the bus with the id of its outbox message", func(t *testing.T) {
+		// arrange
+		bus := broadcast.NewLog[internal.Event](broadcast.DefaultBuffer, broadcast.DefaultHistory)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events, err := bus.Subscribe(ctx, 0)
+		require.NoError(t, err)
+		sk := handler.NewEventStreamSink(bus)
+
+		// act
+		err = sk.Send(context.Background(), internal.Event{ID: 7, Type: internal.EventWarehouseUpdated, Data: internal.Warehouse{Id: 1}})
+
+		// assert
+		require.NoError(t, err)
+		e := <-events
+		require.Equal(t, int64(7), e.ID)
+		require.Equal(t, internal.EventWarehouseUpdated, e.Type)
+	})
+}
+
+func TestProductWatchSink_Send(t *testing.T) {
+	t.Run("success 01 - change of a product published to the feed", func(t *testing.T) {
+		// arrange
+		ev := broadcast.New[internal.ProductEvent](broadcast.DefaultBuffer)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events := ev.Subscribe(ctx)
+		sk := handler.NewProductWatchSink(ev)
+
+		// act
+		err := sk.Send(context.Background(), internal.Event{ID: 7, Type: internal.EventProductUpdated, Data: internal.Product{ID: 1, WarehouseId: 2}})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, internal.ProductEvent{Type: internal.ProductUpdated, Product: internal.Product{ID: 1, WarehouseId: 2}}, <-events)
+	})
+
+	t.Run("success 02 - other events skipped", func(t *testing.T) {
+		// arrange
+		ev := broadcast.New[internal.ProductEvent](broadcast.DefaultBuffer)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events := ev.Subscribe(ctx)
+		sk := handler.NewProductWatchSink(ev)
+
+		// act
+		errStock := sk.Send(context.Background(), internal.Event{Type: internal.EventProductStockChanged, Data: internal.StockChange{ProductID: 1}})
+		errWarehouse := sk.Send(context.Background(), internal.Event{Type: internal.EventWarehouseUpdated, Data: internal.Warehouse{Id: 1}})
+
+		// assert
+		require.NoError(t, errStock)
+		require.NoError(t, errWarehouse)
+		require.Empty(t, events)
+	})
+}
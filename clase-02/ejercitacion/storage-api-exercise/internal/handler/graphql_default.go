@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	_ "embed"
+	"errors"
+	"net/http"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// schemaGraphQL is the GraphQL schema of the api
+//
+//go:embed graphql_default.graphql
+var schemaGraphQL string
+
+// GraphQLDefault is the handler of the GraphQL queries over products and warehouses
+type GraphQLDefault struct {
+	// uow is the unit of work of the repositories, queries read through it
+	uow internal.UnitOfWork
+	// schema is the parsed schema with its resolvers
+	schema *graphql.Schema
+}
+
+// NewGraphQLDefault returns a new GraphQL handler, its queries read through the repositories of uow
+// and its mutations go through the services
+func NewGraphQLDefault(uow internal.UnitOfWork, sp internal.ProductService, sw internal.WarehouseService) *GraphQLDefault {
+	rv := &graphQLResolver{uow: uow, sp: sp, sw: sw}
+	return &GraphQLDefault{
+		uow:    uow,
+		schema: graphql.MustParseSchema(schemaGraphQL, rv, graphql.UseStringDescriptions(), graphql.MaxDepth(10)),
+	}
+}
+
+// RequestBodyGraphQL is the body of a GraphQL request
+type RequestBodyGraphQL struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Query runs a GraphQL query or mutation.
+// It responds 200 with the data and errors of the query, the errors of the resolvers carry the code and
+// status of their problem, and the fields that break a rule, as extensions
+func (h *GraphQLDefault) Query() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		var body RequestBodyGraphQL
+		if err := request.JSON(r, &body); err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		// process
+		// - the loader batches the reads of the query
+		ctx := withGraphQLLoader(r.Context(), h.uow)
+		res := h.schema.Exec(ctx, body.Query, body.OperationName, body.Variables)
+		// - errors of the resolvers as their problem
+		for _, e := range res.Errors {
+			if e.ResolverError == nil {
+				continue
+			}
+			p := graphQLProblem(w, e.ResolverError)
+			e.Message = p.Title
+			if p.Detail != "" {
+				e.Message = p.Detail
+			}
+			e.Extensions = map[string]any{"code": p.Code, "status": p.Status}
+			if p.Errors != nil {
+				e.Extensions["errors"] = p.Errors
+			}
+		}
+
+		// response
+		response.JSON(w, http.StatusOK, res)
+	}
+}
+
+// graphQLProblem returns the problem of the error of a resolver, in the language of the response
+func graphQLProblem(w http.ResponseWriter, err error) (p response.Problem) {
+	p = problemOf(w, err)
+	p.Title = response.Localize(w, p.Title)
+	if errors.Is(err, errInvalidPage) {
+		p.Detail = response.Localize(w, "first must be between 0 and %d", GraphQLMaxPage)
+	}
+	return
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"app/internal"
+	"context"
+	"sort"
+	"sync"
+)
+
+// graphQLLoaderKey is the key of the loader of a GraphQL request in its context
+type graphQLLoaderKey struct{}
+
+// withGraphQLLoader returns a copy of ctx with a new loader of the repositories of uow
+func withGraphQLLoader(ctx context.Context, uow internal.UnitOfWork) context.Context {
+	return context.WithValue(ctx, graphQLLoaderKey{}, &graphQLLoader{uow: uow})
+}
+
+// graphQLLoaderFrom returns the loader of a GraphQL request
+func graphQLLoaderFrom(ctx context.Context) *graphQLLoader {
+	return ctx.Value(graphQLLoaderKey{}).(*graphQLLoader)
+}
+
+// graphQLLoader batches the repository reads of a GraphQL request by key, to avoid a query per resolved entity (N+1).
+// The resolvers of a list tell the loader the keys its nodes will ask for (expect), and the first read of one of them
+// reads all of them with a single query: the products of the warehouses of a page, the warehouses of the products of a page.
+// The entities read are served from memory until a mutation resets it
+type graphQLLoader struct {
+	// uow is the unit of work of the repositories
+	uow internal.UnitOfWork
+	// mu guards the loaded entities and the expected keys, the resolvers of a request run concurrently
+	mu sync.Mutex
+	// productsOfWarehouse are the loaded products ordered by id by warehouse id, with the warehouses read without products
+	productsOfWarehouse map[int][]internal.Product
+	// productsExpected are the ids of the warehouses whose products are read with the next ones asked
+	productsExpected map[int]bool
+	// warehouses are the loaded warehouses by id, nil for the ones read that do not exist
+	warehouses map[int]*internal.Warehouse
+	// warehousesExpected are the ids of the warehouses read with the next one asked
+	warehousesExpected map[int]bool
+}
+
+// keys returns the keys expected with key, sorted, without the ones loaded, and clears them
+func keys[T any](expected map[int]bool, loaded map[int]T, key int) (ks []int) {
+	expected[key] = true
+	for k := range expected {
+		if _, ok := loaded[k]; !ok {
+			ks = append(ks, k)
+		}
+	}
+	clear(expected)
+	sort.Ints(ks)
+	return
+}
+
+// init creates the maps of the loader the first time it is used, or after a reset
+func (l *graphQLLoader) init() {
+	if l.productsOfWarehouse == nil {
+		l.productsOfWarehouse = make(map[int][]internal.Product)
+		l.productsExpected = make(map[int]bool)
+		l.warehouses = make(map[int]*internal.Warehouse)
+		l.warehousesExpected = make(map[int]bool)
+	}
+}
+
+// ExpectProductsOf tells the loader the products of the warehouses of a page will be asked for
+func (l *graphQLLoader) ExpectProductsOf(ws []internal.Warehouse) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.init()
+	for _, w := range ws {
+		l.productsExpected[w.Id] = true
+	}
+}
+
+// ExpectWarehousesOf tells the loader the warehouses of the products of a page will be asked for
+func (l *graphQLLoader) ExpectWarehousesOf(ps []internal.Product) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.init()
+	for _, p := range ps {
+		l.warehousesExpected[p.WarehouseId] = true
+	}
+}
+
+// Products returns all the products ordered by id
+func (l *graphQLLoader) Products(ctx context.Context) (ps []internal.Product, err error) {
+	err = l.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		ps, err = r.Products.GetAll(ctx)
+		return
+	})
+	sort.Slice(ps, func(i, j int) bool { return ps[i].ID < ps[j].ID })
+	return
+}
+
+// ProductsOfWarehouse returns the products of a warehouse ordered by id,
+// read with the ones of the warehouses expected in a single query
+func (l *graphQLLoader) ProductsOfWarehouse(ctx context.Context, id int) (ps []internal.Product, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.init()
+	if ps, ok := l.productsOfWarehouse[id]; ok {
+		return ps, nil
+	}
+
+	ids := keys(l.productsExpected, l.productsOfWarehouse, id)
+	var read []internal.Product
+	err = l.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		read, err = r.Products.GetByWarehouses(ctx, ids)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	for _, k := range ids {
+		l.productsOfWarehouse[k] = []internal.Product{}
+	}
+	for _, p := range read {
+		l.productsOfWarehouse[p.WarehouseId] = append(l.productsOfWarehouse[p.WarehouseId], p)
+	}
+	ps = l.productsOfWarehouse[id]
+	return
+}
+
+// Warehouses returns all the warehouses ordered by id
+func (l *graphQLLoader) Warehouses(ctx context.Context) (ws []internal.Warehouse, err error) {
+	err = l.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		ws, err = r.Warehouses.GetAll(ctx)
+		return
+	})
+	if err != nil {
+		return
+	}
+	sort.Slice(ws, func(i, j int) bool { return ws[i].Id < ws[j].Id })
+
+	// - the warehouses of the products of the request are among them
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.init()
+	for _, w := range ws {
+		w := w
+		l.warehouses[w.Id] = &w
+	}
+	return
+}
+
+// Warehouse returns a warehouse by id, ok is false when it does not exist.
+// It is read with the warehouses expected in a single query
+func (l *graphQLLoader) Warehouse(ctx context.Context, id int) (w internal.Warehouse, ok bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.init()
+	if wh, loaded := l.warehouses[id]; loaded {
+		if wh != nil {
+			w, ok = *wh, true
+		}
+		return
+	}
+
+	ids := keys(l.warehousesExpected, l.warehouses, id)
+	var read []internal.Warehouse
+	err = l.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		read, err = r.Warehouses.GetByIds(ctx, ids)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	for _, k := range ids {
+		l.warehouses[k] = nil
+	}
+	for _, wh := range read {
+		wh := wh
+		l.warehouses[wh.Id] = &wh
+	}
+	if wh := l.warehouses[id]; wh != nil {
+		w, ok = *wh, true
+	}
+	return
+}
+
+// Reset drops the loaded entities and the expected keys, so the reads after a mutation see it
+func (l *graphQLLoader) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.productsOfWarehouse, l.productsExpected = nil, nil
+	l.warehouses, l.warehousesExpected = nil, nil
+}
@@ -0,0 +1,640 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/validate"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// GraphQLMaxPage is the maximum number of nodes of a page of a GraphQL connection
+	GraphQLMaxPage = 100
+	// graphQLCursorPrefix is the prefix of the id encoded in a cursor
+	graphQLCursorPrefix = "cursor:"
+)
+
+var (
+	// errInvalidCursor is used when the after argument of a connection is not a cursor it returned
+	errInvalidCursor = errors.New("handler: invalid cursor")
+	// errInvalidPage is used when the first argument of a connection is out of range
+	errInvalidPage = errors.New("handler: invalid page size")
+)
+
+// graphQLDate is a date of the GraphQL schema (Date), as YYYY-MM-DD.
+// Its format is checked by the validate rules of the inputs, so it is reported with the field that breaks it
+type graphQLDate string
+
+// ImplementsGraphQLType returns whether the date implements a type of the schema
+func (graphQLDate) ImplementsGraphQLType(name string) bool {
+	return name == "Date"
+}
+
+// UnmarshalGraphQL sets the date of an input
+func (d *graphQLDate) UnmarshalGraphQL(input any) error {
+	s, ok := input.(string)
+	if !ok {
+		return fmt.Errorf("date must be a string, got %T", input)
+	}
+	*d = graphQLDate(s)
+	return nil
+}
+
+// graphQLResolver is the root resolver of the GraphQL schema.
+// Queries read through the repositories, batched by the loader of the request, and mutations go through the services
+type graphQLResolver struct {
+	// uow is the unit of work of the repositories
+	uow internal.UnitOfWork
+	// sp is the product service
+	sp internal.ProductService
+	// sw is the warehouse service
+	sw internal.WarehouseService
+}
+
+// - connections
+
+// pageInfo is the resolver of the PageInfo type
+type pageInfo struct {
+	// endCursor is the cursor of the last node of the page, nil when it is empty
+	endCursor *string
+	// hasNextPage tells if there are nodes after the page
+	hasNextPage bool
+}
+
+// EndCursor resolves PageInfo.endCursor
+func (p pageInfo) EndCursor() *string { return p.endCursor }
+
+// HasNextPage resolves PageInfo.hasNextPage
+func (p pageInfo) HasNextPage() bool { return p.hasNextPage }
+
+// graphQLCursor returns the cursor of the node with an id
+func graphQLCursor(id int) string {
+	return base64.StdEncoding.EncodeToString([]byte(graphQLCursorPrefix + strconv.Itoa(id)))
+}
+
+// graphQLPage returns the page of nodes, ordered by id, of first nodes after the node of a cursor
+func graphQLPage[T any](nodes []T, id func(T) int, first int32, after *string) (page []T, info pageInfo, err error) {
+	if first < 0 || first > GraphQLMaxPage {
+		err = errInvalidPage
+		return
+	}
+
+	// - nodes after the cursor
+	if after != nil {
+		b, e := base64.StdEncoding.DecodeString(*after)
+		s, ok := strings.CutPrefix(string(b), graphQLCursorPrefix)
+		afterId, e2 := strconv.Atoi(s)
+		if e != nil || !ok || e2 != nil {
+			err = errInvalidCursor
+			return
+		}
+		i := 0
+		for i < len(nodes) && id(nodes[i]) <= afterId {
+			i++
+		}
+		nodes = nodes[i:]
+	}
+
+	// - page
+	page = nodes
+	if len(page) > int(first) {
+		page = page[:first]
+		info.hasNextPage = true
+	}
+	if len(page) > 0 {
+		cursor := graphQLCursor(id(page[len(page)-1]))
+		info.endCursor = &cursor
+	}
+	return
+}
+
+// - products
+
+// productResolver is the resolver of the Product type
+type productResolver struct {
+	p internal.Product
+}
+
+// ID resolves Product.id
+func (r *productResolver) ID() int32 { return int32(r.p.ID) }
+
+// Name resolves Product.name
+func (r *productResolver) Name() string { return r.p.Name }
+
+// Quantity resolves Product.quantity
+func (r *productResolver) Quantity() int32 { return int32(r.p.Quantity) }
+
+// CodeValue resolves Product.codeValue
+func (r *productResolver) CodeValue() string { return r.p.CodeValue }
+
+// IsPublished resolves Product.isPublished
+func (r *productResolver) IsPublished() bool { return r.p.IsPublished }
+
+// Expiration resolves Product.expiration
+func (r *productResolver) Expiration() graphQLDate {
+	return graphQLDate(r.p.Expiration.Format(time.DateOnly))
+}
+
+// Price resolves Product.price
+func (r *productResolver) Price() float64 { return r.p.Price }
+
+// WarehouseID resolves Product.warehouseId
+func (r *productResolver) WarehouseID() int32 { return int32(r.p.WarehouseId) }
+
+// Version resolves Product.version
+func (r *productResolver) Version() int32 { return int32(r.p.Version) }
+
+// Warehouse resolves Product.warehouse, read with the warehouses of the other products of the page
+func (r *productResolver) Warehouse(ctx context.Context) (w *warehouseResolver, err error) {
+	wh, ok, err := graphQLLoaderFrom(ctx).Warehouse(ctx, r.p.WarehouseId)
+	if err != nil || !ok {
+		return
+	}
+	w = &warehouseResolver{w: wh}
+	return
+}
+
+// productConnection is the resolver of the ProductConnection type
+type productConnection struct {
+	nodes      []internal.Product
+	totalCount int
+	info       pageInfo
+}
+
+// Nodes resolves ProductConnection.nodes
+func (c *productConnection) Nodes() []*productResolver {
+	rs := make([]*productResolver, len(c.nodes))
+	for i, p := range c.nodes {
+		rs[i] = &productResolver{p: p}
+	}
+	return rs
+}
+
+// TotalCount resolves ProductConnection.totalCount
+func (c *productConnection) TotalCount() int32 { return int32(c.totalCount) }
+
+// PageInfo resolves ProductConnection.pageInfo
+func (c *productConnection) PageInfo() pageInfo { return c.info }
+
+// graphQLProductFilter is the ProductFilter input
+type graphQLProductFilter struct {
+	WarehouseId *int32       `json:"warehouseId"`
+	IsPublished *bool        `json:"isPublished"`
+	ExpiresFrom *graphQLDate `json:"expiresFrom" validate:"date"`
+	ExpiresTo   *graphQLDate `json:"expiresTo" validate:"date"`
+}
+
+// productConnectionArgs are the arguments of a connection of products
+type productConnectionArgs struct {
+	Filter *graphQLProductFilter
+	First  int32
+	After  *string
+}
+
+// products returns the connection of the products matching the filter of args
+func products(ps []internal.Product, args productConnectionArgs) (c *productConnection, err error) {
+	// filter
+	var matched []internal.Product
+	switch f := args.Filter; f {
+	case nil:
+		matched = ps
+	default:
+		if err = validate.Struct(f); err != nil {
+			return
+		}
+		var from, to time.Time
+		if f.ExpiresFrom != nil {
+			from, _ = time.Parse(time.DateOnly, string(*f.ExpiresFrom))
+		}
+		if f.ExpiresTo != nil {
+			to, _ = time.Parse(time.DateOnly, string(*f.ExpiresTo))
+		}
+		for _, p := range ps {
+			switch {
+			case f.WarehouseId != nil && p.WarehouseId != int(*f.WarehouseId):
+			case f.IsPublished != nil && p.IsPublished != *f.IsPublished:
+			case f.ExpiresFrom != nil && p.Expiration.Before(from):
+			case f.ExpiresTo != nil && p.Expiration.After(to):
+			default:
+				matched = append(matched, p)
+			}
+		}
+	}
+
+	// page
+	c = &productConnection{totalCount: len(matched)}
+	c.nodes, c.info, err = graphQLPage(matched, func(p internal.Product) int { return p.ID }, args.First, args.After)
+	return
+}
+
+// Product resolves Query.product
+func (r *graphQLResolver) Product(ctx context.Context, args struct{ ID int32 }) (p *productResolver, err error) {
+	var pr internal.Product
+	err = r.uow.Do(ctx, func(ctx context.Context, rp internal.Repositories) (err error) {
+		pr, err = rp.Products.GetOne(ctx, int(args.ID))
+		return
+	})
+	switch {
+	case errors.Is(err, internal.ErrProductNotFound):
+		err = nil
+	case err == nil:
+		p = &productResolver{p: pr}
+	}
+	return
+}
+
+// Products resolves Query.products, the warehouses of its page are read together when they are asked for
+func (r *graphQLResolver) Products(ctx context.Context, args productConnectionArgs) (c *productConnection, err error) {
+	l := graphQLLoaderFrom(ctx)
+	ps, err := l.Products(ctx)
+	if err != nil {
+		return
+	}
+	if c, err = products(ps, args); err != nil {
+		return
+	}
+	l.ExpectWarehousesOf(c.nodes)
+	return
+}
+
+// graphQLProductInput is the ProductInput input
+type graphQLProductInput struct {
+	Name        string      `json:"name" validate:"required"`
+	Quantity    int32       `json:"quantity" validate:"min=0"`
+	CodeValue   string      `json:"codeValue" validate:"required"`
+	IsPublished bool        `json:"isPublished"`
+	Expiration  graphQLDate `json:"expiration" validate:"required,date,notpast"`
+	Price       float64     `json:"price" validate:"min=0"`
+	WarehouseId int32       `json:"warehouseId" validate:"gt=0"`
+}
+
+// graphQLProductUpdate is a product to update: its fields with the ones of a ProductPatch input
+type graphQLProductUpdate struct {
+	Name        string      `json:"name" validate:"required"`
+	Quantity    int32       `json:"quantity" validate:"min=0"`
+	CodeValue   string      `json:"codeValue" validate:"required"`
+	IsPublished bool        `json:"isPublished"`
+	Expiration  graphQLDate `json:"expiration" validate:"required,date"`
+	Price       float64     `json:"price" validate:"min=0"`
+	WarehouseId int32       `json:"warehouseId" validate:"gt=0"`
+}
+
+// graphQLProductPatch is the ProductPatch input, its nil fields are kept
+type graphQLProductPatch struct {
+	Name        *string
+	Quantity    *int32
+	CodeValue   *string
+	IsPublished *bool
+	Expiration  *graphQLDate
+	Price       *float64
+	WarehouseId *int32
+}
+
+// CreateProduct resolves Mutation.createProduct
+func (r *graphQLResolver) CreateProduct(ctx context.Context, args struct{ Input graphQLProductInput }) (p *productResolver, err error) {
+	// validate
+	in := args.Input
+	if err = validate.Struct(in); err != nil {
+		return
+	}
+	exp, _ := time.Parse(time.DateOnly, string(in.Expiration))
+
+	// create
+	pr := internal.Product{
+		Name:        in.Name,
+		Quantity:    int(in.Quantity),
+		CodeValue:   in.CodeValue,
+		IsPublished: in.IsPublished,
+		Expiration:  exp,
+		Price:       in.Price,
+		WarehouseId: int(in.WarehouseId),
+	}
+	if err = r.sp.Create(ctx, &pr); err != nil {
+		return
+	}
+	graphQLLoaderFrom(ctx).Reset()
+
+	p = &productResolver{p: pr}
+	return
+}
+
+// UpdateProduct resolves Mutation.updateProduct
+func (r *graphQLResolver) UpdateProduct(ctx context.Context, args struct {
+	ID      int32
+	Input   graphQLProductPatch
+	Version *int32
+}) (p *productResolver, err error) {
+	// get product
+	pr, err := r.sp.GetOne(ctx, int(args.ID))
+	if err != nil {
+		return
+	}
+	// - check the client has the current version
+	if args.Version != nil && int(*args.Version) != pr.Version {
+		err = internal.ErrProductVersionConflict
+		return
+	}
+
+	// patch product
+	u := graphQLProductUpdate{
+		Name:        pr.Name,
+		Quantity:    int32(pr.Quantity),
+		CodeValue:   pr.CodeValue,
+		IsPublished: pr.IsPublished,
+		Expiration:  graphQLDate(pr.Expiration.Format(time.DateOnly)),
+		Price:       pr.Price,
+		WarehouseId: int32(pr.WarehouseId),
+	}
+	in := args.Input
+	if in.Name != nil {
+		u.Name = *in.Name
+	}
+	if in.Quantity != nil {
+		u.Quantity = *in.Quantity
+	}
+	if in.CodeValue != nil {
+		u.CodeValue = *in.CodeValue
+	}
+	if in.IsPublished != nil {
+		u.IsPublished = *in.IsPublished
+	}
+	if in.Expiration != nil {
+		u.Expiration = *in.Expiration
+	}
+	if in.Price != nil {
+		u.Price = *in.Price
+	}
+	if in.WarehouseId != nil {
+		u.WarehouseId = *in.WarehouseId
+	}
+	if err = validate.Struct(u); err != nil {
+		return
+	}
+	exp, _ := time.Parse(time.DateOnly, string(u.Expiration))
+	pr.Name = u.Name
+	pr.Quantity = int(u.Quantity)
+	pr.CodeValue = u.CodeValue
+	pr.IsPublished = u.IsPublished
+	pr.Expiration = exp
+	pr.Price = u.Price
+	pr.WarehouseId = int(u.WarehouseId)
+
+	// update product
+	if err = r.sp.Update(ctx, &pr); err != nil {
+		return
+	}
+	graphQLLoaderFrom(ctx).Reset()
+
+	p = &productResolver{p: pr}
+	return
+}
+
+// DeleteProduct resolves Mutation.deleteProduct
+func (r *graphQLResolver) DeleteProduct(ctx context.Context, args struct{ ID int32 }) (id int32, err error) {
+	if err = r.sp.Delete(ctx, int(args.ID), 0); err != nil {
+		return
+	}
+	graphQLLoaderFrom(ctx).Reset()
+
+	id = args.ID
+	return
+}
+
+// - warehouses
+
+// warehouseResolver is the resolver of the Warehouse type
+type warehouseResolver struct {
+	w internal.Warehouse
+}
+
+// ID resolves Warehouse.id
+func (r *warehouseResolver) ID() int32 { return int32(r.w.Id) }
+
+// Name resolves Warehouse.name
+func (r *warehouseResolver) Name() string { return r.w.Name }
+
+// Address resolves Warehouse.address
+func (r *warehouseResolver) Address() string { return r.w.Address }
+
+// Telephone resolves Warehouse.telephone
+func (r *warehouseResolver) Telephone() string { return r.w.Telephone }
+
+// Capacity resolves Warehouse.capacity
+func (r *warehouseResolver) Capacity() int32 { return int32(r.w.Capacity) }
+
+// Version resolves Warehouse.version
+func (r *warehouseResolver) Version() int32 { return int32(r.w.Version) }
+
+// Products resolves Warehouse.products, read with the products of the other warehouses of the page.
+// The warehouses of its page are read together when they are asked for
+func (r *warehouseResolver) Products(ctx context.Context, args productConnectionArgs) (c *productConnection, err error) {
+	l := graphQLLoaderFrom(ctx)
+	ps, err := l.ProductsOfWarehouse(ctx, r.w.Id)
+	if err != nil {
+		return
+	}
+	if c, err = products(ps, args); err != nil {
+		return
+	}
+	l.ExpectWarehousesOf(c.nodes)
+	return
+}
+
+// warehouseConnection is the resolver of the WarehouseConnection type
+type warehouseConnection struct {
+	nodes      []internal.Warehouse
+	totalCount int
+	info       pageInfo
+}
+
+// Nodes resolves WarehouseConnection.nodes
+func (c *warehouseConnection) Nodes() []*warehouseResolver {
+	rs := make([]*warehouseResolver, len(c.nodes))
+	for i, w := range c.nodes {
+		rs[i] = &warehouseResolver{w: w}
+	}
+	return rs
+}
+
+// TotalCount resolves WarehouseConnection.totalCount
+func (c *warehouseConnection) TotalCount() int32 { return int32(c.totalCount) }
+
+// PageInfo resolves WarehouseConnection.pageInfo
+func (c *warehouseConnection) PageInfo() pageInfo { return c.info }
+
+// Warehouse resolves Query.warehouse
+func (r *graphQLResolver) Warehouse(ctx context.Context, args struct{ ID int32 }) (w *warehouseResolver, err error) {
+	var wh internal.Warehouse
+	err = r.uow.Do(ctx, func(ctx context.Context, rp internal.Repositories) (err error) {
+		wh, err = rp.Warehouses.GetOne(ctx, int(args.ID))
+		return
+	})
+	switch {
+	case errors.Is(err, internal.ErrWarehouseNotFound):
+		err = nil
+	case err == nil:
+		w = &warehouseResolver{w: wh}
+	}
+	return
+}
+
+// Warehouses resolves Query.warehouses, the products of the warehouses of its page are read together when they are asked for
+func (r *graphQLResolver) Warehouses(ctx context.Context, args struct {
+	First int32
+	After *string
+}) (c *warehouseConnection, err error) {
+	l := graphQLLoaderFrom(ctx)
+	ws, err := l.Warehouses(ctx)
+	if err != nil {
+		return
+	}
+
+	c = &warehouseConnection{totalCount: len(ws)}
+	if c.nodes, c.info, err = graphQLPage(ws, func(w internal.Warehouse) int { return w.Id }, args.First, args.After); err != nil {
+		return
+	}
+	l.ExpectProductsOf(c.nodes)
+	return
+}
+
+// reportProductResolver is the resolver of the ReportProduct type
+type reportProductResolver struct {
+	rp internal.ReportProduct
+}
+
+// Name resolves ReportProduct.name
+func (r *reportProductResolver) Name() string { return r.rp.Name }
+
+// ProductCount resolves ReportProduct.productCount
+func (r *reportProductResolver) ProductCount() int32 { return int32(r.rp.ProductCount) }
+
+// ReportProducts resolves Query.reportProducts
+func (r *graphQLResolver) ReportProducts(ctx context.Context, args struct{ WarehouseId *int32 }) (rs []*reportProductResolver, err error) {
+	var id int
+	if args.WarehouseId != nil {
+		id = int(*args.WarehouseId)
+	}
+	var rp []internal.ReportProduct
+	err = r.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		rp, err = r.Warehouses.ReportProducts(ctx, id)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	rs = make([]*reportProductResolver, len(rp))
+	for i, v := range rp {
+		rs[i] = &reportProductResolver{rp: v}
+	}
+	return
+}
+
+// graphQLWarehouseInput is the WarehouseInput input, and a warehouse to update with the fields of a WarehousePatch input
+type graphQLWarehouseInput struct {
+	Name      string `json:"name" validate:"required"`
+	Address   string `json:"address" validate:"required"`
+	Telephone string `json:"telephone" validate:"required"`
+	Capacity  int32  `json:"capacity" validate:"gt=0"`
+}
+
+// graphQLWarehousePatch is the WarehousePatch input, its nil fields are kept
+type graphQLWarehousePatch struct {
+	Name      *string
+	Address   *string
+	Telephone *string
+	Capacity  *int32
+}
+
+// CreateWarehouse resolves Mutation.createWarehouse
+func (r *graphQLResolver) CreateWarehouse(ctx context.Context, args struct {
+	Input      graphQLWarehouseInput
+	ProductIds *[]int32
+}) (w *warehouseResolver, err error) {
+	// validate
+	in := args.Input
+	if err = validate.Struct(in); err != nil {
+		return
+	}
+
+	// create
+	wh := internal.Warehouse{
+		Name:      in.Name,
+		Address:   in.Address,
+		Telephone: in.Telephone,
+		Capacity:  int(in.Capacity),
+	}
+	var ids []int
+	if args.ProductIds != nil {
+		for _, id := range *args.ProductIds {
+			ids = append(ids, int(id))
+		}
+	}
+	if err = r.sw.Create(ctx, &wh, ids...); err != nil {
+		return
+	}
+	graphQLLoaderFrom(ctx).Reset()
+
+	w = &warehouseResolver{w: wh}
+	return
+}
+
+// UpdateWarehouse resolves Mutation.updateWarehouse
+func (r *graphQLResolver) UpdateWarehouse(ctx context.Context, args struct {
+	ID      int32
+	Input   graphQLWarehousePatch
+	Version *int32
+}) (w *warehouseResolver, err error) {
+	// get warehouse
+	wh, err := r.sw.GetOne(ctx, int(args.ID))
+	if err != nil {
+		return
+	}
+	// - check the client has the current version
+	if args.Version != nil && int(*args.Version) != wh.Version {
+		err = internal.ErrWarehouseVersionConflict
+		return
+	}
+
+	// patch warehouse
+	u := graphQLWarehouseInput{
+		Name:      wh.Name,
+		Address:   wh.Address,
+		Telephone: wh.Telephone,
+		Capacity:  int32(wh.Capacity),
+	}
+	in := args.Input
+	if in.Name != nil {
+		u.Name = *in.Name
+	}
+	if in.Address != nil {
+		u.Address = *in.Address
+	}
+	if in.Telephone != nil {
+		u.Telephone = *in.Telephone
+	}
+	if in.Capacity != nil {
+		u.Capacity = *in.Capacity
+	}
+	if err = validate.Struct(u); err != nil {
+		return
+	}
+	wh.Name = u.Name
+	wh.Address = u.Address
+	wh.Telephone = u.Telephone
+	wh.Capacity = int(u.Capacity)
+
+	// update warehouse
+	if err = r.sw.Update(ctx, &wh); err != nil {
+		return
+	}
+	graphQLLoaderFrom(ctx).Reset()
+
+	w = &warehouseResolver{w: wh}
+	return
+}
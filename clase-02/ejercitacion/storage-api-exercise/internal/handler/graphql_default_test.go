@@ -0,0 +1,291 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/service"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingUnitOfWork is a unit of work that counts the GetAll and keyed reads of its repositories
+type countingUnitOfWork struct {
+	internal.UnitOfWork
+	// products is the number of GetAll calls of the product repository
+	products atomic.Int32
+	// productsByWarehouses is the number of GetByWarehouses calls of the product repository
+	productsByWarehouses atomic.Int32
+	// warehouses is the number of GetAll calls of the warehouse repository
+	warehouses atomic.Int32
+	// warehousesByIds is the number of GetByIds calls of the warehouse repository
+	warehousesByIds atomic.Int32
+}
+
+func (u *countingUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, r internal.Repositories) error) (err error) {
+	return u.UnitOfWork.Do(ctx, func(ctx context.Context, r internal.Repositories) error {
+		r.Products = countingProducts{RepositoryProducts: r.Products, u: u}
+		r.Warehouses = countingWarehouses{WarehouseRepository: r.Warehouses, u: u}
+		return fn(ctx, r)
+	})
+}
+
+type countingProducts struct {
+	internal.RepositoryProducts
+	u *countingUnitOfWork
+}
+
+func (r countingProducts) GetAll(ctx context.Context) (ps []internal.Product, err error) {
+	r.u.products.Add(1)
+	return r.RepositoryProducts.GetAll(ctx)
+}
+
+func (r countingProducts) GetByWarehouses(ctx context.Context, ids []int) (ps []internal.Product, err error) {
+	r.u.productsByWarehouses.Add(1)
+	return r.RepositoryProducts.GetByWarehouses(ctx, ids)
+}
+
+type countingWarehouses struct {
+	internal.WarehouseRepository
+	u *countingUnitOfWork
+}
+
+func (r countingWarehouses) GetAll(ctx context.Context) (ws []internal.Warehouse, err error) {
+	r.u.warehouses.Add(1)
+	return r.WarehouseRepository.GetAll(ctx)
+}
+
+func (r countingWarehouses) GetByIds(ctx context.Context, ids []int) (ws []internal.Warehouse, err error) {
+	r.u.warehousesByIds.Add(1)
+	return r.WarehouseRepository.GetByIds(ctx, ids)
+}
+
+// seedGraphQL inserts two warehouses with three products
+func seedGraphQL(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	for _, q := range []string{
+		"INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)",
+		"INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (2, 'warehouse 2', 'address 2', 'telephone 2', 100)",
+		"INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (1, 'product 1', 10, 'code_value 1', true, '2030-01-10', 10, 1)",
+		"INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (2, 'product 2', 20, 'code_value 2', true, '2030-02-10', 20, 1)",
+		"INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (3, 'product 3', 30, 'code_value 3', false, '2030-01-20', 30, 2)",
+	} {
+		_, err := db.Exec(q)
+		require.NoError(t, err)
+	}
+}
+
+// productsPage is the body of a query of a page of products
+type productsPage struct {
+	Data struct {
+		Products struct {
+			TotalCount int              `json:"totalCount"`
+			Nodes      []map[string]int `json:"nodes"`
+			PageInfo   struct {
+				EndCursor   string `json:"endCursor"`
+				HasNextPage bool   `json:"hasNextPage"`
+			} `json:"pageInfo"`
+		} `json:"products"`
+	} `json:"data"`
+}
+
+// queryGraphQL runs a GraphQL query with its variables
+func queryGraphQL(hd *handler.GraphQLDefault, query string, variables map[string]any) (res *httptest.ResponseRecorder) {
+	body, _ := json.Marshal(handler.RequestBodyGraphQL{Query: query, Variables: variables})
+	req := httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	res = httptest.NewRecorder()
+	hd.Query()(res, req)
+	return
+}
+
+func TestGraphQLDefault_Query(t *testing.T) {
+	t.Run("success 01 - warehouses with their products expiring in a month, read together", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		seedGraphQL(t, db)
+		cuow := &countingUnitOfWork{UnitOfWork: uow}
+		hd := handler.NewGraphQLDefault(cuow, service.NewProductsDefault(cuow), service.NewWarehouseDefault(cuow))
+
+		// act
+		res := queryGraphQL(hd, `{
+			warehouses {
+				totalCount
+				nodes {
+					name
+					products(filter: {expiresFrom: "2030-01-01", expiresTo: "2030-01-31"}) {
+						totalCount
+						nodes { name expiration warehouse { id } }
+					}
+				}
+			}
+		}`, nil)
+
+		// assert
+		expectedCode := http.StatusOK
+		expectedBody := `{"data": {"warehouses": {
+			"totalCount": 2,
+			"nodes": [
+				{"name": "warehouse 1", "products": {"totalCount": 1, "nodes": [{"name": "product 1", "expiration": "2030-01-10", "warehouse": {"id": 1}}]}},
+				{"name": "warehouse 2", "products": {"totalCount": 1, "nodes": [{"name": "product 3", "expiration": "2030-01-20", "warehouse": {"id": 2}}]}}
+			]
+		}}}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+		require.Equal(t, int32(0), cuow.products.Load())
+		require.Equal(t, int32(1), cuow.productsByWarehouses.Load())
+		require.Equal(t, int32(1), cuow.warehouses.Load())
+		require.Equal(t, int32(0), cuow.warehousesByIds.Load())
+	})
+
+	t.Run("success 02 - products paginated with the cursor of the previous page", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		seedGraphQL(t, db)
+		hd := handler.NewGraphQLDefault(uow, service.NewProductsDefault(uow), service.NewWarehouseDefault(uow))
+		query := `query ($after: String) {
+			products(first: 2, after: $after) { totalCount nodes { id } pageInfo { endCursor hasNextPage } }
+		}`
+
+		// act
+		res := queryGraphQL(hd, query, nil)
+		var first productsPage
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &first))
+		resNext := queryGraphQL(hd, query, map[string]any{"after": first.Data.Products.PageInfo.EndCursor})
+		var next productsPage
+		require.NoError(t, json.Unmarshal(resNext.Body.Bytes(), &next))
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, 3, first.Data.Products.TotalCount)
+		require.Equal(t, []map[string]int{{"id": 1}, {"id": 2}}, first.Data.Products.Nodes)
+		require.True(t, first.Data.Products.PageInfo.HasNextPage)
+		require.Equal(t, http.StatusOK, resNext.Code)
+		require.Equal(t, 3, next.Data.Products.TotalCount)
+		require.Equal(t, []map[string]int{{"id": 3}}, next.Data.Products.Nodes)
+		require.False(t, next.Data.Products.PageInfo.HasNextPage)
+	})
+
+	t.Run("success 03 - product not found is null", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewGraphQLDefault(uow, service.NewProductsDefault(uow), service.NewWarehouseDefault(uow))
+
+		// act
+		res := queryGraphQL(hd, `{ product(id: 1) { name } }`, nil)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, `{"data": {"product": null}}`, res.Body.String())
+	})
+
+	t.Run("success 04 - product created and read in the same request", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		seedGraphQL(t, db)
+		hd := handler.NewGraphQLDefault(uow, service.NewProductsDefault(uow), service.NewWarehouseDefault(uow))
+
+		// act
+		res := queryGraphQL(hd, `mutation {
+			createProduct(input: {name: "product 4", quantity: 1, codeValue: "code_value 4", isPublished: true, expiration: "2099-01-01", price: 1.5, warehouseId: 2}) {
+				id name price warehouse { name }
+			}
+		}`, nil)
+
+		// assert
+		expectedBody := `{"data": {"createProduct": {"id": 4, "name": "product 4", "price": 1.5, "warehouse": {"name": "warehouse 2"}}}}`
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("success 05 - warehouses of a page of products read together", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		seedGraphQL(t, db)
+		cuow := &countingUnitOfWork{UnitOfWork: uow}
+		hd := handler.NewGraphQLDefault(cuow, service.NewProductsDefault(cuow), service.NewWarehouseDefault(cuow))
+
+		// act
+		res := queryGraphQL(hd, `{ products { nodes { id warehouse { name } } } }`, nil)
+
+		// assert
+		expectedBody := `{"data": {"products": {"nodes": [
+			{"id": 1, "warehouse": {"name": "warehouse 1"}},
+			{"id": 2, "warehouse": {"name": "warehouse 1"}},
+			{"id": 3, "warehouse": {"name": "warehouse 2"}}
+		]}}}`
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+		require.Equal(t, int32(1), cuow.products.Load())
+		require.Equal(t, int32(0), cuow.warehouses.Load())
+		require.Equal(t, int32(1), cuow.warehousesByIds.Load())
+	})
+
+	t.Run("failure 01 - product input breaks a validation rule", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		seedGraphQL(t, db)
+		hd := handler.NewGraphQLDefault(uow, service.NewProductsDefault(uow), service.NewWarehouseDefault(uow))
+
+		// act
+		res := queryGraphQL(hd, `mutation {
+			createProduct(input: {name: "", quantity: 1, codeValue: "code_value 4", isPublished: true, expiration: "2099-01-01", price: 1.5, warehouseId: 2}) { id }
+		}`, nil)
+
+		// assert
+		expectedBody := `{
+			"data": null,
+			"errors": [{
+				"message": "Request body breaks a validation rule",
+				"path": ["createProduct"],
+				"extensions": {
+					"code": "invalid_body",
+					"status": 422,
+					"errors": [{"field": "name", "code": "required", "message": "name is required"}]
+				}
+			}]
+		}`
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("failure 02 - invalid cursor", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewGraphQLDefault(uow, service.NewProductsDefault(uow), service.NewWarehouseDefault(uow))
+
+		// act
+		res := queryGraphQL(hd, `{ products(after: "abc") { totalCount } }`, nil)
+
+		// assert
+		expectedBody := `{
+			"data": null,
+			"errors": [{"message": "Invalid cursor", "path": ["products"], "extensions": {"code": "invalid_cursor", "status": 400}}]
+		}`
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("failure 03 - invalid request body", func(t *testing.T) {
+		// arrange
+		hd := handler.NewGraphQLDefault(nil, nil, nil)
+
+		// act
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{`))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+		hd.Query()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, "application/problem+json", res.Header().Get("Content-Type"))
+	})
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"app/platform/i18n"
+	"app/platform/validate"
+	"app/platform/web/response"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCErrorDomain is the domain of the ErrorInfo detail of the errors of the gRPC api
+const GRPCErrorDomain = "storage-api"
+
+// grpcCodes are the codes of the gRPC status of the problems, by the status of their http response
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:              codes.InvalidArgument,
+	http.StatusNotFound:                codes.NotFound,
+	http.StatusConflict:                codes.FailedPrecondition,
+	http.StatusPreconditionFailed:      codes.Aborted,
+	http.StatusUnprocessableEntity:     codes.InvalidArgument,
+	response.StatusClientClosedRequest: codes.Canceled,
+	http.StatusGatewayTimeout:          codes.DeadlineExceeded,
+	http.StatusInternalServerError:     codes.Internal,
+}
+
+// grpcCodesByProblem are the codes of the gRPC status of the problems whose status does not tell them apart
+var grpcCodesByProblem = map[string]codes.Code{
+	"product_not_unique":       codes.AlreadyExists,
+	"warehouse_already_exists": codes.AlreadyExists,
+}
+
+// grpcWriter is a response writer that only holds the headers of a gRPC call,
+// so its problems are localized as the ones of the http handlers
+type grpcWriter http.Header
+
+// newGRPCWriter returns the writer of a gRPC call, in the language negotiated with its accept-language metadata
+func newGRPCWriter(ctx context.Context) grpcWriter {
+	md, _ := metadata.FromIncomingContext(ctx)
+	lang := i18n.Negotiate(strings.Join(md.Get("accept-language"), ","))
+	return grpcWriter{"Content-Language": {lang}}
+}
+
+func (w grpcWriter) Header() http.Header         { return http.Header(w) }
+func (w grpcWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w grpcWriter) WriteHeader(int)             {}
+
+// grpcError returns the gRPC status of an error: the code of its problem with its localized title, or detail when it has one.
+// Its details are an ErrorInfo with the code of the problem as reason and, when it is invalid, a BadRequest with its fields
+func grpcError(ctx context.Context, err error) error {
+	w := newGRPCWriter(ctx)
+	p := problemOf(w, err)
+
+	// - code
+	code, ok := grpcCodesByProblem[p.Code]
+	if !ok {
+		code, ok = grpcCodes[p.Status]
+	}
+	if !ok {
+		code = codes.Unknown
+	}
+	// - message
+	message := response.Localize(w, p.Title)
+	if p.Detail != "" {
+		message = p.Detail
+	}
+
+	// details
+	st := status.New(code, message)
+	if withInfo, err := st.WithDetails(&errdetails.ErrorInfo{Reason: p.Code, Domain: GRPCErrorDomain}); err == nil {
+		st = withInfo
+	}
+	if errs, ok := p.Errors.(validate.Errors); ok {
+		br := &errdetails.BadRequest{}
+		for _, e := range errs {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: e.Field, Description: e.Message})
+		}
+		if withFields, err := st.WithDetails(br); err == nil {
+			st = withFields
+		}
+	}
+	return st.Err()
+}
+
+// GRPCDeadline returns a unary interceptor that cancels the context of each call once d has passed since it started,
+// as request.Deadline does for the http handlers. Streams are not bound by it, a watch lasts as long as its client
+func GRPCDeadline(d time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if d <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
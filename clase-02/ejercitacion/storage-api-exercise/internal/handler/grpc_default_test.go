@@ -0,0 +1,249 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/handler/storagepb"
+	"app/internal/service"
+	"app/platform/broadcast"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClients returns the clients of a gRPC server with the services of uow, served in memory
+func newGRPCClients(t *testing.T, uow internal.UnitOfWork) (cp storagepb.ProductServiceClient, cw storagepb.WarehouseServiceClient) {
+	t.Helper()
+
+	// server: the changes reach the watchers through the outbox, as in the application
+	events := broadcast.New[internal.ProductEvent](broadcast.DefaultBuffer)
+	sp := service.NewProductsOutbox(service.NewProductsDefault(uow), uow)
+	sw := service.NewWarehousesOutbox(service.NewWarehouseDefault(uow), uow)
+	rl := service.NewOutboxRelay(uow, 10*time.Millisecond, handler.NewProductWatchSink(events))
+	ctx, cancel := context.WithCancel(context.Background())
+	relayed := make(chan struct{})
+	go func() {
+		defer close(relayed)
+		rl.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-relayed
+	})
+	gs := grpc.NewServer()
+	storagepb.RegisterProductServiceServer(gs, handler.NewProductsGRPC(sp, events))
+	storagepb.RegisterWarehouseServiceServer(gs, handler.NewWarehousesGRPC(sw))
+	lis := bufconn.Listen(1 << 20)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	// clients
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	cp, cw = storagepb.NewProductServiceClient(conn), storagepb.NewWarehouseServiceClient(conn)
+	return
+}
+
+func TestProductsGRPC(t *testing.T) {
+	t.Run("success 01 - product created, read and listed", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+		require.NoError(t, err)
+		cp, _ := newGRPCClients(t, uow)
+		ctx := context.Background()
+
+		// act
+		created, err := cp.CreateProduct(ctx, &storagepb.CreateProductRequest{Name: "product 1", Quantity: 1, CodeValue: "code_value 1", Expiration: "2099-01-01", Price: 1.5, WarehouseId: 1})
+		require.NoError(t, err)
+		got, err := cp.GetProduct(ctx, &storagepb.GetProductRequest{Id: created.Id})
+		require.NoError(t, err)
+		stream, err := cp.ListProducts(ctx, &storagepb.ListProductsRequest{})
+		require.NoError(t, err)
+		var listed []*storagepb.Product
+		for {
+			p, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			listed = append(listed, p)
+		}
+
+		// assert
+		expected := &storagepb.Product{Id: 1, Name: "product 1", Quantity: 1, CodeValue: "code_value 1", Expiration: "2099-01-01", Price: 1.5, WarehouseId: 1, Version: 1}
+		require.Equal(t, expected.String(), created.String())
+		require.Equal(t, expected.String(), got.String())
+		require.Len(t, listed, 1)
+		require.Equal(t, expected.String(), listed[0].String())
+	})
+
+	t.Run("success 02 - changes watched", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+		require.NoError(t, err)
+		cp, _ := newGRPCClients(t, uow)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		watch, err := cp.WatchProducts(ctx, &storagepb.WatchProductsRequest{})
+		require.NoError(t, err)
+		_, err = watch.Header()
+		require.NoError(t, err)
+
+		// act
+		created, err := cp.CreateProduct(ctx, &storagepb.CreateProductRequest{Name: "product 1", Quantity: 1, CodeValue: "code_value 1", Expiration: "2099-01-01", Price: 1.5, WarehouseId: 1})
+		require.NoError(t, err)
+		name := "product renamed"
+		_, err = cp.UpdateProduct(ctx, &storagepb.UpdateProductRequest{Id: created.Id, Name: &name})
+		require.NoError(t, err)
+		_, err = cp.DeleteProduct(ctx, &storagepb.DeleteProductRequest{Id: created.Id})
+		require.NoError(t, err)
+
+		// assert
+		e, err := watch.Recv()
+		require.NoError(t, err)
+		require.Equal(t, storagepb.ProductEvent_TYPE_CREATED, e.Type)
+		require.Equal(t, "product 1", e.Product.Name)
+		e, err = watch.Recv()
+		require.NoError(t, err)
+		require.Equal(t, storagepb.ProductEvent_TYPE_UPDATED, e.Type)
+		require.Equal(t, "product renamed", e.Product.Name)
+		e, err = watch.Recv()
+		require.NoError(t, err)
+		require.Equal(t, storagepb.ProductEvent_TYPE_DELETED, e.Type)
+		require.Equal(t, created.Id, e.Product.Id)
+	})
+
+	t.Run("success 03 - products moved to a created warehouse watched", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+		require.NoError(t, err)
+		_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (1, 'product 1', 10, 'code_value 1', true, '2030-01-10', 10, 1)")
+		require.NoError(t, err)
+		cp, cw := newGRPCClients(t, uow)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		watch, err := cp.WatchProducts(ctx, &storagepb.WatchProductsRequest{})
+		require.NoError(t, err)
+		_, err = watch.Header()
+		require.NoError(t, err)
+
+		// act
+		w, err := cw.CreateWarehouse(ctx, &storagepb.CreateWarehouseRequest{Name: "warehouse 2", Address: "address 2", Telephone: "telephone 2", Capacity: 10, ProductIds: []int64{1}})
+		require.NoError(t, err)
+
+		// assert
+		e, err := watch.Recv()
+		require.NoError(t, err)
+		require.Equal(t, storagepb.ProductEvent_TYPE_UPDATED, e.Type)
+		require.Equal(t, int64(1), e.Product.Id)
+		require.Equal(t, w.Id, e.Product.WarehouseId)
+	})
+
+	t.Run("failure 01 - product not found", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		cp, _ := newGRPCClients(t, uow)
+
+		// act
+		_, err := cp.GetProduct(context.Background(), &storagepb.GetProductRequest{Id: 1})
+
+		// assert
+		st := status.Convert(err)
+		require.Equal(t, codes.NotFound, st.Code())
+		require.Equal(t, "Product not found", st.Message())
+		require.Len(t, st.Details(), 1)
+		require.Equal(t, "product_not_found", st.Details()[0].(*errdetails.ErrorInfo).Reason)
+	})
+
+	t.Run("failure 02 - product breaks a validation rule, in the language of the call", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		cp, _ := newGRPCClients(t, uow)
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "accept-language", "es")
+
+		// act
+		_, err := cp.CreateProduct(ctx, &storagepb.CreateProductRequest{CodeValue: "code_value 1", Expiration: "2099-01-01", WarehouseId: 1})
+
+		// assert
+		st := status.Convert(err)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+		require.Equal(t, "El cuerpo de la solicitud no cumple una regla de validación", st.Message())
+		require.Len(t, st.Details(), 2)
+		require.Equal(t, "invalid_body", st.Details()[0].(*errdetails.ErrorInfo).Reason)
+		violations := st.Details()[1].(*errdetails.BadRequest).FieldViolations
+		require.Len(t, violations, 1)
+		require.Equal(t, "name", violations[0].Field)
+		require.Equal(t, "name es obligatorio", violations[0].Description)
+	})
+
+	t.Run("failure 03 - product of another version", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+		require.NoError(t, err)
+		_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (1, 'product 1', 10, 'code_value 1', true, '2099-01-10', 10, 1)")
+		require.NoError(t, err)
+		cp, _ := newGRPCClients(t, uow)
+		version := int64(2)
+
+		// act
+		_, err = cp.UpdateProduct(context.Background(), &storagepb.UpdateProductRequest{Id: 1, Version: &version})
+
+		// assert
+		require.Equal(t, codes.Aborted, status.Code(err))
+	})
+}
+
+func TestWarehousesGRPC(t *testing.T) {
+	t.Run("success 01 - warehouse created and listed", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		_, cw := newGRPCClients(t, uow)
+		ctx := context.Background()
+
+		// act
+		created, err := cw.CreateWarehouse(ctx, &storagepb.CreateWarehouseRequest{Name: "warehouse 1", Address: "address 1", Telephone: "telephone 1", Capacity: 100})
+		require.NoError(t, err)
+		stream, err := cw.ListWarehouses(ctx, &storagepb.ListWarehousesRequest{})
+		require.NoError(t, err)
+		listed, err := stream.Recv()
+		require.NoError(t, err)
+		_, err = stream.Recv()
+
+		// assert
+		expected := &storagepb.Warehouse{Id: 1, Name: "warehouse 1", Address: "address 1", Telephone: "telephone 1", Capacity: 100, Version: 1}
+		require.Equal(t, expected.String(), created.String())
+		require.Equal(t, expected.String(), listed.String())
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("failure 01 - warehouse not found", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		_, cw := newGRPCClients(t, uow)
+
+		// act
+		_, err := cw.GetWarehouse(context.Background(), &storagepb.GetWarehouseRequest{Id: 1})
+
+		// assert
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/handler/storagepb"
+	"app/platform/validate"
+	"app/platform/web/response"
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewProductsGRPC returns a new instance of ProductsGRPC
+func NewProductsGRPC(sv internal.ProductService, ev internal.ProductEvents) *ProductsGRPC {
+	return &ProductsGRPC{
+		sv: sv,
+		ev: ev,
+	}
+}
+
+// ProductsGRPC is a struct that represents the gRPC product service
+type ProductsGRPC struct {
+	storagepb.UnimplementedProductServiceServer
+	// sv is the product service
+	sv internal.ProductService
+	// ev is the feed of the changes of products
+	ev internal.ProductEvents
+}
+
+// productPB returns the message of a product
+func productPB(p internal.Product) *storagepb.Product {
+	return &storagepb.Product{
+		Id:          int64(p.ID),
+		Name:        p.Name,
+		Quantity:    int64(p.Quantity),
+		CodeValue:   p.CodeValue,
+		IsPublished: p.IsPublished,
+		Expiration:  p.Expiration.Format(time.DateOnly),
+		Price:       p.Price,
+		WarehouseId: int64(p.WarehouseId),
+		Version:     int64(p.Version),
+	}
+}
+
+// productEventTypes are the types of the messages of the product events
+var productEventTypes = map[internal.ProductEventType]storagepb.ProductEvent_Type{
+	internal.ProductCreated: storagepb.ProductEvent_TYPE_CREATED,
+	internal.ProductUpdated: storagepb.ProductEvent_TYPE_UPDATED,
+	internal.ProductDeleted: storagepb.ProductEvent_TYPE_DELETED,
+}
+
+// GetProduct returns a product by id
+func (h *ProductsGRPC) GetProduct(ctx context.Context, req *storagepb.GetProductRequest) (p *storagepb.Product, err error) {
+	pr, err := h.sv.GetOne(ctx, int(req.GetId()))
+	if err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+
+	p = productPB(pr)
+	return
+}
+
+// ListProducts streams the products matching the filter of the request
+func (h *ProductsGRPC) ListProducts(req *storagepb.ListProductsRequest, stream storagepb.ProductService_ListProductsServer) (err error) {
+	ctx := stream.Context()
+
+	// filter
+	var f internal.ProductFilter
+	if req.WarehouseId != nil {
+		f.WarehouseId = int(req.GetWarehouseId())
+	}
+	f.IsPublished = req.IsPublished
+
+	// process
+	products, err := h.sv.GetAll(ctx, f)
+	if err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+
+	// stream
+	for _, p := range products {
+		if err = stream.Send(productPB(p)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// CreateProduct validates and creates a product
+func (h *ProductsGRPC) CreateProduct(ctx context.Context, req *storagepb.CreateProductRequest) (p *storagepb.Product, err error) {
+	// request
+	body := RequestBodyProductCreate{
+		Name:        req.GetName(),
+		Quantity:    int(req.GetQuantity()),
+		CodeValue:   req.GetCodeValue(),
+		IsPublished: req.GetIsPublished(),
+		Expiration:  req.GetExpiration(),
+		Price:       req.GetPrice(),
+		WarehouseId: int(req.GetWarehouseId()),
+	}
+	if err = validate.Struct(body); err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+	exp, _ := time.Parse(time.DateOnly, body.Expiration)
+
+	// process
+	pr := internal.Product{
+		Name:        body.Name,
+		Quantity:    body.Quantity,
+		CodeValue:   body.CodeValue,
+		IsPublished: body.IsPublished,
+		Expiration:  exp,
+		Price:       body.Price,
+		WarehouseId: body.WarehouseId,
+	}
+	if err = h.sv.Create(ctx, &pr); err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+
+	p = productPB(pr)
+	return
+}
+
+// UpdateProduct updates the fields of a product set in the request
+func (h *ProductsGRPC) UpdateProduct(ctx context.Context, req *storagepb.UpdateProductRequest) (p *storagepb.Product, err error) {
+	// get product
+	pr, err := h.sv.GetOne(ctx, int(req.GetId()))
+	if err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+	// - check the client has the current version
+	if req.Version != nil && int(req.GetVersion()) != pr.Version {
+		err = grpcError(ctx, internal.ErrProductVersionConflict)
+		return
+	}
+
+	// patch product
+	body := RequestBodyProductUpdate{
+		Name:        pr.Name,
+		Quantity:    pr.Quantity,
+		CodeValue:   pr.CodeValue,
+		IsPublished: pr.IsPublished,
+		Expiration:  pr.Expiration.Format(time.DateOnly),
+		Price:       pr.Price,
+		WarehouseId: pr.WarehouseId,
+	}
+	if req.Name != nil {
+		body.Name = req.GetName()
+	}
+	if req.Quantity != nil {
+		body.Quantity = int(req.GetQuantity())
+	}
+	if req.CodeValue != nil {
+		body.CodeValue = req.GetCodeValue()
+	}
+	if req.IsPublished != nil {
+		body.IsPublished = req.GetIsPublished()
+	}
+	if req.Expiration != nil {
+		body.Expiration = req.GetExpiration()
+	}
+	if req.Price != nil {
+		body.Price = req.GetPrice()
+	}
+	if req.WarehouseId != nil {
+		body.WarehouseId = int(req.GetWarehouseId())
+	}
+	if err = validate.Struct(body); err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+	exp, _ := time.Parse(time.DateOnly, body.Expiration)
+	pr.Name = body.Name
+	pr.Quantity = body.Quantity
+	pr.CodeValue = body.CodeValue
+	pr.IsPublished = body.IsPublished
+	pr.Expiration = exp
+	pr.Price = body.Price
+	pr.WarehouseId = body.WarehouseId
+
+	// update product
+	if err = h.sv.Update(ctx, &pr); err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+
+	p = productPB(pr)
+	return
+}
+
+// DeleteProduct deletes a product by id
+func (h *ProductsGRPC) DeleteProduct(ctx context.Context, req *storagepb.DeleteProductRequest) (res *storagepb.DeleteProductResponse, err error) {
+	if err = h.sv.Delete(ctx, int(req.GetId()), 0); err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+
+	res = &storagepb.DeleteProductResponse{Id: req.GetId()}
+	return
+}
+
+// WatchProducts streams the changes of products from the call on, until the client cancels it.
+// It ends with ResourceExhausted when the client falls behind the feed
+func (h *ProductsGRPC) WatchProducts(req *storagepb.WatchProductsRequest, stream storagepb.ProductService_WatchProductsServer) (err error) {
+	ctx := stream.Context()
+	events := h.ev.Subscribe(ctx)
+
+	// headers: the client knows it is subscribed before the first change
+	if err = stream.SendHeader(nil); err != nil {
+		return
+	}
+
+	for {
+		e, ok := <-events
+		switch {
+		case !ok && ctx.Err() != nil:
+			err = grpcError(ctx, ctx.Err())
+			return
+		case !ok:
+			err = status.Error(codes.ResourceExhausted, response.Localize(newGRPCWriter(ctx), "watch fell behind the changes of products"))
+			return
+		}
+		// - filter
+		if req.WarehouseId != nil && e.Type != internal.ProductDeleted && e.Product.WarehouseId != int(req.GetWarehouseId()) {
+			continue
+		}
+
+		msg := &storagepb.ProductEvent{Type: productEventTypes[e.Type], Product: productPB(e.Product)}
+		if e.Type == internal.ProductDeleted {
+			msg.Product = &storagepb.Product{Id: int64(e.Product.ID)}
+		}
+		if err = stream.Send(msg); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/handler/storagepb"
+	"app/platform/validate"
+	"context"
+)
+
+// NewWarehousesGRPC returns a new instance of WarehousesGRPC
+func NewWarehousesGRPC(sv internal.WarehouseService) *WarehousesGRPC {
+	return &WarehousesGRPC{
+		sv: sv,
+	}
+}
+
+// WarehousesGRPC is a struct that represents the gRPC warehouse service
+type WarehousesGRPC struct {
+	storagepb.UnimplementedWarehouseServiceServer
+	// sv is the warehouse service
+	sv internal.WarehouseService
+}
+
+// warehousePB returns the message of a warehouse
+func warehousePB(w internal.Warehouse) *storagepb.Warehouse {
+	return &storagepb.Warehouse{
+		Id:        int64(w.Id),
+		Name:      w.Name,
+		Address:   w.Address,
+		Telephone: w.Telephone,
+		Capacity:  int64(w.Capacity),
+		Version:   int64(w.Version),
+	}
+}
+
+// GetWarehouse returns a warehouse by id
+func (h *WarehousesGRPC) GetWarehouse(ctx context.Context, req *storagepb.GetWarehouseRequest) (w *storagepb.Warehouse, err error) {
+	wh, err := h.sv.GetOne(ctx, int(req.GetId()))
+	if err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+
+	w = warehousePB(wh)
+	return
+}
+
+// ListWarehouses streams the warehouses
+func (h *WarehousesGRPC) ListWarehouses(req *storagepb.ListWarehousesRequest, stream storagepb.WarehouseService_ListWarehousesServer) (err error) {
+	ctx := stream.Context()
+
+	warehouses, err := h.sv.GetAll(ctx)
+	if err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+
+	for _, w := range warehouses {
+		if err = stream.Send(warehousePB(w)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// CreateWarehouse validates and creates a warehouse, moving the products of the request into it
+func (h *WarehousesGRPC) CreateWarehouse(ctx context.Context, req *storagepb.CreateWarehouseRequest) (w *storagepb.Warehouse, err error) {
+	// request
+	body := BodyWarehouseJSON{
+		Name:      req.GetName(),
+		Address:   req.GetAddress(),
+		Telephone: req.GetTelephone(),
+		Capacity:  int(req.GetCapacity()),
+	}
+	if err = validate.Struct(body); err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+
+	// process
+	wh := internal.Warehouse{
+		Name:      body.Name,
+		Address:   body.Address,
+		Telephone: body.Telephone,
+		Capacity:  body.Capacity,
+	}
+	ids := make([]int, len(req.GetProductIds()))
+	for i, id := range req.GetProductIds() {
+		ids[i] = int(id)
+	}
+	if err = h.sv.Create(ctx, &wh, ids...); err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+
+	w = warehousePB(wh)
+	return
+}
+
+// UpdateWarehouse updates the fields of a warehouse set in the request
+func (h *WarehousesGRPC) UpdateWarehouse(ctx context.Context, req *storagepb.UpdateWarehouseRequest) (w *storagepb.Warehouse, err error) {
+	// get warehouse
+	wh, err := h.sv.GetOne(ctx, int(req.GetId()))
+	if err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+	// - check the client has the current version
+	if req.Version != nil && int(req.GetVersion()) != wh.Version {
+		err = grpcError(ctx, internal.ErrWarehouseVersionConflict)
+		return
+	}
+
+	// patch warehouse
+	body := BodyWarehouseJSON{
+		Name:      wh.Name,
+		Address:   wh.Address,
+		Telephone: wh.Telephone,
+		Capacity:  wh.Capacity,
+	}
+	if req.Name != nil {
+		body.Name = req.GetName()
+	}
+	if req.Address != nil {
+		body.Address = req.GetAddress()
+	}
+	if req.Telephone != nil {
+		body.Telephone = req.GetTelephone()
+	}
+	if req.Capacity != nil {
+		body.Capacity = int(req.GetCapacity())
+	}
+	if err = validate.Struct(body); err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+	wh.Name = body.Name
+	wh.Address = body.Address
+	wh.Telephone = body.Telephone
+	wh.Capacity = body.Capacity
+
+	// update warehouse
+	if err = h.sv.Update(ctx, &wh); err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+
+	w = warehousePB(wh)
+	return
+}
+
+// ReportProducts counts the products of a warehouse, or of every warehouse when the request has no id
+func (h *WarehousesGRPC) ReportProducts(ctx context.Context, req *storagepb.ReportProductsRequest) (res *storagepb.ReportProductsResponse, err error) {
+	rp, err := h.sv.ReportProducts(ctx, int(req.GetId()))
+	if err != nil {
+		err = grpcError(ctx, err)
+		return
+	}
+
+	res = &storagepb.ReportProductsResponse{Report: make([]*storagepb.ReportProduct, len(rp))}
+	for i, r := range rp {
+		res.Report[i] = &storagepb.ReportProduct{Name: r.Name, ProductCount: int64(r.ProductCount)}
+	}
+	return
+}
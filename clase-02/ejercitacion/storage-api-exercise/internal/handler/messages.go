@@ -0,0 +1,104 @@
+package handler
+
+import "app/platform/i18n"
+
+// init registers the Spanish messages of the handlers
+func init() {
+	i18n.Register(i18n.Spanish, messagesES)
+}
+
+// messagesES are the Spanish translations of the messages of the handlers, keyed by the message in English
+var messagesES = map[string]string{
+	// problems
+	"Product breaks a validation rule":                "El producto no cumple una regla de validación",
+	"Product not found":                               "Producto no encontrado",
+	"Product not unique":                              "El producto no es único",
+	"Product relation error":                          "Error de relación del producto",
+	"Product has been modified":                       "El producto fue modificado",
+	"Product to move not found":                       "Producto a mover no encontrado",
+	"Warehouse breaks a validation rule":              "El almacén no cumple una regla de validación",
+	"Warehouse not found":                             "Almacén no encontrado",
+	"Warehouse already exists":                        "El almacén ya existe",
+	"Warehouse capacity exceeded":                     "Capacidad del almacén excedida",
+	"Warehouse has been modified":                     "El almacén fue modificado",
+	"Webhook breaks a validation rule":                "El webhook no cumple una regla de validación",
+	"Webhook not found":                               "Webhook no encontrado",
+	"Webhook has been modified":                       "El webhook fue modificado",
+	"Dead letter not found":                           "Evento no entregado no encontrado",
+	"Request body breaks a validation rule":           "El cuerpo de la solicitud no cumple una regla de validación",
+	"Request timed out":                               "Se agotó el tiempo de la solicitud",
+	"Client closed request":                           "El cliente cerró la solicitud",
+	"Internal server error":                           "Error interno del servidor",
+	"Invalid cursor":                                  "Cursor inválido",
+	"Invalid page size":                               "Tamaño de página inválido",
+	"products to move exceed the capacity":            "los productos a mover exceden la capacidad",
+	"capacity is below the products of the warehouse": "la capacidad es menor que los productos del almacén",
+	// rules of the service
+	"%[1]s is required":                                         "%[1]s es obligatorio",
+	"%[1]s must not be negative":                                "%[1]s no debe ser negativo",
+	"%[1]s must be positive":                                    "%[1]s debe ser positivo",
+	"%[1]s has passed, an expired product can not be published": "%[1]s ya pasó, un producto vencido no puede publicarse",
+	"%[1]s must be an http or https url":                        "%[1]s debe ser una url http o https",
+	"%[1]s must be known event types":                           "%[1]s deben ser tipos de evento conocidos",
+	// request
+	"invalid id":               "id inválido",
+	"invalid filter":           "filtro inválido",
+	"invalid mode":             "modo inválido",
+	"invalid request":          "solicitud inválida",
+	"invalid request body":     "cuerpo de la solicitud inválido",
+	"invalid expiration date":  "fecha de vencimiento inválida",
+	"unsupported patch format": "formato de patch no soportado",
+	"patch test failed":        "falló la prueba del patch",
+	"unsupported format":       "formato no soportado",
+	"unsupported content type": "tipo de contenido no soportado",
+	"invalid last event id":    "id del último evento inválido",
+	"unknown field %s":         "campo %s desconocido",
+	"unknown include %s":       "relación %s desconocida",
+	"missing search query":     "falta la consulta de búsqueda",
+	"invalid limit":            "límite inválido",
+	// products
+	"products found":  "productos encontrados",
+	"product found":   "producto encontrado",
+	"product created": "producto creado",
+	"product updated": "producto actualizado",
+	"product deleted": "producto eliminado",
+	// warehouses
+	"warehouses found":                "almacenes encontrados",
+	"warehouse found":                 "almacén encontrado",
+	"warehouse created":               "almacén creado",
+	"warehouse updated":               "almacén actualizado",
+	"generate report product success": "reporte de productos generado",
+	// bulk
+	"a batch must have between 1 and %d products": "un lote debe tener entre 1 y %d productos",
+	"batch applied":                "lote aplicado",
+	"batch failed":                 "lote fallido",
+	"batch partially applied":      "lote aplicado parcialmente",
+	"batch aborted: %s":            "lote abortado: %s",
+	"batch aborted: invalid items": "lote abortado: ítems inválidos",
+	"not applied, batch aborted":   "no aplicado, lote abortado",
+	// csv
+	"csv file missing":                       "falta el archivo csv",
+	"csv header missing":                     "falta el encabezado del csv",
+	"csv column %s missing":                  "falta la columna %s del csv",
+	"csv file too large":                     "archivo csv demasiado grande",
+	"invalid csv file":                       "archivo csv inválido",
+	"invalid csv row":                        "fila del csv inválida",
+	"products imported":                      "productos importados",
+	"quantity must be an integer":            "quantity debe ser un entero",
+	"is_published must be a boolean":         "is_published debe ser un booleano",
+	"expiration must be a date (YYYY-MM-DD)": "expiration debe ser una fecha (AAAA-MM-DD)",
+	"price must be a number":                 "price debe ser un número",
+	"warehouse_id must be an integer":        "warehouse_id debe ser un entero",
+	// webhooks
+	"webhooks found":     "webhooks encontrados",
+	"webhook found":      "webhook encontrado",
+	"webhook created":    "webhook creado",
+	"webhook updated":    "webhook actualizado",
+	"webhook deleted":    "webhook eliminado",
+	"dead letters found": "eventos no entregados encontrados",
+	"dead letter queued": "evento no entregado encolado",
+	// graphql
+	"first must be between 0 and %d": "first debe estar entre 0 y %d",
+	// grpc
+	"watch fell behind the changes of products": "la observación se atrasó respecto de los cambios de productos",
+}
@@ -2,10 +2,13 @@ package handler
 
 import (
 	"app/internal"
+	"app/platform/validate"
 	"app/platform/web/request"
 	"app/platform/web/response"
 	"errors"
+	"fmt"
 	"net/http"
+	"path"
 	"strconv"
 	"time"
 
@@ -13,16 +16,16 @@ import (
 )
 
 // NewProductsDefault returns a new instance of ProductsDefault
-func NewProductsDefault(rp internal.RepositoryProducts) *ProductsDefault {
+func NewProductsDefault(sv internal.ProductService) *ProductsDefault {
 	return &ProductsDefault{
-		rp: rp,
+		sv: sv,
 	}
 }
 
 // ProductsDefault is a struct that represents the default product handler
 type ProductsDefault struct {
-	// rp is the product repository
-	rp internal.RepositoryProducts
+	// sv is the product service
+	sv internal.ProductService
 }
 
 // ProductJSON is a struct that represents a product in JSON
@@ -37,37 +40,120 @@ type ProductJSON struct {
 	WarehouseId int     `json:"warehouse_id"`
 }
 
+// ProductWarehouseJSON is a struct that represents a product with its warehouse embedded in JSON (?include=warehouse)
+type ProductWarehouseJSON struct {
+	ProductJSON
+	Warehouse WarehouseJSON `json:"warehouse"`
+}
+
+// productJSON returns the JSON of a product
+func productJSON(p internal.Product) ProductJSON {
+	return ProductJSON{
+		ID:          p.ID,
+		Name:        p.Name,
+		Quantity:    p.Quantity,
+		CodeValue:   p.CodeValue,
+		IsPublished: p.IsPublished,
+		Expiration:  p.Expiration.Format(time.DateOnly),
+		Price:       p.Price,
+		WarehouseId: p.WarehouseId,
+	}
+}
+
+// productWarehouseJSON returns the JSON of a product with its warehouse
+func productWarehouseJSON(p internal.ProductWarehouse) ProductWarehouseJSON {
+	return ProductWarehouseJSON{
+		ProductJSON: productJSON(p.Product),
+		Warehouse:   warehouseJSON(p.Warehouse),
+	}
+}
+
+// productETag returns the entity tag of the current version of a product
+func productETag(p internal.Product) string {
+	return fmt.Sprintf("\"%d\"", p.Version)
+}
+
+// productWarehouseETag returns the entity tag of a product with its warehouse, weak since it changes with either of them
+// and so it is not the one of the product a write must match
+func productWarehouseETag(p internal.ProductWarehouse) string {
+	return fmt.Sprintf("W/\"%d-%d\"", p.Version, p.Warehouse.Version)
+}
+
+// productFilter returns the filter of the products list set in the request query (warehouse_id and is_published)
+func productFilter(r *http.Request) (f internal.ProductFilter, err error) {
+	query := r.URL.Query()
+
+	if v := query.Get("warehouse_id"); v != "" {
+		if f.WarehouseId, err = strconv.Atoi(v); err != nil {
+			return
+		}
+	}
+	if v := query.Get("is_published"); v != "" {
+		var b bool
+		if b, err = strconv.ParseBool(v); err != nil {
+			return
+		}
+		f.IsPublished = &b
+	}
+	return
+}
+
+// GetAll returns all products matching the query filters, with the fields asked (?fields=id,name) and their warehouse
+// when it is included (?include=warehouse)
 func (h *ProductsDefault) GetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		products, err := h.rp.GetAll()
+		f, err := productFilter(r)
 		if err != nil {
-			if errors.Is(err, internal.ErrProductNotFound) {
-				response.Error(w, http.StatusNotFound, "products not found")
-				return
-			}
-			response.Error(w, http.StatusInternalServerError, "internal server error")
+			response.Error(w, http.StatusBadRequest, "invalid filter")
 			return
 		}
+		rp, ok := parseRepresentation(w, r, "warehouse")
+		if !ok {
+			return
+		}
+
+		var data any
+		var count int
+		switch {
+		case rp.includes("warehouse"):
+			products, err := h.sv.GetAllWithWarehouse(r.Context(), f)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
 
-		var productsJSON []ProductJSON
-		for _, p := range products {
-			productsJSON = append(productsJSON, ProductJSON{
-				ID:          p.ID,
-				Name:        p.Name,
-				Quantity:    p.Quantity,
-				CodeValue:   p.CodeValue,
-				IsPublished: p.IsPublished,
-				Expiration:  p.Expiration.Format(time.DateOnly),
-				Price:       p.Price,
-				WarehouseId: p.WarehouseId,
-			})
+			productsJSON := make([]ProductWarehouseJSON, 0, len(products))
+			for _, p := range products {
+				productsJSON = append(productsJSON, productWarehouseJSON(p))
+			}
+			data, count = productsJSON, len(productsJSON)
+		default:
+			products, err := h.sv.GetAll(r.Context(), f)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			productsJSON := make([]ProductJSON, 0, len(products))
+			for _, p := range products {
+				productsJSON = append(productsJSON, productJSON(p))
+			}
+			data, count = productsJSON, len(productsJSON)
+		}
+		if data, ok = rp.sparse(w, data); !ok {
+			return
 		}
 
-		response.JSON(w, http.StatusOK, map[string]any{"message": "products found", "data": productsJSON})
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "products found"),
+			Data:    data,
+			Meta:    map[string]any{"count": count},
+			Links:   map[string]string{"self": r.URL.RequestURI()},
+		})
 	}
 }
 
-// GetOne returns a product by id
+// GetOne returns a product by id, with the fields asked (?fields=id,name) and its warehouse when it is included (?include=warehouse)
 func (h *ProductsDefault) GetOne() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// request
@@ -76,44 +162,59 @@ func (h *ProductsDefault) GetOne() http.HandlerFunc {
 			response.Error(w, http.StatusBadRequest, "invalid id")
 			return
 		}
+		rp, ok := parseRepresentation(w, r, "warehouse")
+		if !ok {
+			return
+		}
 
 		// process
-		p, err := h.rp.GetOne(id)
-		if err != nil {
-			switch {
-			case errors.Is(err, internal.ErrProductNotFound):
-				response.Error(w, http.StatusNotFound, "product not found")
-			default:
-				response.Error(w, http.StatusInternalServerError, "internal server error"+err.Error())
+		var data any
+		var etag string
+		switch {
+		case rp.includes("warehouse"):
+			p, err := h.sv.GetOneWithWarehouse(r.Context(), id)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			data, etag = productWarehouseJSON(p), productWarehouseETag(p)
+		default:
+			p, err := h.sv.GetOne(r.Context(), id)
+			if err != nil {
+				writeError(w, err)
+				return
 			}
+			data, etag = productJSON(p), productETag(p)
+		}
+		// - check the client cached version
+		if request.IfNoneMatch(r, etag) {
+			response.NotModified(w, etag)
 			return
 		}
 
 		// response
 		// - serialize
-		data := ProductJSON{
-			ID:          p.ID,
-			Name:        p.Name,
-			Quantity:    p.Quantity,
-			CodeValue:   p.CodeValue,
-			IsPublished: p.IsPublished,
-			Expiration:  p.Expiration.Format(time.DateOnly),
-			Price:       p.Price,
-			WarehouseId: p.WarehouseId,
+		if data, ok = rp.sparse(w, data); !ok {
+			return
 		}
-		response.JSON(w, http.StatusOK, map[string]any{"message": "product found", "data": data})
+		response.ETag(w, etag)
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "product found"),
+			Data:    data,
+			Links:   map[string]string{"self": r.URL.Path},
+		})
 	}
 }
 
 // RequestBodyProductCreate is a struct that represents the request body of a product to create
 type RequestBodyProductCreate struct {
-	Name        string  `json:"name"`
-	Quantity    int     `json:"quantity"`
-	CodeValue   string  `json:"code_value"`
+	Name        string  `json:"name" validate:"required"`
+	Quantity    int     `json:"quantity" validate:"min=0"`
+	CodeValue   string  `json:"code_value" validate:"required"`
 	IsPublished bool    `json:"is_published"`
-	Expiration  string  `json:"expiration"`
-	Price       float64 `json:"price"`
-	WarehouseId int     `json:"warehouse_id"`
+	Expiration  string  `json:"expiration" validate:"required,date,notpast"`
+	Price       float64 `json:"price" validate:"min=0"`
+	WarehouseId int     `json:"warehouse_id" validate:"gt=0"`
 }
 
 // Create creates a product
@@ -121,8 +222,12 @@ func (h *ProductsDefault) Create() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// request
 		var body RequestBodyProductCreate
-		if err := request.JSON(r, &body); err != nil {
-			response.Error(w, http.StatusBadRequest, "invalid request body")
+		if err := request.Body(r, &body); err != nil {
+			writeBodyError(w, err)
+			return
+		}
+		if err := validate.Struct(body); err != nil {
+			writeError(w, err)
 			return
 		}
 		exp, err := time.Parse(time.DateOnly, body.Expiration)
@@ -141,15 +246,8 @@ func (h *ProductsDefault) Create() http.HandlerFunc {
 			Price:       body.Price,
 			WarehouseId: body.WarehouseId,
 		}
-		if err := h.rp.Store(&p); err != nil {
-			switch {
-			case errors.Is(err, internal.ErrProductNotUnique):
-				response.Error(w, http.StatusConflict, "product not unique")
-			case errors.Is(err, internal.ErrProductRelation):
-				response.Error(w, http.StatusConflict, "product relation error")
-			default:
-				response.Error(w, http.StatusInternalServerError, "internal server error")
-			}
+		if err := h.sv.Create(r.Context(), &p); err != nil {
+			writeError(w, err)
 			return
 		}
 
@@ -165,19 +263,24 @@ func (h *ProductsDefault) Create() http.HandlerFunc {
 			Price:       p.Price,
 			WarehouseId: p.WarehouseId,
 		}
-		response.JSON(w, http.StatusCreated, map[string]any{"message": "product created", "data": data})
+		response.ETag(w, productETag(p))
+		response.Success(w, http.StatusCreated, response.Body{
+			Message: response.Localize(w, "product created"),
+			Data:    data,
+			Links:   map[string]string{"self": path.Join(r.URL.Path, strconv.Itoa(p.ID))},
+		})
 	}
 }
 
 // RequestBodyProductUpdate is a struct that represents the request body of a product to update
 type RequestBodyProductUpdate struct {
-	Name        string  `json:"name"`
-	Quantity    int     `json:"quantity"`
-	CodeValue   string  `json:"code_value"`
+	Name        string  `json:"name" validate:"required"`
+	Quantity    int     `json:"quantity" validate:"min=0"`
+	CodeValue   string  `json:"code_value" validate:"required"`
 	IsPublished bool    `json:"is_published"`
-	Expiration  string  `json:"expiration"`
-	Price       float64 `json:"price"`
-	WarehouseId int     `json:"warehouse_id"`
+	Expiration  string  `json:"expiration" validate:"required,date"`
+	Price       float64 `json:"price" validate:"min=0"`
+	WarehouseId int     `json:"warehouse_id" validate:"gt=0"`
 }
 
 // Update updates a product
@@ -192,14 +295,14 @@ func (h *ProductsDefault) Update() http.HandlerFunc {
 
 		// process
 		// - get product
-		p, err := h.rp.GetOne(id)
+		p, err := h.sv.GetOne(r.Context(), id)
 		if err != nil {
-			switch {
-			case errors.Is(err, internal.ErrProductNotFound):
-				response.Error(w, http.StatusNotFound, "product not found")
-			default:
-				response.Error(w, http.StatusInternalServerError, "internal server error")
-			}
+			writeError(w, err)
+			return
+		}
+		// - check the client has the current version
+		if !request.IfMatch(r, productETag(p)) {
+			writeError(w, internal.ErrProductVersionConflict)
 			return
 		}
 		// - patch product
@@ -212,8 +315,20 @@ func (h *ProductsDefault) Update() http.HandlerFunc {
 			Price:       p.Price,
 			WarehouseId: p.WarehouseId,
 		}
-		if err := request.JSON(r, &body); err != nil {
-			response.Error(w, http.StatusBadRequest, "invalid request body")
+		if err := request.Patch(r, &body); err != nil {
+			switch {
+			case errors.Is(err, request.ErrRequestContentTypeNotPatch):
+				w.Header().Set("Accept-Patch", request.ContentTypeMergePatch+", "+request.ContentTypeJSONPatch)
+				response.Error(w, http.StatusUnsupportedMediaType, "unsupported patch format")
+			case errors.Is(err, request.ErrRequestPatchTestFailed):
+				response.Error(w, http.StatusConflict, "patch test failed")
+			default:
+				response.Error(w, http.StatusBadRequest, "invalid request body")
+			}
+			return
+		}
+		if err := validate.Struct(body); err != nil {
+			writeError(w, err)
 			return
 		}
 		exp, err := time.Parse(time.DateOnly, body.Expiration)
@@ -229,15 +344,8 @@ func (h *ProductsDefault) Update() http.HandlerFunc {
 		p.Price = body.Price
 		p.WarehouseId = body.WarehouseId
 		// - update product
-		if err := h.rp.Update(&p); err != nil {
-			switch {
-			case errors.Is(err, internal.ErrProductNotUnique):
-				response.Error(w, http.StatusConflict, "product not unique")
-			case errors.Is(err, internal.ErrProductRelation):
-				response.Error(w, http.StatusConflict, "product relation error")
-			default:
-				response.Error(w, http.StatusInternalServerError, "internal server error")
-			}
+		if err := h.sv.Update(r.Context(), &p); err != nil {
+			writeError(w, err)
 			return
 		}
 
@@ -253,7 +361,12 @@ func (h *ProductsDefault) Update() http.HandlerFunc {
 			Price:       p.Price,
 			WarehouseId: p.WarehouseId,
 		}
-		response.JSON(w, http.StatusOK, map[string]any{"message": "product updated", "data": data})
+		response.ETag(w, productETag(p))
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "product updated"),
+			Data:    data,
+			Links:   map[string]string{"self": r.URL.Path},
+		})
 	}
 }
 
@@ -268,12 +381,34 @@ func (h *ProductsDefault) Delete() http.HandlerFunc {
 		}
 
 		// process
-		if err := h.rp.Delete(id); err != nil {
-			response.Error(w, http.StatusInternalServerError, "internal server error")
+		// - check the client has the current version, the delete fails if it changes meanwhile
+		var version int
+		if r.Header.Get("If-Match") != "" {
+			p, err := h.sv.GetOne(r.Context(), id)
+			if err != nil {
+				switch {
+				case errors.Is(err, internal.ErrProductNotFound):
+					writeError(w, internal.ErrProductVersionConflict)
+				default:
+					writeError(w, err)
+				}
+				return
+			}
+			if !request.IfMatch(r, productETag(p)) {
+				writeError(w, internal.ErrProductVersionConflict)
+				return
+			}
+			if r.Header.Get("If-Match") != "*" {
+				version = p.Version
+			}
+		}
+		// - delete product
+		if err := h.sv.Delete(r.Context(), id, version); err != nil {
+			writeError(w, err)
 			return
 		}
 
 		// response
-		response.JSON(w, http.StatusOK, map[string]any{"message": "product deleted", "data": id})
+		response.Success(w, http.StatusOK, response.Body{Message: response.Localize(w, "product deleted"), Data: id})
 	}
 }
@@ -0,0 +1,327 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/validate"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// BulkMaxItems is the maximum number of items accepted by a bulk request
+const BulkMaxItems = 1000
+
+// BulkItemResultJSON is a struct that represents the result of an item of a bulk request in JSON
+type BulkItemResultJSON struct {
+	Index int    `json:"index"`
+	ID    int    `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Code is the code of the problem of a failed item (e.g. product_not_found)
+	Code string `json:"code,omitempty"`
+	// Errors are the fields of an invalid item that break a rule
+	Errors validate.Errors `json:"errors,omitempty"`
+}
+
+// bulkAtomic returns whether a bulk request asked for all-or-nothing (default) or best-effort semantics
+func bulkAtomic(r *http.Request) (atomic bool, ok bool) {
+	switch r.URL.Query().Get("mode") {
+	case "", "atomic":
+		atomic, ok = true, true
+	case "best-effort":
+		ok = true
+	}
+	return
+}
+
+// bulkError returns the status code and message, in the language of the response, of a product service error of a bulk item
+func bulkError(w http.ResponseWriter, err error) (code int, message string) {
+	p := problemOf(w, err)
+	code, message = p.Status, response.Localize(w, p.Title)
+	if p.Detail != "" {
+		message = p.Detail
+	}
+	return
+}
+
+// bulkItemError sets the error of an item of a bulk request, with the fields that break a rule when it is invalid
+func bulkItemError(w http.ResponseWriter, res *BulkItemResultJSON, err error) {
+	p := problemOf(w, err)
+	_, res.Error = bulkError(w, err)
+	res.Code = p.Code
+	if errs, ok := p.Errors.(validate.Errors); ok {
+		res.Errors = errs
+	}
+}
+
+// bulkResponse writes the response of a bulk request.
+// results holds the items that failed validation, idx maps the items sent to the repository to their index in results.
+func bulkResponse(w http.ResponseWriter, okCode int, results []BulkItemResultJSON, idx []int, errs []error, err error) {
+	// batch failed
+	if err != nil {
+		if !errors.Is(err, internal.ErrProductBulkAborted) {
+			writeError(w, err)
+			return
+		}
+		for k, i := range idx {
+			switch {
+			case errs != nil && errs[k] != nil:
+				bulkItemError(w, &results[i], errs[k])
+			default:
+				results[i].Error = response.Localize(w, "not applied, batch aborted")
+			}
+		}
+		code, message := bulkError(w, err)
+		response.Success(w, code, response.Body{Message: response.Localize(w, "batch aborted: %s", message), Data: results})
+		return
+	}
+
+	// per item errors
+	for k, i := range idx {
+		if errs != nil && errs[k] != nil {
+			bulkItemError(w, &results[i], errs[k])
+		}
+	}
+	var failed int
+	for _, res := range results {
+		if res.Error != "" {
+			failed++
+		}
+	}
+
+	// response
+	switch {
+	case failed == 0:
+		response.Success(w, okCode, response.Body{Message: response.Localize(w, "batch applied"), Data: results})
+	case failed == len(results):
+		response.Success(w, http.StatusConflict, response.Body{Message: response.Localize(w, "batch failed"), Data: results})
+	default:
+		response.Success(w, http.StatusMultiStatus, response.Body{Message: response.Localize(w, "batch partially applied"), Data: results})
+	}
+}
+
+// bulkInvalidResponse writes the response of an all-or-nothing bulk request with invalid items
+func bulkInvalidResponse(w http.ResponseWriter, results []BulkItemResultJSON) {
+	for i := range results {
+		if results[i].Error == "" {
+			results[i].Error = response.Localize(w, "not applied, batch aborted")
+		}
+	}
+	response.Success(w, http.StatusUnprocessableEntity, response.Body{Message: response.Localize(w, "batch aborted: invalid items"), Data: results})
+}
+
+// CreateBulk creates several products
+func (h *ProductsDefault) CreateBulk() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		atomic, ok := bulkAtomic(r)
+		if !ok {
+			response.Error(w, http.StatusBadRequest, "invalid mode")
+			return
+		}
+		var body []RequestBodyProductCreate
+		if err := request.Body(r, &body); err != nil {
+			writeBodyError(w, err)
+			return
+		}
+		if len(body) == 0 || len(body) > BulkMaxItems {
+			response.Errorf(w, http.StatusBadRequest, "a batch must have between 1 and %d products", BulkMaxItems)
+			return
+		}
+
+		// process
+		// - validate items
+		results := make([]BulkItemResultJSON, len(body))
+		var ps []internal.Product
+		var idx []int
+		for i, b := range body {
+			results[i].Index = i
+			if err := validate.Struct(b); err != nil {
+				bulkItemError(w, &results[i], err)
+				continue
+			}
+			exp, err := time.Parse(time.DateOnly, b.Expiration)
+			if err != nil {
+				results[i].Error = response.Localize(w, "invalid expiration date")
+				continue
+			}
+			ps = append(ps, internal.Product{
+				Name:        b.Name,
+				Quantity:    b.Quantity,
+				CodeValue:   b.CodeValue,
+				IsPublished: b.IsPublished,
+				Expiration:  exp,
+				Price:       b.Price,
+				WarehouseId: b.WarehouseId,
+			})
+			idx = append(idx, i)
+		}
+		if atomic && len(idx) != len(body) {
+			bulkInvalidResponse(w, results)
+			return
+		}
+		// - store items
+		var errs []error
+		var err error
+		if len(ps) > 0 {
+			errs, err = h.sv.CreateBulk(r.Context(), ps, atomic)
+		}
+		for k, i := range idx {
+			if err == nil && (errs == nil || errs[k] == nil) {
+				results[i].ID = ps[k].ID
+			}
+		}
+
+		// response
+		bulkResponse(w, http.StatusCreated, results, idx, errs, err)
+	}
+}
+
+// RequestBodyProductUpdateBulk is a struct that represents an item of a bulk update:
+// the identity of the product, its optional expected version and the fields to update, the missing ones are kept
+type RequestBodyProductUpdateBulk struct {
+	ID          int      `json:"id"`
+	Version     *int     `json:"version"`
+	Name        *string  `json:"name"`
+	Quantity    *int     `json:"quantity"`
+	CodeValue   *string  `json:"code_value"`
+	IsPublished *bool    `json:"is_published"`
+	Expiration  *string  `json:"expiration"`
+	Price       *float64 `json:"price"`
+	WarehouseId *int     `json:"warehouse_id"`
+}
+
+// UpdateBulk patches several products, each item holds the id, the optional expected version and the fields to update
+func (h *ProductsDefault) UpdateBulk() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		atomic, ok := bulkAtomic(r)
+		if !ok {
+			response.Error(w, http.StatusBadRequest, "invalid mode")
+			return
+		}
+		var body []RequestBodyProductUpdateBulk
+		if err := request.Body(r, &body); err != nil {
+			writeBodyError(w, err)
+			return
+		}
+		if len(body) == 0 || len(body) > BulkMaxItems {
+			response.Errorf(w, http.StatusBadRequest, "a batch must have between 1 and %d products", BulkMaxItems)
+			return
+		}
+
+		// process
+		// - patch items
+		results := make([]BulkItemResultJSON, len(body))
+		var ps []internal.Product
+		var idx []int
+		for i, item := range body {
+			results[i].Index = i
+			results[i].ID = item.ID
+			p, err := h.sv.GetOne(r.Context(), item.ID)
+			if err != nil {
+				bulkItemError(w, &results[i], err)
+				continue
+			}
+			if item.Version != nil && *item.Version != p.Version {
+				bulkItemError(w, &results[i], internal.ErrProductVersionConflict)
+				continue
+			}
+			patch := RequestBodyProductUpdate{
+				Name:        p.Name,
+				Quantity:    p.Quantity,
+				CodeValue:   p.CodeValue,
+				IsPublished: p.IsPublished,
+				Expiration:  p.Expiration.Format(time.DateOnly),
+				Price:       p.Price,
+				WarehouseId: p.WarehouseId,
+			}
+			if item.Name != nil {
+				patch.Name = *item.Name
+			}
+			if item.Quantity != nil {
+				patch.Quantity = *item.Quantity
+			}
+			if item.CodeValue != nil {
+				patch.CodeValue = *item.CodeValue
+			}
+			if item.IsPublished != nil {
+				patch.IsPublished = *item.IsPublished
+			}
+			if item.Expiration != nil {
+				patch.Expiration = *item.Expiration
+			}
+			if item.Price != nil {
+				patch.Price = *item.Price
+			}
+			if item.WarehouseId != nil {
+				patch.WarehouseId = *item.WarehouseId
+			}
+			if err := validate.Struct(patch); err != nil {
+				bulkItemError(w, &results[i], err)
+				continue
+			}
+			exp, err := time.Parse(time.DateOnly, patch.Expiration)
+			if err != nil {
+				results[i].Error = response.Localize(w, "invalid expiration date")
+				continue
+			}
+			p.Name = patch.Name
+			p.Quantity = patch.Quantity
+			p.CodeValue = patch.CodeValue
+			p.IsPublished = patch.IsPublished
+			p.Expiration = exp
+			p.Price = patch.Price
+			p.WarehouseId = patch.WarehouseId
+			ps = append(ps, p)
+			idx = append(idx, i)
+		}
+		if atomic && len(idx) != len(body) {
+			bulkInvalidResponse(w, results)
+			return
+		}
+		// - update items
+		var errs []error
+		var err error
+		if len(ps) > 0 {
+			errs, err = h.sv.UpdateBulk(r.Context(), ps, atomic)
+		}
+
+		// response
+		bulkResponse(w, http.StatusOK, results, idx, errs, err)
+	}
+}
+
+// DeleteBulk deletes several products by id
+func (h *ProductsDefault) DeleteBulk() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		atomic, ok := bulkAtomic(r)
+		if !ok {
+			response.Error(w, http.StatusBadRequest, "invalid mode")
+			return
+		}
+		var ids []int
+		if err := request.Body(r, &ids); err != nil {
+			writeBodyError(w, err)
+			return
+		}
+		if len(ids) == 0 || len(ids) > BulkMaxItems {
+			response.Errorf(w, http.StatusBadRequest, "a batch must have between 1 and %d products", BulkMaxItems)
+			return
+		}
+
+		// process
+		results := make([]BulkItemResultJSON, len(ids))
+		idx := make([]int, len(ids))
+		for i, id := range ids {
+			results[i] = BulkItemResultJSON{Index: i, ID: id}
+			idx[i] = i
+		}
+		errs, err := h.sv.DeleteBulk(r.Context(), ids, atomic)
+
+		// response
+		bulkResponse(w, http.StatusOK, results, idx, errs, err)
+	}
+}
@@ -0,0 +1,224 @@
+package handler_test
+
+import (
+	"app/internal/handler"
+	"app/internal/service"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductDefault_CreateBulk(t *testing.T) {
+	// arrange a database with the warehouse 1 and the product with code value A0
+	arrange := func(t *testing.T) (db *sql.DB, hd *handler.ProductsDefault) {
+		db, uow := newTestUnitOfWork(t)
+
+		err := func() error {
+			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (10, 'product 0', 1, 'A0', false, '2999-12-31', 1, 1)")
+			return err
+		}()
+		require.NoError(t, err)
+
+		hd = handler.NewProductsDefault(service.NewProductsDefault(uow))
+		return
+	}
+	// item returns the body of a product to create
+	item := func(code string, warehouseId int) string {
+		return `{"name":"product ` + code + `","quantity":1,"code_value":"` + code + `","expiration":"2999-12-31","price":1,"warehouse_id":` + strconv.Itoa(warehouseId) + `}`
+	}
+	// results decodes the results of a bulk response
+	results := func(t *testing.T, res *httptest.ResponseRecorder) (rs []handler.BulkItemResultJSON) {
+		var body struct {
+			Data []handler.BulkItemResultJSON `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+		rs = body.Data
+		return
+	}
+	// ids returns the ids of the stored products by code value
+	ids := func(t *testing.T, db *sql.DB) (ids map[string]int) {
+		rows, err := db.Query("SELECT `id`, `code_value` FROM `products`")
+		require.NoError(t, err)
+		defer rows.Close()
+		ids = make(map[string]int)
+		for rows.Next() {
+			var id int
+			var code string
+			require.NoError(t, rows.Scan(&id, &code))
+			ids[code] = id
+		}
+		require.NoError(t, rows.Err())
+		return
+	}
+
+	t.Run("success 01 - atomic, with the id of each product stored", func(t *testing.T) {
+		// arrange
+		db, hd := arrange(t)
+		body := "[" + item("A2", 1) + "," + item("A1", 1) + "]"
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/products/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		hd.CreateBulk()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusCreated, res.Code)
+		stored := ids(t, db)
+		require.Len(t, stored, 3)
+		require.Equal(t, []handler.BulkItemResultJSON{{Index: 0, ID: stored["A2"]}, {Index: 1, ID: stored["A1"]}}, results(t, res))
+	})
+
+	t.Run("success 02 - best effort, with the error of each item that failed", func(t *testing.T) {
+		// arrange
+		db, hd := arrange(t)
+		body := "[" + item("A1", 1) + "," + item("A0", 1) + "," + item("A2", 2) + `,{"name":"product"}]`
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/products/bulk?mode=best-effort", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		hd.CreateBulk()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusMultiStatus, res.Code)
+		stored := ids(t, db)
+		require.Len(t, stored, 2)
+		rs := results(t, res)
+		require.Len(t, rs, 4)
+		require.Equal(t, handler.BulkItemResultJSON{Index: 0, ID: stored["A1"]}, rs[0])
+		require.Equal(t, "product_not_unique", rs[1].Code)
+		require.Equal(t, "product_relation", rs[2].Code)
+		require.Equal(t, "invalid_body", rs[3].Code)
+		require.NotEmpty(t, rs[3].Errors)
+	})
+
+	t.Run("failure 01 - atomic, aborted by an item that failed", func(t *testing.T) {
+		// arrange
+		db, hd := arrange(t)
+		body := "[" + item("A1", 1) + "," + item("A0", 1) + "]"
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/products/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		hd.CreateBulk()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusConflict, res.Code)
+		require.Len(t, ids(t, db), 1)
+		rs := results(t, res)
+		require.Len(t, rs, 2)
+		require.Zero(t, rs[0].ID)
+		require.NotEmpty(t, rs[0].Error)
+		require.NotEmpty(t, rs[1].Error)
+	})
+
+	t.Run("failure 02 - atomic, aborted by an invalid item", func(t *testing.T) {
+		// arrange
+		db, hd := arrange(t)
+		body := "[" + item("A1", 1) + `,{"name":"product"}]`
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/products/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		hd.CreateBulk()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		require.Len(t, ids(t, db), 1)
+		rs := results(t, res)
+		require.Equal(t, "invalid_body", rs[1].Code)
+	})
+
+	t.Run("failure 03 - invalid mode", func(t *testing.T) {
+		// arrange
+		_, hd := arrange(t)
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/products/bulk?mode=partial", strings.NewReader("["+item("A1", 1)+"]"))
+		req.Header.Set("Content-Type", "application/json")
+		hd.CreateBulk()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+	})
+}
+
+func TestProductDefault_UpdateBulk(t *testing.T) {
+	// arrange a database with the products 1 and 2 at version 2
+	arrange := func(t *testing.T) (db *sql.DB, hd *handler.ProductsDefault) {
+		db, uow := newTestUnitOfWork(t)
+
+		err := func() error {
+			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`, `version`) VALUES " +
+				"(1, 'product 1', 1, 'A1', false, '2999-12-31', 1, 1, 2), (2, 'product 2', 1, 'A2', false, '2999-12-31', 1, 1, 2)")
+			return err
+		}()
+		require.NoError(t, err)
+
+		hd = handler.NewProductsDefault(service.NewProductsDefault(uow))
+		return
+	}
+	// quantities returns the stored quantity of the products 1 and 2
+	quantities := func(t *testing.T, db *sql.DB) (q [2]int) {
+		require.NoError(t, db.QueryRow("SELECT `quantity` FROM `products` WHERE `id` = 1").Scan(&q[0]))
+		require.NoError(t, db.QueryRow("SELECT `quantity` FROM `products` WHERE `id` = 2").Scan(&q[1]))
+		return
+	}
+	body := `[{"id":1,"version":2,"quantity":5},{"id":2,"version":1,"quantity":5},{"id":3,"quantity":5}]`
+
+	t.Run("success 01 - best effort, with the error of each item that failed", func(t *testing.T) {
+		// arrange
+		db, hd := arrange(t)
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("PATCH", "/products/bulk?mode=best-effort", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		hd.UpdateBulk()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusMultiStatus, res.Code)
+		require.Equal(t, [2]int{5, 1}, quantities(t, db))
+		var rs struct {
+			Data []handler.BulkItemResultJSON `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &rs))
+		require.Len(t, rs.Data, 3)
+		require.Empty(t, rs.Data[0].Error)
+		require.Equal(t, "product_version_conflict", rs.Data[1].Code)
+		require.Equal(t, "product_not_found", rs.Data[2].Code)
+	})
+
+	t.Run("failure 01 - atomic, aborted by an item that failed", func(t *testing.T) {
+		// arrange
+		db, hd := arrange(t)
+
+		// act
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("PATCH", "/products/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		hd.UpdateBulk()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		require.Equal(t, [2]int{1, 1}, quantities(t, db))
+	})
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProductCSVColumns are the columns of the csv representation of a product
+var ProductCSVColumns = []string{"name", "quantity", "code_value", "is_published", "expiration", "price", "warehouse_id"}
+
+// ImportMaxBytes is the maximum size of a csv upload
+const ImportMaxBytes = 10 << 20
+
+// ImportRowErrorJSON is a struct that represents an error of a row of a csv import in JSON
+type ImportRowErrorJSON struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReportJSON is a struct that represents the report of a csv import in JSON
+type ImportReportJSON struct {
+	Created int                  `json:"created"`
+	Updated int                  `json:"updated"`
+	Failed  int                  `json:"failed"`
+	Errors  []ImportRowErrorJSON `json:"errors"`
+}
+
+// Export writes all products matching the list filters in csv format
+func (h *ProductsDefault) Export() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		if format := r.URL.Query().Get("format"); format != "" && format != "csv" {
+			response.Error(w, http.StatusBadRequest, "unsupported format")
+			return
+		}
+		f, err := productFilter(r)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid filter")
+			return
+		}
+
+		// process
+		products, err := h.sv.GetAll(r.Context(), f)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// response
+		cw := response.CSV(w, http.StatusOK, "products.csv")
+		cw.Write(ProductCSVColumns)
+		for _, p := range products {
+			cw.Write([]string{
+				p.Name,
+				strconv.Itoa(p.Quantity),
+				p.CodeValue,
+				strconv.FormatBool(p.IsPublished),
+				p.Expiration.Format(time.DateOnly),
+				strconv.FormatFloat(p.Price, 'f', -1, 64),
+				strconv.Itoa(p.WarehouseId),
+			})
+		}
+		cw.Flush()
+	}
+}
+
+// Import creates or updates (matching by code value) the products of a csv upload.
+// Invalid rows are skipped and reported.
+func (h *ProductsDefault) Import() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		r.Body = http.MaxBytesReader(w, r.Body, ImportMaxBytes)
+		rd, err := request.CSV(r)
+		if err != nil {
+			switch {
+			case errors.Is(err, request.ErrRequestContentTypeNotCSV):
+				response.Error(w, http.StatusUnsupportedMediaType, "unsupported content type")
+			default:
+				response.Error(w, http.StatusBadRequest, "csv file missing")
+			}
+			return
+		}
+		// - header
+		header, err := rd.Read()
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "csv header missing")
+			return
+		}
+		columns := make(map[string]int)
+		for i, c := range header {
+			columns[strings.ToLower(strings.TrimSpace(c))] = i
+		}
+		for _, c := range ProductCSVColumns {
+			if _, ok := columns[c]; !ok {
+				response.Errorf(w, http.StatusBadRequest, "csv column %s missing", c)
+				return
+			}
+		}
+
+		// process
+		report := ImportReportJSON{Errors: []ImportRowErrorJSON{}}
+		for {
+			record, err := rd.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				var parseErr *csv.ParseError
+				var maxBytesErr *http.MaxBytesError
+				switch {
+				case errors.As(err, &parseErr):
+					report.Failed++
+					report.Errors = append(report.Errors, ImportRowErrorJSON{Row: parseErr.StartLine, Message: response.Localize(w, "invalid csv row")})
+					continue
+				case errors.As(err, &maxBytesErr):
+					response.Error(w, http.StatusRequestEntityTooLarge, "csv file too large")
+				default:
+					response.Error(w, http.StatusBadRequest, "invalid csv file")
+				}
+				return
+			}
+			row, _ := rd.FieldPos(0)
+
+			// - parse row
+			p, rowErr := productFromCSV(record, columns)
+			if rowErr != nil {
+				rowErr.Row = row
+				rowErr.Message = response.Localize(w, rowErr.Message)
+				report.Failed++
+				report.Errors = append(report.Errors, *rowErr)
+				continue
+			}
+
+			// - upsert by code value
+			created, err := h.sv.Import(r.Context(), &p)
+			if err != nil {
+				// - the request was canceled or timed out: the rest of the rows would fail too
+				if errCtx := r.Context().Err(); errCtx != nil {
+					writeError(w, errCtx)
+					return
+				}
+				_, message := bulkError(w, err)
+				rowErr := ImportRowErrorJSON{Row: row, Message: message}
+				var fieldErr *internal.FieldError
+				if errors.As(err, &fieldErr) {
+					rowErr.Column = fieldErr.Field
+				}
+				report.Failed++
+				report.Errors = append(report.Errors, rowErr)
+				continue
+			}
+			if created {
+				report.Created++
+			} else {
+				report.Updated++
+			}
+		}
+
+		// response
+		response.Success(w, http.StatusOK, response.Body{Message: response.Localize(w, "products imported"), Data: report})
+	}
+}
+
+// productFromCSV parses a csv record into a product, the product service validates it
+func productFromCSV(record []string, columns map[string]int) (p internal.Product, rowErr *ImportRowErrorJSON) {
+	field := func(c string) string {
+		i := columns[c]
+		if i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	fail := func(c, message string) *ImportRowErrorJSON {
+		return &ImportRowErrorJSON{Column: c, Message: message}
+	}
+
+	var err error
+	p.Name = field("name")
+	if p.Quantity, err = strconv.Atoi(field("quantity")); err != nil {
+		rowErr = fail("quantity", "quantity must be an integer")
+		return
+	}
+	p.CodeValue = field("code_value")
+	if p.IsPublished, err = strconv.ParseBool(field("is_published")); err != nil {
+		rowErr = fail("is_published", "is_published must be a boolean")
+		return
+	}
+	if p.Expiration, err = time.Parse(time.DateOnly, field("expiration")); err != nil {
+		rowErr = fail("expiration", "expiration must be a date (YYYY-MM-DD)")
+		return
+	}
+	if p.Price, err = strconv.ParseFloat(field("price"), 64); err != nil {
+		rowErr = fail("price", "price must be a number")
+		return
+	}
+	if p.WarehouseId, err = strconv.Atoi(field("warehouse_id")); err != nil {
+		rowErr = fail("warehouse_id", "warehouse_id must be an integer")
+		return
+	}
+	return
+}
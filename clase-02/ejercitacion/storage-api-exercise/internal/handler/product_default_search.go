@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/search"
+	"app/platform/web/response"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ProductHighlightJSON is a struct that represents the name and code value of a product found by a search in JSON,
+// HTML escaped and with the parts matched by the query wrapped in <mark> tags
+type ProductHighlightJSON struct {
+	Name      string `json:"name"`
+	CodeValue string `json:"code_value"`
+}
+
+// ProductMatchJSON is a struct that represents a product found by a search in JSON
+type ProductMatchJSON struct {
+	ProductJSON
+	Score     float64              `json:"score"`
+	Highlight ProductHighlightJSON `json:"highlight"`
+}
+
+// productMatchJSON returns the JSON of a product found by a search
+func productMatchJSON(m internal.ProductMatch) ProductMatchJSON {
+	return ProductMatchJSON{
+		ProductJSON: productJSON(m.Product),
+		Score:       m.Score,
+		Highlight: ProductHighlightJSON{
+			Name:      highlight(m.Name, m.Matched),
+			CodeValue: highlight(m.CodeValue, m.Matched),
+		},
+	}
+}
+
+// highlight returns a text HTML escaped with the parts matched by a search wrapped in <mark> tags
+func highlight(text string, matched map[string]int) string {
+	var b strings.Builder
+	last := 0
+	for _, s := range search.Highlight(text, matched) {
+		b.WriteString(html.EscapeString(text[last:s.Start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[s.Start:s.End]))
+		b.WriteString("</mark>")
+		last = s.End
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// Search returns the products whose name or code value match the query (?q=), also by a prefix or with a typo of their terms,
+// the most relevant first and up to the limit (?limit=), with the fields asked (?fields=id,name)
+func (h *ProductsDefault) Search() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		query := r.URL.Query().Get("q")
+		if strings.TrimSpace(query) == "" {
+			response.Error(w, http.StatusBadRequest, "missing search query")
+			return
+		}
+		limit := internal.ProductSearchLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			var err error
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit <= 0 {
+				response.Error(w, http.StatusBadRequest, "invalid limit")
+				return
+			}
+		}
+		rp, ok := parseRepresentation(w, r)
+		if !ok {
+			return
+		}
+
+		// process
+		ms, err := h.sv.Search(r.Context(), query, limit)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// response
+		matchesJSON := make([]ProductMatchJSON, 0, len(ms))
+		for _, m := range ms {
+			matchesJSON = append(matchesJSON, productMatchJSON(m))
+		}
+		data, ok := rp.sparse(w, matchesJSON)
+		if !ok {
+			return
+		}
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "products found"),
+			Data:    data,
+			Meta:    map[string]any{"count": len(matchesJSON)},
+			Links:   map[string]string{"self": r.URL.RequestURI()},
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/service"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductDefault_Search(t *testing.T) {
+	t.Run("success 01 - products found with the parts matched highlighted", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+		require.NoError(t, err)
+
+		sv := service.NewProductsDefault(uow)
+		for _, p := range []internal.Product{
+			{Name: "Dulce de leche", Quantity: 1, CodeValue: "DL-1", Expiration: time.Now().AddDate(1, 0, 0), Price: 10, WarehouseId: 1},
+			{Name: "Leche <entera>", Quantity: 1, CodeValue: "LE-1", Expiration: time.Now().AddDate(1, 0, 0), Price: 10, WarehouseId: 1},
+			{Name: "Yerba mate", Quantity: 1, CodeValue: "YM-1", Expiration: time.Now().AddDate(1, 0, 0), Price: 10, WarehouseId: 1},
+		} {
+			require.NoError(t, sv.Create(context.Background(), &p))
+		}
+		hd := handler.NewProductsDefault(sv)
+
+		//act
+		req := httptest.NewRequest("GET", "/products/search?q=lech+enter&fields=code_value,highlight", nil)
+		res := httptest.NewRecorder()
+		hd.Search()(res, req)
+
+		// assert
+		expectedCode := http.StatusOK
+		expectedBody := `{"data": [
+			{"code_value": "LE-1", "highlight": {"name": "<mark>Lech</mark>e &lt;<mark>enter</mark>a&gt;", "code_value": "LE-1"}},
+			{"code_value": "DL-1", "highlight": {"name": "Dulce de <mark>lech</mark>e", "code_value": "DL-1"}}
+		], "message": "products found"}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("success 02 - up to the limit", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+		require.NoError(t, err)
+
+		sv := service.NewProductsDefault(uow)
+		for _, code := range []string{"A1", "A2", "A3"} {
+			p := internal.Product{Name: "Galletas " + code, Quantity: 1, CodeValue: code, Expiration: time.Now().AddDate(1, 0, 0), Price: 10, WarehouseId: 1}
+			require.NoError(t, sv.Create(context.Background(), &p))
+		}
+		hd := handler.NewProductsDefault(sv)
+
+		//act
+		req := httptest.NewRequest("GET", "/products/search?q=galletas&limit=2&fields=id", nil)
+		res := httptest.NewRecorder()
+		hd.Search()(res, req)
+
+		// assert
+		expectedCode := http.StatusOK
+		expectedBody := `{"data": [{"id": 1}, {"id": 2}], "message": "products found"}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("failure 01 - missing search query", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		//act
+		req := httptest.NewRequest("GET", "/products/search?q=+", nil)
+		res := httptest.NewRecorder()
+		hd.Search()(res, req)
+
+		// assert
+		expectedCode := http.StatusBadRequest
+		expectedBody := `{"type":"about:blank", "title":"Bad Request", "status":400, "code":"bad_request", "detail":"missing search query"}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("failure 02 - invalid limit", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		//act
+		req := httptest.NewRequest("GET", "/products/search?q=leche&limit=0", nil)
+		res := httptest.NewRecorder()
+		hd.Search()(res, req)
+
+		// assert
+		expectedCode := http.StatusBadRequest
+		expectedBody := `{"type":"about:blank", "title":"Bad Request", "status":400, "code":"bad_request", "detail":"invalid limit"}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+}
@@ -1,30 +1,42 @@
 package handler_test
 
 import (
+	"app/internal"
 	"app/internal/handler"
-	"app/internal/repository"
+	"app/internal/service"
+	"app/platform/web/codec"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"bytes"
+	"context"
 	"database/sql"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/require"
 )
 
 func TestProductDefault_GetAll(t *testing.T) {
 	t.Run("success 01 - products found", func(t *testing.T) {
 		// arrange
-		db, err := sql.Open("txdb", "my_db_test")
-		require.NoError(t, err)
+		db, uow := newTestUnitOfWork(t)
 
-		err = func() error {
-			_, err := db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (1, 'product 1', 100, 'code_value 1', true, '2021-12-31', 100, 1)")
+		err := func() error {
+			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (1, 'product 1', 100, 'code_value 1', true, '2021-12-31', 100, 1)")
 			return err
 		}()
 		require.NoError(t, err)
 
-		rp := repository.NewProductsMySQL(db)
-		hd := handler.NewProductsDefault(rp)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
 
 		//act
 		req := httptest.NewRequest("GET", "/products", nil)
@@ -41,4 +53,470 @@ func TestProductDefault_GetAll(t *testing.T) {
 		require.Equal(t, expectedHeader, res.Header())
 	})
 
+	t.Run("success 02 - fields asked with the warehouse included", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+
+		err := func() error {
+			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (1, 'product 1', 100, 'code_value 1', true, '2021-12-31', 100, 1)")
+			return err
+		}()
+		require.NoError(t, err)
+
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		//act
+		req := httptest.NewRequest("GET", "/products?fields=price,id,name&include=warehouse", nil)
+		res := httptest.NewRecorder()
+		hd.GetAll()(res, req)
+
+		// assert
+		expectedCode := http.StatusOK
+		expectedBody := `{"data": [{"id": 1,"name": "product 1","price": 100,"warehouse": {"id": 1,"name": "warehouse 1","address": "address 1","telephone": "telephone 1","capacity": 100}}],"message": "products found"}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("success 03 - fields asked written as csv", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+
+		err := func() error {
+			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (1, 'product 1', 100, 'code_value 1', true, '2021-12-31', 100, 1)")
+			return err
+		}()
+		require.NoError(t, err)
+
+		hd := response.Negotiated(handler.NewProductsDefault(service.NewProductsDefault(uow)).GetAll())
+
+		//act
+		req := httptest.NewRequest("GET", "/products?fields=id,name", nil)
+		req.Header.Set("Accept", "text/csv")
+		res := httptest.NewRecorder()
+		hd.ServeHTTP(res, req)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, "id,name\n1,product 1\n", res.Body.String())
+	})
+
+	t.Run("failure 04 - unknown field", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		//act
+		req := httptest.NewRequest("GET", "/products?fields=id,warehouse", nil)
+		res := httptest.NewRecorder()
+		hd.GetAll()(res, req)
+
+		// assert
+		expectedCode := http.StatusBadRequest
+		expectedBody := `{"type":"about:blank", "title":"Bad Request", "status":400, "code":"bad_request", "detail":"unknown field warehouse"}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("failure 05 - unknown include", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		//act
+		req := httptest.NewRequest("GET", "/products?include=products", nil)
+		res := httptest.NewRecorder()
+		hd.GetAll()(res, req)
+
+		// assert
+		expectedCode := http.StatusBadRequest
+		expectedBody := `{"type":"about:blank", "title":"Bad Request", "status":400, "code":"bad_request", "detail":"unknown include products"}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("failure 01 - request deadline exceeded", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		//act
+		req := httptest.NewRequest("GET", "/products", nil).WithContext(ctx)
+		res := httptest.NewRecorder()
+		hd.GetAll()(res, req)
+
+		// assert
+		expectedCode := http.StatusGatewayTimeout
+		expectedBody := `{"type":"/problems/request_timeout", "title":"Request timed out", "status":504, "code":"request_timeout"}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("failure 02 - client closed request", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		//act
+		req := httptest.NewRequest("GET", "/products", nil).WithContext(ctx)
+		res := httptest.NewRecorder()
+		hd.GetAll()(res, req)
+
+		// assert
+		expectedCode := response.StatusClientClosedRequest
+		expectedBody := `{"type":"/problems/client_closed_request", "title":"Client closed request", "status":499, "code":"client_closed_request"}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("failure 03 - internal error without details", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+		require.NoError(t, db.Close())
+
+		//act
+		req := httptest.NewRequest("GET", "/products", nil)
+		req.Header.Set("X-Request-Id", "id")
+		res := httptest.NewRecorder()
+		request.ID(hd.GetAll()).ServeHTTP(res, req)
+
+		// assert
+		expectedCode := http.StatusInternalServerError
+		expectedBody := `{"type":"/problems/internal_error", "title":"Internal server error", "status":500, "code":"internal_error", "request_id":"id"}`
+		expectedHeader := http.Header{"Content-Type": []string{"application/problem+json"}, "X-Request-Id": []string{"id"}}
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+		require.Equal(t, expectedHeader, res.Header())
+	})
+}
+
+func TestProductDefault_Create(t *testing.T) {
+	t.Run("failure 01 - invalid body", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		//act
+		body := `{"name":"", "quantity":-1, "code_value":"code_value 1", "expiration":"2020-01-01", "price":10, "warehouse_id":0}`
+		req := httptest.NewRequest("POST", "/products", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+		hd.Create()(res, req)
+
+		// assert
+		expectedCode := http.StatusUnprocessableEntity
+		expectedBody := `{"type":"/problems/invalid_body", "title":"Request body breaks a validation rule", "status":422, "code":"invalid_body", "errors":[
+			{"field":"name", "code":"required", "message":"name is required"},
+			{"field":"quantity", "code":"min", "message":"quantity must be at least 0"},
+			{"field":"expiration", "code":"notpast", "message":"expiration must not be in the past"},
+			{"field":"warehouse_id", "code":"gt", "message":"warehouse_id must be greater than 0"}
+		]}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("failure 02 - invalid body in the language of the client", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		//act
+		body := `{"name":"", "quantity":1, "code_value":"code_value 1", "expiration":"2999-01-01", "price":10, "warehouse_id":1}`
+		req := httptest.NewRequest("POST", "/products", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", "es-AR,es;q=0.9")
+		res := httptest.NewRecorder()
+		request.Language(hd.Create()).ServeHTTP(res, req)
+
+		// assert
+		expectedCode := http.StatusUnprocessableEntity
+		expectedBody := `{"type":"/problems/invalid_body", "title":"El cuerpo de la solicitud no cumple una regla de validación", "status":422, "code":"invalid_body", "errors":[
+			{"field":"name", "code":"required", "message":"name es obligatorio"}
+		]}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+		require.Equal(t, "es", res.Header().Get("Content-Language"))
+	})
+}
+
+// staleProductService is a product service that reads a product as it was before its last update,
+// as a handler does when the product changes between its read and its write
+type staleProductService struct {
+	internal.ProductService
+	// stale is the product as it was
+	stale internal.Product
+}
+
+// GetOne returns the product as it was
+func (s *staleProductService) GetOne(ctx context.Context, id int) (p internal.Product, err error) {
+	p = s.stale
+	return
+}
+
+func TestProductDefault_Delete(t *testing.T) {
+	// newRequest returns a request to delete the product 1 with an If-Match header
+	newRequest := func(ifMatch string) *http.Request {
+		req := httptest.NewRequest("DELETE", "/products/1", nil)
+		req.Header.Set("If-Match", ifMatch)
+		chiCtx := chi.NewRouteContext()
+		chiCtx.URLParams.Add("id", "1")
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	}
+
+	t.Run("success 01 - current version", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+
+		err := func() error {
+			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`, `version`) VALUES (1, 'product 1', 100, 'code_value 1', true, '2021-12-31', 100, 1, 2)")
+			return err
+		}()
+		require.NoError(t, err)
+
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Delete()(res, newRequest(`"2"`))
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, `{"message":"product deleted", "data":1}`, res.Body.String())
+	})
+
+	t.Run("failure 01 - stale version", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+
+		err := func() error {
+			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`, `version`) VALUES (1, 'product 1', 100, 'code_value 1', true, '2021-12-31', 100, 1, 2)")
+			return err
+		}()
+		require.NoError(t, err)
+
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Delete()(res, newRequest(`"1"`))
+
+		// assert
+		require.Equal(t, http.StatusPreconditionFailed, res.Code)
+		var count int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM `products`").Scan(&count))
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("failure 02 - updated between the check and the delete", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+
+		err := func() error {
+			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`, `version`) VALUES (1, 'product 1', 100, 'code_value 1', true, '2021-12-31', 100, 1, 2)")
+			return err
+		}()
+		require.NoError(t, err)
+
+		sv := &staleProductService{ProductService: service.NewProductsDefault(uow), stale: internal.Product{ID: 1, Version: 1}}
+		hd := handler.NewProductsDefault(sv)
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Delete()(res, newRequest(`"1"`))
+
+		// assert
+		require.Equal(t, http.StatusPreconditionFailed, res.Code)
+		var count int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM `products`").Scan(&count))
+		require.Equal(t, 1, count)
+	})
+}
+
+func TestProductDefault_Update(t *testing.T) {
+	// arrange a database with the product 1 at version 2
+	arrange := func(t *testing.T) (db *sql.DB, uow internal.UnitOfWork) {
+		db, uow = newTestUnitOfWork(t)
+
+		err := func() error {
+			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`, `version`) VALUES (1, 'product 1', 100, 'code_value 1', true, '2999-12-31', 100, 1, 2)")
+			return err
+		}()
+		require.NoError(t, err)
+		return
+	}
+	// newRequest returns a request to patch the product 1
+	newRequest := func(contentType string, body io.Reader, ifMatch string) *http.Request {
+		req := httptest.NewRequest("PATCH", "/products/1", body)
+		req.Header.Set("Content-Type", contentType)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		chiCtx := chi.NewRouteContext()
+		chiCtx.URLParams.Add("id", "1")
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	}
+	// quantity returns the stored quantity of the product 1
+	quantity := func(t *testing.T, db *sql.DB) (q int) {
+		require.NoError(t, db.QueryRow("SELECT `quantity` FROM `products` WHERE `id` = 1").Scan(&q))
+		return
+	}
+	expectedBody := `{"message":"product updated", "data":{"id":1, "name":"product 1", "quantity":5, "code_value":"code_value 1", "is_published":true, "expiration":"2999-12-31", "price":100, "warehouse_id":1}}`
+
+	t.Run("success 01 - fields of a json body", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest("application/json", strings.NewReader(`{"quantity":5}`), `"2"`))
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+		require.Equal(t, `"3"`, res.Header().Get("ETag"))
+		require.Equal(t, 5, quantity(t, db))
+	})
+
+	t.Run("success 02 - json merge patch", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest(request.ContentTypeMergePatch, strings.NewReader(`{"quantity":5}`), ""))
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+		require.Equal(t, 5, quantity(t, db))
+	})
+
+	t.Run("success 03 - json patch", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		body := `[{"op":"test","path":"/quantity","value":100},{"op":"replace","path":"/quantity","value":5}]`
+		hd.Update()(res, newRequest(request.ContentTypeJSONPatch, strings.NewReader(body), ""))
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+		require.Equal(t, 5, quantity(t, db))
+	})
+
+	t.Run("success 04 - fields of a msgpack body", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+		var body bytes.Buffer
+		require.NoError(t, codec.Encode(&body, codec.MessagePack, map[string]any{"quantity": 5}))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest(codec.MessagePack, &body, ""))
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+		require.Equal(t, 5, quantity(t, db))
+	})
+
+	t.Run("failure 01 - stale version", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest("application/json", strings.NewReader(`{"quantity":5}`), `"1"`))
+
+		// assert
+		require.Equal(t, http.StatusPreconditionFailed, res.Code)
+		require.Contains(t, res.Body.String(), `"code":"product_version_conflict"`)
+		require.Equal(t, 100, quantity(t, db))
+	})
+
+	t.Run("failure 02 - updated between the read and the update", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		sv := &staleProductService{ProductService: service.NewProductsDefault(uow), stale: internal.Product{
+			ID: 1, Name: "product 1", Quantity: 100, CodeValue: "code_value 1", IsPublished: true,
+			Expiration: time.Date(2999, 12, 31, 0, 0, 0, 0, time.UTC), Price: 100, WarehouseId: 1, Version: 1,
+		}}
+		hd := handler.NewProductsDefault(sv)
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest(request.ContentTypeMergePatch, strings.NewReader(`{"quantity":5}`), ""))
+
+		// assert
+		require.Equal(t, http.StatusPreconditionFailed, res.Code)
+		require.Contains(t, res.Body.String(), `"code":"product_version_conflict"`)
+		require.Equal(t, 100, quantity(t, db))
+	})
+
+	t.Run("failure 03 - json patch test failed", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		body := `[{"op":"test","path":"/quantity","value":1},{"op":"replace","path":"/quantity","value":5}]`
+		hd.Update()(res, newRequest(request.ContentTypeJSONPatch, strings.NewReader(body), ""))
+
+		// assert
+		require.Equal(t, http.StatusConflict, res.Code)
+		require.Equal(t, 100, quantity(t, db))
+	})
+
+	t.Run("failure 04 - unsupported patch format", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		hd := handler.NewProductsDefault(service.NewProductsDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest("text/plain", strings.NewReader(`quantity=5`), ""))
+
+		// assert
+		require.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+		require.Equal(t, request.ContentTypeMergePatch+", "+request.ContentTypeJSONPatch, res.Header().Get("Accept-Patch"))
+		require.Equal(t, 100, quantity(t, db))
+	})
 }
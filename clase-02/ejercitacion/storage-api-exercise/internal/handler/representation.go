@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+// representation is a struct that represents the representation of a resource asked by the query of a request:
+// its fields (?fields=id,name) and the related resources embedded in it (?include=warehouse)
+type representation struct {
+	// fields are the fields asked, all of them when empty
+	fields []string
+	// include are the related resources asked
+	include []string
+}
+
+// parseRepresentation returns the representation asked by a request, relations are the resources that can be included.
+// It writes the error response and returns false when a relation is unknown
+func parseRepresentation(w http.ResponseWriter, r *http.Request, relations ...string) (rp representation, ok bool) {
+	rp.fields = request.QueryList(r, "fields")
+	rp.include = request.QueryList(r, "include")
+	for _, relation := range rp.include {
+		if !slices.Contains(relations, relation) {
+			response.Errorf(w, http.StatusBadRequest, "unknown include %s", relation)
+			return
+		}
+	}
+	ok = true
+	return
+}
+
+// includes reports whether a related resource is asked
+func (rp representation) includes(relation string) bool {
+	return slices.Contains(rp.include, relation)
+}
+
+// sparse returns data with only the fields asked, along with the related resources included, or data as is when none are asked.
+// It writes the error response and returns false when a field is unknown
+func (rp representation) sparse(w http.ResponseWriter, data any) (sparse any, ok bool) {
+	names := rp.fields
+	if len(names) > 0 {
+		names = append(slices.Clone(names), rp.include...)
+	}
+
+	sparse, err := response.Fields(data, names)
+	if err != nil {
+		var errField *response.FieldUnknownError
+		switch {
+		case errors.As(err, &errField):
+			response.Errorf(w, http.StatusBadRequest, "unknown field %s", errField.Name)
+		default:
+			writeError(w, err)
+		}
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,4 @@
+// Package storagepb holds the protobuf messages and gRPC services of storage.proto
+package storagepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative storage.proto
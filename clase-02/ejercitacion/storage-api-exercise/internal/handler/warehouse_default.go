@@ -2,22 +2,27 @@ package handler
 
 import (
 	"app/internal"
+	"app/platform/validate"
+	"app/platform/web/request"
 	"app/platform/web/response"
-	"encoding/json"
 	"errors"
+	"fmt"
+	"hash/fnv"
 	"net/http"
+	"path"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
 )
 
 type WarehouseDefault struct {
-	rp internal.WarehouseRepository
+	// sv is the warehouse service
+	sv internal.WarehouseService
 }
 
-func NewWarehouseDefault(rp internal.WarehouseRepository) *WarehouseDefault {
+func NewWarehouseDefault(sv internal.WarehouseService) *WarehouseDefault {
 	return &WarehouseDefault{
-		rp: rp,
+		sv: sv,
 	}
 }
 
@@ -30,82 +35,166 @@ type WarehouseJSON struct {
 }
 
 type BodyWarehouseJSON struct {
-	Name      string `json:"name"`
-	Address   string `json:"address"`
-	Telephone string `json:"telephone"`
-	Capacity  int    `json:"capacity"`
+	Name      string `json:"name" validate:"required"`
+	Address   string `json:"address" validate:"required"`
+	Telephone string `json:"telephone" validate:"required"`
+	Capacity  int    `json:"capacity" validate:"gt=0"`
+}
+
+// RequestBodyWarehouseCreate is the body of a warehouse creation, with the ids of the products to move into it
+type RequestBodyWarehouseCreate struct {
+	BodyWarehouseJSON
+	ProductIds []int `json:"product_ids,omitempty"`
 }
 
 type ReportProduct struct {
 	Name         string `json:"name"`
-	ProductCount int    `json: "product_count"`
+	ProductCount int    `json:"product_count"`
+}
+
+// WarehouseProductsJSON is a struct that represents a warehouse with its products embedded in JSON (?include=products)
+type WarehouseProductsJSON struct {
+	WarehouseJSON
+	Products []ProductJSON `json:"products"`
+}
+
+// warehouseJSON returns the JSON of a warehouse
+func warehouseJSON(w internal.Warehouse) WarehouseJSON {
+	return WarehouseJSON{
+		Id:        w.Id,
+		Name:      w.Name,
+		Address:   w.Address,
+		Telephone: w.Telephone,
+		Capacity:  w.Capacity,
+	}
+}
+
+// warehouseProductsJSON returns the JSON of a warehouse with its products
+func warehouseProductsJSON(w internal.WarehouseProducts) WarehouseProductsJSON {
+	products := make([]ProductJSON, 0, len(w.Products))
+	for _, p := range w.Products {
+		products = append(products, productJSON(p))
+	}
+	return WarehouseProductsJSON{
+		WarehouseJSON: warehouseJSON(w.Warehouse),
+		Products:      products,
+	}
+}
+
+// warehouseETag returns the entity tag of the current version of a warehouse
+func warehouseETag(w internal.Warehouse) string {
+	return fmt.Sprintf("\"%d\"", w.Version)
 }
 
+// warehouseProductsETag returns the entity tag of a warehouse with its products, weak since it changes with any of them
+// and so it is not the one of the warehouse a write must match
+func warehouseProductsETag(w internal.WarehouseProducts) string {
+	h := fnv.New64a()
+	for _, p := range w.Products {
+		fmt.Fprintf(h, "%d:%d,", p.ID, p.Version)
+	}
+	return fmt.Sprintf("W/\"%d-%x\"", w.Version, h.Sum64())
+}
+
+// GetAll returns all warehouses, with the fields asked (?fields=id,name) and their products when they are included (?include=products)
 func (h *WarehouseDefault) GetAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		warehouses, err := h.rp.GetAll()
-		if err != nil {
-			switch {
-			case errors.Is(err, internal.ErrWarehouseNotFound):
-				response.Error(w, http.StatusNotFound, "warehouse not found")
+		rp, ok := parseRepresentation(w, r, "products")
+		if !ok {
+			return
+		}
+
+		var data any
+		var count int
+		switch {
+		case rp.includes("products"):
+			warehouses, err := h.sv.GetAllWithProducts(r.Context())
+			if err != nil {
+				writeError(w, err)
 				return
-			default:
-				response.Error(w, http.StatusInternalServerError, "internal server error"+err.Error())
+			}
+
+			warehousesJSON := make([]WarehouseProductsJSON, 0, len(warehouses))
+			for _, w := range warehouses {
+				warehousesJSON = append(warehousesJSON, warehouseProductsJSON(w))
+			}
+			data, count = warehousesJSON, len(warehousesJSON)
+		default:
+			warehouses, err := h.sv.GetAll(r.Context())
+			if err != nil {
+				writeError(w, err)
 				return
 			}
-		}
 
-		var wawrehousesJSON []WarehouseJSON
-		for _, w := range warehouses {
-			wawrehousesJSON = append(wawrehousesJSON, WarehouseJSON{
-				Id:        w.Id,
-				Name:      w.Name,
-				Address:   w.Address,
-				Telephone: w.Telephone,
-				Capacity:  w.Capacity,
-			})
+			warehousesJSON := make([]WarehouseJSON, 0, len(warehouses))
+			for _, w := range warehouses {
+				warehousesJSON = append(warehousesJSON, warehouseJSON(w))
+			}
+			data, count = warehousesJSON, len(warehousesJSON)
+		}
+		if data, ok = rp.sparse(w, data); !ok {
+			return
 		}
 
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message":    "warehouses found",
-			"warehouses": wawrehousesJSON,
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "warehouses found"),
+			Key:     "warehouses",
+			Data:    data,
+			Meta:    map[string]any{"count": count},
+			Links:   map[string]string{"self": r.URL.RequestURI()},
 		})
 	}
 }
+
+// GetOne returns a warehouse by id, with the fields asked (?fields=id,name) and its products when they are included (?include=products)
 func (h *WarehouseDefault) GetOne() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// requests
-		id := chi.URLParam(r, "id")
-		idInt, err := strconv.Atoi(id)
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
 			response.Error(w, http.StatusBadRequest, "invalid id")
 			return
 		}
+		rp, ok := parseRepresentation(w, r, "products")
+		if !ok {
+			return
+		}
 
 		// process
-		warehouse, err := h.rp.GetOne(idInt)
-		if err != nil {
-			switch {
-			case errors.Is(err, internal.ErrWarehouseNotFound):
-				response.Error(w, http.StatusNotFound, "warehouse not found")
-			default:
-				response.Error(w, http.StatusInternalServerError, "internal server error")
+		var data any
+		var etag string
+		switch {
+		case rp.includes("products"):
+			warehouse, err := h.sv.GetOneWithProducts(r.Context(), id)
+			if err != nil {
+				writeError(w, err)
+				return
 			}
+			data, etag = warehouseProductsJSON(warehouse), warehouseProductsETag(warehouse)
+		default:
+			warehouse, err := h.sv.GetOne(r.Context(), id)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			data, etag = warehouseJSON(warehouse), warehouseETag(warehouse)
+		}
+		// - check the client cached version
+		if request.IfNoneMatch(r, etag) {
+			response.NotModified(w, etag)
 			return
 		}
 
 		// serialize response
-		warehouseJSON := WarehouseJSON{
-			Id:        warehouse.Id,
-			Name:      warehouse.Name,
-			Address:   warehouse.Address,
-			Telephone: warehouse.Telephone,
-			Capacity:  warehouse.Capacity,
+		if data, ok = rp.sparse(w, data); !ok {
+			return
 		}
-
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message":   "warehouse found",
-			"warehouse": warehouseJSON,
+		response.ETag(w, etag)
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "warehouse found"),
+			Key:     "warehouse",
+			Data:    data,
+			Links:   map[string]string{"self": r.URL.Path},
 		})
 	}
 }
@@ -113,12 +202,16 @@ func (h *WarehouseDefault) GetOne() http.HandlerFunc {
 func (h *WarehouseDefault) Store() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// requests
-		var warehouseJSON BodyWarehouseJSON
-		err := json.NewDecoder(r.Body).Decode(&warehouseJSON)
-		if err != nil {
-			response.Error(w, http.StatusBadRequest, "invalid request")
+		var body RequestBodyWarehouseCreate
+		if err := request.Body(r, &body); err != nil {
+			writeBodyError(w, err)
+			return
+		}
+		if err := validate.Struct(body); err != nil {
+			writeError(w, err)
 			return
 		}
+		warehouseJSON := body.BodyWarehouseJSON
 
 		//serialize request
 		warehouse := internal.Warehouse{
@@ -127,26 +220,115 @@ func (h *WarehouseDefault) Store() http.HandlerFunc {
 			Telephone: warehouseJSON.Telephone,
 			Capacity:  warehouseJSON.Capacity,
 		}
-		err = h.rp.Store(&warehouse)
+		err := h.sv.Create(r.Context(), &warehouse, body.ProductIds...)
 		if err != nil {
 			switch {
-			case errors.Is(err, internal.ErrWarehouseAlreadyExists):
-				response.Error(w, http.StatusConflict, "warehouse already exists")
+			case errors.Is(err, internal.ErrWarehouseCapacityExceeded):
+				writeErrorDetail(w, err, "products to move exceed the capacity")
+			case errors.Is(err, internal.ErrProductNotFound):
+				response.WriteProblem(w, response.Problem{Status: http.StatusConflict, Code: "product_to_move_not_found", Title: "Product to move not found"})
 			default:
-				response.Error(w, http.StatusInternalServerError, "internal server error")
+				writeError(w, err)
 			}
 			return
 		}
 
 		// serialize response
-		response.JSON(w, http.StatusCreated, map[string]any{
-			"message": "warehouse created",
-			"data":    warehouseJSON,
+		data := WarehouseJSON{
+			Id:        warehouse.Id,
+			Name:      warehouse.Name,
+			Address:   warehouse.Address,
+			Telephone: warehouse.Telephone,
+			Capacity:  warehouse.Capacity,
+		}
+		response.ETag(w, warehouseETag(warehouse))
+		response.Success(w, http.StatusCreated, response.Body{
+			Message: response.Localize(w, "warehouse created"),
+			Data:    data,
+			Links:   map[string]string{"self": path.Join(r.URL.Path, strconv.Itoa(warehouse.Id))},
 		})
 
 	}
 }
 
+// Update patches a warehouse by id
+func (h *WarehouseDefault) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// requests
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		// - get warehouse
+		warehouse, err := h.sv.GetOne(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		// - check the client has the current version
+		if !request.IfMatch(r, warehouseETag(warehouse)) {
+			writeError(w, internal.ErrWarehouseVersionConflict)
+			return
+		}
+		// - patch warehouse
+		body := BodyWarehouseJSON{
+			Name:      warehouse.Name,
+			Address:   warehouse.Address,
+			Telephone: warehouse.Telephone,
+			Capacity:  warehouse.Capacity,
+		}
+		if err := request.Patch(r, &body); err != nil {
+			switch {
+			case errors.Is(err, request.ErrRequestContentTypeNotPatch):
+				w.Header().Set("Accept-Patch", request.ContentTypeMergePatch+", "+request.ContentTypeJSONPatch)
+				response.Error(w, http.StatusUnsupportedMediaType, "unsupported patch format")
+			case errors.Is(err, request.ErrRequestPatchTestFailed):
+				response.Error(w, http.StatusConflict, "patch test failed")
+			default:
+				response.Error(w, http.StatusBadRequest, "invalid request")
+			}
+			return
+		}
+		if err := validate.Struct(body); err != nil {
+			writeError(w, err)
+			return
+		}
+		warehouse.Name = body.Name
+		warehouse.Address = body.Address
+		warehouse.Telephone = body.Telephone
+		warehouse.Capacity = body.Capacity
+		// - update warehouse
+		if err := h.sv.Update(r.Context(), &warehouse); err != nil {
+			switch {
+			case errors.Is(err, internal.ErrWarehouseCapacityExceeded):
+				writeErrorDetail(w, err, "capacity is below the products of the warehouse")
+			default:
+				writeError(w, err)
+			}
+			return
+		}
+
+		// serialize response
+		warehouseJSON := WarehouseJSON{
+			Id:        warehouse.Id,
+			Name:      warehouse.Name,
+			Address:   warehouse.Address,
+			Telephone: warehouse.Telephone,
+			Capacity:  warehouse.Capacity,
+		}
+		response.ETag(w, warehouseETag(warehouse))
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "warehouse updated"),
+			Key:     "warehouse",
+			Data:    warehouseJSON,
+			Links:   map[string]string{"self": r.URL.Path},
+		})
+	}
+}
+
 func (h *WarehouseDefault) ReportProduct() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := r.URL.Query().Get("id")
@@ -155,19 +337,13 @@ func (h *WarehouseDefault) ReportProduct() http.HandlerFunc {
 			idInt = 0
 		}
 
-		rp, err := h.rp.ReportProducts(idInt)
+		rp, err := h.sv.ReportProducts(r.Context(), idInt)
 		if err != nil {
-			switch {
-			case errors.Is(err, internal.ErrWarehouseNotFound):
-				response.Error(w, http.StatusNotFound, "warehouse not found")
-				return
-			default:
-				response.Error(w, http.StatusInternalServerError, "internal server error"+err.Error())
-				return
-			}
+			writeError(w, err)
+			return
 		}
 
-		var reportsProduct []ReportProduct
+		reportsProduct := make([]ReportProduct, 0, len(rp))
 		for _, v := range rp {
 			reportsProduct = append(reportsProduct, ReportProduct{
 				Name:         v.Name,
@@ -175,9 +351,10 @@ func (h *WarehouseDefault) ReportProduct() http.HandlerFunc {
 			})
 		}
 
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "generate report product success",
-			"data":    reportsProduct,
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "generate report product success"),
+			Data:    reportsProduct,
+			Meta:    map[string]any{"count": len(reportsProduct)},
 		})
 
 	}
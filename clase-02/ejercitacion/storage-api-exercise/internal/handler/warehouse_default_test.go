@@ -1,49 +1,34 @@
 package handler_test
 
 import (
+	"app/internal"
 	"app/internal/handler"
-	"app/internal/repository"
+	"app/internal/service"
+	"app/platform/web/request"
+	"app/platform/web/response"
 	"context"
 	"database/sql"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strings"
 	"testing"
 
-	"github.com/DATA-DOG/go-txdb"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/require"
 )
 
-func init() {
-	cfg := mysql.Config{
-		User:                 "user1",
-		Passwd:               "secret_password",
-		Net:                  "tcp",
-		Addr:                 "localhost:3306",
-		DBName:               "my_db_test",
-		ParseTime:            true,
-		AllowNativePasswords: true, // Enable MySQL native password authentication
-	}
-	txdb.Register("txdb", "mysql", cfg.FormatDSN())
-}
-
 func TestWarehouseDefault_GetAll(t *testing.T) {
 	t.Run("success 01 - warehouse found", func(t *testing.T) {
 		// arrange
-		db, err := sql.Open("txdb", os.Getenv("DB_NAME_TEST"))
-		require.NoError(t, err)
+		db, uow := newTestUnitOfWork(t)
 
-		err = func() error {
+		err := func() error {
 			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
 			return err
 		}()
 		require.NoError(t, err)
 
-		rp := repository.NewWarehouseMySQL(db)
-		hd := handler.NewWarehouseDefault(rp)
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
 
 		//act
 		req := httptest.NewRequest("GET", "/warehouses", nil)
@@ -65,17 +50,15 @@ func TestWarehouseDefault_GetAll(t *testing.T) {
 func TestWarehouseDefault_GetByID(t *testing.T) {
 	t.Run("success 01 - warehouse found", func(t *testing.T) {
 		// arrange
-		db, err := sql.Open("txdb", os.Getenv("DB_NAME_TEST"))
-		require.NoError(t, err)
+		db, uow := newTestUnitOfWork(t)
 
-		err = func() error {
+		err := func() error {
 			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
 			return err
 		}()
 		require.NoError(t, err)
 
-		rp := repository.NewWarehouseMySQL(db)
-		hd := handler.NewWarehouseDefault(rp)
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
 
 		req := httptest.NewRequest("GET", "/warehouses/1", nil)
 		chiCtx := chi.NewRouteContext()
@@ -87,22 +70,52 @@ func TestWarehouseDefault_GetByID(t *testing.T) {
 		// assert
 		expectedCode := http.StatusOK
 		expectedBody := `{"message":"warehouse found", "warehouse":{"address":"address 1", "capacity":100, "id":1, "name":"warehouse 1", "telephone":"telephone 1"}}`
-		expectedHeader := http.Header{"Content-Type": []string{"application/json"}}
+		expectedHeader := http.Header{"Content-Type": []string{"application/json"}, "Etag": []string{`"1"`}}
 		require.Equal(t, expectedCode, res.Code)
 		require.JSONEq(t, expectedBody, res.Body.String())
 		require.Equal(t, expectedHeader, res.Header())
 
 	})
+
+	t.Run("success 02 - products included, with the etag of both", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+
+		err := func() error {
+			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (1, 'product 1', 100, 'code_value 1', true, '2021-12-31', 100, 1)")
+			return err
+		}()
+		require.NoError(t, err)
+
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
+
+		req := httptest.NewRequest("GET", "/warehouses/1?fields=id,name&include=products", nil)
+		chiCtx := chi.NewRouteContext()
+		chiCtx.URLParams.Add("id", "1")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+		res := httptest.NewRecorder()
+		hd.GetOne()(res, req)
+
+		// assert
+		expectedCode := http.StatusOK
+		expectedBody := `{"message":"warehouse found", "warehouse":{"id":1, "name":"warehouse 1", "products":[
+			{"id": 1,"name": "product 1","quantity": 100,"code_value": "code_value 1","is_published": true,"expiration": "2021-12-31","price": 100,"warehouse_id": 1}
+		]}}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+		require.True(t, strings.HasPrefix(res.Header().Get("ETag"), `W/"1-`))
+	})
 }
 
 func TestWarehouseDefault_Store(t *testing.T) {
 	t.Run("success 01 - warehouse stored", func(t *testing.T) {
 		// arrange
-		db, err := sql.Open("txdb", os.Getenv("DB_NAME_TEST"))
-		require.NoError(t, err)
-
-		rp := repository.NewWarehouseMySQL(db)
-		hd := handler.NewWarehouseDefault(rp)
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
 
 		req := httptest.NewRequest("POST", "/warehouses", strings.NewReader(`{"name":"warehouse 1", "address":"address 1", "telephone":"telephone 1", "capacity":100}`))
 		res := httptest.NewRecorder()
@@ -112,6 +125,7 @@ func TestWarehouseDefault_Store(t *testing.T) {
 		expectedCode := http.StatusCreated
 		expectedBody := `{
 			"data": {
+				"id": 1,
 				"name": "warehouse 1",
 				"address": "address 1",
 				"telephone": "telephone 1",
@@ -119,10 +133,273 @@ func TestWarehouseDefault_Store(t *testing.T) {
 			},
 			"message": "warehouse created"
 		}`
-		expectedHeader := http.Header{"Content-Type": []string{"application/json"}}
+		expectedHeader := http.Header{"Content-Type": []string{"application/json"}, "Etag": []string{`"1"`}}
 		require.Equal(t, expectedCode, res.Code)
 		require.JSONEq(t, expectedBody, res.Body.String())
 		require.Equal(t, expectedHeader, res.Header())
 
 	})
+
+	t.Run("success 02 - warehouse stored with products moved into it", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+
+		err := func() error {
+			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100)")
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (1, 'product 1', 100, 'code_value 1', true, '2021-12-31', 100, 1)")
+			return err
+		}()
+		require.NoError(t, err)
+
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
+
+		req := httptest.NewRequest("POST", "/warehouses", strings.NewReader(`{"name":"warehouse 2", "address":"address 2", "telephone":"telephone 2", "capacity":100, "product_ids":[1]}`))
+		res := httptest.NewRecorder()
+		hd.Store()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusCreated, res.Code)
+		var warehouseId int
+		err = db.QueryRow("SELECT `id_warehouse` FROM `products` WHERE `id` = 1").Scan(&warehouseId)
+		require.NoError(t, err)
+		require.NotEqual(t, 1, warehouseId)
+	})
+
+	t.Run("success 03 - warehouse stored from xml, responded in the accepted format", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := response.Negotiated(handler.NewWarehouseDefault(service.NewWarehouseDefault(uow)).Store())
+
+		req := httptest.NewRequest("POST", "/warehouses", strings.NewReader(`<request><name>warehouse 1</name><address>address 1</address><telephone>telephone 1</telephone><capacity>100</capacity></request>`))
+		req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+		req.Header.Set("Accept", "application/xml")
+		res := httptest.NewRecorder()
+		hd.ServeHTTP(res, req)
+
+		// assert
+		expectedCode := http.StatusCreated
+		expectedBody := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+			`<response><data><id>1</id><name>warehouse 1</name><address>address 1</address><telephone>telephone 1</telephone><capacity>100</capacity></data><message>warehouse created</message></response>`
+		require.Equal(t, expectedCode, res.Code)
+		require.Equal(t, "application/xml; charset=utf-8", res.Header().Get("Content-Type"))
+		require.Equal(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("failure 01 - product to move not found", func(t *testing.T) {
+		// arrange
+		db, uow := newTestUnitOfWork(t)
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
+
+		req := httptest.NewRequest("POST", "/warehouses", strings.NewReader(`{"name":"warehouse 1", "address":"address 1", "telephone":"telephone 1", "capacity":100, "product_ids":[1]}`))
+		res := httptest.NewRecorder()
+		hd.Store()(res, req)
+
+		// assert
+		expectedCode := http.StatusConflict
+		expectedBody := `{"type":"/problems/product_to_move_not_found", "title":"Product to move not found", "status":409, "code":"product_to_move_not_found"}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+		var count int
+		err := db.QueryRow("SELECT COUNT(*) FROM `warehouses`").Scan(&count)
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+
+	t.Run("failure 02 - invalid body", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
+
+		req := httptest.NewRequest("POST", "/warehouses", strings.NewReader(`{"name":"", "address":"address 1", "telephone":" ", "capacity":0}`))
+		res := httptest.NewRecorder()
+		hd.Store()(res, req)
+
+		// assert
+		expectedCode := http.StatusUnprocessableEntity
+		expectedBody := `{"type":"/problems/invalid_body", "title":"Request body breaks a validation rule", "status":422, "code":"invalid_body", "errors":[
+			{"field":"name", "code":"required", "message":"name is required"},
+			{"field":"telephone", "code":"required", "message":"telephone is required"},
+			{"field":"capacity", "code":"gt", "message":"capacity must be greater than 0"}
+		]}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("failure 03 - unsupported content type", func(t *testing.T) {
+		// arrange
+		_, uow := newTestUnitOfWork(t)
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
+
+		req := httptest.NewRequest("POST", "/warehouses", strings.NewReader(`name: warehouse 1`))
+		req.Header.Set("Content-Type", "application/yaml")
+		res := httptest.NewRecorder()
+		hd.Store()(res, req)
+
+		// assert
+		expectedCode := http.StatusUnsupportedMediaType
+		expectedBody := `{"type":"about:blank", "title":"Unsupported Media Type", "status":415, "detail":"unsupported content type", "code":"unsupported_media_type"}`
+		require.Equal(t, expectedCode, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+	})
+}
+
+// staleWarehouseService is a warehouse service that reads a warehouse as it was before its last update,
+// as a handler does when the warehouse changes between its read and its write
+type staleWarehouseService struct {
+	internal.WarehouseService
+	// stale is the warehouse as it was
+	stale internal.Warehouse
+}
+
+// GetOne returns the warehouse as it was
+func (s *staleWarehouseService) GetOne(ctx context.Context, id int) (w internal.Warehouse, err error) {
+	w = s.stale
+	return
+}
+
+func TestWarehouseDefault_Update(t *testing.T) {
+	// arrange a database with the warehouse 1 at version 2 holding a product
+	arrange := func(t *testing.T) (db *sql.DB, uow internal.UnitOfWork) {
+		db, uow = newTestUnitOfWork(t)
+
+		err := func() error {
+			_, err := db.Exec("INSERT INTO `warehouses` (`id`, `name`, `adress`, `telephone`, `capacity`, `version`) VALUES (1, 'warehouse 1', 'address 1', 'telephone 1', 100, 2)")
+			if err != nil {
+				return err
+			}
+			_, err = db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (1, 'product 1', 100, 'code_value 1', false, '2999-12-31', 100, 1)")
+			return err
+		}()
+		require.NoError(t, err)
+		return
+	}
+	// newRequest returns a request to patch the warehouse 1
+	newRequest := func(contentType string, body string, ifMatch string) *http.Request {
+		req := httptest.NewRequest("PATCH", "/warehouses/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		chiCtx := chi.NewRouteContext()
+		chiCtx.URLParams.Add("id", "1")
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+	}
+	// capacity returns the stored capacity of the warehouse 1
+	capacity := func(t *testing.T, db *sql.DB) (c int) {
+		require.NoError(t, db.QueryRow("SELECT `capacity` FROM `warehouses` WHERE `id` = 1").Scan(&c))
+		return
+	}
+
+	t.Run("success 01 - json merge patch of the current version", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest(request.ContentTypeMergePatch, `{"capacity":50}`, `"2"`))
+
+		// assert
+		expectedBody := `{"message":"warehouse updated", "warehouse":{"address":"address 1", "capacity":50, "id":1, "name":"warehouse 1", "telephone":"telephone 1"}}`
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, expectedBody, res.Body.String())
+		require.Equal(t, `"3"`, res.Header().Get("ETag"))
+		require.Equal(t, 50, capacity(t, db))
+	})
+
+	t.Run("success 02 - json patch", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest(request.ContentTypeJSONPatch, `[{"op":"test","path":"/capacity","value":100},{"op":"replace","path":"/capacity","value":50}]`, ""))
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, 50, capacity(t, db))
+	})
+
+	t.Run("failure 01 - stale version", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest(request.ContentTypeMergePatch, `{"capacity":50}`, `"1"`))
+
+		// assert
+		require.Equal(t, http.StatusPreconditionFailed, res.Code)
+		require.Contains(t, res.Body.String(), `"code":"warehouse_version_conflict"`)
+		require.Equal(t, 100, capacity(t, db))
+	})
+
+	t.Run("failure 02 - updated between the read and the update", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		sv := &staleWarehouseService{WarehouseService: service.NewWarehouseDefault(uow), stale: internal.Warehouse{
+			Id: 1, Name: "warehouse 1", Address: "address 1", Telephone: "telephone 1", Capacity: 100, Version: 1,
+		}}
+		hd := handler.NewWarehouseDefault(sv)
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest(request.ContentTypeMergePatch, `{"capacity":50}`, ""))
+
+		// assert
+		require.Equal(t, http.StatusPreconditionFailed, res.Code)
+		require.Contains(t, res.Body.String(), `"code":"warehouse_version_conflict"`)
+		require.Equal(t, 100, capacity(t, db))
+	})
+
+	t.Run("failure 03 - json patch test failed", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest(request.ContentTypeJSONPatch, `[{"op":"test","path":"/capacity","value":1},{"op":"replace","path":"/capacity","value":50}]`, ""))
+
+		// assert
+		require.Equal(t, http.StatusConflict, res.Code)
+		require.Equal(t, 100, capacity(t, db))
+	})
+
+	t.Run("failure 04 - capacity below the products of the warehouse", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		_, err := db.Exec("INSERT INTO `products` (`id`, `name`, `quantity`,`code_value`,`is_published`,`expiration`,`price`,`id_warehouse`) VALUES (2, 'product 2', 100, 'code_value 2', false, '2999-12-31', 100, 1)")
+		require.NoError(t, err)
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest(request.ContentTypeMergePatch, `{"capacity":1}`, ""))
+
+		// assert
+		require.Equal(t, http.StatusConflict, res.Code)
+		require.Contains(t, res.Body.String(), `"code":"warehouse_capacity_exceeded"`)
+		require.Equal(t, 100, capacity(t, db))
+	})
+
+	t.Run("failure 05 - unsupported patch format", func(t *testing.T) {
+		// arrange
+		db, uow := arrange(t)
+		hd := handler.NewWarehouseDefault(service.NewWarehouseDefault(uow))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.Update()(res, newRequest("text/plain", `capacity=50`, ""))
+
+		// assert
+		require.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+		require.Equal(t, request.ContentTypeMergePatch+", "+request.ContentTypeJSONPatch, res.Header().Get("Accept-Patch"))
+		require.Equal(t, 100, capacity(t, db))
+	})
 }
@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/validate"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewWebhooksDefault returns a new instance of WebhooksDefault
+func NewWebhooksDefault(sv internal.WebhookService, dp *WebhookDispatcher) *WebhooksDefault {
+	return &WebhooksDefault{
+		sv: sv,
+		dp: dp,
+	}
+}
+
+// WebhooksDefault is a struct that represents the default webhook handler
+type WebhooksDefault struct {
+	// sv is the webhook service
+	sv internal.WebhookService
+	// dp delivers the dead letters again
+	dp *WebhookDispatcher
+}
+
+// WebhookJSON is a struct that represents a webhook in JSON, its secret is only written when it is created
+type WebhookJSON struct {
+	ID     int      `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+// DeadLetterJSON is a struct that represents a dead letter in JSON
+type DeadLetterJSON struct {
+	ID        int       `json:"id"`
+	WebhookID int       `json:"webhook_id"`
+	Event     EventJSON `json:"event"`
+	Attempts  int       `json:"attempts"`
+	Error     string    `json:"error"`
+	FailedAt  string    `json:"failed_at"`
+}
+
+// RequestBodyWebhook is a struct that represents the request body of a webhook to create or update.
+// Events are the event types it receives, every type when it is empty. Its secret is generated when it is empty
+type RequestBodyWebhook struct {
+	URL    string   `json:"url" validate:"required"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// webhookETag returns the entity tag of the current version of a webhook
+func webhookETag(wh internal.Webhook) string {
+	return fmt.Sprintf("\"%d\"", wh.Version)
+}
+
+// webhookJSON returns the JSON of a webhook, without its secret
+func webhookJSON(wh internal.Webhook) WebhookJSON {
+	events := make([]string, 0, len(wh.Events))
+	for _, e := range wh.Events {
+		events = append(events, string(e))
+	}
+	return WebhookJSON{ID: wh.ID, URL: wh.URL, Events: events}
+}
+
+// eventTypes returns the event types of the events of a request body
+func eventTypes(events []string) (ts []internal.EventType) {
+	for _, e := range events {
+		ts = append(ts, internal.EventType(e))
+	}
+	return
+}
+
+// GetAll returns all webhooks
+func (h *WebhooksDefault) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhooks, err := h.sv.GetAll(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		data := make([]WebhookJSON, 0, len(webhooks))
+		for _, wh := range webhooks {
+			data = append(data, webhookJSON(wh))
+		}
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "webhooks found"),
+			Key:     "webhooks",
+			Data:    data,
+			Meta:    map[string]any{"count": len(data)},
+			Links:   map[string]string{"self": r.URL.RequestURI()},
+		})
+	}
+}
+
+// GetOne returns a webhook by id
+func (h *WebhooksDefault) GetOne() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		wh, err := h.sv.GetOne(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// response
+		response.ETag(w, webhookETag(wh))
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "webhook found"),
+			Key:     "webhook",
+			Data:    webhookJSON(wh),
+			Links: map[string]string{
+				"self":         r.URL.Path,
+				"dead_letters": path.Join(r.URL.Path, "dead-letters"),
+			},
+		})
+	}
+}
+
+// Create creates a webhook, its response is the only one with its secret
+func (h *WebhooksDefault) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		var body RequestBodyWebhook
+		if err := request.Body(r, &body); err != nil {
+			writeBodyError(w, err)
+			return
+		}
+		if err := validate.Struct(body); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// process
+		wh := internal.Webhook{URL: body.URL, Events: eventTypes(body.Events), Secret: body.Secret}
+		if err := h.sv.Create(r.Context(), &wh); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// response
+		data := webhookJSON(wh)
+		data.Secret = wh.Secret
+		response.ETag(w, webhookETag(wh))
+		response.Success(w, http.StatusCreated, response.Body{
+			Message: response.Localize(w, "webhook created"),
+			Data:    data,
+			Links:   map[string]string{"self": path.Join(r.URL.Path, strconv.Itoa(wh.ID))},
+		})
+	}
+}
+
+// Update patches a webhook by id, a new secret is not written back
+func (h *WebhooksDefault) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		// - get webhook
+		wh, err := h.sv.GetOne(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		// - check the client has the current version
+		if !request.IfMatch(r, webhookETag(wh)) {
+			writeError(w, internal.ErrWebhookVersionConflict)
+			return
+		}
+		// - patch webhook: the secret is kept unless one is given
+		body := RequestBodyWebhook{URL: wh.URL, Events: webhookJSON(wh).Events}
+		if err := request.Patch(r, &body); err != nil {
+			switch {
+			case errors.Is(err, request.ErrRequestContentTypeNotPatch):
+				w.Header().Set("Accept-Patch", request.ContentTypeMergePatch+", "+request.ContentTypeJSONPatch)
+				response.Error(w, http.StatusUnsupportedMediaType, "unsupported patch format")
+			case errors.Is(err, request.ErrRequestPatchTestFailed):
+				response.Error(w, http.StatusConflict, "patch test failed")
+			default:
+				response.Error(w, http.StatusBadRequest, "invalid request body")
+			}
+			return
+		}
+		if err := validate.Struct(body); err != nil {
+			writeError(w, err)
+			return
+		}
+		wh.URL = body.URL
+		wh.Events = eventTypes(body.Events)
+		if body.Secret != "" {
+			wh.Secret = body.Secret
+		}
+		// - update webhook
+		if err := h.sv.Update(r.Context(), &wh); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// response
+		response.ETag(w, webhookETag(wh))
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "webhook updated"),
+			Key:     "webhook",
+			Data:    webhookJSON(wh),
+			Links:   map[string]string{"self": r.URL.Path},
+		})
+	}
+}
+
+// Delete deletes a webhook by id, with its dead letters
+func (h *WebhooksDefault) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		if err := h.sv.Delete(r.Context(), id); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// response
+		response.Success(w, http.StatusOK, response.Body{Message: response.Localize(w, "webhook deleted"), Data: id})
+	}
+}
+
+// DeadLetters returns the events not delivered to a webhook, the oldest first
+func (h *WebhooksDefault) DeadLetters() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		ds, err := h.sv.DeadLetters(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// response
+		data := make([]DeadLetterJSON, 0, len(ds))
+		for _, d := range ds {
+			data = append(data, DeadLetterJSON{
+				ID:        d.ID,
+				WebhookID: d.WebhookID,
+				Event:     eventJSON(d.Event),
+				Attempts:  d.Attempts,
+				Error:     d.Error,
+				FailedAt:  d.FailedAt.UTC().Format(time.RFC3339),
+			})
+		}
+		response.Success(w, http.StatusOK, response.Body{
+			Message: response.Localize(w, "dead letters found"),
+			Key:     "dead_letters",
+			Data:    data,
+			Meta:    map[string]any{"count": len(data)},
+			Links:   map[string]string{"self": r.URL.RequestURI()},
+		})
+	}
+}
+
+// Redeliver queues the event of a dead letter to its webhook again, the dead letter is removed.
+// It responds before the delivery, which becomes a dead letter again if it fails
+func (h *WebhooksDefault) Redeliver() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		deadLetterID, err := strconv.Atoi(chi.URLParam(r, "deadLetterId"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+
+		// process
+		if err := h.dp.Redeliver(r.Context(), id, deadLetterID); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// response
+		response.Success(w, http.StatusAccepted, response.Body{Message: response.Localize(w, "dead letter queued"), Data: deadLetterID})
+	}
+}
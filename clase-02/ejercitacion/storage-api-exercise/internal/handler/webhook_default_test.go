@@ -0,0 +1,219 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// delivery is a request received by a webhook stand-in
+type delivery struct {
+	header http.Header
+	body   string
+}
+
+// newWebhookStandIn returns a server that stands for the receiver of a webhook: it answers each delivery with the status of status,
+// and sends the ones it accepts to the returned channel
+func newWebhookStandIn(t *testing.T, status *atomic.Int32) (srv *httptest.Server, accepted <-chan delivery) {
+	t.Helper()
+
+	ch := make(chan delivery, 16)
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		code := int(status.Load())
+		if code < 300 {
+			ch <- delivery{header: r.Header.Clone(), body: string(b)}
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(srv.Close)
+	accepted = ch
+	return
+}
+
+// newWebhookDispatcher returns a started dispatcher of the events it is sent to the webhooks of sv, retried without delay
+func newWebhookDispatcher(t *testing.T, sv internal.WebhookService, client *http.Client) (dp *handler.WebhookDispatcher) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dp = handler.NewWebhookDispatcher(sv, handler.WebhookRetry{Attempts: 3, Backoff: time.Millisecond}, client)
+	dp.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		dp.Wait()
+	})
+	return
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	// event is the event of a created product, numbered by its outbox message
+	event := internal.Event{
+		ID:   2,
+		Type: internal.EventProductCreated,
+		Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Data: internal.Product{ID: 1, Name: "product 1", Quantity: 5, CodeValue: "A1", Expiration: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC), Price: 1.5, WarehouseId: 1},
+	}
+
+	t.Run("success 01 - event posted signed with the secret of the webhook", func(t *testing.T) {
+		// arrange
+		var status atomic.Int32
+		status.Store(http.StatusNoContent)
+		srv, accepted := newWebhookStandIn(t, &status)
+		sv := service.NewWebhookDefault(repository.NewWebhookMemory())
+		wh := internal.Webhook{URL: srv.URL, Events: []internal.EventType{internal.EventProductCreated}, Secret: "secret"}
+		require.NoError(t, sv.Create(context.Background(), &wh))
+		dp := newWebhookDispatcher(t, sv, srv.Client())
+
+		// act
+		errWarehouse := dp.Send(context.Background(), internal.Event{ID: 1, Type: internal.EventWarehouseCreated, Data: internal.Warehouse{Id: 1}})
+		err := dp.Send(context.Background(), event)
+		d := <-accepted
+
+		// assert
+		require.NoError(t, errWarehouse)
+		require.NoError(t, err)
+		require.Equal(t, "application/json", d.header.Get("Content-Type"))
+		require.Equal(t, "product.created", d.header.Get("X-Webhook-Event"))
+		require.Equal(t, "2", d.header.Get("X-Webhook-Event-Id"))
+		timestamp := d.header.Get("X-Webhook-Timestamp")
+		require.Equal(t, handler.SignWebhook("secret", timestamp, []byte(d.body)), d.header.Get("X-Webhook-Signature"))
+		require.True(t, strings.HasPrefix(d.header.Get("X-Webhook-Signature"), "sha256="))
+		require.JSONEq(t, `{"id":2,"type":"product.created","time":"2024-01-01T00:00:00Z","data":{"id":1,"name":"product 1","quantity":5,"code_value":"A1","is_published":false,"expiration":"2099-01-01","price":1.5,"warehouse_id":1}}`, d.body)
+	})
+
+	t.Run("success 02 - dead letter redelivered", func(t *testing.T) {
+		// arrange
+		var status atomic.Int32
+		status.Store(http.StatusInternalServerError)
+		srv, accepted := newWebhookStandIn(t, &status)
+		sv := service.NewWebhookDefault(repository.NewWebhookMemory())
+		wh := internal.Webhook{URL: srv.URL}
+		require.NoError(t, sv.Create(context.Background(), &wh))
+		dp := newWebhookDispatcher(t, sv, srv.Client())
+		require.NoError(t, dp.Send(context.Background(), event))
+		var ds []internal.DeadLetter
+		require.Eventually(t, func() bool {
+			ds, _ = sv.DeadLetters(context.Background(), wh.ID)
+			return len(ds) == 1
+		}, time.Second, time.Millisecond)
+		status.Store(http.StatusOK)
+
+		// act
+		err := dp.Redeliver(context.Background(), wh.ID, ds[0].ID)
+		d := <-accepted
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "2", d.header.Get("X-Webhook-Event-Id"))
+		ds, err = sv.DeadLetters(context.Background(), wh.ID)
+		require.NoError(t, err)
+		require.Empty(t, ds)
+	})
+
+	t.Run("failure 01 - dead letter once the attempts run out", func(t *testing.T) {
+		// arrange
+		var status atomic.Int32
+		status.Store(http.StatusServiceUnavailable)
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(int(status.Load()))
+		}))
+		t.Cleanup(srv.Close)
+		sv := service.NewWebhookDefault(repository.NewWebhookMemory())
+		wh := internal.Webhook{URL: srv.URL}
+		require.NoError(t, sv.Create(context.Background(), &wh))
+		dp := newWebhookDispatcher(t, sv, srv.Client())
+
+		// act
+		require.NoError(t, dp.Send(context.Background(), event))
+		var ds []internal.DeadLetter
+		require.Eventually(t, func() bool {
+			ds, _ = sv.DeadLetters(context.Background(), wh.ID)
+			return len(ds) == 1
+		}, time.Second, time.Millisecond)
+
+		// assert
+		require.Equal(t, int32(3), attempts.Load())
+		require.Equal(t, 3, ds[0].Attempts)
+		require.Equal(t, "webhook: unexpected status 503", ds[0].Error)
+		require.Equal(t, int64(2), ds[0].Event.ID)
+	})
+
+	t.Run("failure 02 - event sent to a stopped dispatcher", func(t *testing.T) {
+		// arrange
+		sv := service.NewWebhookDefault(repository.NewWebhookMemory())
+		dp := handler.NewWebhookDispatcher(sv, handler.WebhookRetry{}, nil)
+
+		// act
+		err := dp.Send(context.Background(), event)
+
+		// assert
+		require.ErrorIs(t, err, handler.ErrWebhookStopped)
+	})
+}
+
+func TestWebhooksDefault_Create(t *testing.T) {
+	t.Run("success 01 - webhook created with its secret", func(t *testing.T) {
+		// arrange
+		hd := handler.NewWebhooksDefault(service.NewWebhookDefault(repository.NewWebhookMemory()), nil)
+
+		// act
+		req := httptest.NewRequest("POST", "/webhooks", strings.NewReader(`{"url":"http://localhost:9000/hook","events":["product.created"],"secret":"secret"}`))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+		hd.Create()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusCreated, res.Code)
+		require.JSONEq(t, `{"message":"webhook created","data":{"id":1,"url":"http://localhost:9000/hook","events":["product.created"],"secret":"secret"}}`, res.Body.String())
+	})
+
+	t.Run("failure 01 - url not http", func(t *testing.T) {
+		// arrange
+		hd := handler.NewWebhooksDefault(service.NewWebhookDefault(repository.NewWebhookMemory()), nil)
+
+		// act
+		req := httptest.NewRequest("POST", "/webhooks", strings.NewReader(`{"url":"localhost"}`))
+		res := httptest.NewRecorder()
+		hd.Create()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		require.JSONEq(t, `{"type":"/problems/webhook_invalid","title":"Webhook breaks a validation rule","status":422,"detail":"url must be an http or https url","code":"webhook_invalid","errors":[{"field":"url","code":"url","message":"url must be an http or https url"}]}`, res.Body.String())
+	})
+}
+
+func TestWebhooksDefault_GetOne(t *testing.T) {
+	t.Run("success 01 - webhook found without its secret", func(t *testing.T) {
+		// arrange
+		sv := service.NewWebhookDefault(repository.NewWebhookMemory())
+		wh := internal.Webhook{URL: "https://example.com/hook"}
+		require.NoError(t, sv.Create(context.Background(), &wh))
+		hd := handler.NewWebhooksDefault(sv, nil)
+
+		// act
+		req := httptest.NewRequest("GET", "/webhooks/1", nil)
+		chiCtx := chi.NewRouteContext()
+		chiCtx.URLParams.Add("id", "1")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+		res := httptest.NewRecorder()
+		hd.GetOne()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.JSONEq(t, `{"message":"webhook found","webhook":{"id":1,"url":"https://example.com/hook","events":[]}}`, res.Body.String())
+	})
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"app/internal"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWebhookAttempts is the number of deliveries of an event to a webhook before it becomes a dead letter
+	DefaultWebhookAttempts = 5
+	// DefaultWebhookBackoff is the delay before the second delivery, doubled before each next one
+	DefaultWebhookBackoff = time.Second
+	// webhookMaxBackoff is the longest delay between two deliveries
+	webhookMaxBackoff = 5 * time.Minute
+	// webhookTimeout is the deadline of each delivery
+	webhookTimeout = 10 * time.Second
+	// webhookQueue is the number of events waiting to be delivered to a webhook, past it they become dead letters
+	webhookQueue = 256
+)
+
+var (
+	// ErrWebhookQueueFull is the error of the dead letter of an event that did not fit in the queue of its webhook
+	ErrWebhookQueueFull = errors.New("webhook: delivery queue full")
+	// ErrWebhookStatus is the error of a delivery answered by a status other than 2xx
+	ErrWebhookStatus = errors.New("webhook: unexpected status")
+	// ErrWebhookStopped is returned when an event is sent to a dispatcher not started or whose context is done
+	ErrWebhookStopped = errors.New("webhook: dispatcher stopped")
+)
+
+// SignWebhook returns the signature of a webhook payload: the hex HMAC-SHA256, keyed by the secret of the webhook,
+// of its timestamp and its body joined by a dot. Receivers compute it to check the payload comes from the api and is recent
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookRetry is a struct that represents the retry policy of the deliveries
+type WebhookRetry struct {
+	// Attempts is the number of deliveries of an event, DefaultWebhookAttempts when it is zero or less
+	Attempts int
+	// Backoff is the delay before the second delivery, doubled before each next one. DefaultWebhookBackoff when it is zero or less
+	Backoff time.Duration
+}
+
+// NewWebhookDispatcher returns a new instance of WebhookDispatcher, it posts the events it is sent to the webhooks of sv
+func NewWebhookDispatcher(sv internal.WebhookService, rt WebhookRetry, client *http.Client) *WebhookDispatcher {
+	if rt.Attempts <= 0 {
+		rt.Attempts = DefaultWebhookAttempts
+	}
+	if rt.Backoff <= 0 {
+		rt.Backoff = DefaultWebhookBackoff
+	}
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	return &WebhookDispatcher{
+		sv:     sv,
+		retry:  rt,
+		client: client,
+		queues: make(map[int]chan webhookDelivery),
+	}
+}
+
+// WebhookDispatcher is a struct that represents the sink of the events relayed from the outbox that posts them to the webhooks subscribed to them.
+// Each webhook has its own queue, so its events are delivered in order and a slow one does not hold the others back.
+// A delivery is retried with exponential backoff, once its attempts run out the event becomes a dead letter of the webhook
+type WebhookDispatcher struct {
+	// sv is the webhook service
+	sv internal.WebhookService
+	// retry is the retry policy
+	retry WebhookRetry
+	// client posts the payloads
+	client *http.Client
+	// mu guards ctx, queues and wg
+	mu sync.Mutex
+	// ctx is the context of Start, nil before it starts
+	ctx context.Context
+	// queues are the events waiting to be delivered to each webhook by its id
+	queues map[int]chan webhookDelivery
+	// wg waits for the workers of the queues
+	wg sync.WaitGroup
+}
+
+// webhookDelivery is an event to deliver to a webhook
+type webhookDelivery struct {
+	w internal.Webhook
+	e internal.Event
+}
+
+// Start makes the dispatcher deliver the events it is sent in the background, until ctx is done
+func (d *WebhookDispatcher) Start(ctx context.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ctx = ctx
+}
+
+// Wait waits for the deliveries in progress to stop, once the context of Start is done
+func (d *WebhookDispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Send queues an event to the webhooks subscribed to its type. It returns an error when they can not be read
+// or the dispatcher is stopped, so the event is sent again; a queued event is retried by the dispatcher itself
+func (d *WebhookDispatcher) Send(ctx context.Context, e internal.Event) (err error) {
+	d.mu.Lock()
+	stopped := d.ctx == nil || d.ctx.Err() != nil
+	d.mu.Unlock()
+	if stopped {
+		err = ErrWebhookStopped
+		return
+	}
+
+	ws, err := d.sv.Subscribed(ctx, e.Type)
+	if err != nil {
+		return
+	}
+	for _, w := range ws {
+		d.enqueue(w, e)
+	}
+	return
+}
+
+// Redeliver takes a dead letter of a webhook and queues its event again, with its attempts renewed
+func (d *WebhookDispatcher) Redeliver(ctx context.Context, webhookID int, id int) (err error) {
+	w, err := d.sv.GetOne(ctx, webhookID)
+	if err != nil {
+		return
+	}
+	dl, err := d.sv.TakeDeadLetter(ctx, webhookID, id)
+	if err != nil {
+		return
+	}
+	d.enqueue(w, dl.Event)
+	return
+}
+
+// enqueue queues an event to a webhook, starting the worker of its queue. An event that does not fit becomes a dead letter
+func (d *WebhookDispatcher) enqueue(w internal.Webhook, e internal.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ctx == nil || d.ctx.Err() != nil {
+		return
+	}
+
+	q, ok := d.queues[w.ID]
+	if !ok {
+		q = make(chan webhookDelivery, webhookQueue)
+		d.queues[w.ID] = q
+		d.wg.Add(1)
+		go d.work(d.ctx, q)
+	}
+	select {
+	case q <- webhookDelivery{w: w, e: e}:
+	default:
+		d.deadLetter(d.ctx, w, e, 0, ErrWebhookQueueFull)
+	}
+}
+
+// work delivers the events of a queue one at a time until ctx is done
+func (d *WebhookDispatcher) work(ctx context.Context, q <-chan webhookDelivery) {
+	defer d.wg.Done()
+	for {
+		select {
+		case dv := <-q:
+			d.deliver(ctx, dv.w, dv.e)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver posts an event to a webhook until it is accepted or its attempts run out, when it becomes a dead letter
+func (d *WebhookDispatcher) deliver(ctx context.Context, w internal.Webhook, e internal.Event) {
+	body, err := json.Marshal(eventJSON(e))
+	if err != nil {
+		d.deadLetter(ctx, w, e, 0, err)
+		return
+	}
+
+	backoff := d.retry.Backoff
+	for attempt := 1; ; attempt++ {
+		if err = d.post(ctx, w, e, body); err == nil {
+			return
+		}
+		if attempt == d.retry.Attempts || ctx.Err() != nil {
+			d.deadLetter(ctx, w, e, attempt, err)
+			return
+		}
+
+		// - wait before the next attempt
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			d.deadLetter(ctx, w, e, attempt, ctx.Err())
+			return
+		}
+		backoff = min(2*backoff, webhookMaxBackoff)
+	}
+}
+
+// post posts the payload of an event to a webhook, signed with its secret. It returns an error unless it is answered with a 2xx status
+func (d *WebhookDispatcher) post(ctx context.Context, w internal.Webhook, e internal.Event, body []byte) (err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "storage-api-webhooks")
+	req.Header.Set("X-Webhook-Id", strconv.Itoa(w.ID))
+	req.Header.Set("X-Webhook-Event", string(e.Type))
+	req.Header.Set("X-Webhook-Event-Id", strconv.FormatInt(e.ID, 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", SignWebhook(w.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		err = fmt.Errorf("%w %d", ErrWebhookStatus, res.StatusCode)
+	}
+	return
+}
+
+// deadLetter saves an event not delivered to a webhook, even after ctx is done so the deliveries cut short are kept
+func (d *WebhookDispatcher) deadLetter(ctx context.Context, w internal.Webhook, e internal.Event, attempts int, err error) {
+	dl := internal.DeadLetter{
+		WebhookID: w.ID,
+		Event:     e,
+		Attempts:  attempts,
+		Error:     err.Error(),
+		FailedAt:  time.Now(),
+	}
+	_ = d.sv.AddDeadLetter(context.WithoutCancel(ctx), &dl)
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OutboxMessage is a struct that represents an event written to the outbox in the transaction of its change,
+// pending until it is relayed to the sinks
+type OutboxMessage struct {
+	// ID is the number of the message, in order of writing
+	ID int64
+	// Entity is the key of the entity the event is about (e.g. product:1), the messages of an entity are relayed in order
+	Entity string
+	// Type is the kind of change
+	Type EventType
+	// Payload is the data of the event in JSON
+	Payload []byte
+	// CreatedAt is when the change was made
+	CreatedAt time.Time
+}
+
+// NewOutboxMessage returns the outbox message of an event
+func NewOutboxMessage(e Event) (m OutboxMessage, err error) {
+	payload, err := json.Marshal(e.Data)
+	if err != nil {
+		return
+	}
+	m = OutboxMessage{Entity: entityOf(e), Type: e.Type, Payload: payload, CreatedAt: e.Time}
+	return
+}
+
+// Event returns the event of an outbox message, numbered by the message
+func (m OutboxMessage) Event() (e Event, err error) {
+	e = Event{ID: m.ID, Type: m.Type, Time: m.CreatedAt}
+	switch {
+	case m.Type == EventProductStockChanged:
+		var d StockChange
+		err = json.Unmarshal(m.Payload, &d)
+		e.Data = d
+	case strings.HasPrefix(string(m.Type), "product."):
+		var d Product
+		err = json.Unmarshal(m.Payload, &d)
+		e.Data = d
+	case strings.HasPrefix(string(m.Type), "warehouse."):
+		var d Warehouse
+		err = json.Unmarshal(m.Payload, &d)
+		e.Data = d
+	default:
+		err = fmt.Errorf("outbox: unknown event type %q", m.Type)
+	}
+	return
+}
+
+// entityOf returns the key of the entity of an event: its resource and id
+func entityOf(e Event) string {
+	switch d := e.Data.(type) {
+	case Product:
+		return "product:" + strconv.Itoa(d.ID)
+	case StockChange:
+		return "product:" + strconv.Itoa(d.ProductID)
+	case Warehouse:
+		return "warehouse:" + strconv.Itoa(d.Id)
+	}
+	return string(e.Type)
+}
@@ -0,0 +1,20 @@
+package internal
+
+import "context"
+
+// OutboxRepository is an interface that represents the outbox: the events written in the transaction of their change, pending until relayed.
+// Its methods stop and return the error of ctx when it is canceled or its deadline passes
+type OutboxRepository interface {
+	// Append writes a message to the outbox, numbering it
+	Append(ctx context.Context, m *OutboxMessage) (err error)
+	// Pending returns up to limit messages not relayed yet, the oldest first
+	Pending(ctx context.Context, limit int) (ms []OutboxMessage, err error)
+	// MarkRelayed marks a message as relayed, so it is no longer pending
+	MarkRelayed(ctx context.Context, id int64) (err error)
+}
+
+// EventSink is an interface that represents a destination of the events relayed from the outbox (e.g. a log file, the webhooks)
+type EventSink interface {
+	// Send delivers an event, an error makes the relay send it again later
+	Send(ctx context.Context, e Event) (err error)
+}
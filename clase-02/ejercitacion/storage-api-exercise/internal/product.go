@@ -20,4 +20,22 @@ type Product struct {
 	Price float64
 	// WarehouseId is the warehouse id of the product
 	WarehouseId int
+	// Version is the optimistic concurrency version of the product
+	Version int
+}
+
+// ProductWarehouse is a struct that represents a product with its warehouse
+type ProductWarehouse struct {
+	Product
+	// Warehouse is the warehouse of the product
+	Warehouse Warehouse
+}
+
+// ProductMatch is a struct that represents a product found by a search
+type ProductMatch struct {
+	Product
+	// Score is the relevance of the product to the query, the higher the more relevant
+	Score float64
+	// Matched are the terms of the name and the code value matched by the query, with the number of their leading letters matched
+	Matched map[string]int
 }
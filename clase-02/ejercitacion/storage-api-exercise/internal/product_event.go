@@ -0,0 +1,32 @@
+package internal
+
+import "context"
+
+// ProductEventType is the kind of change of a product
+type ProductEventType string
+
+const (
+	// ProductCreated is the type of the event of a created product
+	ProductCreated ProductEventType = "created"
+	// ProductUpdated is the type of the event of an updated product
+	ProductUpdated ProductEventType = "updated"
+	// ProductDeleted is the type of the event of a deleted product, only its id is set
+	ProductDeleted ProductEventType = "deleted"
+)
+
+// ProductEvent is a struct that represents a change of a product
+type ProductEvent struct {
+	// Type is the kind of change
+	Type ProductEventType
+	// Product is the product after the change
+	Product Product
+}
+
+// ProductEvents is an interface that represents the feed of the changes of products
+type ProductEvents interface {
+	// Publish sends an event to the current subscribers
+	Publish(e ProductEvent)
+	// Subscribe returns the events published from now on, the channel is closed when ctx is done
+	// or the subscriber falls behind
+	Subscribe(ctx context.Context) <-chan ProductEvent
+}
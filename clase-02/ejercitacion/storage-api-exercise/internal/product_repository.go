@@ -1,6 +1,9 @@
 package internal
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 var (
 	// ErrProductNotFound is an error that will be returned when a product is not found
@@ -9,18 +12,44 @@ var (
 	ErrProductNotUnique = errors.New("repository: product not unique")
 	// ErrProductRelation is an error that will be returned when a product relation fails
 	ErrProductRelation = errors.New("repository: product relation error")
+	// ErrProductVersionConflict is an error that will be returned when a product was modified by someone else
+	ErrProductVersionConflict = errors.New("repository: product version conflict")
+	// ErrProductBulkAborted is an error that will be returned when an all-or-nothing bulk operation is rolled back
+	ErrProductBulkAborted = errors.New("repository: product bulk operation aborted")
 )
 
-// RepositoryProducts is an interface that represents a product repository
+// RepositoryProducts is an interface that represents a product repository.
+// Its methods stop and return the error of ctx when it is canceled or its deadline passes
 type RepositoryProducts interface {
 	// GetAll returns all products
-	GetAll() (products []Product, err error)
+	GetAll(ctx context.Context) (products []Product, err error)
 	// GetOne returns a product by id
-	GetOne(id int) (p Product, err error)
+	GetOne(ctx context.Context, id int) (p Product, err error)
+	// GetAllWithWarehouse returns all products sorted by id with their warehouse, read together
+	GetAllWithWarehouse(ctx context.Context) (products []ProductWarehouse, err error)
+	// GetOneWithWarehouse returns a product by id with its warehouse, read together
+	GetOneWithWarehouse(ctx context.Context, id int) (p ProductWarehouse, err error)
+	// GetByCodeValue returns a product by code value
+	GetByCodeValue(ctx context.Context, code string) (p Product, err error)
+	// GetByWarehouses returns the products of the warehouses of ids sorted by id, read together
+	GetByWarehouses(ctx context.Context, ids []int) (products []Product, err error)
 	// Store stores a product
-	Store(p *Product) (err error)
-	// Update updates a product
-	Update(p *Product) (err error)
-	// Delete deletes a product by id
-	Delete(id int) (err error)
+	Store(ctx context.Context, p *Product) (err error)
+	// Update updates a product if its version matches the stored one, incrementing it
+	Update(ctx context.Context, p *Product) (err error)
+	// Delete deletes a product by id if its version matches the stored one, whatever its version when it is 0
+	Delete(ctx context.Context, id int, version int) (err error)
+
+	// StoreBulk stores products in a single transaction.
+	// When atomic is true any failure rolls back the whole batch, otherwise only the failing products are skipped.
+	// errs holds the error of each product by index, err reports the failure of the batch itself.
+	StoreBulk(ctx context.Context, ps []Product, atomic bool) (errs []error, err error)
+	// UpdateBulk updates products in a single transaction, with the same semantics as StoreBulk
+	UpdateBulk(ctx context.Context, ps []Product, atomic bool) (errs []error, err error)
+	// DeleteBulk deletes products by id in a single transaction, with the same semantics as StoreBulk
+	DeleteBulk(ctx context.Context, ids []int, atomic bool) (errs []error, err error)
+
+	// Search returns the products whose name or code value match a query, also by a prefix or with a typo of their terms,
+	// the most relevant first and up to limit
+	Search(ctx context.Context, query string, limit int) (ms []ProductMatch, err error)
 }
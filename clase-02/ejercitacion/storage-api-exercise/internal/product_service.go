@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrProductInvalid is an error that will be returned when a product breaks a validation rule, as a *FieldError
+	ErrProductInvalid = errors.New("service: product invalid")
+	// ErrWarehouseCapacityExceeded is an error that will be returned when a warehouse has no room for more products
+	ErrWarehouseCapacityExceeded = errors.New("service: warehouse capacity exceeded")
+)
+
+const (
+	// ProductSearchLimit is the number of products a search returns when its limit is not set
+	ProductSearchLimit = 20
+	// ProductSearchMaxLimit is the most products a search returns
+	ProductSearchMaxLimit = 100
+)
+
+// ProductFilter is a struct that represents the filter of a products list, its zero value matches every product
+type ProductFilter struct {
+	// WarehouseId matches the products of a warehouse when it is not zero
+	WarehouseId int
+	// IsPublished matches the products with this published status when it is not nil
+	IsPublished *bool
+}
+
+// Match returns whether a product matches the filter
+func (f ProductFilter) Match(p Product) bool {
+	if f.WarehouseId != 0 && p.WarehouseId != f.WarehouseId {
+		return false
+	}
+	if f.IsPublished != nil && p.IsPublished != *f.IsPublished {
+		return false
+	}
+	return true
+}
+
+// ProductService is an interface that represents the business rules of products.
+// Writes validate the product, keep its code value unique and its warehouse within capacity,
+// and do not publish expired products
+type ProductService interface {
+	// GetAll returns the products matching the filter
+	GetAll(ctx context.Context, f ProductFilter) (products []Product, err error)
+	// GetOne returns a product by id
+	GetOne(ctx context.Context, id int) (p Product, err error)
+	// GetAllWithWarehouse returns the products matching the filter with their warehouse
+	GetAllWithWarehouse(ctx context.Context, f ProductFilter) (products []ProductWarehouse, err error)
+	// GetOneWithWarehouse returns a product by id with its warehouse
+	GetOneWithWarehouse(ctx context.Context, id int) (p ProductWarehouse, err error)
+	// Create creates a product
+	Create(ctx context.Context, p *Product) (err error)
+	// Update updates a product if its version matches the stored one
+	Update(ctx context.Context, p *Product) (err error)
+	// Delete deletes a product by id if its version matches the stored one, whatever its version when it is 0
+	Delete(ctx context.Context, id int, version int) (err error)
+	// Import creates a product or updates the one with its code value, created reports which one happened
+	Import(ctx context.Context, p *Product) (created bool, err error)
+
+	// CreateBulk creates products in a single transaction, with the semantics of RepositoryProducts.StoreBulk.
+	// A product that breaks a rule fails as it would in the repository
+	CreateBulk(ctx context.Context, ps []Product, atomic bool) (errs []error, err error)
+	// UpdateBulk updates products in a single transaction, with the same semantics as CreateBulk
+	UpdateBulk(ctx context.Context, ps []Product, atomic bool) (errs []error, err error)
+	// DeleteBulk deletes products by id in a single transaction, with the same semantics as CreateBulk
+	DeleteBulk(ctx context.Context, ids []int, atomic bool) (errs []error, err error)
+
+	// Search returns the products whose name or code value match a query, the most relevant first.
+	// It returns up to limit of them: ProductSearchLimit when it is zero or less, at most ProductSearchMaxLimit
+	Search(ctx context.Context, query string, limit int) (ms []ProductMatch, err error)
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+)
+
+// bulkTx runs fn for each of the n items of a batch within a single transaction, the one of the unit of work if c is one.
+// In best effort mode the failure of an item only undoes its own changes: each item writes with a single statement,
+// which is atomic by itself in mysql and sqlite, and runs inside a savepoint when savepoints is true,
+// for databases where a failed statement aborts the whole transaction (postgres).
+func bulkTx(ctx context.Context, c conn, savepoints bool, n int, atomic bool, fn func(tx conn, i int) error) (errs []error, err error) {
+	errs = make([]error, n)
+	err = inTx(ctx, c, func(tx conn) (err error) {
+		for i := 0; i < n; i++ {
+			if !atomic && savepoints {
+				if _, err = tx.ExecContext(ctx, "SAVEPOINT bulk_item"); err != nil {
+					return
+				}
+			}
+
+			errs[i] = fn(tx, i)
+			if errs[i] == nil {
+				continue
+			}
+			if atomic {
+				err = fmt.Errorf("%w: %w", internal.ErrProductBulkAborted, errs[i])
+				return
+			}
+			if savepoints {
+				if _, err = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT bulk_item"); err != nil {
+					return
+				}
+			}
+		}
+		return
+	})
+	return
+}
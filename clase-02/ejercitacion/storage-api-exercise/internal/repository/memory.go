@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"app/internal"
+	"app/platform/search"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// NewMemory returns a new empty in-memory database
+func NewMemory() *Memory {
+	return &Memory{
+		products:     make(map[int]internal.Product),
+		productIndex: newProductIndex(),
+		warehouses:   make(map[int]internal.Warehouse),
+	}
+}
+
+// Memory is an in-memory database shared by the memory repositories, as a *sql.DB is shared by the sql ones.
+// It is safe for concurrent use.
+type Memory struct {
+	// mu guards the tables
+	mu sync.RWMutex
+	// txMu makes the units of work run one at a time
+	txMu sync.Mutex
+	// products is the products table by id
+	products map[int]internal.Product
+	// lastProductId is the last id assigned to a product
+	lastProductId int
+	// productIndex is the full-text index of the products table, updated on every write
+	productIndex *search.Index
+	// warehouses is the warehouses table by id
+	warehouses map[int]internal.Warehouse
+	// lastWarehouseId is the last id assigned to a warehouse
+	lastWarehouseId int
+	// outbox is the outbox table, in order of id
+	outbox []outboxRow
+	// lastOutboxId is the last id assigned to an outbox message
+	lastOutboxId int64
+}
+
+// outboxRow is a row of the outbox table
+type outboxRow struct {
+	m       internal.OutboxMessage
+	relayed bool
+}
+
+// memorySnapshot is a struct that represents a copy of the tables of a Memory
+type memorySnapshot struct {
+	products        map[int]internal.Product
+	lastProductId   int
+	warehouses      map[int]internal.Warehouse
+	lastWarehouseId int
+	outbox          []outboxRow
+	lastOutboxId    int64
+}
+
+// snapshot returns a copy of the tables
+func (db *Memory) snapshot() memorySnapshot {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return memorySnapshot{
+		products:        maps.Clone(db.products),
+		lastProductId:   db.lastProductId,
+		warehouses:      maps.Clone(db.warehouses),
+		lastWarehouseId: db.lastWarehouseId,
+		outbox:          slices.Clone(db.outbox),
+		lastOutboxId:    db.lastOutboxId,
+	}
+}
+
+// restore replaces the tables with a snapshot, and brings the index of the products up to date with it
+func (db *Memory) restore(s memorySnapshot) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.products, db.lastProductId = s.products, s.lastProductId
+	syncProductIndex(db.productIndex, db.products)
+	db.warehouses, db.lastWarehouseId = s.warehouses, s.lastWarehouseId
+	db.outbox, db.lastOutboxId = s.outbox, s.lastOutboxId
+}
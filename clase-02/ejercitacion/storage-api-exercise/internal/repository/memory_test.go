@@ -0,0 +1,74 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/repository/repositorytest"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryFactory returns repositories of a new in-memory database
+func memoryFactory(t *testing.T) (rp internal.RepositoryProducts, rw internal.WarehouseRepository) {
+	db := repository.NewMemory()
+	rp = repository.NewProductsMemory(db)
+	rw = repository.NewWarehouseMemory(db)
+	return
+}
+
+func TestProductsMemory(t *testing.T) {
+	repositorytest.Products(t, memoryFactory)
+
+	t.Run("concurrent stores get distinct ids", func(t *testing.T) {
+		// arrange
+		rp, rw := memoryFactory(t)
+		w := internal.Warehouse{Name: "warehouse 1"}
+		require.NoError(t, rw.Store(context.Background(), &w))
+
+		// act
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				p := internal.Product{CodeValue: fmt.Sprintf("C%d", i), WarehouseId: w.Id}
+				rp.Store(context.Background(), &p)
+			}(i)
+		}
+		wg.Wait()
+
+		// assert
+		ps, err := rp.GetAll(context.Background())
+		require.NoError(t, err)
+		require.Len(t, ps, 50)
+		for i, p := range ps {
+			require.Equal(t, i+1, p.ID)
+		}
+	})
+}
+
+func TestWarehouseMemory(t *testing.T) {
+	repositorytest.Warehouses(t, memoryFactory)
+}
+
+func TestUnitOfWorkMemory(t *testing.T) {
+	repositorytest.UnitOfWork(t, func(t *testing.T) internal.UnitOfWork {
+		return repository.NewUnitOfWorkMemory(repository.NewMemory())
+	})
+}
+
+func TestOutboxMemory(t *testing.T) {
+	repositorytest.Outbox(t, func(t *testing.T) internal.UnitOfWork {
+		return repository.NewUnitOfWorkMemory(repository.NewMemory())
+	})
+}
+
+func TestWebhookMemory(t *testing.T) {
+	repositorytest.Webhooks(t, func(t *testing.T) internal.WebhookRepository {
+		return repository.NewWebhookMemory()
+	})
+}
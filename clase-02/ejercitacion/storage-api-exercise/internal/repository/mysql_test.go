@@ -0,0 +1,127 @@
+package repository_test
+
+import (
+	"app/docs/db/migrations"
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/repository/repositorytest"
+	"app/platform/migrate"
+	"app/platform/mysqltest"
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mysqlServer is the embedded mysql compatible server shared by the tests of the package, started on first use
+var mysqlServer = sync.OnceValues(mysqltest.NewServer)
+
+// mysqlDB returns a new database of the embedded server with the migrations applied
+func mysqlDB(t *testing.T) (db *sql.DB) {
+	s, err := mysqlServer()
+	require.NoError(t, err)
+	cfg, err := s.NewDatabase()
+	require.NoError(t, err)
+	db, err = sql.Open("mysql", cfg.FormatDSN())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	// schema
+	fsys, err := migrations.FS("mysql")
+	require.NoError(t, err)
+	ms, err := migrate.Load(fsys)
+	require.NoError(t, err)
+	_, err = migrate.NewMigrator(db, ms).Up(0)
+	require.NoError(t, err)
+	// - without the default warehouse
+	_, err = db.Exec("DELETE FROM `warehouses`")
+	require.NoError(t, err)
+	return
+}
+
+// mysqlFactory returns repositories of a new database of the embedded server
+func mysqlFactory(t *testing.T) (rp internal.RepositoryProducts, rw internal.WarehouseRepository) {
+	db := mysqlDB(t)
+	rp = repository.NewProductsMySQL(db)
+	rw = repository.NewWarehouseMySQL(db)
+	return
+}
+
+func TestProductsMySQL(t *testing.T) {
+	repositorytest.Products(t, mysqlFactory)
+}
+
+func TestProductsMySQL_Search(t *testing.T) {
+	t.Run("reindex writes the search terms of the products stored without them", func(t *testing.T) {
+		// arrange
+		db := mysqlDB(t)
+		rp := repository.NewProductsMySQL(db)
+		w := internal.Warehouse{Name: "warehouse 1", Address: "address", Telephone: "telephone", Capacity: 10}
+		require.NoError(t, repository.NewWarehouseMySQL(db).Store(context.Background(), &w))
+		_, err := db.Exec(
+			"INSERT INTO `products` (`name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`) "+
+				"VALUES ('Queso rallado', 1, 'A1', true, '2030-12-31', 9.5, ?)",
+			w.Id,
+		)
+		require.NoError(t, err)
+		before, err := rp.Search(context.Background(), "queso", 10)
+		require.NoError(t, err)
+		require.Empty(t, before)
+
+		// act
+		n, err := rp.Reindex(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		after, err := rp.Search(context.Background(), "qeso", 10)
+		require.NoError(t, err)
+		require.Len(t, after, 1)
+		require.Equal(t, "A1", after[0].CodeValue)
+	})
+
+	t.Run("a product is not stored when its search terms are not", func(t *testing.T) {
+		// arrange
+		db := mysqlDB(t)
+		rp := repository.NewProductsMySQL(db)
+		w := internal.Warehouse{Name: "warehouse 1", Address: "address", Telephone: "telephone", Capacity: 10}
+		require.NoError(t, repository.NewWarehouseMySQL(db).Store(context.Background(), &w))
+		_, err := db.Exec("DROP TABLE `product_search_typos`")
+		require.NoError(t, err)
+		p := internal.Product{Name: "Queso rallado", Quantity: 1, CodeValue: "A1", Expiration: time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC), WarehouseId: w.Id}
+
+		// act
+		err = rp.Store(context.Background(), &p)
+
+		// assert
+		require.Error(t, err)
+		ps, err := rp.GetAll(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, ps)
+	})
+}
+
+func TestWarehouseMySQL(t *testing.T) {
+	repositorytest.Warehouses(t, mysqlFactory)
+}
+
+func TestUnitOfWorkMySQL(t *testing.T) {
+	repositorytest.UnitOfWork(t, func(t *testing.T) internal.UnitOfWork {
+		return repository.NewUnitOfWorkMySQL(mysqlDB(t))
+	})
+}
+
+func TestOutboxMySQL(t *testing.T) {
+	repositorytest.Outbox(t, func(t *testing.T) internal.UnitOfWork {
+		return repository.NewUnitOfWorkMySQL(mysqlDB(t))
+	})
+}
+
+func TestWebhookMySQL(t *testing.T) {
+	repositorytest.Webhooks(t, func(t *testing.T) internal.WebhookRepository {
+		return repository.NewWebhookMySQL(mysqlDB(t))
+	})
+}
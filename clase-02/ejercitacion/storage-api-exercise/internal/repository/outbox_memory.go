@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+)
+
+// NewOutboxMemory returns a new instance of OutboxMemory
+func NewOutboxMemory(db *Memory) *OutboxMemory {
+	return &OutboxMemory{
+		db: db,
+	}
+}
+
+// OutboxMemory is a struct that represents an outbox repository over an in-memory database
+type OutboxMemory struct {
+	// db is the in-memory database
+	db *Memory
+}
+
+// Append writes a message to the outbox
+func (r *OutboxMemory) Append(ctx context.Context, m *internal.OutboxMessage) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.lastOutboxId++
+	m.ID = r.db.lastOutboxId
+	r.db.outbox = append(r.db.outbox, outboxRow{m: *m})
+	return
+}
+
+// Pending returns up to limit messages not relayed yet, the oldest first
+func (r *OutboxMemory) Pending(ctx context.Context, limit int) (ms []internal.OutboxMessage, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, row := range r.db.outbox {
+		if len(ms) == limit {
+			break
+		}
+		if !row.relayed {
+			ms = append(ms, row.m)
+		}
+	}
+	return
+}
+
+// MarkRelayed marks a message as relayed
+func (r *OutboxMemory) MarkRelayed(ctx context.Context, id int64) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for i := range r.db.outbox {
+		if r.db.outbox[i].m.ID == id {
+			r.db.outbox[i].relayed = true
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// NewOutboxMySQL returns a new instance of OutboxMySQL
+func NewOutboxMySQL(db *sql.DB) *OutboxMySQL {
+	return &OutboxMySQL{
+		db: db,
+	}
+}
+
+// OutboxMySQL is a struct that represents an outbox repository
+type OutboxMySQL struct {
+	// db is the database connection, or the transaction of a unit of work
+	db conn
+}
+
+// Append writes a message to the outbox
+func (r *OutboxMySQL) Append(ctx context.Context, m *internal.OutboxMessage) (err error) {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO `outbox` (`entity`, `type`, `payload`, `created_at`) VALUES (?, ?, ?, ?)",
+		m.Entity, m.Type, string(m.Payload), m.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return
+	}
+	m.ID, err = result.LastInsertId()
+	return
+}
+
+// Pending returns up to limit messages not relayed yet, the oldest first
+func (r *OutboxMySQL) Pending(ctx context.Context, limit int) (ms []internal.OutboxMessage, err error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT `id`, `entity`, `type`, `payload`, `created_at` FROM `outbox` WHERE `relayed_at` IS NULL ORDER BY `id` LIMIT ?",
+		limit,
+	)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	ms, err = scanOutbox(rows)
+	return
+}
+
+// MarkRelayed marks a message as relayed
+func (r *OutboxMySQL) MarkRelayed(ctx context.Context, id int64) (err error) {
+	_, err = r.db.ExecContext(ctx, "UPDATE `outbox` SET `relayed_at` = ? WHERE `id` = ?", time.Now().UTC(), id)
+	return
+}
+
+// scanOutbox returns the messages of the rows of the outbox (id, entity, type, payload, created_at)
+func scanOutbox(rows *sql.Rows) (ms []internal.OutboxMessage, err error) {
+	for rows.Next() {
+		var m internal.OutboxMessage
+		var payload string
+		if err = rows.Scan(&m.ID, &m.Entity, &m.Type, &payload, &m.CreatedAt); err != nil {
+			return
+		}
+		m.Payload = []byte(payload)
+		ms = append(ms, m)
+	}
+	err = rows.Err()
+	return
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// NewOutboxPostgres returns a new instance of OutboxPostgres
+func NewOutboxPostgres(db *sql.DB) *OutboxPostgres {
+	return &OutboxPostgres{
+		db: db,
+	}
+}
+
+// OutboxPostgres is a struct that represents an outbox repository
+type OutboxPostgres struct {
+	// db is the database connection, or the transaction of a unit of work
+	db conn
+}
+
+// Append writes a message to the outbox
+func (r *OutboxPostgres) Append(ctx context.Context, m *internal.OutboxMessage) (err error) {
+	err = r.db.QueryRowContext(ctx,
+		"INSERT INTO outbox (entity, type, payload, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
+		m.Entity, m.Type, string(m.Payload), m.CreatedAt.UTC(),
+	).Scan(&m.ID)
+	return
+}
+
+// Pending returns up to limit messages not relayed yet, the oldest first
+func (r *OutboxPostgres) Pending(ctx context.Context, limit int) (ms []internal.OutboxMessage, err error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, entity, type, payload, created_at FROM outbox WHERE relayed_at IS NULL ORDER BY id LIMIT $1",
+		limit,
+	)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	ms, err = scanOutbox(rows)
+	return
+}
+
+// MarkRelayed marks a message as relayed
+func (r *OutboxPostgres) MarkRelayed(ctx context.Context, id int64) (err error) {
+	_, err = r.db.ExecContext(ctx, "UPDATE outbox SET relayed_at = $1 WHERE id = $2", time.Now().UTC(), id)
+	return
+}
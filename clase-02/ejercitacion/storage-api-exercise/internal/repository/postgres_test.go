@@ -0,0 +1,88 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/repository/repositorytest"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/DATA-DOG/go-txdb"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	txdb.Register("txdb_postgres", "pgx", os.Getenv("TEST_DB_URL"))
+}
+
+// postgresFactory returns repositories inside a rolled back transaction of the migrated postgres test database at TEST_DB_URL
+func postgresFactory(t *testing.T) (rp internal.RepositoryProducts, rw internal.WarehouseRepository) {
+	if os.Getenv("TEST_DB_DRIVER") != "postgres" {
+		t.Skip("set TEST_DB_DRIVER=postgres and TEST_DB_URL to run against a postgres test database")
+	}
+
+	db, err := sql.Open("txdb_postgres", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec("DELETE FROM products")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM warehouses")
+	require.NoError(t, err)
+
+	rp = repository.NewProductsPostgres(db)
+	rw = repository.NewWarehousePostgres(db)
+	return
+}
+
+func TestProductsPostgres(t *testing.T) {
+	repositorytest.Products(t, postgresFactory)
+}
+
+func TestWarehousePostgres(t *testing.T) {
+	repositorytest.Warehouses(t, postgresFactory)
+}
+
+// postgresUnitOfWork returns a unit of work over the migrated postgres test database at TEST_DB_URL, emptied.
+// It does not run within a txdb transaction, which would not let it roll back its own
+func postgresUnitOfWork(t *testing.T) internal.UnitOfWork {
+	if os.Getenv("TEST_DB_DRIVER") != "postgres" {
+		t.Skip("set TEST_DB_DRIVER=postgres and TEST_DB_URL to run against a postgres test database")
+	}
+
+	db, err := sql.Open("pgx", os.Getenv("TEST_DB_URL"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec("DELETE FROM products")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM warehouses")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM outbox")
+	require.NoError(t, err)
+
+	return repository.NewUnitOfWorkPostgres(db)
+}
+
+func TestUnitOfWorkPostgres(t *testing.T) {
+	repositorytest.UnitOfWork(t, postgresUnitOfWork)
+}
+
+func TestOutboxPostgres(t *testing.T) {
+	repositorytest.Outbox(t, postgresUnitOfWork)
+}
+
+func TestWebhookPostgres(t *testing.T) {
+	repositorytest.Webhooks(t, func(t *testing.T) internal.WebhookRepository {
+		if os.Getenv("TEST_DB_DRIVER") != "postgres" {
+			t.Skip("set TEST_DB_DRIVER=postgres and TEST_DB_URL to run against a postgres test database")
+		}
+
+		db, err := sql.Open("txdb_postgres", t.Name())
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		_, err = db.Exec("DELETE FROM webhooks")
+		require.NoError(t, err)
+
+		return repository.NewWebhookPostgres(db)
+	})
+}
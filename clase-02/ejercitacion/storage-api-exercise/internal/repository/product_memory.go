@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+)
+
+// NewProductsMemory returns a new instance of ProductsMemory
+func NewProductsMemory(db *Memory) *ProductsMemory {
+	return &ProductsMemory{
+		db: db,
+	}
+}
+
+// ProductsMemory is a struct that represents a product repository
+type ProductsMemory struct {
+	// db is the in-memory database
+	db *Memory
+}
+
+// GetAll returns all products sorted by id
+func (r *ProductsMemory) GetAll(ctx context.Context) (products []internal.Product, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, p := range r.db.products {
+		products = append(products, p)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+
+	return
+}
+
+// GetOne returns a product by id
+func (r *ProductsMemory) GetOne(ctx context.Context, id int) (p internal.Product, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	p, ok := r.db.products[id]
+	if !ok {
+		err = internal.ErrProductNotFound
+		return
+	}
+
+	return
+}
+
+// GetAllWithWarehouse returns all products sorted by id with their warehouse
+func (r *ProductsMemory) GetAllWithWarehouse(ctx context.Context) (products []internal.ProductWarehouse, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, p := range r.db.products {
+		products = append(products, internal.ProductWarehouse{Product: p, Warehouse: r.db.warehouses[p.WarehouseId]})
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+
+	return
+}
+
+// GetOneWithWarehouse returns a product by id with its warehouse
+func (r *ProductsMemory) GetOneWithWarehouse(ctx context.Context, id int) (p internal.ProductWarehouse, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	product, ok := r.db.products[id]
+	if !ok {
+		err = internal.ErrProductNotFound
+		return
+	}
+	p = internal.ProductWarehouse{Product: product, Warehouse: r.db.warehouses[product.WarehouseId]}
+
+	return
+}
+
+// GetByCodeValue returns a product by code value
+func (r *ProductsMemory) GetByCodeValue(ctx context.Context, code string) (p internal.Product, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, v := range r.db.products {
+		if v.CodeValue == code {
+			p = v
+			return
+		}
+	}
+
+	err = internal.ErrProductNotFound
+	return
+}
+
+// GetByWarehouses returns the products of the warehouses of ids sorted by id
+func (r *ProductsMemory) GetByWarehouses(ctx context.Context, ids []int) (products []internal.Product, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, p := range r.db.products {
+		if slices.Contains(ids, p.WarehouseId) {
+			products = append(products, p)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+
+	return
+}
+
+// Store stores a product
+func (r *ProductsMemory) Store(ctx context.Context, p *internal.Product) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	err = r.store(p)
+	return
+}
+
+// Update updates a product if its version matches the stored one
+func (r *ProductsMemory) Update(ctx context.Context, p *internal.Product) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	err = r.update(p)
+	return
+}
+
+// Delete deletes a product by id if its version matches the stored one, whatever its version when it is 0
+func (r *ProductsMemory) Delete(ctx context.Context, id int, version int) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	err = r.delete(id, version)
+	return
+}
+
+// StoreBulk stores products as a single operation
+func (r *ProductsMemory) StoreBulk(ctx context.Context, ps []internal.Product, atomic bool) (errs []error, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	errs, err = r.bulk(len(ps), atomic, func(i int) error {
+		return r.store(&ps[i])
+	})
+	return
+}
+
+// UpdateBulk updates products as a single operation
+func (r *ProductsMemory) UpdateBulk(ctx context.Context, ps []internal.Product, atomic bool) (errs []error, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	errs, err = r.bulk(len(ps), atomic, func(i int) error {
+		return r.update(&ps[i])
+	})
+	return
+}
+
+// DeleteBulk deletes products by id as a single operation
+func (r *ProductsMemory) DeleteBulk(ctx context.Context, ids []int, atomic bool) (errs []error, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	errs, err = r.bulk(len(ids), atomic, func(i int) error {
+		return r.delete(ids[i], 0)
+	})
+	return
+}
+
+// Search returns the products matched by a query in the full-text index
+func (r *ProductsMemory) Search(ctx context.Context, query string, limit int) (ms []internal.ProductMatch, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	// - a search sorts the terms of the index when they changed, so it writes
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	ms = searchProducts(r.db.productIndex, r.db.products, query, limit)
+	return
+}
+
+// bulk runs fn for each of the n items of a batch holding the lock.
+// In atomic mode a failure restores the products as they were before the batch.
+func (r *ProductsMemory) bulk(n int, atomic bool, fn func(i int) error) (errs []error, err error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	// snapshot
+	products, lastId := maps.Clone(r.db.products), r.db.lastProductId
+
+	errs = make([]error, n)
+	for i := 0; i < n; i++ {
+		errs[i] = fn(i)
+		if errs[i] != nil && atomic {
+			r.db.products, r.db.lastProductId = products, lastId
+			syncProductIndex(r.db.productIndex, r.db.products)
+			err = fmt.Errorf("%w: %w", internal.ErrProductBulkAborted, errs[i])
+			return
+		}
+	}
+	return
+}
+
+// check returns the constraint violated by a product, if any
+func (r *ProductsMemory) check(p *internal.Product) (err error) {
+	// - unique code value
+	for _, v := range r.db.products {
+		if v.ID != p.ID && v.CodeValue == p.CodeValue {
+			err = internal.ErrProductNotUnique
+			return
+		}
+	}
+	// - warehouse relation
+	if _, ok := r.db.warehouses[p.WarehouseId]; !ok {
+		err = internal.ErrProductRelation
+		return
+	}
+	return
+}
+
+// store inserts a product, the lock must be held
+func (r *ProductsMemory) store(p *internal.Product) (err error) {
+	p.ID = 0
+	if err = r.check(p); err != nil {
+		return
+	}
+
+	r.db.lastProductId++
+	p.ID = r.db.lastProductId
+	p.Version = 1
+	r.db.products[p.ID] = *p
+	indexProduct(r.db.productIndex, *p)
+	return
+}
+
+// update updates a product if its version matches the stored one, the lock must be held
+func (r *ProductsMemory) update(p *internal.Product) (err error) {
+	current, ok := r.db.products[p.ID]
+	if !ok {
+		err = internal.ErrProductNotFound
+		return
+	}
+	if current.Version != p.Version {
+		err = internal.ErrProductVersionConflict
+		return
+	}
+	if err = r.check(p); err != nil {
+		return
+	}
+
+	p.Version++
+	r.db.products[p.ID] = *p
+	indexProduct(r.db.productIndex, *p)
+	return
+}
+
+// delete deletes a product by id if its version matches the stored one, whatever its version when it is 0, the lock must be held
+func (r *ProductsMemory) delete(id int, version int) (err error) {
+	current, ok := r.db.products[id]
+	if !ok {
+		err = internal.ErrProductNotFound
+		return
+	}
+	if version != 0 && current.Version != version {
+		err = internal.ErrProductVersionConflict
+		return
+	}
+
+	delete(r.db.products, id)
+	r.db.productIndex.Remove(id)
+	return
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"app/internal"
+	"context"
 	"database/sql"
 	"errors"
+
+	"github.com/go-sql-driver/mysql"
 )
 
 // NewProductsMySQL returns a new instance of ProductsMySQL
@@ -15,17 +18,17 @@ func NewProductsMySQL(db *sql.DB) *ProductsMySQL {
 
 // ProductsMySQL is a struct that represents a product repository
 type ProductsMySQL struct {
-	// db is the database connection
-	db *sql.DB
+	// db is the database connection, or the transaction of a unit of work
+	db conn
 }
 
 // GetAll returns all products
-func (r *ProductsMySQL) GetAll() (products []internal.Product, err error) {
-	query := "SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse` FROM `products`"
-	
-	row, err := r.db.Query(query)
+func (r *ProductsMySQL) GetAll(ctx context.Context) (products []internal.Product, err error) {
+	query := "SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`, `version` FROM `products`"
+
+	row, err := r.db.QueryContext(ctx, query)
 	if err != nil {
-		if errors.Is(err,sql.ErrNoRows) {
+		if errors.Is(err, sql.ErrNoRows) {
 			err = internal.ErrProductNotFound
 			return
 		}
@@ -34,22 +37,22 @@ func (r *ProductsMySQL) GetAll() (products []internal.Product, err error) {
 	defer row.Close()
 
 	var p internal.Product
-	for row.Next(){
-		err = row.Scan(&p.ID,&p.Name,&p.Quantity,&p.CodeValue,&p.IsPublished,&p.Expiration,&p.Price,&p.WarehouseId)
+	for row.Next() {
+		err = row.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.WarehouseId, &p.Version)
 		if err != nil {
 			return
 		}
-		products = append(products,p)
+		products = append(products, p)
 	}
 
 	return
 }
 
 // GetOne returns a product by id
-func (r *ProductsMySQL) GetOne(id int) (p internal.Product, err error) {
+func (r *ProductsMySQL) GetOne(ctx context.Context, id int) (p internal.Product, err error) {
 	// execute the query
-	row := r.db.QueryRow(
-		"SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse` "+
+	row := r.db.QueryRowContext(ctx,
+		"SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`, `version` "+
 			"FROM `products` WHERE `id` = ?",
 		id,
 	)
@@ -58,7 +61,7 @@ func (r *ProductsMySQL) GetOne(id int) (p internal.Product, err error) {
 	}
 
 	// scan the row into the product
-	err = row.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.WarehouseId)
+	err = row.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.WarehouseId, &p.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			err = internal.ErrProductNotFound
@@ -69,15 +72,152 @@ func (r *ProductsMySQL) GetOne(id int) (p internal.Product, err error) {
 	return
 }
 
-// Store stores a product
-func (r *ProductsMySQL) Store(p *internal.Product) (err error) {
+// productWarehouseQueryMySQL selects the products joined with their warehouse
+const productWarehouseQueryMySQL = "SELECT p.`id`, p.`name`, p.`quantity`, p.`code_value`, p.`is_published`, p.`expiration`, p.`price`, p.`id_warehouse`, p.`version`, " +
+	"w.`id`, w.`name`, w.`adress`, w.`telephone`, w.`capacity`, w.`version` " +
+	"FROM `products` p INNER JOIN `warehouses` w ON w.`id` = p.`id_warehouse`"
+
+// GetAllWithWarehouse returns all products sorted by id with their warehouse, in a single query joining them
+func (r *ProductsMySQL) GetAllWithWarehouse(ctx context.Context) (products []internal.ProductWarehouse, err error) {
+	rows, err := r.db.QueryContext(ctx, productWarehouseQueryMySQL+" ORDER BY p.`id`")
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p internal.ProductWarehouse
+		if p, err = scanProductWarehouse(rows); err != nil {
+			return
+		}
+		products = append(products, p)
+	}
+	err = rows.Err()
+
+	return
+}
+
+// GetOneWithWarehouse returns a product by id with its warehouse, in a single query joining them
+func (r *ProductsMySQL) GetOneWithWarehouse(ctx context.Context, id int) (p internal.ProductWarehouse, err error) {
+	row := r.db.QueryRowContext(ctx, productWarehouseQueryMySQL+" WHERE p.`id` = ?", id)
+
+	p, err = scanProductWarehouse(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// GetByCodeValue returns a product by code value
+func (r *ProductsMySQL) GetByCodeValue(ctx context.Context, code string) (p internal.Product, err error) {
 	// execute the query
-	result, err := r.db.Exec(
+	row := r.db.QueryRowContext(ctx,
+		"SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`, `version` "+
+			"FROM `products` WHERE `code_value` = ?",
+		code,
+	)
+
+	// scan the row into the product
+	err = row.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.WarehouseId, &p.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// GetByWarehouses returns the products of the warehouses of ids sorted by id, in a single query
+func (r *ProductsMySQL) GetByWarehouses(ctx context.Context, ids []int) (products []internal.Product, err error) {
+	if len(ids) == 0 {
+		return
+	}
+	list, args := inIds(ids, false)
+	query := "SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`, `version` FROM `products` WHERE `id_warehouse` IN " + list + " ORDER BY `id`"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	products, err = scanProducts(rows)
+	return
+}
+
+// Store stores a product with its search terms
+func (r *ProductsMySQL) Store(ctx context.Context, p *internal.Product) (err error) {
+	err = inTx(ctx, r.db, func(tx conn) (err error) {
+		if err = storeProductMySQL(ctx, tx, p); err != nil {
+			return
+		}
+		err = indexProductsSQL(ctx, tx, searchMySQL, []internal.Product{*p})
+		return
+	})
+	return
+}
+
+// Update updates a product with its search terms if its version matches the stored one
+func (r *ProductsMySQL) Update(ctx context.Context, p *internal.Product) (err error) {
+	err = inTx(ctx, r.db, func(tx conn) (err error) {
+		if err = updateProductMySQL(ctx, tx, p); err != nil {
+			return
+		}
+		err = indexProductsSQL(ctx, tx, searchMySQL, []internal.Product{*p})
+		return
+	})
+	return
+}
+
+// Delete deletes a product by id if its version matches the stored one, whatever its version when it is 0
+func (r *ProductsMySQL) Delete(ctx context.Context, id int, version int) (err error) {
+	err = deleteProductMySQL(ctx, r.db, id, version)
+	return
+}
+
+// Search returns the products matched by a query through their search terms
+func (r *ProductsMySQL) Search(ctx context.Context, query string, limit int) (ms []internal.ProductMatch, err error) {
+	ms, err = searchProductsSQL(ctx, r.db, searchMySQL, query, limit)
+	return
+}
+
+// Reindex writes again the search terms of every product, for the ones stored before the search tables.
+// It returns the number of products indexed
+func (r *ProductsMySQL) Reindex(ctx context.Context) (n int, err error) {
+	n, err = reindexProductsSQL(ctx, r.db, searchMySQL)
+	return
+}
+
+// productErrorMySQL maps a mysql error into a product repository error
+func productErrorMySQL(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1062:
+			return internal.ErrProductNotUnique
+		case 1452:
+			return internal.ErrProductRelation
+		}
+	}
+	return err
+}
+
+// storeProductMySQL inserts a product
+func storeProductMySQL(ctx context.Context, db conn, p *internal.Product) (err error) {
+	// execute the query
+	result, err := db.ExecContext(ctx,
 		"INSERT INTO `products` (`name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`) "+
 			"VALUES (?, ?, ?, ?, ?, ?, ?)",
 		p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.WarehouseId,
 	)
 	if err != nil {
+		err = productErrorMySQL(err)
 		return
 	}
 
@@ -87,33 +227,75 @@ func (r *ProductsMySQL) Store(p *internal.Product) (err error) {
 		return
 	}
 	p.ID = int(id)
+	p.Version = 1
 
 	return
 }
 
-// Update updates a product
-func (r *ProductsMySQL) Update(p *internal.Product) (err error) {
+// updateProductMySQL updates a product if its version matches the stored one
+func updateProductMySQL(ctx context.Context, db conn, p *internal.Product) (err error) {
 	// execute the query
-	_, err = r.db.Exec(
-		"UPDATE `products` SET `name` = ?, `quantity` = ?, `code_value` = ?, `is_published` = ?, `expiration` = ?, `price` = ? , `id_warehouse` = ?"+
-			"WHERE `id` = ?",
-		p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.WarehouseId, p.ID,
+	result, err := db.ExecContext(ctx,
+		"UPDATE `products` SET `name` = ?, `quantity` = ?, `code_value` = ?, `is_published` = ?, `expiration` = ?, `price` = ?, `id_warehouse` = ?, `version` = `version` + 1 "+
+			"WHERE `id` = ? AND `version` = ?",
+		p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.WarehouseId, p.ID, p.Version,
 	)
 	if err != nil {
+		err = productErrorMySQL(err)
 		return
 	}
 
+	// check the affected rows
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if rowsAffected == 0 {
+		// - the product was either deleted or modified by someone else
+		var exists bool
+		err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM `products` WHERE `id` = ?)", p.ID).Scan(&exists)
+		if err != nil {
+			return
+		}
+		err = internal.ErrProductVersionConflict
+		if !exists {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+	p.Version++
+
 	return
 }
 
-// Delete deletes a product by id
-func (r *ProductsMySQL) Delete(id int) (err error) {
+// deleteProductMySQL deletes a product by id if its version matches the stored one, whatever its version when it is 0
+func deleteProductMySQL(ctx context.Context, db conn, id int, version int) (err error) {
 	// execute the query
-	_, err = r.db.Exec(
-		"DELETE FROM `products` WHERE `id` = ?",
-		id,
+	result, err := db.ExecContext(ctx,
+		"DELETE FROM `products` WHERE `id` = ? AND (? = 0 OR `version` = ?)",
+		id, version, version,
 	)
 	if err != nil {
+		err = productErrorMySQL(err)
+		return
+	}
+
+	// check the affected rows
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if rowsAffected == 0 {
+		// - the product was either deleted or modified by someone else
+		var exists bool
+		err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM `products` WHERE `id` = ?)", id).Scan(&exists)
+		if err != nil {
+			return
+		}
+		err = internal.ErrProductVersionConflict
+		if !exists {
+			err = internal.ErrProductNotFound
+		}
 		return
 	}
 
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// bulkInsertSizeMySQL is the maximum number of rows of a multi-row insert
+const bulkInsertSizeMySQL = 500
+
+// StoreBulk stores products in a single transaction
+func (r *ProductsMySQL) StoreBulk(ctx context.Context, ps []internal.Product, atomic bool) (errs []error, err error) {
+	// best effort: one insert per product, then the search terms of the ones stored
+	if !atomic {
+		errs, err = indexBulkSQL(ctx, r.db, searchMySQL, ps, func(tx conn) (errs []error, err error) {
+			return bulkTx(ctx, tx, false, len(ps), atomic, func(tx conn, i int) error {
+				return storeProductMySQL(ctx, tx, &ps[i])
+			})
+		})
+		return
+	}
+
+	// all or nothing: multi-row inserts
+	err = inTx(ctx, r.db, func(tx conn) (err error) {
+		for start := 0; start < len(ps); start += bulkInsertSizeMySQL {
+			end := min(start+bulkInsertSizeMySQL, len(ps))
+			chunk := ps[start:end]
+
+			// - build the query
+			placeholders := make([]string, len(chunk))
+			args := make([]any, 0, len(chunk)*7)
+			for i, p := range chunk {
+				placeholders[i] = "(?, ?, ?, ?, ?, ?, ?)"
+				args = append(args, p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.WarehouseId)
+			}
+			query := "INSERT INTO `products` (`name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`) " +
+				"VALUES " + strings.Join(placeholders, ", ")
+
+			// - execute the query
+			if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+				err = fmt.Errorf("%w: %w", internal.ErrProductBulkAborted, productErrorMySQL(err))
+				return
+			}
+
+			// - read back the id of each product by its unique code value,
+			//   as the ids of a multi-row insert are not guaranteed to be consecutive
+			if err = storedIdsMySQL(ctx, tx, chunk); err != nil {
+				return
+			}
+
+			// - write their search terms
+			if err = indexProductsSQL(ctx, tx, searchMySQL, chunk); err != nil {
+				return
+			}
+		}
+
+		return
+	})
+	return
+}
+
+// storedIdsMySQL sets the id and the version of the products just inserted, found by their code value
+func storedIdsMySQL(ctx context.Context, tx conn, ps []internal.Product) (err error) {
+	// build the query
+	placeholders := make([]string, len(ps))
+	args := make([]any, len(ps))
+	index := make(map[string]int, len(ps))
+	for i, p := range ps {
+		placeholders[i] = "?"
+		args[i] = p.CodeValue
+		index[p.CodeValue] = i
+	}
+	query := "SELECT `id`, `code_value` FROM `products` WHERE `code_value` IN (" + strings.Join(placeholders, ", ") + ")"
+
+	// execute the query
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	// set the ids
+	var n int
+	for rows.Next() {
+		var id int
+		var code string
+		if err = rows.Scan(&id, &code); err != nil {
+			return
+		}
+		ps[index[code]].ID = id
+		ps[index[code]].Version = 1
+		n++
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+	if n != len(ps) {
+		err = fmt.Errorf("%w: %d of %d products stored", internal.ErrProductBulkAborted, n, len(ps))
+	}
+	return
+}
+
+// UpdateBulk updates products in a single transaction, then the search terms of the ones updated
+func (r *ProductsMySQL) UpdateBulk(ctx context.Context, ps []internal.Product, atomic bool) (errs []error, err error) {
+	errs, err = indexBulkSQL(ctx, r.db, searchMySQL, ps, func(tx conn) (errs []error, err error) {
+		return bulkTx(ctx, tx, false, len(ps), atomic, func(tx conn, i int) error {
+			return updateProductMySQL(ctx, tx, &ps[i])
+		})
+	})
+	return
+}
+
+// DeleteBulk deletes products by id in a single transaction
+func (r *ProductsMySQL) DeleteBulk(ctx context.Context, ids []int, atomic bool) (errs []error, err error) {
+	errs, err = bulkTx(ctx, r.db, false, len(ids), atomic, func(tx conn, i int) error {
+		return deleteProductMySQL(ctx, tx, ids[i], 0)
+	})
+	return
+}
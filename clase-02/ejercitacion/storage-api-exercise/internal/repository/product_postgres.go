@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the pgx driver
+)
+
+// NewProductsPostgres returns a new instance of ProductsPostgres
+func NewProductsPostgres(db *sql.DB) *ProductsPostgres {
+	return &ProductsPostgres{
+		db: db,
+	}
+}
+
+// ProductsPostgres is a struct that represents a product repository
+type ProductsPostgres struct {
+	// db is the database connection, or the transaction of a unit of work
+	db conn
+}
+
+// GetAll returns all products
+func (r *ProductsPostgres) GetAll(ctx context.Context) (products []internal.Product, err error) {
+	query := "SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version FROM products"
+
+	row, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return
+	}
+	defer row.Close()
+
+	var p internal.Product
+	for row.Next() {
+		err = row.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.WarehouseId, &p.Version)
+		if err != nil {
+			return
+		}
+		products = append(products, p)
+	}
+	err = row.Err()
+
+	return
+}
+
+// GetOne returns a product by id
+func (r *ProductsPostgres) GetOne(ctx context.Context, id int) (p internal.Product, err error) {
+	// execute the query
+	row := r.db.QueryRowContext(ctx,
+		"SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version "+
+			"FROM products WHERE id = $1",
+		id,
+	)
+
+	// scan the row into the product
+	err = row.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.WarehouseId, &p.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// productWarehouseQueryPostgres selects the products joined with their warehouse
+const productWarehouseQueryPostgres = "SELECT p.id, p.name, p.quantity, p.code_value, p.is_published, p.expiration, p.price, p.id_warehouse, p.version, " +
+	"w.id, w.name, w.adress, w.telephone, w.capacity, w.version " +
+	"FROM products p INNER JOIN warehouses w ON w.id = p.id_warehouse"
+
+// GetAllWithWarehouse returns all products sorted by id with their warehouse, in a single query joining them
+func (r *ProductsPostgres) GetAllWithWarehouse(ctx context.Context) (products []internal.ProductWarehouse, err error) {
+	rows, err := r.db.QueryContext(ctx, productWarehouseQueryPostgres+" ORDER BY p.id")
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p internal.ProductWarehouse
+		if p, err = scanProductWarehouse(rows); err != nil {
+			return
+		}
+		products = append(products, p)
+	}
+	err = rows.Err()
+
+	return
+}
+
+// GetOneWithWarehouse returns a product by id with its warehouse, in a single query joining them
+func (r *ProductsPostgres) GetOneWithWarehouse(ctx context.Context, id int) (p internal.ProductWarehouse, err error) {
+	row := r.db.QueryRowContext(ctx, productWarehouseQueryPostgres+" WHERE p.id = $1", id)
+
+	p, err = scanProductWarehouse(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// GetByCodeValue returns a product by code value
+func (r *ProductsPostgres) GetByCodeValue(ctx context.Context, code string) (p internal.Product, err error) {
+	// execute the query
+	row := r.db.QueryRowContext(ctx,
+		"SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version "+
+			"FROM products WHERE code_value = $1",
+		code,
+	)
+
+	// scan the row into the product
+	err = row.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.WarehouseId, &p.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// GetByWarehouses returns the products of the warehouses of ids sorted by id, in a single query
+func (r *ProductsPostgres) GetByWarehouses(ctx context.Context, ids []int) (products []internal.Product, err error) {
+	if len(ids) == 0 {
+		return
+	}
+	list, args := inIds(ids, true)
+	query := "SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version FROM products WHERE id_warehouse IN " + list + " ORDER BY id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	products, err = scanProducts(rows)
+	return
+}
+
+// Store stores a product with its search terms
+func (r *ProductsPostgres) Store(ctx context.Context, p *internal.Product) (err error) {
+	err = inTx(ctx, r.db, func(tx conn) (err error) {
+		if err = storeProductPostgres(ctx, tx, p); err != nil {
+			return
+		}
+		err = indexProductsSQL(ctx, tx, searchPostgres, []internal.Product{*p})
+		return
+	})
+	return
+}
+
+// Update updates a product with its search terms if its version matches the stored one
+func (r *ProductsPostgres) Update(ctx context.Context, p *internal.Product) (err error) {
+	err = inTx(ctx, r.db, func(tx conn) (err error) {
+		if err = updateProductPostgres(ctx, tx, p); err != nil {
+			return
+		}
+		err = indexProductsSQL(ctx, tx, searchPostgres, []internal.Product{*p})
+		return
+	})
+	return
+}
+
+// Delete deletes a product by id if its version matches the stored one, whatever its version when it is 0
+func (r *ProductsPostgres) Delete(ctx context.Context, id int, version int) (err error) {
+	err = deleteProductPostgres(ctx, r.db, id, version)
+	return
+}
+
+// Search returns the products matched by a query through their search terms
+func (r *ProductsPostgres) Search(ctx context.Context, query string, limit int) (ms []internal.ProductMatch, err error) {
+	ms, err = searchProductsSQL(ctx, r.db, searchPostgres, query, limit)
+	return
+}
+
+// Reindex writes again the search terms of every product, for the ones stored before the search tables.
+// It returns the number of products indexed
+func (r *ProductsPostgres) Reindex(ctx context.Context) (n int, err error) {
+	n, err = reindexProductsSQL(ctx, r.db, searchPostgres)
+	return
+}
+
+// productErrorPostgres maps a postgres error into a product repository error
+func productErrorPostgres(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return internal.ErrProductNotUnique
+		case "23503": // foreign_key_violation
+			return internal.ErrProductRelation
+		}
+	}
+	return err
+}
+
+// storeProductPostgres inserts a product
+func storeProductPostgres(ctx context.Context, db conn, p *internal.Product) (err error) {
+	// execute the query, returning the generated id
+	err = db.QueryRowContext(ctx,
+		"INSERT INTO products (name, quantity, code_value, is_published, expiration, price, id_warehouse) "+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.WarehouseId,
+	).Scan(&p.ID)
+	if err != nil {
+		err = productErrorPostgres(err)
+		return
+	}
+	p.Version = 1
+
+	return
+}
+
+// updateProductPostgres updates a product if its version matches the stored one
+func updateProductPostgres(ctx context.Context, db conn, p *internal.Product) (err error) {
+	// execute the query
+	result, err := db.ExecContext(ctx,
+		"UPDATE products SET name = $1, quantity = $2, code_value = $3, is_published = $4, expiration = $5, price = $6, id_warehouse = $7, version = version + 1 "+
+			"WHERE id = $8 AND version = $9",
+		p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.WarehouseId, p.ID, p.Version,
+	)
+	if err != nil {
+		err = productErrorPostgres(err)
+		return
+	}
+
+	// check the affected rows
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if rowsAffected == 0 {
+		// - the product was either deleted or modified by someone else
+		var exists bool
+		err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)", p.ID).Scan(&exists)
+		if err != nil {
+			return
+		}
+		err = internal.ErrProductVersionConflict
+		if !exists {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+	p.Version++
+
+	return
+}
+
+// deleteProductPostgres deletes a product by id if its version matches the stored one, whatever its version when it is 0
+func deleteProductPostgres(ctx context.Context, db conn, id int, version int) (err error) {
+	// execute the query
+	result, err := db.ExecContext(ctx,
+		"DELETE FROM products WHERE id = $1 AND ($2 = 0 OR version = $2)",
+		id, version,
+	)
+	if err != nil {
+		err = productErrorPostgres(err)
+		return
+	}
+
+	// check the affected rows
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if rowsAffected == 0 {
+		// - the product was either deleted or modified by someone else
+		var exists bool
+		err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)", id).Scan(&exists)
+		if err != nil {
+			return
+		}
+		err = internal.ErrProductVersionConflict
+		if !exists {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+
+	return
+}
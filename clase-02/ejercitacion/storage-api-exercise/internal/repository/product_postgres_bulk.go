@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// bulkInsertSizePostgres is the maximum number of rows of a multi-row insert
+const bulkInsertSizePostgres = 500
+
+// StoreBulk stores products in a single transaction
+func (r *ProductsPostgres) StoreBulk(ctx context.Context, ps []internal.Product, atomic bool) (errs []error, err error) {
+	// best effort: one insert per product, then the search terms of the ones stored
+	if !atomic {
+		errs, err = indexBulkSQL(ctx, r.db, searchPostgres, ps, func(tx conn) (errs []error, err error) {
+			return bulkTx(ctx, tx, true, len(ps), atomic, func(tx conn, i int) error {
+				return storeProductPostgres(ctx, tx, &ps[i])
+			})
+		})
+		return
+	}
+
+	// all or nothing: multi-row inserts
+	err = inTx(ctx, r.db, func(tx conn) (err error) {
+		for start := 0; start < len(ps); start += bulkInsertSizePostgres {
+			end := min(start+bulkInsertSizePostgres, len(ps))
+			chunk := ps[start:end]
+
+			// - build the query
+			placeholders := make([]string, len(chunk))
+			args := make([]any, 0, len(chunk)*7)
+			for i, p := range chunk {
+				n := i * 7
+				placeholders[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7)
+				args = append(args, p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.WarehouseId)
+			}
+			query := "INSERT INTO products (name, quantity, code_value, is_published, expiration, price, id_warehouse) " +
+				"VALUES " + strings.Join(placeholders, ", ") + " RETURNING id"
+
+			// - execute the query, the ids are returned in the order of the values
+			err = func() (err error) {
+				rows, err := tx.QueryContext(ctx, query, args...)
+				if err != nil {
+					return
+				}
+				defer rows.Close()
+				for i := 0; rows.Next(); i++ {
+					if err = rows.Scan(&chunk[i].ID); err != nil {
+						return
+					}
+					chunk[i].Version = 1
+				}
+				err = rows.Err()
+				return
+			}()
+			if err != nil {
+				err = fmt.Errorf("%w: %w", internal.ErrProductBulkAborted, productErrorPostgres(err))
+				return
+			}
+
+			// - write their search terms
+			if err = indexProductsSQL(ctx, tx, searchPostgres, chunk); err != nil {
+				return
+			}
+		}
+
+		return
+	})
+	return
+}
+
+// UpdateBulk updates products in a single transaction, then the search terms of the ones updated
+func (r *ProductsPostgres) UpdateBulk(ctx context.Context, ps []internal.Product, atomic bool) (errs []error, err error) {
+	errs, err = indexBulkSQL(ctx, r.db, searchPostgres, ps, func(tx conn) (errs []error, err error) {
+		return bulkTx(ctx, tx, true, len(ps), atomic, func(tx conn, i int) error {
+			return updateProductPostgres(ctx, tx, &ps[i])
+		})
+	})
+	return
+}
+
+// DeleteBulk deletes products by id in a single transaction
+func (r *ProductsPostgres) DeleteBulk(ctx context.Context, ids []int, atomic bool) (errs []error, err error) {
+	errs, err = bulkTx(ctx, r.db, true, len(ids), atomic, func(tx conn, i int) error {
+		return deleteProductPostgres(ctx, tx, ids[i], 0)
+	})
+	return
+}
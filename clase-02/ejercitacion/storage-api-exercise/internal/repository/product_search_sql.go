@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"app/internal"
+	"app/platform/search"
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// The sql repositories search the products through two tables written with each product in its transaction:
+// product_search_terms, the terms of its name and code value, and product_search_typos, the typo keys of each term
+// (search.TypoKeys) stored once for every product with it. Their queries use unquoted identifiers and ? placeholders,
+// so the three dialects share them but for what searchDialect tells apart
+
+// searchDialect is a struct that represents what the queries of the search terms differ in between sql dialects
+type searchDialect struct {
+	// dollar numbers the placeholders from $1, as postgres does
+	dollar bool
+	// ignore inserts rows skipping the ones with a key already stored: INSERT IGNORE in mysql, ON CONFLICT elsewhere
+	ignore func(insert string) string
+}
+
+var (
+	// searchMySQL is the dialect of the search terms of mysql
+	searchMySQL = searchDialect{ignore: func(insert string) string {
+		return strings.Replace(insert, "INSERT INTO", "INSERT IGNORE INTO", 1)
+	}}
+	// searchPostgres is the dialect of the search terms of postgres
+	searchPostgres = searchDialect{dollar: true, ignore: onConflictDoNothing}
+	// searchSQLite is the dialect of the search terms of sqlite
+	searchSQLite = searchDialect{ignore: onConflictDoNothing}
+)
+
+// onConflictDoNothing returns an insert skipping the rows with a key already stored, in postgres and sqlite
+func onConflictDoNothing(insert string) string {
+	return insert + " ON CONFLICT DO NOTHING"
+}
+
+// rebind returns a query with its ? placeholders numbered from $1 in a dialect that does so
+func (d searchDialect) rebind(query string) string {
+	if !d.dollar {
+		return query
+	}
+	var b strings.Builder
+	var n int
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// inValues returns the list of placeholders of an IN condition on values, with their arguments
+func inValues(values []string) (list string, args []any) {
+	placeholders := make([]string, len(values))
+	args = make([]any, len(values))
+	for i, v := range values {
+		placeholders[i] = "?"
+		args[i] = v
+	}
+	list = "(" + strings.Join(placeholders, ", ") + ")"
+	return
+}
+
+// searchInsertSize is the maximum number of rows of a multi-row insert of search terms
+const searchInsertSize = 1000
+
+// indexProductsSQL writes the search terms of products, replacing the ones they had, and the typo keys of the terms
+// not stored yet. The terms of a deleted product are deleted with it by their foreign key, their typo keys are kept
+// for the next products with them
+func indexProductsSQL(ctx context.Context, db conn, d searchDialect, ps []internal.Product) (err error) {
+	if len(ps) == 0 {
+		return
+	}
+
+	// delete the previous terms
+	ids := make([]int, len(ps))
+	for i, p := range ps {
+		ids[i] = p.ID
+	}
+	list, args := inIds(ids, d.dollar)
+	if _, err = db.ExecContext(ctx, "DELETE FROM product_search_terms WHERE id_product IN "+list, args...); err != nil {
+		return
+	}
+
+	// rows of the terms and of their typo keys
+	var terms, typos [][]any
+	seen := make(map[string]bool)
+	for _, p := range ps {
+		for _, t := range search.Terms(p.Name + " " + p.CodeValue) {
+			terms = append(terms, []any{t, p.ID})
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			for _, k := range search.TypoKeys(t) {
+				typos = append(typos, []any{k, t})
+			}
+		}
+	}
+	if err = insertRowsSQL(ctx, db, d, "product_search_terms (term, id_product)", terms); err != nil {
+		return
+	}
+	err = insertRowsSQL(ctx, db, d, "product_search_typos (variant, term)", typos)
+	return
+}
+
+// insertRowsSQL inserts rows into a table, given with its columns, in multi-row inserts of up to searchInsertSize rows.
+// The rows with a key already stored are skipped
+func insertRowsSQL(ctx context.Context, db conn, d searchDialect, table string, rows [][]any) (err error) {
+	for start := 0; start < len(rows); start += searchInsertSize {
+		chunk := rows[start:min(start+searchInsertSize, len(rows))]
+
+		// - build the query
+		values := make([]string, len(chunk))
+		var args []any
+		for i, row := range chunk {
+			values[i] = "(" + strings.TrimSuffix(strings.Repeat("?, ", len(row)), ", ") + ")"
+			args = append(args, row...)
+		}
+		query := d.ignore("INSERT INTO " + table + " VALUES " + strings.Join(values, ", "))
+
+		// - execute the query
+		if _, err = db.ExecContext(ctx, d.rebind(query), args...); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// indexBulkSQL runs a bulk write of products in a transaction, then writes the search terms of the ones written in it.
+// The terms are written apart from each product, so a product that fails in best effort mode leaves none behind
+// without the savepoints mysql and sqlite do not need for a single statement
+func indexBulkSQL(ctx context.Context, c conn, d searchDialect, ps []internal.Product, bulk func(tx conn) (errs []error, err error)) (errs []error, err error) {
+	err = inTx(ctx, c, func(tx conn) (err error) {
+		if errs, err = bulk(tx); err != nil {
+			return
+		}
+
+		var written []internal.Product
+		for i, p := range ps {
+			if errs[i] == nil {
+				written = append(written, p)
+			}
+		}
+		err = indexProductsSQL(ctx, tx, d, written)
+		return
+	})
+	return
+}
+
+// reindexProductsSQL writes again the search terms of every product, for the ones stored before the search tables.
+// It returns the number of products indexed
+func reindexProductsSQL(ctx context.Context, db conn, d searchDialect) (n int, err error) {
+	err = inTx(ctx, db, func(tx conn) (err error) {
+		rows, err := tx.QueryContext(ctx, "SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version FROM products")
+		if err != nil {
+			return
+		}
+		ps, err := scanProducts(rows)
+		rows.Close()
+		if err != nil {
+			return
+		}
+
+		if err = indexProductsSQL(ctx, tx, d, ps); err != nil {
+			return
+		}
+		n = len(ps)
+		return
+	})
+	return
+}
+
+// searchTermsSQL returns the stored terms matched by a query, each term of the query looked up by an index:
+// the terms it starts by in the range of the primary key of product_search_terms, and its typos by their typo keys,
+// the ones it is or has among its own keys (search.QueryTypoKeys) in product_search_terms and product_search_typos
+func searchTermsSQL(ctx context.Context, db conn, d searchDialect, query string) (terms []string, err error) {
+	seen := make(map[string]bool)
+	for _, q := range search.Terms(query) {
+		// - the terms starting by q sort between q and q followed by the greatest rune, as the columns compare by code point
+		lookup := "SELECT term FROM product_search_terms WHERE term >= ? AND term < ?"
+		args := []any{q, q + string(utf8.MaxRune)}
+		if keys := search.QueryTypoKeys(q); len(keys) > 0 {
+			list, keyArgs := inValues(keys)
+			lookup += " UNION SELECT term FROM product_search_terms WHERE term IN " + list +
+				" UNION SELECT term FROM product_search_typos WHERE variant IN " + list
+			args = append(append(args, keyArgs...), keyArgs...)
+		}
+
+		err = func() (err error) {
+			rows, err := db.QueryContext(ctx, d.rebind(lookup), args...)
+			if err != nil {
+				return
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var term string
+				if err = rows.Scan(&term); err != nil {
+					return
+				}
+				if _, ok := search.Match(q, term); ok && !seen[term] {
+					seen[term] = true
+					terms = append(terms, term)
+				}
+			}
+			err = rows.Err()
+			return
+		}()
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// searchProductsSQL returns the products matched by a query through their search terms, ranked as the memory
+// repository does: the candidates are the products with a term matched, indexed in memory with the number of
+// products as their corpus, so the rarity of a term weighs as it does in an index of all of them
+func searchProductsSQL(ctx context.Context, db conn, d searchDialect, query string, limit int) (ms []internal.ProductMatch, err error) {
+	// terms matched
+	terms, err := searchTermsSQL(ctx, db, d, query)
+	if err != nil || len(terms) == 0 {
+		return
+	}
+
+	// candidates
+	list, args := inValues(terms)
+	candidates := "SELECT id, name, quantity, code_value, is_published, expiration, price, id_warehouse, version FROM products " +
+		"WHERE id IN (SELECT id_product FROM product_search_terms WHERE term IN " + list + ")"
+	rows, err := db.QueryContext(ctx, d.rebind(candidates), args...)
+	if err != nil {
+		return
+	}
+	ps, err := scanProducts(rows)
+	rows.Close()
+	if err != nil {
+		return
+	}
+	products := make(map[int]internal.Product, len(ps))
+	x := newProductIndex()
+	for _, p := range ps {
+		products[p.ID] = p
+		indexProduct(x, p)
+	}
+
+	// rank
+	var total int
+	if err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products").Scan(&total); err != nil {
+		return
+	}
+	x.SetCorpus(total)
+	ms = searchProducts(x, products, query, limit)
+	return
+}
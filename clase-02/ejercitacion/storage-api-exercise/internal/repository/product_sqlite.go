@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// DSNSQLite returns the data source name of a sqlite database file.
+// Foreign keys are enforced and locked databases are waited for, as mysql does.
+// Transactions take the write lock when they begin, so those of units of work that read before writing do not deadlock.
+func DSNSQLite(path string) string {
+	q := url.Values{}
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Set("_time_format", "sqlite")
+	q.Set("_txlock", "immediate")
+	return "file:" + path + "?" + q.Encode()
+}
+
+// NewProductsSQLite returns a new instance of ProductsSQLite
+func NewProductsSQLite(db *sql.DB) *ProductsSQLite {
+	return &ProductsSQLite{
+		db: db,
+	}
+}
+
+// ProductsSQLite is a struct that represents a product repository
+type ProductsSQLite struct {
+	// db is the database connection, or the transaction of a unit of work
+	db conn
+}
+
+// GetAll returns all products
+func (r *ProductsSQLite) GetAll(ctx context.Context) (products []internal.Product, err error) {
+	query := "SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`, `version` FROM `products`"
+
+	row, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return
+	}
+	defer row.Close()
+
+	var p internal.Product
+	for row.Next() {
+		err = row.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.WarehouseId, &p.Version)
+		if err != nil {
+			return
+		}
+		products = append(products, p)
+	}
+	err = row.Err()
+
+	return
+}
+
+// GetOne returns a product by id
+func (r *ProductsSQLite) GetOne(ctx context.Context, id int) (p internal.Product, err error) {
+	// execute the query
+	row := r.db.QueryRowContext(ctx,
+		"SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`, `version` "+
+			"FROM `products` WHERE `id` = ?",
+		id,
+	)
+
+	// scan the row into the product
+	err = row.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.WarehouseId, &p.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// productWarehouseQuerySQLite selects the products joined with their warehouse
+const productWarehouseQuerySQLite = "SELECT p.`id`, p.`name`, p.`quantity`, p.`code_value`, p.`is_published`, p.`expiration`, p.`price`, p.`id_warehouse`, p.`version`, " +
+	"w.`id`, w.`name`, w.`adress`, w.`telephone`, w.`capacity`, w.`version` " +
+	"FROM `products` p INNER JOIN `warehouses` w ON w.`id` = p.`id_warehouse`"
+
+// GetAllWithWarehouse returns all products sorted by id with their warehouse, in a single query joining them
+func (r *ProductsSQLite) GetAllWithWarehouse(ctx context.Context) (products []internal.ProductWarehouse, err error) {
+	rows, err := r.db.QueryContext(ctx, productWarehouseQuerySQLite+" ORDER BY p.`id`")
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p internal.ProductWarehouse
+		if p, err = scanProductWarehouse(rows); err != nil {
+			return
+		}
+		products = append(products, p)
+	}
+	err = rows.Err()
+
+	return
+}
+
+// GetOneWithWarehouse returns a product by id with its warehouse, in a single query joining them
+func (r *ProductsSQLite) GetOneWithWarehouse(ctx context.Context, id int) (p internal.ProductWarehouse, err error) {
+	row := r.db.QueryRowContext(ctx, productWarehouseQuerySQLite+" WHERE p.`id` = ?", id)
+
+	p, err = scanProductWarehouse(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// GetByCodeValue returns a product by code value
+func (r *ProductsSQLite) GetByCodeValue(ctx context.Context, code string) (p internal.Product, err error) {
+	// execute the query
+	row := r.db.QueryRowContext(ctx,
+		"SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`, `version` "+
+			"FROM `products` WHERE `code_value` = ?",
+		code,
+	)
+
+	// scan the row into the product
+	err = row.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.WarehouseId, &p.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+
+	return
+}
+
+// GetByWarehouses returns the products of the warehouses of ids sorted by id, in a single query
+func (r *ProductsSQLite) GetByWarehouses(ctx context.Context, ids []int) (products []internal.Product, err error) {
+	if len(ids) == 0 {
+		return
+	}
+	list, args := inIds(ids, false)
+	query := "SELECT `id`, `name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`, `version` FROM `products` WHERE `id_warehouse` IN " + list + " ORDER BY `id`"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	products, err = scanProducts(rows)
+	return
+}
+
+// Store stores a product with its search terms
+func (r *ProductsSQLite) Store(ctx context.Context, p *internal.Product) (err error) {
+	err = inTx(ctx, r.db, func(tx conn) (err error) {
+		if err = storeProductSQLite(ctx, tx, p); err != nil {
+			return
+		}
+		err = indexProductsSQL(ctx, tx, searchSQLite, []internal.Product{*p})
+		return
+	})
+	return
+}
+
+// Update updates a product with its search terms if its version matches the stored one
+func (r *ProductsSQLite) Update(ctx context.Context, p *internal.Product) (err error) {
+	err = inTx(ctx, r.db, func(tx conn) (err error) {
+		if err = updateProductSQLite(ctx, tx, p); err != nil {
+			return
+		}
+		err = indexProductsSQL(ctx, tx, searchSQLite, []internal.Product{*p})
+		return
+	})
+	return
+}
+
+// Delete deletes a product by id if its version matches the stored one, whatever its version when it is 0
+func (r *ProductsSQLite) Delete(ctx context.Context, id int, version int) (err error) {
+	err = deleteProductSQLite(ctx, r.db, id, version)
+	return
+}
+
+// Search returns the products matched by a query through their search terms
+func (r *ProductsSQLite) Search(ctx context.Context, query string, limit int) (ms []internal.ProductMatch, err error) {
+	ms, err = searchProductsSQL(ctx, r.db, searchSQLite, query, limit)
+	return
+}
+
+// Reindex writes again the search terms of every product, for the ones stored before the search tables.
+// It returns the number of products indexed
+func (r *ProductsSQLite) Reindex(ctx context.Context) (n int, err error) {
+	n, err = reindexProductsSQL(ctx, r.db, searchSQLite)
+	return
+}
+
+// productErrorSQLite maps a sqlite error into a product repository error
+func productErrorSQLite(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return internal.ErrProductNotUnique
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return internal.ErrProductRelation
+		}
+	}
+	return err
+}
+
+// storeProductSQLite inserts a product
+func storeProductSQLite(ctx context.Context, db conn, p *internal.Product) (err error) {
+	// execute the query
+	result, err := db.ExecContext(ctx,
+		"INSERT INTO `products` (`name`, `quantity`, `code_value`, `is_published`, `expiration`, `price`, `id_warehouse`) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?)",
+		p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.WarehouseId,
+	)
+	if err != nil {
+		err = productErrorSQLite(err)
+		return
+	}
+
+	// get the last inserted id
+	id, err := result.LastInsertId()
+	if err != nil {
+		return
+	}
+	p.ID = int(id)
+	p.Version = 1
+
+	return
+}
+
+// updateProductSQLite updates a product if its version matches the stored one
+func updateProductSQLite(ctx context.Context, db conn, p *internal.Product) (err error) {
+	// execute the query
+	result, err := db.ExecContext(ctx,
+		"UPDATE `products` SET `name` = ?, `quantity` = ?, `code_value` = ?, `is_published` = ?, `expiration` = ?, `price` = ?, `id_warehouse` = ?, `version` = `version` + 1 "+
+			"WHERE `id` = ? AND `version` = ?",
+		p.Name, p.Quantity, p.CodeValue, p.IsPublished, p.Expiration, p.Price, p.WarehouseId, p.ID, p.Version,
+	)
+	if err != nil {
+		err = productErrorSQLite(err)
+		return
+	}
+
+	// check the affected rows
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if rowsAffected == 0 {
+		// - the product was either deleted or modified by someone else
+		var exists bool
+		err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM `products` WHERE `id` = ?)", p.ID).Scan(&exists)
+		if err != nil {
+			return
+		}
+		err = internal.ErrProductVersionConflict
+		if !exists {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+	p.Version++
+
+	return
+}
+
+// deleteProductSQLite deletes a product by id if its version matches the stored one, whatever its version when it is 0
+func deleteProductSQLite(ctx context.Context, db conn, id int, version int) (err error) {
+	// execute the query
+	result, err := db.ExecContext(ctx,
+		"DELETE FROM `products` WHERE `id` = ? AND (? = 0 OR `version` = ?)",
+		id, version, version,
+	)
+	if err != nil {
+		err = productErrorSQLite(err)
+		return
+	}
+
+	// check the affected rows
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if rowsAffected == 0 {
+		// - the product was either deleted or modified by someone else
+		var exists bool
+		err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM `products` WHERE `id` = ?)", id).Scan(&exists)
+		if err != nil {
+			return
+		}
+		err = internal.ErrProductVersionConflict
+		if !exists {
+			err = internal.ErrProductNotFound
+		}
+		return
+	}
+
+	return
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"app/internal"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// scanner is the subset of *sql.Row and *sql.Rows used to scan a row
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// inIds returns the list of placeholders of an IN condition on ids, with their arguments:
+// ? for mysql and sqlite, or numbered from $1 when dollar is true for postgres
+func inIds(ids []int, dollar bool) (list string, args []any) {
+	placeholders := make([]string, len(ids))
+	args = make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		if dollar {
+			placeholders[i] = fmt.Sprintf("$%d", i+1)
+		}
+		args[i] = id
+	}
+	list = "(" + strings.Join(placeholders, ", ") + ")"
+	return
+}
+
+// scanProducts scans the rows of products, the columns of a product as the sql repositories select them
+func scanProducts(rows *sql.Rows) (products []internal.Product, err error) {
+	for rows.Next() {
+		var p internal.Product
+		err = rows.Scan(&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.WarehouseId, &p.Version)
+		if err != nil {
+			return
+		}
+		products = append(products, p)
+	}
+	err = rows.Err()
+	return
+}
+
+// scanWarehouses scans the rows of warehouses, the columns of a warehouse as the sql repositories select them
+func scanWarehouses(rows *sql.Rows) (w []internal.Warehouse, err error) {
+	for rows.Next() {
+		var warehouse internal.Warehouse
+		err = rows.Scan(&warehouse.Id, &warehouse.Name, &warehouse.Address, &warehouse.Telephone, &warehouse.Capacity, &warehouse.Version)
+		if err != nil {
+			return
+		}
+		w = append(w, warehouse)
+	}
+	err = rows.Err()
+	return
+}
+
+// scanProductWarehouse scans a row of a product joined with its warehouse:
+// the columns of the product followed by the ones of the warehouse, as the sql repositories select them
+func scanProductWarehouse(row scanner) (p internal.ProductWarehouse, err error) {
+	err = row.Scan(
+		&p.ID, &p.Name, &p.Quantity, &p.CodeValue, &p.IsPublished, &p.Expiration, &p.Price, &p.WarehouseId, &p.Version,
+		&p.Warehouse.Id, &p.Warehouse.Name, &p.Warehouse.Address, &p.Warehouse.Telephone, &p.Warehouse.Capacity, &p.Warehouse.Version,
+	)
+	return
+}
+
+// scanWarehouseProducts scans the rows of warehouses left joined with their products, sorted by warehouse:
+// the columns of the warehouse followed by the ones of a product, null when the warehouse has none.
+// The rows of a warehouse are grouped into one with its products
+func scanWarehouseProducts(rows *sql.Rows) (w []internal.WarehouseProducts, err error) {
+	for rows.Next() {
+		var warehouse internal.Warehouse
+		var (
+			id, quantity, warehouseId, version sql.NullInt64
+			name, codeValue                    sql.NullString
+			isPublished                        sql.NullBool
+			expiration                         sql.NullTime
+			price                              sql.NullFloat64
+		)
+		err = rows.Scan(
+			&warehouse.Id, &warehouse.Name, &warehouse.Address, &warehouse.Telephone, &warehouse.Capacity, &warehouse.Version,
+			&id, &name, &quantity, &codeValue, &isPublished, &expiration, &price, &warehouseId, &version,
+		)
+		if err != nil {
+			return
+		}
+
+		// a row of a new warehouse
+		if len(w) == 0 || w[len(w)-1].Id != warehouse.Id {
+			w = append(w, internal.WarehouseProducts{Warehouse: warehouse})
+		}
+		// a product of the warehouse
+		if id.Valid {
+			last := &w[len(w)-1]
+			last.Products = append(last.Products, internal.Product{
+				ID:          int(id.Int64),
+				Name:        name.String,
+				Quantity:    int(quantity.Int64),
+				CodeValue:   codeValue.String,
+				IsPublished: isPublished.Bool,
+				Expiration:  expiration.Time,
+				Price:       price.Float64,
+				WarehouseId: int(warehouseId.Int64),
+				Version:     int(version.Int64),
+			})
+		}
+	}
+	err = rows.Err()
+	return
+}
@@ -0,0 +1,753 @@
+// Package repositorytest holds the contract every implementation of the repositories must satisfy.
+// Each backend runs it from its own tests with a factory of empty repositories.
+package repositorytest
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns new repositories, without products nor warehouses, sharing the same database
+type Factory func(t *testing.T) (rp internal.RepositoryProducts, rw internal.WarehouseRepository)
+
+// newWarehouse stores a warehouse for the products of a test
+func newWarehouse(t *testing.T, rw internal.WarehouseRepository, name string) (w internal.Warehouse) {
+	t.Helper()
+	w = internal.Warehouse{Name: name, Address: "address", Telephone: "telephone", Capacity: 100}
+	require.NoError(t, rw.Store(context.Background(), &w))
+	return
+}
+
+// newProduct returns a product of a warehouse, not stored yet
+func newProduct(code string, warehouseId int) internal.Product {
+	return internal.Product{
+		Name:        "product " + code,
+		Quantity:    10,
+		CodeValue:   code,
+		IsPublished: true,
+		Expiration:  time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC),
+		Price:       9.5,
+		WarehouseId: warehouseId,
+	}
+}
+
+// requireProduct asserts two products are equal, comparing the expiration by date
+func requireProduct(t *testing.T, expected, actual internal.Product) {
+	t.Helper()
+	require.Equal(t, expected.Expiration.Format(time.DateOnly), actual.Expiration.Format(time.DateOnly))
+	expected.Expiration, actual.Expiration = time.Time{}, time.Time{}
+	require.Equal(t, expected, actual)
+}
+
+// Products runs the contract of internal.RepositoryProducts
+func Products(t *testing.T, factory Factory) {
+	t.Run("store assigns id and version", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p1, p2 := newProduct("A1", w.Id), newProduct("A2", w.Id)
+
+		// act
+		err1 := rp.Store(context.Background(), &p1)
+		err2 := rp.Store(context.Background(), &p2)
+
+		// assert
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		require.Positive(t, p1.ID)
+		require.Positive(t, p2.ID)
+		require.NotEqual(t, p1.ID, p2.ID)
+		require.Equal(t, 1, p1.Version)
+		stored, err := rp.GetOne(context.Background(), p1.ID)
+		require.NoError(t, err)
+		requireProduct(t, p1, stored)
+	})
+
+	t.Run("store not unique code value", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p := newProduct("A1", w.Id)
+		require.NoError(t, rp.Store(context.Background(), &p))
+		duplicate := newProduct("A1", w.Id)
+
+		// act
+		err := rp.Store(context.Background(), &duplicate)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductNotUnique)
+	})
+
+	t.Run("store unknown warehouse", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p := newProduct("A1", w.Id+1)
+
+		// act
+		err := rp.Store(context.Background(), &p)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductRelation)
+	})
+
+	t.Run("get one not found", func(t *testing.T) {
+		// arrange
+		rp, _ := factory(t)
+
+		// act
+		_, err := rp.GetOne(context.Background(), 1)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+	})
+
+	t.Run("get by code value", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p := newProduct("A1", w.Id)
+		require.NoError(t, rp.Store(context.Background(), &p))
+
+		// act
+		found, errFound := rp.GetByCodeValue(context.Background(), "A1")
+		_, errNotFound := rp.GetByCodeValue(context.Background(), "B1")
+
+		// assert
+		require.NoError(t, errFound)
+		requireProduct(t, p, found)
+		require.ErrorIs(t, errNotFound, internal.ErrProductNotFound)
+	})
+
+	t.Run("get by warehouses", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w1, w2, w3 := newWarehouse(t, rw, "warehouse 1"), newWarehouse(t, rw, "warehouse 2"), newWarehouse(t, rw, "warehouse 3")
+		p1, p2, p3 := newProduct("A1", w2.Id), newProduct("A2", w1.Id), newProduct("A3", w3.Id)
+		for _, p := range []*internal.Product{&p1, &p2, &p3} {
+			require.NoError(t, rp.Store(context.Background(), p))
+		}
+
+		// act
+		ps, err := rp.GetByWarehouses(context.Background(), []int{w2.Id, w1.Id, 999})
+		none, errNone := rp.GetByWarehouses(context.Background(), nil)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, ps, 2)
+		requireProduct(t, p1, ps[0])
+		requireProduct(t, p2, ps[1])
+		require.NoError(t, errNone)
+		require.Empty(t, none)
+	})
+
+	t.Run("get all", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p1, p2 := newProduct("A1", w.Id), newProduct("A2", w.Id)
+		require.NoError(t, rp.Store(context.Background(), &p1))
+		require.NoError(t, rp.Store(context.Background(), &p2))
+
+		// act
+		ps, err := rp.GetAll(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, ps, 2)
+		ids := []int{ps[0].ID, ps[1].ID}
+		require.ElementsMatch(t, []int{p1.ID, p2.ID}, ids)
+	})
+
+	t.Run("update increments version", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w1, w2 := newWarehouse(t, rw, "warehouse 1"), newWarehouse(t, rw, "warehouse 2")
+		p := newProduct("A1", w1.Id)
+		require.NoError(t, rp.Store(context.Background(), &p))
+		p.Name, p.Quantity, p.WarehouseId = "updated", 20, w2.Id
+
+		// act
+		err := rp.Update(context.Background(), &p)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 2, p.Version)
+		stored, err := rp.GetOne(context.Background(), p.ID)
+		require.NoError(t, err)
+		requireProduct(t, p, stored)
+	})
+
+	t.Run("update with the same values", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p := newProduct("A1", w.Id)
+		require.NoError(t, rp.Store(context.Background(), &p))
+
+		// act
+		err := rp.Update(context.Background(), &p)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 2, p.Version)
+	})
+
+	t.Run("update version conflict", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p := newProduct("A1", w.Id)
+		require.NoError(t, rp.Store(context.Background(), &p))
+		stale := p
+		require.NoError(t, rp.Update(context.Background(), &p))
+
+		// act
+		err := rp.Update(context.Background(), &stale)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductVersionConflict)
+	})
+
+	t.Run("update not found", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p := newProduct("A1", w.Id)
+		p.ID, p.Version = 1, 1
+
+		// act
+		err := rp.Update(context.Background(), &p)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+	})
+
+	t.Run("update not unique code value", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p1, p2 := newProduct("A1", w.Id), newProduct("A2", w.Id)
+		require.NoError(t, rp.Store(context.Background(), &p1))
+		require.NoError(t, rp.Store(context.Background(), &p2))
+		p2.CodeValue = "A1"
+
+		// act
+		err := rp.Update(context.Background(), &p2)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductNotUnique)
+	})
+
+	t.Run("update unknown warehouse", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p := newProduct("A1", w.Id)
+		require.NoError(t, rp.Store(context.Background(), &p))
+		p.WarehouseId = w.Id + 1
+
+		// act
+		err := rp.Update(context.Background(), &p)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductRelation)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p := newProduct("A1", w.Id)
+		require.NoError(t, rp.Store(context.Background(), &p))
+
+		// act
+		err := rp.Delete(context.Background(), p.ID, 0)
+		errNotFound := rp.Delete(context.Background(), p.ID, 0)
+
+		// assert
+		require.NoError(t, err)
+		require.ErrorIs(t, errNotFound, internal.ErrProductNotFound)
+		_, err = rp.GetOne(context.Background(), p.ID)
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+	})
+
+	t.Run("delete version conflict", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p := newProduct("A1", w.Id)
+		require.NoError(t, rp.Store(context.Background(), &p))
+		stale := p.Version
+		require.NoError(t, rp.Update(context.Background(), &p))
+
+		// act
+		errConflict := rp.Delete(context.Background(), p.ID, stale)
+		err := rp.Delete(context.Background(), p.ID, p.Version)
+		errNotFound := rp.Delete(context.Background(), p.ID, p.Version)
+
+		// assert
+		require.ErrorIs(t, errConflict, internal.ErrProductVersionConflict)
+		require.NoError(t, err)
+		require.ErrorIs(t, errNotFound, internal.ErrProductNotFound)
+	})
+
+	t.Run("store bulk atomic", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		ps := []internal.Product{newProduct("A1", w.Id), newProduct("A2", w.Id), newProduct("A3", w.Id)}
+
+		// act
+		_, err := rp.StoreBulk(context.Background(), ps, true)
+
+		// assert
+		require.NoError(t, err)
+		for _, p := range ps {
+			require.Equal(t, 1, p.Version)
+			stored, err := rp.GetOne(context.Background(), p.ID)
+			require.NoError(t, err)
+			requireProduct(t, p, stored)
+		}
+	})
+
+	t.Run("store bulk atomic in several inserts", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		ps := make([]internal.Product, 1001)
+		for i := range ps {
+			ps[i] = newProduct(fmt.Sprintf("B%d", len(ps)-i), w.Id)
+		}
+
+		// act
+		_, err := rp.StoreBulk(context.Background(), ps, true)
+
+		// assert
+		require.NoError(t, err)
+		for _, p := range ps {
+			stored, err := rp.GetByCodeValue(context.Background(), p.CodeValue)
+			require.NoError(t, err)
+			require.Equal(t, stored.ID, p.ID)
+		}
+	})
+
+	t.Run("store bulk atomic aborted", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		ps := []internal.Product{newProduct("A1", w.Id), newProduct("A1", w.Id)}
+
+		// act
+		_, err := rp.StoreBulk(context.Background(), ps, true)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductBulkAborted)
+		require.ErrorIs(t, err, internal.ErrProductNotUnique)
+		stored, err := rp.GetAll(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, stored)
+	})
+
+	t.Run("store bulk best effort", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		ps := []internal.Product{newProduct("A1", w.Id), newProduct("A1", w.Id), newProduct("A2", w.Id+1), newProduct("A3", w.Id)}
+
+		// act
+		errs, err := rp.StoreBulk(context.Background(), ps, false)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, errs, 4)
+		require.NoError(t, errs[0])
+		require.ErrorIs(t, errs[1], internal.ErrProductNotUnique)
+		require.ErrorIs(t, errs[2], internal.ErrProductRelation)
+		require.NoError(t, errs[3])
+		stored, err := rp.GetAll(context.Background())
+		require.NoError(t, err)
+		require.Len(t, stored, 2)
+	})
+
+	t.Run("update bulk best effort", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		ps := []internal.Product{newProduct("A1", w.Id), newProduct("A2", w.Id)}
+		_, err := rp.StoreBulk(context.Background(), ps, true)
+		require.NoError(t, err)
+		ps[0].Name = "updated"
+		ps[1].Version = 5
+
+		// act
+		errs, err := rp.UpdateBulk(context.Background(), ps, false)
+
+		// assert
+		require.NoError(t, err)
+		require.NoError(t, errs[0])
+		require.ErrorIs(t, errs[1], internal.ErrProductVersionConflict)
+		stored, err := rp.GetOne(context.Background(), ps[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, "updated", stored.Name)
+		require.Equal(t, 2, stored.Version)
+	})
+
+	t.Run("delete bulk atomic aborted", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p := newProduct("A1", w.Id)
+		require.NoError(t, rp.Store(context.Background(), &p))
+
+		// act
+		_, err := rp.DeleteBulk(context.Background(), []int{p.ID, p.ID + 1}, true)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductBulkAborted)
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+		_, err = rp.GetOne(context.Background(), p.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("get with warehouse", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w1, w2 := newWarehouse(t, rw, "warehouse 1"), newWarehouse(t, rw, "warehouse 2")
+		p1, p2 := newProduct("A1", w1.Id), newProduct("A2", w2.Id)
+		require.NoError(t, rp.Store(context.Background(), &p1))
+		require.NoError(t, rp.Store(context.Background(), &p2))
+
+		// act
+		all, errAll := rp.GetAllWithWarehouse(context.Background())
+		one, errOne := rp.GetOneWithWarehouse(context.Background(), p2.ID)
+		_, errNotFound := rp.GetOneWithWarehouse(context.Background(), p2.ID+1)
+
+		// assert
+		require.NoError(t, errAll)
+		require.Len(t, all, 2)
+		requireProduct(t, p1, all[0].Product)
+		require.Equal(t, w1, all[0].Warehouse)
+		requireProduct(t, p2, all[1].Product)
+		require.Equal(t, w2, all[1].Warehouse)
+		require.NoError(t, errOne)
+		requireProduct(t, p2, one.Product)
+		require.Equal(t, w2, one.Warehouse)
+		require.ErrorIs(t, errNotFound, internal.ErrProductNotFound)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		p := newProduct("A1", w.Id)
+
+		// act
+		_, errGet := rp.GetAll(ctx)
+		errStore := rp.Store(ctx, &p)
+
+		// assert
+		require.ErrorIs(t, errGet, context.Canceled)
+		require.ErrorIs(t, errStore, context.Canceled)
+		ps, err := rp.GetAll(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, ps)
+	})
+
+	t.Run("search by term, prefix and typo", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p1, p2, p3 := newProduct("A1", w.Id), newProduct("B2", w.Id), newProduct("C3", w.Id)
+		p1.Name, p2.Name, p3.Name = "Chocolate amargo", "Leche chocolatada", "Café molido"
+		_, err := rp.StoreBulk(context.Background(), []internal.Product{p1, p2}, true)
+		require.NoError(t, err)
+		require.NoError(t, rp.Store(context.Background(), &p3))
+
+		// act
+		term, errTerm := rp.Search(context.Background(), "amargo", 10)
+		prefix, errPrefix := rp.Search(context.Background(), "choco", 10)
+		typo, errTypo := rp.Search(context.Background(), "cafe molio", 10)
+		code, errCode := rp.Search(context.Background(), "b2", 10)
+		none, errNone := rp.Search(context.Background(), "pan", 10)
+
+		// assert
+		require.NoError(t, errTerm)
+		require.Len(t, term, 1)
+		require.Equal(t, "A1", term[0].CodeValue)
+		require.Equal(t, map[string]int{"amargo": 6}, term[0].Matched)
+		require.NoError(t, errPrefix)
+		require.Len(t, prefix, 2)
+		require.NoError(t, errTypo)
+		require.Len(t, typo, 1)
+		require.Equal(t, p3.ID, typo[0].ID)
+		require.Positive(t, typo[0].Score)
+		require.NoError(t, errCode)
+		require.Len(t, code, 1)
+		require.Equal(t, "B2", code[0].CodeValue)
+		require.NoError(t, errNone)
+		require.Empty(t, none)
+	})
+
+	t.Run("search ranks and limits", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p1, p2, p3 := newProduct("A1", w.Id), newProduct("A2", w.Id), newProduct("A3", w.Id)
+		p1.Name, p2.Name, p3.Name = "Jugo de naranja", "Naranja", "Mermelada de naranja y durazno"
+		for _, p := range []*internal.Product{&p1, &p2, &p3} {
+			require.NoError(t, rp.Store(context.Background(), p))
+		}
+
+		// act
+		all, errAll := rp.Search(context.Background(), "naranja", 10)
+		limited, errLimited := rp.Search(context.Background(), "naranja", 2)
+
+		// assert
+		require.NoError(t, errAll)
+		require.Len(t, all, 3)
+		require.GreaterOrEqual(t, all[0].Score, all[1].Score)
+		require.GreaterOrEqual(t, all[1].Score, all[2].Score)
+		require.NoError(t, errLimited)
+		require.Len(t, limited, 2)
+		require.Equal(t, all[:2], limited)
+	})
+
+	t.Run("search follows updates and deletes", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		p1, p2 := newProduct("A1", w.Id), newProduct("A2", w.Id)
+		p1.Name, p2.Name = "Yerba mate", "Galletas de agua"
+		require.NoError(t, rp.Store(context.Background(), &p1))
+		require.NoError(t, rp.Store(context.Background(), &p2))
+		p1.Name = "Te verde"
+
+		// act
+		require.NoError(t, rp.Update(context.Background(), &p1))
+		require.NoError(t, rp.Delete(context.Background(), p2.ID, 0))
+		old, errOld := rp.Search(context.Background(), "yerba", 10)
+		updated, errUpdated := rp.Search(context.Background(), "verde", 10)
+		deleted, errDeleted := rp.Search(context.Background(), "galletas", 10)
+
+		// assert
+		require.NoError(t, errOld)
+		require.Empty(t, old)
+		require.NoError(t, errUpdated)
+		require.Len(t, updated, 1)
+		requireProduct(t, p1, updated[0].Product)
+		require.NoError(t, errDeleted)
+		require.Empty(t, deleted)
+	})
+
+	t.Run("search without the products of a failed bulk", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		ps := []internal.Product{newProduct("A1", w.Id), newProduct("A1", w.Id), newProduct("A2", w.Id)}
+		ps[0].Name, ps[1].Name, ps[2].Name = "Arroz integral", "Arroz blanco", "Harina integral"
+
+		// act
+		_, errAtomic := rp.StoreBulk(context.Background(), ps[:2], true)
+		_, errBestEffort := rp.StoreBulk(context.Background(), []internal.Product{ps[1], ps[1], ps[2]}, false)
+		ms, err := rp.Search(context.Background(), "arroz integral", 10)
+
+		// assert
+		require.ErrorIs(t, errAtomic, internal.ErrProductBulkAborted)
+		require.NoError(t, errBestEffort)
+		require.NoError(t, err)
+		require.Len(t, ms, 2)
+		require.ElementsMatch(t, []string{"Arroz blanco", "Harina integral"}, []string{ms[0].Name, ms[1].Name})
+	})
+}
+
+// Warehouses runs the contract of internal.WarehouseRepository
+func Warehouses(t *testing.T, factory Factory) {
+	t.Run("store assigns id and version", func(t *testing.T) {
+		// arrange
+		_, rw := factory(t)
+		w1 := internal.Warehouse{Name: "warehouse 1", Address: "address 1", Telephone: "telephone 1", Capacity: 100}
+		w2 := internal.Warehouse{Name: "warehouse 2", Address: "address 2", Telephone: "telephone 2", Capacity: 200}
+
+		// act
+		err1 := rw.Store(context.Background(), &w1)
+		err2 := rw.Store(context.Background(), &w2)
+
+		// assert
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		require.Positive(t, w1.Id)
+		require.NotEqual(t, w1.Id, w2.Id)
+		require.Equal(t, 1, w1.Version)
+		stored, err := rw.GetOne(context.Background(), w1.Id)
+		require.NoError(t, err)
+		require.Equal(t, w1, stored)
+	})
+
+	t.Run("get one not found", func(t *testing.T) {
+		// arrange
+		_, rw := factory(t)
+
+		// act
+		_, err := rw.GetOne(context.Background(), 1)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrWarehouseNotFound)
+	})
+
+	t.Run("get one for update", func(t *testing.T) {
+		// arrange
+		_, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+
+		// act
+		locked, err := rw.GetOneForUpdate(context.Background(), w.Id)
+		_, errNotFound := rw.GetOneForUpdate(context.Background(), w.Id+1)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, w, locked)
+		require.ErrorIs(t, errNotFound, internal.ErrWarehouseNotFound)
+	})
+
+	t.Run("get all", func(t *testing.T) {
+		// arrange
+		_, rw := factory(t)
+		w1, w2 := newWarehouse(t, rw, "warehouse 1"), newWarehouse(t, rw, "warehouse 2")
+
+		// act
+		ws, err := rw.GetAll(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		require.ElementsMatch(t, []internal.Warehouse{w1, w2}, ws)
+	})
+
+	t.Run("get by ids", func(t *testing.T) {
+		// arrange
+		_, rw := factory(t)
+		w1, _, w3 := newWarehouse(t, rw, "warehouse 1"), newWarehouse(t, rw, "warehouse 2"), newWarehouse(t, rw, "warehouse 3")
+
+		// act
+		ws, err := rw.GetByIds(context.Background(), []int{w3.Id, 999, w1.Id})
+		none, errNone := rw.GetByIds(context.Background(), nil)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []internal.Warehouse{w1, w3}, ws)
+		require.NoError(t, errNone)
+		require.Empty(t, none)
+	})
+
+	t.Run("update increments version", func(t *testing.T) {
+		// arrange
+		_, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		w.Name, w.Capacity = "updated", 300
+
+		// act
+		err := rw.Update(context.Background(), &w)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 2, w.Version)
+		stored, err := rw.GetOne(context.Background(), w.Id)
+		require.NoError(t, err)
+		require.Equal(t, w, stored)
+	})
+
+	t.Run("update version conflict", func(t *testing.T) {
+		// arrange
+		_, rw := factory(t)
+		w := newWarehouse(t, rw, "warehouse 1")
+		stale := w
+		require.NoError(t, rw.Update(context.Background(), &w))
+
+		// act
+		err := rw.Update(context.Background(), &stale)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrWarehouseVersionConflict)
+	})
+
+	t.Run("update not found", func(t *testing.T) {
+		// arrange
+		_, rw := factory(t)
+		w := internal.Warehouse{Id: 1, Name: "warehouse 1", Version: 1}
+
+		// act
+		err := rw.Update(context.Background(), &w)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrWarehouseNotFound)
+	})
+
+	t.Run("report products", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w1, w2 := newWarehouse(t, rw, "warehouse 1"), newWarehouse(t, rw, "warehouse 2")
+		p1, p2 := newProduct("A1", w1.Id), newProduct("A2", w1.Id)
+		require.NoError(t, rp.Store(context.Background(), &p1))
+		require.NoError(t, rp.Store(context.Background(), &p2))
+
+		// act
+		all, errAll := rw.ReportProducts(context.Background(), 0)
+		one, errOne := rw.ReportProducts(context.Background(), w2.Id)
+
+		// assert
+		require.NoError(t, errAll)
+		require.ElementsMatch(t, []internal.ReportProduct{{Name: "warehouse 1", ProductCount: 2}, {Name: "warehouse 2", ProductCount: 0}}, all)
+		require.NoError(t, errOne)
+		require.Equal(t, []internal.ReportProduct{{Name: "warehouse 2", ProductCount: 0}}, one)
+	})
+	t.Run("get with products", func(t *testing.T) {
+		// arrange
+		rp, rw := factory(t)
+		w1, w2 := newWarehouse(t, rw, "warehouse 1"), newWarehouse(t, rw, "warehouse 2")
+		p1, p2 := newProduct("A1", w1.Id), newProduct("A2", w1.Id)
+		require.NoError(t, rp.Store(context.Background(), &p1))
+		require.NoError(t, rp.Store(context.Background(), &p2))
+
+		// act
+		all, errAll := rw.GetAllWithProducts(context.Background())
+		one, errOne := rw.GetOneWithProducts(context.Background(), w1.Id)
+		empty, errEmpty := rw.GetOneWithProducts(context.Background(), w2.Id)
+		_, errNotFound := rw.GetOneWithProducts(context.Background(), w2.Id+1)
+
+		// assert
+		require.NoError(t, errAll)
+		require.Len(t, all, 2)
+		require.Equal(t, w1, all[0].Warehouse)
+		require.Len(t, all[0].Products, 2)
+		requireProduct(t, p1, all[0].Products[0])
+		requireProduct(t, p2, all[0].Products[1])
+		require.Equal(t, w2, all[1].Warehouse)
+		require.Empty(t, all[1].Products)
+		require.NoError(t, errOne)
+		require.Equal(t, w1, one.Warehouse)
+		require.Len(t, one.Products, 2)
+		require.NoError(t, errEmpty)
+		require.Equal(t, w2, empty.Warehouse)
+		require.Empty(t, empty.Products)
+		require.ErrorIs(t, errNotFound, internal.ErrWarehouseNotFound)
+	})
+	t.Run("canceled context", func(t *testing.T) {
+		// arrange
+		_, rw := factory(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// act
+		_, err := rw.GetAll(ctx)
+
+		// assert
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
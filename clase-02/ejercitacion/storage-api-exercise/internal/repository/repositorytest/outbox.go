@@ -0,0 +1,107 @@
+package repositorytest
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newOutboxMessage returns a message of the outbox about a product
+func newOutboxMessage(entity string) internal.OutboxMessage {
+	return internal.OutboxMessage{
+		Entity:    entity,
+		Type:      internal.EventProductUpdated,
+		Payload:   []byte(`{"ID":1}`),
+		CreatedAt: time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC),
+	}
+}
+
+// pending returns the pending messages of the outbox of a unit of work
+func pending(t *testing.T, uow internal.UnitOfWork, limit int) (ms []internal.OutboxMessage) {
+	t.Helper()
+	err := uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+		ms, err = r.Outbox.Pending(ctx, limit)
+		return
+	})
+	require.NoError(t, err)
+	return
+}
+
+// Outbox runs the contract of internal.OutboxRepository, written within a unit of work
+func Outbox(t *testing.T, factory UnitOfWorkFactory) {
+	t.Run("appended messages are pending in order", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		m1, m2 := newOutboxMessage("product:1"), newOutboxMessage("product:2")
+
+		// act
+		err := uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+			if err = r.Outbox.Append(ctx, &m1); err != nil {
+				return
+			}
+			err = r.Outbox.Append(ctx, &m2)
+			return
+		})
+
+		// assert
+		require.NoError(t, err)
+		require.Less(t, m1.ID, m2.ID)
+		ms := pending(t, uow, 10)
+		require.Len(t, ms, 2)
+		require.Equal(t, m1.ID, ms[0].ID)
+		require.Equal(t, "product:1", ms[0].Entity)
+		require.Equal(t, internal.EventProductUpdated, ms[0].Type)
+		require.JSONEq(t, `{"ID":1}`, string(ms[0].Payload))
+		require.True(t, m1.CreatedAt.Equal(ms[0].CreatedAt))
+		require.Equal(t, m2.ID, ms[1].ID)
+	})
+
+	t.Run("messages of a rolled back unit of work are dropped", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		errFail := errors.New("fail")
+
+		// act
+		err := uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+			m := newOutboxMessage("product:1")
+			require.NoError(t, r.Outbox.Append(ctx, &m))
+			err = errFail
+			return
+		})
+
+		// assert
+		require.ErrorIs(t, err, errFail)
+		require.Empty(t, pending(t, uow, 10))
+	})
+
+	t.Run("relayed messages are no longer pending", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		ms := []internal.OutboxMessage{newOutboxMessage("product:1"), newOutboxMessage("product:1"), newOutboxMessage("product:1")}
+		err := uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+			for i := range ms {
+				if err = r.Outbox.Append(ctx, &ms[i]); err != nil {
+					return
+				}
+			}
+			return
+		})
+		require.NoError(t, err)
+
+		// act
+		err = uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+			err = r.Outbox.MarkRelayed(ctx, ms[0].ID)
+			return
+		})
+
+		// assert
+		require.NoError(t, err)
+		left := pending(t, uow, 1)
+		require.Len(t, left, 1)
+		require.Equal(t, ms[1].ID, left[0].ID)
+	})
+}
@@ -0,0 +1,175 @@
+package repositorytest
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// UnitOfWorkFactory returns a new unit of work over a database without products nor warehouses
+type UnitOfWorkFactory func(t *testing.T) (uow internal.UnitOfWork)
+
+// requireEmpty asserts a unit of work sees no products nor warehouses
+func requireEmpty(t *testing.T, uow internal.UnitOfWork) {
+	t.Helper()
+	err := uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+		ps, err := r.Products.GetAll(ctx)
+		require.NoError(t, err)
+		require.Empty(t, ps)
+		ws, err := r.Warehouses.GetAll(ctx)
+		require.NoError(t, err)
+		require.Empty(t, ws)
+		return
+	})
+	require.NoError(t, err)
+}
+
+// UnitOfWork runs the contract of internal.UnitOfWork
+func UnitOfWork(t *testing.T, factory UnitOfWorkFactory) {
+	t.Run("commit", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		var w internal.Warehouse
+		var p internal.Product
+
+		// act
+		err := uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+			w = newWarehouse(t, r.Warehouses, "warehouse 1")
+			p = newProduct("A1", w.Id)
+			err = r.Products.Store(ctx, &p)
+			return
+		})
+
+		// assert
+		require.NoError(t, err)
+		err = uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+			stored, err := r.Products.GetOne(ctx, p.ID)
+			require.NoError(t, err)
+			requireProduct(t, p, stored)
+			_, err = r.Warehouses.GetOne(ctx, w.Id)
+			return
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		errFail := errors.New("fail")
+
+		// act
+		err := uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+			w := newWarehouse(t, r.Warehouses, "warehouse 1")
+			p := newProduct("A1", w.Id)
+			require.NoError(t, r.Products.Store(ctx, &p))
+			err = errFail
+			return
+		})
+
+		// assert
+		require.ErrorIs(t, err, errFail)
+		requireEmpty(t, uow)
+	})
+
+	t.Run("rollback on panic", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+
+		// act
+		fn := func() {
+			uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+				newWarehouse(t, r.Warehouses, "warehouse 1")
+				panic("fail")
+			})
+		}
+
+		// assert
+		require.Panics(t, fn)
+		requireEmpty(t, uow)
+	})
+
+	t.Run("nested call joins the transaction", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		errFail := errors.New("fail")
+
+		// act
+		err := uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+			w := newWarehouse(t, r.Warehouses, "warehouse 1")
+			// - the inner call sees the uncommitted warehouse
+			err = uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+				p := newProduct("A1", w.Id)
+				err = r.Products.Store(ctx, &p)
+				return
+			})
+			require.NoError(t, err)
+			err = errFail
+			return
+		})
+
+		// assert
+		require.ErrorIs(t, err, errFail)
+		requireEmpty(t, uow)
+	})
+
+	t.Run("bulk joins the transaction", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		errFail := errors.New("fail")
+
+		// act
+		err := uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+			w := newWarehouse(t, r.Warehouses, "warehouse 1")
+			ps := []internal.Product{newProduct("A1", w.Id), newProduct("A2", w.Id)}
+			_, err = r.Products.StoreBulk(ctx, ps, true)
+			require.NoError(t, err)
+			_, err = r.Products.UpdateBulk(ctx, ps, false)
+			require.NoError(t, err)
+			err = errFail
+			return
+		})
+
+		// assert
+		require.ErrorIs(t, err, errFail)
+		requireEmpty(t, uow)
+	})
+
+	t.Run("canceled context rolls back", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// act
+		err := uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+			newWarehouse(t, r.Warehouses, "warehouse 1")
+			cancel()
+			return
+		})
+
+		// assert
+		require.Error(t, err)
+		requireEmpty(t, uow)
+	})
+
+	t.Run("canceled context does not begin", func(t *testing.T) {
+		// arrange
+		uow := factory(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var called bool
+
+		// act
+		err := uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+			called = true
+			return
+		})
+
+		// assert
+		require.ErrorIs(t, err, context.Canceled)
+		require.False(t, called)
+	})
+}
@@ -0,0 +1,196 @@
+package repositorytest
+
+import (
+	"app/internal"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// WebhookFactory returns a new webhook repository without webhooks
+type WebhookFactory func(t *testing.T) (rh internal.WebhookRepository)
+
+// newWebhook stores a webhook and returns it
+func newWebhook(t *testing.T, rh internal.WebhookRepository, url string, events ...internal.EventType) (w internal.Webhook) {
+	t.Helper()
+	w = internal.Webhook{URL: url, Events: events, Secret: "secret"}
+	require.NoError(t, rh.Store(context.Background(), &w))
+	return
+}
+
+// newDeadLetter returns a dead letter of a webhook about a created product, not stored yet
+func newDeadLetter(webhookID int, eventID int64) internal.DeadLetter {
+	return internal.DeadLetter{
+		WebhookID: webhookID,
+		Event: internal.Event{
+			ID:   eventID,
+			Type: internal.EventProductCreated,
+			Time: time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC),
+			Data: internal.Product{ID: 1, Name: "product 1", CodeValue: "A1", Expiration: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC), WarehouseId: 1},
+		},
+		Attempts: 3,
+		Error:    "webhook: status 500",
+		FailedAt: time.Date(2024, 1, 1, 12, 31, 0, 0, time.UTC),
+	}
+}
+
+// requireDeadLetter asserts two dead letters are equal, comparing their times as instants
+func requireDeadLetter(t *testing.T, expected, actual internal.DeadLetter) {
+	t.Helper()
+	require.True(t, expected.Event.Time.Equal(actual.Event.Time))
+	require.True(t, expected.FailedAt.Equal(actual.FailedAt))
+	expected.Event.Time, actual.Event.Time = time.Time{}, time.Time{}
+	expected.FailedAt, actual.FailedAt = time.Time{}, time.Time{}
+	require.Equal(t, expected, actual)
+}
+
+// Webhooks runs the contract of internal.WebhookRepository
+func Webhooks(t *testing.T, factory WebhookFactory) {
+	t.Run("store assigns id and version", func(t *testing.T) {
+		// arrange
+		rh := factory(t)
+		w1 := internal.Webhook{URL: "http://localhost/1", Events: []internal.EventType{internal.EventProductCreated, internal.EventWarehouseUpdated}, Secret: "secret 1"}
+		w2 := internal.Webhook{URL: "http://localhost/2", Secret: "secret 2"}
+
+		// act
+		err1 := rh.Store(context.Background(), &w1)
+		err2 := rh.Store(context.Background(), &w2)
+
+		// assert
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		require.Positive(t, w1.ID)
+		require.NotEqual(t, w1.ID, w2.ID)
+		require.Equal(t, 1, w1.Version)
+		stored, err := rh.GetOne(context.Background(), w1.ID)
+		require.NoError(t, err)
+		require.Equal(t, w1, stored)
+		all, err := rh.GetAll(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []internal.Webhook{w1, w2}, all)
+	})
+
+	t.Run("get one not found", func(t *testing.T) {
+		// arrange
+		rh := factory(t)
+
+		// act
+		_, err := rh.GetOne(context.Background(), 1)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrWebhookNotFound)
+	})
+
+	t.Run("update increments version", func(t *testing.T) {
+		// arrange
+		rh := factory(t)
+		w := newWebhook(t, rh, "http://localhost/1")
+		w.URL, w.Events = "http://localhost/updated", []internal.EventType{internal.EventProductDeleted}
+
+		// act
+		err := rh.Update(context.Background(), &w)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 2, w.Version)
+		stored, err := rh.GetOne(context.Background(), w.ID)
+		require.NoError(t, err)
+		require.Equal(t, w, stored)
+	})
+
+	t.Run("update version conflict and not found", func(t *testing.T) {
+		// arrange
+		rh := factory(t)
+		w := newWebhook(t, rh, "http://localhost/1")
+		stale, missing := w, w
+		stale.Version = 5
+		missing.ID = w.ID + 1
+
+		// act
+		errStale := rh.Update(context.Background(), &stale)
+		errMissing := rh.Update(context.Background(), &missing)
+
+		// assert
+		require.ErrorIs(t, errStale, internal.ErrWebhookVersionConflict)
+		require.ErrorIs(t, errMissing, internal.ErrWebhookNotFound)
+	})
+
+	t.Run("delete with its dead letters", func(t *testing.T) {
+		// arrange
+		rh := factory(t)
+		w := newWebhook(t, rh, "http://localhost/1")
+		d := newDeadLetter(w.ID, 1)
+		require.NoError(t, rh.StoreDeadLetter(context.Background(), &d))
+
+		// act
+		err := rh.Delete(context.Background(), w.ID)
+		errAgain := rh.Delete(context.Background(), w.ID)
+
+		// assert
+		require.NoError(t, err)
+		require.ErrorIs(t, errAgain, internal.ErrWebhookNotFound)
+		_, err = rh.GetOne(context.Background(), w.ID)
+		require.ErrorIs(t, err, internal.ErrWebhookNotFound)
+		_, err = rh.DeleteDeadLetter(context.Background(), w.ID, d.ID)
+		require.ErrorIs(t, err, internal.ErrDeadLetterNotFound)
+	})
+
+	t.Run("dead letters stored in order with their event", func(t *testing.T) {
+		// arrange
+		rh := factory(t)
+		w1, w2 := newWebhook(t, rh, "http://localhost/1"), newWebhook(t, rh, "http://localhost/2")
+		d1, d2, other := newDeadLetter(w1.ID, 7), newDeadLetter(w1.ID, 8), newDeadLetter(w2.ID, 7)
+
+		// act
+		require.NoError(t, rh.StoreDeadLetter(context.Background(), &d1))
+		require.NoError(t, rh.StoreDeadLetter(context.Background(), &d2))
+		require.NoError(t, rh.StoreDeadLetter(context.Background(), &other))
+		ds, err := rh.DeadLetters(context.Background(), w1.ID)
+
+		// assert
+		require.NoError(t, err)
+		require.Positive(t, d1.ID)
+		require.Less(t, d1.ID, d2.ID)
+		require.Len(t, ds, 2)
+		requireDeadLetter(t, d1, ds[0])
+		requireDeadLetter(t, d2, ds[1])
+	})
+
+	t.Run("dead letters of a webhook not found", func(t *testing.T) {
+		// arrange
+		rh := factory(t)
+		d := newDeadLetter(1, 1)
+
+		// act
+		_, err := rh.DeadLetters(context.Background(), 1)
+		errStore := rh.StoreDeadLetter(context.Background(), &d)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrWebhookNotFound)
+		require.ErrorIs(t, errStore, internal.ErrWebhookNotFound)
+	})
+
+	t.Run("delete dead letter returns it", func(t *testing.T) {
+		// arrange
+		rh := factory(t)
+		w1, w2 := newWebhook(t, rh, "http://localhost/1"), newWebhook(t, rh, "http://localhost/2")
+		d := newDeadLetter(w1.ID, 7)
+		require.NoError(t, rh.StoreDeadLetter(context.Background(), &d))
+
+		// act
+		_, errOther := rh.DeleteDeadLetter(context.Background(), w2.ID, d.ID)
+		deleted, err := rh.DeleteDeadLetter(context.Background(), w1.ID, d.ID)
+		_, errAgain := rh.DeleteDeadLetter(context.Background(), w1.ID, d.ID)
+
+		// assert
+		require.ErrorIs(t, errOther, internal.ErrDeadLetterNotFound)
+		require.NoError(t, err)
+		requireDeadLetter(t, d, deleted)
+		require.ErrorIs(t, errAgain, internal.ErrDeadLetterNotFound)
+		ds, err := rh.DeadLetters(context.Background(), w1.ID)
+		require.NoError(t, err)
+		require.Empty(t, ds)
+	})
+}
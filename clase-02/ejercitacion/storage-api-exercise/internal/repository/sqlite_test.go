@@ -0,0 +1,67 @@
+package repository_test
+
+import (
+	"app/docs/db/migrations"
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/repository/repositorytest"
+	"app/platform/migrate"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sqliteDB returns a new temp-file sqlite database with the migrations applied
+func sqliteDB(t *testing.T) (db *sql.DB) {
+	db, err := sql.Open("sqlite", repository.DSNSQLite(filepath.Join(t.TempDir(), "test.db")))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	// schema
+	fsys, err := migrations.FS("sqlite")
+	require.NoError(t, err)
+	ms, err := migrate.Load(fsys)
+	require.NoError(t, err)
+	_, err = migrate.NewMigrator(db, ms).Up(0)
+	require.NoError(t, err)
+	// - without the default warehouse
+	_, err = db.Exec("DELETE FROM `warehouses`")
+	require.NoError(t, err)
+	return
+}
+
+// sqliteFactory returns repositories of a new sqlite database
+func sqliteFactory(t *testing.T) (rp internal.RepositoryProducts, rw internal.WarehouseRepository) {
+	db := sqliteDB(t)
+	rp = repository.NewProductsSQLite(db)
+	rw = repository.NewWarehouseSQLite(db)
+	return
+}
+
+func TestProductsSQLite(t *testing.T) {
+	repositorytest.Products(t, sqliteFactory)
+}
+
+func TestWarehouseSQLite(t *testing.T) {
+	repositorytest.Warehouses(t, sqliteFactory)
+}
+
+func TestUnitOfWorkSQLite(t *testing.T) {
+	repositorytest.UnitOfWork(t, func(t *testing.T) internal.UnitOfWork {
+		return repository.NewUnitOfWorkSQLite(sqliteDB(t))
+	})
+}
+
+func TestOutboxSQLite(t *testing.T) {
+	repositorytest.Outbox(t, func(t *testing.T) internal.UnitOfWork {
+		return repository.NewUnitOfWorkSQLite(sqliteDB(t))
+	})
+}
+
+func TestWebhookSQLite(t *testing.T) {
+	repositorytest.Webhooks(t, func(t *testing.T) internal.WebhookRepository {
+		return repository.NewWebhookSQLite(sqliteDB(t))
+	})
+}
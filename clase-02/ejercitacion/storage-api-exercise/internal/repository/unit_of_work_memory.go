@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+)
+
+// NewUnitOfWorkMemory returns a unit of work over an in-memory database
+func NewUnitOfWorkMemory(db *Memory) *UnitOfWorkMemory {
+	return &UnitOfWorkMemory{
+		db: db,
+	}
+}
+
+// UnitOfWorkMemory is a struct that represents a unit of work over an in-memory database.
+// Units of work run one at a time and a rollback restores the tables as they were when it began,
+// so writes made meanwhile by repositories outside a unit of work are not isolated from it
+type UnitOfWorkMemory struct {
+	// db is the in-memory database
+	db *Memory
+}
+
+// Do runs fn as a transaction
+func (u *UnitOfWorkMemory) Do(ctx context.Context, fn func(ctx context.Context, r internal.Repositories) error) (err error) {
+	// nested: join the transaction
+	if r, ok := joinUnitOfWork(ctx, u); ok {
+		err = fn(ctx, r)
+		return
+	}
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	u.db.txMu.Lock()
+	defer u.db.txMu.Unlock()
+
+	// roll back unless committed, also on panic
+	s := u.db.snapshot()
+	var committed bool
+	defer func() {
+		if !committed {
+			u.db.restore(s)
+		}
+	}()
+
+	r := internal.Repositories{Products: NewProductsMemory(u.db), Warehouses: NewWarehouseMemory(u.db), Outbox: NewOutboxMemory(u.db)}
+	if err = fn(withUnitOfWork(ctx, u, r), r); err != nil {
+		return
+	}
+	// - a done context rolls back, as it does a sql transaction
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	committed = true
+	return
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"database/sql"
+)
+
+// conn is the subset of *sql.DB and *sql.Tx used by the sql repositories
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// inTx runs fn within a transaction: a new one when c is a *sql.DB, committed when fn returns nil,
+// or c itself when it is the transaction of a unit of work, which ends it
+func inTx(ctx context.Context, c conn, fn func(tx conn) error) (err error) {
+	db, ok := c.(*sql.DB)
+	if !ok {
+		err = fn(c)
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	if err = fn(tx); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// NewUnitOfWorkMySQL returns a unit of work over a mysql database
+func NewUnitOfWorkMySQL(db *sql.DB) *UnitOfWorkSQL {
+	return &UnitOfWorkSQL{
+		db: db,
+		repositories: func(c conn) internal.Repositories {
+			return internal.Repositories{Products: &ProductsMySQL{db: c}, Warehouses: &WarehouseMySQL{db: c}, Outbox: &OutboxMySQL{db: c}}
+		},
+	}
+}
+
+// NewUnitOfWorkPostgres returns a unit of work over a postgres database
+func NewUnitOfWorkPostgres(db *sql.DB) *UnitOfWorkSQL {
+	return &UnitOfWorkSQL{
+		db: db,
+		repositories: func(c conn) internal.Repositories {
+			return internal.Repositories{Products: &ProductsPostgres{db: c}, Warehouses: &WarehousePostgres{db: c}, Outbox: &OutboxPostgres{db: c}}
+		},
+	}
+}
+
+// NewUnitOfWorkSQLite returns a unit of work over a sqlite database
+func NewUnitOfWorkSQLite(db *sql.DB) *UnitOfWorkSQL {
+	return &UnitOfWorkSQL{
+		db: db,
+		repositories: func(c conn) internal.Repositories {
+			return internal.Repositories{Products: &ProductsSQLite{db: c}, Warehouses: &WarehouseSQLite{db: c}, Outbox: &OutboxSQLite{db: c}}
+		},
+	}
+}
+
+// UnitOfWorkSQL is a struct that represents a unit of work over a sql database, its repositories share a *sql.Tx
+type UnitOfWorkSQL struct {
+	// db is the database connection
+	db *sql.DB
+	// repositories returns the repositories of the database dialect running on a connection
+	repositories func(c conn) internal.Repositories
+}
+
+// Do runs fn within a transaction
+func (u *UnitOfWorkSQL) Do(ctx context.Context, fn func(ctx context.Context, r internal.Repositories) error) (err error) {
+	// nested: join the transaction
+	if r, ok := joinUnitOfWork(ctx, u); ok {
+		err = fn(ctx, r)
+		return
+	}
+
+	// a canceled context rolls the transaction back
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	r := u.repositories(tx)
+	if err = fn(withUnitOfWork(ctx, u, r), r); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// unitOfWorkKey is the context key of the unit of work in progress
+type unitOfWorkKey struct{}
+
+// unitOfWorkTx is the unit of work in progress of a context
+type unitOfWorkTx struct {
+	// owner is the unit of work
+	owner any
+	// r is the repositories of its transaction
+	r internal.Repositories
+}
+
+// withUnitOfWork returns a context carrying the transaction of a unit of work, for nested calls to join it
+func withUnitOfWork(ctx context.Context, owner any, r internal.Repositories) context.Context {
+	return context.WithValue(ctx, unitOfWorkKey{}, &unitOfWorkTx{owner: owner, r: r})
+}
+
+// joinUnitOfWork returns the repositories of the transaction of owner in progress in ctx, if any
+func joinUnitOfWork(ctx context.Context, owner any) (r internal.Repositories, ok bool) {
+	tx, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWorkTx)
+	if !ok || tx.owner != owner {
+		ok = false
+		return
+	}
+	r = tx.r
+	return
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"slices"
+	"sort"
+)
+
+type WarehouseMemory struct {
+	// db is the in-memory database
+	db *Memory
+}
+
+func NewWarehouseMemory(db *Memory) *WarehouseMemory {
+	return &WarehouseMemory{
+		db: db,
+	}
+}
+
+func (r *WarehouseMemory) GetAll(ctx context.Context) (w []internal.Warehouse, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, warehouse := range r.db.warehouses {
+		w = append(w, warehouse)
+	}
+	sort.Slice(w, func(i, j int) bool { return w[i].Id < w[j].Id })
+	return
+}
+
+func (r *WarehouseMemory) GetOne(ctx context.Context, id int) (w internal.Warehouse, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	w, ok := r.db.warehouses[id]
+	if !ok {
+		err = internal.ErrWarehouseNotFound
+		return
+	}
+	return
+}
+
+// GetOneForUpdate returns a warehouse by id.
+// The units of work of the memory database already run one at a time
+func (r *WarehouseMemory) GetOneForUpdate(ctx context.Context, id int) (w internal.Warehouse, err error) {
+	w, err = r.GetOne(ctx, id)
+	return
+}
+
+// GetByIds returns the warehouses of ids sorted by id, without the ones that do not exist
+func (r *WarehouseMemory) GetByIds(ctx context.Context, ids []int) (w []internal.Warehouse, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, id := range ids {
+		if warehouse, ok := r.db.warehouses[id]; ok && !slices.ContainsFunc(w, func(v internal.Warehouse) bool { return v.Id == id }) {
+			w = append(w, warehouse)
+		}
+	}
+	sort.Slice(w, func(i, j int) bool { return w[i].Id < w[j].Id })
+	return
+}
+
+// GetAllWithProducts returns all warehouses sorted by id with their products
+func (r *WarehouseMemory) GetAllWithProducts(ctx context.Context) (w []internal.WarehouseProducts, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, warehouse := range r.db.warehouses {
+		w = append(w, r.withProducts(warehouse))
+	}
+	sort.Slice(w, func(i, j int) bool { return w[i].Id < w[j].Id })
+	return
+}
+
+// GetOneWithProducts returns a warehouse by id with its products
+func (r *WarehouseMemory) GetOneWithProducts(ctx context.Context, id int) (w internal.WarehouseProducts, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	warehouse, ok := r.db.warehouses[id]
+	if !ok {
+		err = internal.ErrWarehouseNotFound
+		return
+	}
+	w = r.withProducts(warehouse)
+	return
+}
+
+// withProducts returns a warehouse with its products sorted by id, the caller holds the lock of the database
+func (r *WarehouseMemory) withProducts(warehouse internal.Warehouse) (w internal.WarehouseProducts) {
+	w.Warehouse = warehouse
+	for _, p := range r.db.products {
+		if p.WarehouseId == warehouse.Id {
+			w.Products = append(w.Products, p)
+		}
+	}
+	sort.Slice(w.Products, func(i, j int) bool { return w.Products[i].ID < w.Products[j].ID })
+	return
+}
+
+func (r *WarehouseMemory) Store(ctx context.Context, w *internal.Warehouse) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.lastWarehouseId++
+	w.Id = r.db.lastWarehouseId
+	w.Version = 1
+	r.db.warehouses[w.Id] = *w
+	return
+}
+
+func (r *WarehouseMemory) Update(ctx context.Context, w *internal.Warehouse) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	current, ok := r.db.warehouses[w.Id]
+	if !ok {
+		err = internal.ErrWarehouseNotFound
+		return
+	}
+	if current.Version != w.Version {
+		// the warehouse was modified by someone else
+		err = internal.ErrWarehouseVersionConflict
+		return
+	}
+	w.Version++
+	r.db.warehouses[w.Id] = *w
+	return
+}
+
+// ReportProducts counts the products of each warehouse name, as the sql repositories group by name
+func (r *WarehouseMemory) ReportProducts(ctx context.Context, id int) (rp []internal.ReportProduct, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, w := range r.db.warehouses {
+		if id > 0 && w.Id != id {
+			continue
+		}
+		if _, ok := counts[w.Name]; !ok {
+			counts[w.Name] = 0
+		}
+		for _, p := range r.db.products {
+			if p.WarehouseId == w.Id {
+				counts[w.Name]++
+			}
+		}
+	}
+
+	for name, count := range counts {
+		rp = append(rp, internal.ReportProduct{Name: name, ProductCount: count})
+	}
+	sort.Slice(rp, func(i, j int) bool { return rp[i].Name < rp[j].Name })
+	return
+}
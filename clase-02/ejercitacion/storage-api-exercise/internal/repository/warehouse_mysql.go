@@ -2,6 +2,7 @@ package repository
 
 import (
 	"app/internal"
+	"context"
 	"database/sql"
 	"errors"
 
@@ -9,8 +10,8 @@ import (
 )
 
 type WarehouseMySQL struct {
-	// db is the database connection
-	db *sql.DB
+	// db is the database connection, or the transaction of a unit of work
+	db conn
 }
 
 func NewWarehouseMySQL(db *sql.DB) *WarehouseMySQL {
@@ -19,9 +20,9 @@ func NewWarehouseMySQL(db *sql.DB) *WarehouseMySQL {
 	}
 }
 
-func (r *WarehouseMySQL) GetAll() (w []internal.Warehouse, err error) {
-	query := "SELECT `id`, `name`, `adress`, `telephone`, `capacity` FROM `warehouses`"
-	row, err := r.db.Query(query)
+func (r *WarehouseMySQL) GetAll(ctx context.Context) (w []internal.Warehouse, err error) {
+	query := "SELECT `id`, `name`, `adress`, `telephone`, `capacity`, `version` FROM `warehouses`"
+	row, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			err = internal.ErrWarehouseNotFound
@@ -32,7 +33,7 @@ func (r *WarehouseMySQL) GetAll() (w []internal.Warehouse, err error) {
 
 	for row.Next() {
 		var warehouse internal.Warehouse
-		err = row.Scan(&warehouse.Id, &warehouse.Name, &warehouse.Address, &warehouse.Telephone, &warehouse.Capacity)
+		err = row.Scan(&warehouse.Id, &warehouse.Name, &warehouse.Address, &warehouse.Telephone, &warehouse.Capacity, &warehouse.Version)
 		if err != nil {
 			return
 		}
@@ -41,10 +42,10 @@ func (r *WarehouseMySQL) GetAll() (w []internal.Warehouse, err error) {
 	return
 }
 
-func (r *WarehouseMySQL) GetOne(id int) (w internal.Warehouse, err error) {
-	query := "SELECT `id`, `name`, `adress`, `telephone`, `capacity` FROM `warehouses` WHERE `id` = ?"
+func (r *WarehouseMySQL) GetOne(ctx context.Context, id int) (w internal.Warehouse, err error) {
+	query := "SELECT `id`, `name`, `adress`, `telephone`, `capacity`, `version` FROM `warehouses` WHERE `id` = ?"
 
-	row := r.db.QueryRow(query, id)
+	row := r.db.QueryRowContext(ctx, query, id)
 	if err = row.Err(); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			err = internal.ErrWarehouseNotFound
@@ -53,16 +54,88 @@ func (r *WarehouseMySQL) GetOne(id int) (w internal.Warehouse, err error) {
 		return
 	}
 
-	err = row.Scan(&w.Id, &w.Name, &w.Address, &w.Telephone, &w.Capacity)
+	err = row.Scan(&w.Id, &w.Name, &w.Address, &w.Telephone, &w.Capacity, &w.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrWarehouseNotFound
+		}
+		return
+	}
+	return
+}
+
+// GetOneForUpdate returns a warehouse by id, locking its row until the transaction ends
+func (r *WarehouseMySQL) GetOneForUpdate(ctx context.Context, id int) (w internal.Warehouse, err error) {
+	query := "SELECT `id`, `name`, `adress`, `telephone`, `capacity`, `version` FROM `warehouses` WHERE `id` = ? FOR UPDATE"
+
+	err = r.db.QueryRowContext(ctx, query, id).Scan(&w.Id, &w.Name, &w.Address, &w.Telephone, &w.Capacity, &w.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrWarehouseNotFound
+		}
+		return
+	}
+	return
+}
+
+// GetByIds returns the warehouses of ids sorted by id, in a single query
+func (r *WarehouseMySQL) GetByIds(ctx context.Context, ids []int) (w []internal.Warehouse, err error) {
+	if len(ids) == 0 {
+		return
+	}
+	list, args := inIds(ids, false)
+	query := "SELECT `id`, `name`, `adress`, `telephone`, `capacity`, `version` FROM `warehouses` WHERE `id` IN " + list + " ORDER BY `id`"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	w, err = scanWarehouses(rows)
+	return
+}
+
+// warehouseProductsQueryMySQL selects the warehouses left joined with their products, a row with null products for a warehouse without them
+const warehouseProductsQueryMySQL = "SELECT w.`id`, w.`name`, w.`adress`, w.`telephone`, w.`capacity`, w.`version`, " +
+	"p.`id`, p.`name`, p.`quantity`, p.`code_value`, p.`is_published`, p.`expiration`, p.`price`, p.`id_warehouse`, p.`version` " +
+	"FROM `warehouses` w LEFT JOIN `products` p ON p.`id_warehouse` = w.`id`"
+
+// GetAllWithProducts returns all warehouses sorted by id with their products, in a single query joining them
+func (r *WarehouseMySQL) GetAllWithProducts(ctx context.Context) (w []internal.WarehouseProducts, err error) {
+	rows, err := r.db.QueryContext(ctx, warehouseProductsQueryMySQL+" ORDER BY w.`id`, p.`id`")
 	if err != nil {
 		return
 	}
+	defer rows.Close()
+
+	w, err = scanWarehouseProducts(rows)
 	return
 }
 
-func (r *WarehouseMySQL) Store(w *internal.Warehouse) (err error) {
+// GetOneWithProducts returns a warehouse by id with its products, in a single query joining them
+func (r *WarehouseMySQL) GetOneWithProducts(ctx context.Context, id int) (w internal.WarehouseProducts, err error) {
+	rows, err := r.db.QueryContext(ctx, warehouseProductsQueryMySQL+" WHERE w.`id` = ? ORDER BY p.`id`", id)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	ws, err := scanWarehouseProducts(rows)
+	if err != nil {
+		return
+	}
+	if len(ws) == 0 {
+		err = internal.ErrWarehouseNotFound
+		return
+	}
+	w = ws[0]
+	return
+}
+
+func (r *WarehouseMySQL) Store(ctx context.Context, w *internal.Warehouse) (err error) {
 	query := "INSERT INTO `warehouses` (`name`, `adress`, `telephone`, `capacity`) VALUES (?, ?, ?, ?)"
-	result, err := r.db.Exec(query, w.Name, w.Address, w.Telephone, w.Capacity)
+	result, err := r.db.ExecContext(ctx, query, w.Name, w.Address, w.Telephone, w.Capacity)
 	if err != nil {
 		var mysqlErr *mysql.MySQLError
 		if errors.As(err, &mysqlErr) {
@@ -81,10 +154,39 @@ func (r *WarehouseMySQL) Store(w *internal.Warehouse) (err error) {
 		return
 	}
 	w.Id = int(id)
+	w.Version = 1
+	return
+}
+
+func (r *WarehouseMySQL) Update(ctx context.Context, w *internal.Warehouse) (err error) {
+	query := "UPDATE `warehouses` SET `name` = ?, `adress` = ?, `telephone` = ?, `capacity` = ?, `version` = `version` + 1 WHERE `id` = ? AND `version` = ?"
+	result, err := r.db.ExecContext(ctx, query, w.Name, w.Address, w.Telephone, w.Capacity, w.Id, w.Version)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			err = internal.ErrWarehouseAlreadyExists
+		}
+		return
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if rowsAffected == 0 {
+		// the warehouse was either deleted or modified by someone else
+		_, err = r.GetOne(ctx, w.Id)
+		if err != nil {
+			return
+		}
+		err = internal.ErrWarehouseVersionConflict
+		return
+	}
+	w.Version++
 	return
 }
 
-func (r *WarehouseMySQL) ReportProducts(id int) (rp []internal.ReportProduct, err error) {
+func (r *WarehouseMySQL) ReportProducts(ctx context.Context, id int) (rp []internal.ReportProduct, err error) {
 	query := "SELECT w.`name`,count(p.id) as `product_count` FROM warehouses w LEFT JOIN products p ON w.id = p.id_warehouse "
 	if id > 0 {
 		query += "WHERE w.id = ? "
@@ -93,9 +195,9 @@ func (r *WarehouseMySQL) ReportProducts(id int) (rp []internal.ReportProduct, er
 
 	var result *sql.Rows
 	if id > 0 {
-		result, err = r.db.Query(query, id)
+		result, err = r.db.QueryContext(ctx, query, id)
 	} else {
-		result, err = r.db.Query(query)
+		result, err = r.db.QueryContext(ctx, query)
 	}
 
 	if err != nil {
@@ -107,7 +209,7 @@ func (r *WarehouseMySQL) ReportProducts(id int) (rp []internal.ReportProduct, er
 	}
 
 	var reportProducts internal.ReportProduct
-	if result.Next() {
+	for result.Next() {
 		err = result.Scan(&reportProducts.Name, &reportProducts.ProductCount)
 		if err != nil {
 			return
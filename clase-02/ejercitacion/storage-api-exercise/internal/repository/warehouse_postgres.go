@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type WarehousePostgres struct {
+	// db is the database connection, or the transaction of a unit of work
+	db conn
+}
+
+func NewWarehousePostgres(db *sql.DB) *WarehousePostgres {
+	return &WarehousePostgres{
+		db: db,
+	}
+}
+
+// warehouseErrorPostgres maps a postgres error into a warehouse repository error
+func warehouseErrorPostgres(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return internal.ErrWarehouseAlreadyExists
+		}
+	}
+	return err
+}
+
+func (r *WarehousePostgres) GetAll(ctx context.Context) (w []internal.Warehouse, err error) {
+	query := "SELECT id, name, adress, telephone, capacity, version FROM warehouses"
+	row, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var warehouse internal.Warehouse
+		err = row.Scan(&warehouse.Id, &warehouse.Name, &warehouse.Address, &warehouse.Telephone, &warehouse.Capacity, &warehouse.Version)
+		if err != nil {
+			return
+		}
+		w = append(w, warehouse)
+	}
+	err = row.Err()
+	return
+}
+
+func (r *WarehousePostgres) GetOne(ctx context.Context, id int) (w internal.Warehouse, err error) {
+	query := "SELECT id, name, adress, telephone, capacity, version FROM warehouses WHERE id = $1"
+
+	err = r.db.QueryRowContext(ctx, query, id).Scan(&w.Id, &w.Name, &w.Address, &w.Telephone, &w.Capacity, &w.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrWarehouseNotFound
+		}
+		return
+	}
+	return
+}
+
+// GetOneForUpdate returns a warehouse by id, locking its row until the transaction ends
+func (r *WarehousePostgres) GetOneForUpdate(ctx context.Context, id int) (w internal.Warehouse, err error) {
+	query := "SELECT id, name, adress, telephone, capacity, version FROM warehouses WHERE id = $1 FOR UPDATE"
+
+	err = r.db.QueryRowContext(ctx, query, id).Scan(&w.Id, &w.Name, &w.Address, &w.Telephone, &w.Capacity, &w.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrWarehouseNotFound
+		}
+		return
+	}
+	return
+}
+
+// GetByIds returns the warehouses of ids sorted by id, in a single query
+func (r *WarehousePostgres) GetByIds(ctx context.Context, ids []int) (w []internal.Warehouse, err error) {
+	if len(ids) == 0 {
+		return
+	}
+	list, args := inIds(ids, true)
+	query := "SELECT id, name, adress, telephone, capacity, version FROM warehouses WHERE id IN " + list + " ORDER BY id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	w, err = scanWarehouses(rows)
+	return
+}
+
+// warehouseProductsQueryPostgres selects the warehouses left joined with their products, a row with null products for a warehouse without them
+const warehouseProductsQueryPostgres = "SELECT w.id, w.name, w.adress, w.telephone, w.capacity, w.version, " +
+	"p.id, p.name, p.quantity, p.code_value, p.is_published, p.expiration, p.price, p.id_warehouse, p.version " +
+	"FROM warehouses w LEFT JOIN products p ON p.id_warehouse = w.id"
+
+// GetAllWithProducts returns all warehouses sorted by id with their products, in a single query joining them
+func (r *WarehousePostgres) GetAllWithProducts(ctx context.Context) (w []internal.WarehouseProducts, err error) {
+	rows, err := r.db.QueryContext(ctx, warehouseProductsQueryPostgres+" ORDER BY w.id, p.id")
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	w, err = scanWarehouseProducts(rows)
+	return
+}
+
+// GetOneWithProducts returns a warehouse by id with its products, in a single query joining them
+func (r *WarehousePostgres) GetOneWithProducts(ctx context.Context, id int) (w internal.WarehouseProducts, err error) {
+	rows, err := r.db.QueryContext(ctx, warehouseProductsQueryPostgres+" WHERE w.id = $1 ORDER BY p.id", id)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	ws, err := scanWarehouseProducts(rows)
+	if err != nil {
+		return
+	}
+	if len(ws) == 0 {
+		err = internal.ErrWarehouseNotFound
+		return
+	}
+	w = ws[0]
+	return
+}
+
+func (r *WarehousePostgres) Store(ctx context.Context, w *internal.Warehouse) (err error) {
+	query := "INSERT INTO warehouses (name, adress, telephone, capacity) VALUES ($1, $2, $3, $4) RETURNING id"
+	err = r.db.QueryRowContext(ctx, query, w.Name, w.Address, w.Telephone, w.Capacity).Scan(&w.Id)
+	if err != nil {
+		err = warehouseErrorPostgres(err)
+		return
+	}
+	w.Version = 1
+	return
+}
+
+func (r *WarehousePostgres) Update(ctx context.Context, w *internal.Warehouse) (err error) {
+	query := "UPDATE warehouses SET name = $1, adress = $2, telephone = $3, capacity = $4, version = version + 1 WHERE id = $5 AND version = $6"
+	result, err := r.db.ExecContext(ctx, query, w.Name, w.Address, w.Telephone, w.Capacity, w.Id, w.Version)
+	if err != nil {
+		err = warehouseErrorPostgres(err)
+		return
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if rowsAffected == 0 {
+		// the warehouse was either deleted or modified by someone else
+		_, err = r.GetOne(ctx, w.Id)
+		if err != nil {
+			return
+		}
+		err = internal.ErrWarehouseVersionConflict
+		return
+	}
+	w.Version++
+	return
+}
+
+func (r *WarehousePostgres) ReportProducts(ctx context.Context, id int) (rp []internal.ReportProduct, err error) {
+	query := "SELECT w.name, count(p.id) AS product_count FROM warehouses w LEFT JOIN products p ON w.id = p.id_warehouse "
+	var args []any
+	if id > 0 {
+		query += "WHERE w.id = $1 "
+		args = append(args, id)
+	}
+	query += "GROUP BY w.name"
+
+	result, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer result.Close()
+
+	for result.Next() {
+		var reportProducts internal.ReportProduct
+		err = result.Scan(&reportProducts.Name, &reportProducts.ProductCount)
+		if err != nil {
+			return
+		}
+		rp = append(rp, reportProducts)
+	}
+	err = result.Err()
+	return
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"database/sql"
+	"errors"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+type WarehouseSQLite struct {
+	// db is the database connection, or the transaction of a unit of work
+	db conn
+}
+
+func NewWarehouseSQLite(db *sql.DB) *WarehouseSQLite {
+	return &WarehouseSQLite{
+		db: db,
+	}
+}
+
+// warehouseErrorSQLite maps a sqlite error into a warehouse repository error
+func warehouseErrorSQLite(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return internal.ErrWarehouseAlreadyExists
+		}
+	}
+	return err
+}
+
+func (r *WarehouseSQLite) GetAll(ctx context.Context) (w []internal.Warehouse, err error) {
+	query := "SELECT `id`, `name`, `adress`, `telephone`, `capacity`, `version` FROM `warehouses`"
+	row, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var warehouse internal.Warehouse
+		err = row.Scan(&warehouse.Id, &warehouse.Name, &warehouse.Address, &warehouse.Telephone, &warehouse.Capacity, &warehouse.Version)
+		if err != nil {
+			return
+		}
+		w = append(w, warehouse)
+	}
+	err = row.Err()
+	return
+}
+
+func (r *WarehouseSQLite) GetOne(ctx context.Context, id int) (w internal.Warehouse, err error) {
+	query := "SELECT `id`, `name`, `adress`, `telephone`, `capacity`, `version` FROM `warehouses` WHERE `id` = ?"
+
+	err = r.db.QueryRowContext(ctx, query, id).Scan(&w.Id, &w.Name, &w.Address, &w.Telephone, &w.Capacity, &w.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = internal.ErrWarehouseNotFound
+		}
+		return
+	}
+	return
+}
+
+// GetOneForUpdate returns a warehouse by id.
+// sqlite has no row locks, the transaction already holds the write lock of the database since it began
+func (r *WarehouseSQLite) GetOneForUpdate(ctx context.Context, id int) (w internal.Warehouse, err error) {
+	w, err = r.GetOne(ctx, id)
+	return
+}
+
+// GetByIds returns the warehouses of ids sorted by id, in a single query
+func (r *WarehouseSQLite) GetByIds(ctx context.Context, ids []int) (w []internal.Warehouse, err error) {
+	if len(ids) == 0 {
+		return
+	}
+	list, args := inIds(ids, false)
+	query := "SELECT `id`, `name`, `adress`, `telephone`, `capacity`, `version` FROM `warehouses` WHERE `id` IN " + list + " ORDER BY `id`"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	w, err = scanWarehouses(rows)
+	return
+}
+
+// warehouseProductsQuerySQLite selects the warehouses left joined with their products, a row with null products for a warehouse without them
+const warehouseProductsQuerySQLite = "SELECT w.`id`, w.`name`, w.`adress`, w.`telephone`, w.`capacity`, w.`version`, " +
+	"p.`id`, p.`name`, p.`quantity`, p.`code_value`, p.`is_published`, p.`expiration`, p.`price`, p.`id_warehouse`, p.`version` " +
+	"FROM `warehouses` w LEFT JOIN `products` p ON p.`id_warehouse` = w.`id`"
+
+// GetAllWithProducts returns all warehouses sorted by id with their products, in a single query joining them
+func (r *WarehouseSQLite) GetAllWithProducts(ctx context.Context) (w []internal.WarehouseProducts, err error) {
+	rows, err := r.db.QueryContext(ctx, warehouseProductsQuerySQLite+" ORDER BY w.`id`, p.`id`")
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	w, err = scanWarehouseProducts(rows)
+	return
+}
+
+// GetOneWithProducts returns a warehouse by id with its products, in a single query joining them
+func (r *WarehouseSQLite) GetOneWithProducts(ctx context.Context, id int) (w internal.WarehouseProducts, err error) {
+	rows, err := r.db.QueryContext(ctx, warehouseProductsQuerySQLite+" WHERE w.`id` = ? ORDER BY p.`id`", id)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	ws, err := scanWarehouseProducts(rows)
+	if err != nil {
+		return
+	}
+	if len(ws) == 0 {
+		err = internal.ErrWarehouseNotFound
+		return
+	}
+	w = ws[0]
+	return
+}
+
+func (r *WarehouseSQLite) Store(ctx context.Context, w *internal.Warehouse) (err error) {
+	query := "INSERT INTO `warehouses` (`name`, `adress`, `telephone`, `capacity`) VALUES (?, ?, ?, ?)"
+	result, err := r.db.ExecContext(ctx, query, w.Name, w.Address, w.Telephone, w.Capacity)
+	if err != nil {
+		err = warehouseErrorSQLite(err)
+		return
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return
+	}
+	w.Id = int(id)
+	w.Version = 1
+	return
+}
+
+func (r *WarehouseSQLite) Update(ctx context.Context, w *internal.Warehouse) (err error) {
+	query := "UPDATE `warehouses` SET `name` = ?, `adress` = ?, `telephone` = ?, `capacity` = ?, `version` = `version` + 1 WHERE `id` = ? AND `version` = ?"
+	result, err := r.db.ExecContext(ctx, query, w.Name, w.Address, w.Telephone, w.Capacity, w.Id, w.Version)
+	if err != nil {
+		err = warehouseErrorSQLite(err)
+		return
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if rowsAffected == 0 {
+		// the warehouse was either deleted or modified by someone else
+		_, err = r.GetOne(ctx, w.Id)
+		if err != nil {
+			return
+		}
+		err = internal.ErrWarehouseVersionConflict
+		return
+	}
+	w.Version++
+	return
+}
+
+func (r *WarehouseSQLite) ReportProducts(ctx context.Context, id int) (rp []internal.ReportProduct, err error) {
+	query := "SELECT w.`name`, count(p.id) AS `product_count` FROM warehouses w LEFT JOIN products p ON w.id = p.id_warehouse "
+	var args []any
+	if id > 0 {
+		query += "WHERE w.id = ? "
+		args = append(args, id)
+	}
+	query += "GROUP BY w.`name`"
+
+	result, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return
+	}
+	defer result.Close()
+
+	for result.Next() {
+		var reportProducts internal.ReportProduct
+		err = result.Scan(&reportProducts.Name, &reportProducts.ProductCount)
+		if err != nil {
+			return
+		}
+		rp = append(rp, reportProducts)
+	}
+	err = result.Err()
+	return
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// NewWebhookMemory returns a new empty in-memory webhook repository
+func NewWebhookMemory() *WebhookMemory {
+	return &WebhookMemory{
+		webhooks:    make(map[int]internal.Webhook),
+		deadLetters: make(map[int][]internal.DeadLetter),
+	}
+}
+
+// WebhookMemory is a struct that represents a webhook repository kept in memory, so the webhooks are lost on restart.
+// It is safe for concurrent use
+type WebhookMemory struct {
+	// mu guards the tables
+	mu sync.RWMutex
+	// webhooks is the webhooks table by id
+	webhooks map[int]internal.Webhook
+	// lastWebhookId is the last id assigned to a webhook
+	lastWebhookId int
+	// deadLetters are the dead letters of each webhook by its id, the oldest first
+	deadLetters map[int][]internal.DeadLetter
+	// lastDeadLetterId is the last id assigned to a dead letter
+	lastDeadLetterId int
+}
+
+// GetAll returns all webhooks, ordered by id
+func (r *WebhookMemory) GetAll(ctx context.Context) (ws []internal.Webhook, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, w := range r.webhooks {
+		ws = append(ws, cloneWebhook(w))
+	}
+	sort.Slice(ws, func(i, j int) bool { return ws[i].ID < ws[j].ID })
+	return
+}
+
+// GetOne returns a webhook by id
+func (r *WebhookMemory) GetOne(ctx context.Context, id int) (w internal.Webhook, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.webhooks[id]
+	if !ok {
+		err = internal.ErrWebhookNotFound
+		return
+	}
+	w = cloneWebhook(w)
+	return
+}
+
+// Store saves a webhook
+func (r *WebhookMemory) Store(ctx context.Context, w *internal.Webhook) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastWebhookId++
+	w.ID = r.lastWebhookId
+	w.Version = 1
+	r.webhooks[w.ID] = cloneWebhook(*w)
+	return
+}
+
+// Update updates a webhook if its version matches the stored one, incrementing it
+func (r *WebhookMemory) Update(ctx context.Context, w *internal.Webhook) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.webhooks[w.ID]
+	if !ok {
+		err = internal.ErrWebhookNotFound
+		return
+	}
+	if current.Version != w.Version {
+		// the webhook was modified by someone else
+		err = internal.ErrWebhookVersionConflict
+		return
+	}
+	w.Version++
+	r.webhooks[w.ID] = cloneWebhook(*w)
+	return
+}
+
+// Delete deletes a webhook by id, with its dead letters
+func (r *WebhookMemory) Delete(ctx context.Context, id int) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		err = internal.ErrWebhookNotFound
+		return
+	}
+	delete(r.webhooks, id)
+	delete(r.deadLetters, id)
+	return
+}
+
+// DeadLetters returns the dead letters of a webhook, the oldest first
+func (r *WebhookMemory) DeadLetters(ctx context.Context, webhookID int) (ds []internal.DeadLetter, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.webhooks[webhookID]; !ok {
+		err = internal.ErrWebhookNotFound
+		return
+	}
+	ds = slices.Clone(r.deadLetters[webhookID])
+	return
+}
+
+// StoreDeadLetter saves a dead letter
+func (r *WebhookMemory) StoreDeadLetter(ctx context.Context, d *internal.DeadLetter) (err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[d.WebhookID]; !ok {
+		err = internal.ErrWebhookNotFound
+		return
+	}
+	r.lastDeadLetterId++
+	d.ID = r.lastDeadLetterId
+	r.deadLetters[d.WebhookID] = append(r.deadLetters[d.WebhookID], *d)
+	return
+}
+
+// DeleteDeadLetter deletes a dead letter of a webhook by id, returning it
+func (r *WebhookMemory) DeleteDeadLetter(ctx context.Context, webhookID int, id int) (d internal.DeadLetter, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ds := r.deadLetters[webhookID]
+	i := slices.IndexFunc(ds, func(d internal.DeadLetter) bool { return d.ID == id })
+	if i < 0 {
+		err = internal.ErrDeadLetterNotFound
+		return
+	}
+	d = ds[i]
+	r.deadLetters[webhookID] = slices.Delete(ds, i, i+1)
+	return
+}
+
+// cloneWebhook returns a copy of a webhook that does not share its events
+func cloneWebhook(w internal.Webhook) internal.Webhook {
+	w.Events = slices.Clone(w.Events)
+	return w
+}
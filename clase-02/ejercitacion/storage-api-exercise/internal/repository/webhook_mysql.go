@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// NewWebhookMySQL returns a new instance of WebhookMySQL
+func NewWebhookMySQL(db *sql.DB) *WebhookMySQL {
+	return &WebhookMySQL{
+		db: db,
+	}
+}
+
+// WebhookMySQL is a struct that represents a webhook repository
+type WebhookMySQL struct {
+	// db is the database connection
+	db *sql.DB
+}
+
+// GetAll returns all webhooks, ordered by id
+func (r *WebhookMySQL) GetAll(ctx context.Context) (ws []internal.Webhook, err error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT `id`, `url`, `events`, `secret`, `version` FROM `webhooks` ORDER BY `id`")
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var w internal.Webhook
+		if w, err = scanWebhook(rows); err != nil {
+			return
+		}
+		ws = append(ws, w)
+	}
+	err = rows.Err()
+	return
+}
+
+// GetOne returns a webhook by id
+func (r *WebhookMySQL) GetOne(ctx context.Context, id int) (w internal.Webhook, err error) {
+	row := r.db.QueryRowContext(ctx, "SELECT `id`, `url`, `events`, `secret`, `version` FROM `webhooks` WHERE `id` = ?", id)
+	w, err = scanWebhook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = internal.ErrWebhookNotFound
+	}
+	return
+}
+
+// Store saves a webhook
+func (r *WebhookMySQL) Store(ctx context.Context, w *internal.Webhook) (err error) {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO `webhooks` (`url`, `events`, `secret`, `version`) VALUES (?, ?, ?, 1)",
+		w.URL, joinEventTypes(w.Events), w.Secret,
+	)
+	if err != nil {
+		return
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return
+	}
+	w.ID = int(id)
+	w.Version = 1
+	return
+}
+
+// Update updates a webhook if its version matches the stored one, incrementing it
+func (r *WebhookMySQL) Update(ctx context.Context, w *internal.Webhook) (err error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE `webhooks` SET `url` = ?, `events` = ?, `secret` = ?, `version` = `version` + 1 WHERE `id` = ? AND `version` = ?",
+		w.URL, joinEventTypes(w.Events), w.Secret, w.ID, w.Version,
+	)
+	if err != nil {
+		return
+	}
+	err = webhookUpdated(ctx, result, w, r.GetOne)
+	return
+}
+
+// Delete deletes a webhook by id, its dead letters are deleted by the foreign key
+func (r *WebhookMySQL) Delete(ctx context.Context, id int) (err error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM `webhooks` WHERE `id` = ?", id)
+	if err != nil {
+		return
+	}
+	err = affected(result, internal.ErrWebhookNotFound)
+	return
+}
+
+// DeadLetters returns the dead letters of a webhook, the oldest first
+func (r *WebhookMySQL) DeadLetters(ctx context.Context, webhookID int) (ds []internal.DeadLetter, err error) {
+	if _, err = r.GetOne(ctx, webhookID); err != nil {
+		return
+	}
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT `id`, `id_webhook`, `event_id`, `event_type`, `event_payload`, `event_time`, `attempts`, `error`, `failed_at` "+
+			"FROM `webhook_dead_letters` WHERE `id_webhook` = ? ORDER BY `id`",
+		webhookID,
+	)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	ds, err = scanDeadLetters(rows)
+	return
+}
+
+// StoreDeadLetter saves a dead letter
+func (r *WebhookMySQL) StoreDeadLetter(ctx context.Context, d *internal.DeadLetter) (err error) {
+	if _, err = r.GetOne(ctx, d.WebhookID); err != nil {
+		return
+	}
+	m, err := internal.NewOutboxMessage(d.Event)
+	if err != nil {
+		return
+	}
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO `webhook_dead_letters` (`id_webhook`, `event_id`, `event_type`, `event_payload`, `event_time`, `attempts`, `error`, `failed_at`) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		d.WebhookID, d.Event.ID, m.Type, string(m.Payload), m.CreatedAt.UTC(), d.Attempts, d.Error, d.FailedAt.UTC(),
+	)
+	if err != nil {
+		return
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return
+	}
+	d.ID = int(id)
+	return
+}
+
+// DeleteDeadLetter deletes a dead letter of a webhook by id, returning it
+func (r *WebhookMySQL) DeleteDeadLetter(ctx context.Context, webhookID int, id int) (d internal.DeadLetter, err error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT `id`, `id_webhook`, `event_id`, `event_type`, `event_payload`, `event_time`, `attempts`, `error`, `failed_at` "+
+			"FROM `webhook_dead_letters` WHERE `id_webhook` = ? AND `id` = ?",
+		webhookID, id,
+	)
+	if err != nil {
+		return
+	}
+	ds, err := scanDeadLetters(rows)
+	rows.Close()
+	if err != nil {
+		return
+	}
+	if len(ds) == 0 {
+		err = internal.ErrDeadLetterNotFound
+		return
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM `webhook_dead_letters` WHERE `id_webhook` = ? AND `id` = ?", webhookID, id)
+	if err != nil {
+		return
+	}
+	if err = affected(result, internal.ErrDeadLetterNotFound); err != nil {
+		return
+	}
+	d = ds[0]
+	return
+}
+
+// scanWebhook returns the webhook of a row (id, url, events, secret, version)
+func scanWebhook(row scanner) (w internal.Webhook, err error) {
+	var events string
+	if err = row.Scan(&w.ID, &w.URL, &events, &w.Secret, &w.Version); err != nil {
+		return
+	}
+	w.Events = splitEventTypes(events)
+	return
+}
+
+// scanDeadLetters returns the dead letters of the rows
+// (id, id_webhook, event_id, event_type, event_payload, event_time, attempts, error, failed_at)
+func scanDeadLetters(rows *sql.Rows) (ds []internal.DeadLetter, err error) {
+	for rows.Next() {
+		var d internal.DeadLetter
+		var m internal.OutboxMessage
+		var payload string
+		if err = rows.Scan(&d.ID, &d.WebhookID, &m.ID, &m.Type, &payload, &m.CreatedAt, &d.Attempts, &d.Error, &d.FailedAt); err != nil {
+			return
+		}
+		m.Payload = []byte(payload)
+		if d.Event, err = m.Event(); err != nil {
+			return
+		}
+		ds = append(ds, d)
+	}
+	err = rows.Err()
+	return
+}
+
+// joinEventTypes returns the column of the event types of a webhook, empty for every type
+func joinEventTypes(ts []internal.EventType) string {
+	s := make([]string, len(ts))
+	for i, t := range ts {
+		s[i] = string(t)
+	}
+	return strings.Join(s, ",")
+}
+
+// splitEventTypes returns the event types of the column of a webhook, nil for every type
+func splitEventTypes(s string) (ts []internal.EventType) {
+	if s == "" {
+		return
+	}
+	for _, t := range strings.Split(s, ",") {
+		ts = append(ts, internal.EventType(t))
+	}
+	return
+}
+
+// webhookUpdated increments the version of a webhook updated by result, or returns why no row was updated:
+// the webhook is gone or was modified by someone else
+func webhookUpdated(ctx context.Context, result sql.Result, w *internal.Webhook, getOne func(ctx context.Context, id int) (internal.Webhook, error)) (err error) {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if n == 0 {
+		if _, err = getOne(ctx, w.ID); err != nil {
+			return
+		}
+		err = internal.ErrWebhookVersionConflict
+		return
+	}
+	w.Version++
+	return
+}
+
+// affected returns errNotFound when result deleted no row
+func affected(result sql.Result, errNotFound error) (err error) {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if n == 0 {
+		err = errNotFound
+	}
+	return
+}
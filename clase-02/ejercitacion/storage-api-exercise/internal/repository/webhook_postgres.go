@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// NewWebhookPostgres returns a new instance of WebhookPostgres
+func NewWebhookPostgres(db *sql.DB) *WebhookPostgres {
+	return &WebhookPostgres{
+		db: db,
+	}
+}
+
+// WebhookPostgres is a struct that represents a webhook repository
+type WebhookPostgres struct {
+	// db is the database connection
+	db *sql.DB
+}
+
+// GetAll returns all webhooks, ordered by id
+func (r *WebhookPostgres) GetAll(ctx context.Context) (ws []internal.Webhook, err error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, url, events, secret, version FROM webhooks ORDER BY id")
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var w internal.Webhook
+		if w, err = scanWebhook(rows); err != nil {
+			return
+		}
+		ws = append(ws, w)
+	}
+	err = rows.Err()
+	return
+}
+
+// GetOne returns a webhook by id
+func (r *WebhookPostgres) GetOne(ctx context.Context, id int) (w internal.Webhook, err error) {
+	row := r.db.QueryRowContext(ctx, "SELECT id, url, events, secret, version FROM webhooks WHERE id = $1", id)
+	w, err = scanWebhook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = internal.ErrWebhookNotFound
+	}
+	return
+}
+
+// Store saves a webhook
+func (r *WebhookPostgres) Store(ctx context.Context, w *internal.Webhook) (err error) {
+	err = r.db.QueryRowContext(ctx,
+		"INSERT INTO webhooks (url, events, secret, version) VALUES ($1, $2, $3, 1) RETURNING id",
+		w.URL, joinEventTypes(w.Events), w.Secret,
+	).Scan(&w.ID)
+	if err != nil {
+		return
+	}
+	w.Version = 1
+	return
+}
+
+// Update updates a webhook if its version matches the stored one, incrementing it
+func (r *WebhookPostgres) Update(ctx context.Context, w *internal.Webhook) (err error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE webhooks SET url = $1, events = $2, secret = $3, version = version + 1 WHERE id = $4 AND version = $5",
+		w.URL, joinEventTypes(w.Events), w.Secret, w.ID, w.Version,
+	)
+	if err != nil {
+		return
+	}
+	err = webhookUpdated(ctx, result, w, r.GetOne)
+	return
+}
+
+// Delete deletes a webhook by id, its dead letters are deleted by the foreign key
+func (r *WebhookPostgres) Delete(ctx context.Context, id int) (err error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM webhooks WHERE id = $1", id)
+	if err != nil {
+		return
+	}
+	err = affected(result, internal.ErrWebhookNotFound)
+	return
+}
+
+// DeadLetters returns the dead letters of a webhook, the oldest first
+func (r *WebhookPostgres) DeadLetters(ctx context.Context, webhookID int) (ds []internal.DeadLetter, err error) {
+	if _, err = r.GetOne(ctx, webhookID); err != nil {
+		return
+	}
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, id_webhook, event_id, event_type, event_payload, event_time, attempts, error, failed_at "+
+			"FROM webhook_dead_letters WHERE id_webhook = $1 ORDER BY id",
+		webhookID,
+	)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	ds, err = scanDeadLetters(rows)
+	return
+}
+
+// StoreDeadLetter saves a dead letter
+func (r *WebhookPostgres) StoreDeadLetter(ctx context.Context, d *internal.DeadLetter) (err error) {
+	if _, err = r.GetOne(ctx, d.WebhookID); err != nil {
+		return
+	}
+	m, err := internal.NewOutboxMessage(d.Event)
+	if err != nil {
+		return
+	}
+	err = r.db.QueryRowContext(ctx,
+		"INSERT INTO webhook_dead_letters (id_webhook, event_id, event_type, event_payload, event_time, attempts, error, failed_at) "+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
+		d.WebhookID, d.Event.ID, m.Type, string(m.Payload), m.CreatedAt.UTC(), d.Attempts, d.Error, d.FailedAt.UTC(),
+	).Scan(&d.ID)
+	return
+}
+
+// DeleteDeadLetter deletes a dead letter of a webhook by id, returning it
+func (r *WebhookPostgres) DeleteDeadLetter(ctx context.Context, webhookID int, id int) (d internal.DeadLetter, err error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, id_webhook, event_id, event_type, event_payload, event_time, attempts, error, failed_at "+
+			"FROM webhook_dead_letters WHERE id_webhook = $1 AND id = $2",
+		webhookID, id,
+	)
+	if err != nil {
+		return
+	}
+	ds, err := scanDeadLetters(rows)
+	rows.Close()
+	if err != nil {
+		return
+	}
+	if len(ds) == 0 {
+		err = internal.ErrDeadLetterNotFound
+		return
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM webhook_dead_letters WHERE id_webhook = $1 AND id = $2", webhookID, id)
+	if err != nil {
+		return
+	}
+	if err = affected(result, internal.ErrDeadLetterNotFound); err != nil {
+		return
+	}
+	d = ds[0]
+	return
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// NewWebhookSQLite returns a new instance of WebhookSQLite
+func NewWebhookSQLite(db *sql.DB) *WebhookSQLite {
+	return &WebhookSQLite{
+		db: db,
+	}
+}
+
+// WebhookSQLite is a struct that represents a webhook repository
+type WebhookSQLite struct {
+	// db is the database connection
+	db *sql.DB
+}
+
+// GetAll returns all webhooks, ordered by id
+func (r *WebhookSQLite) GetAll(ctx context.Context) (ws []internal.Webhook, err error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT `id`, `url`, `events`, `secret`, `version` FROM `webhooks` ORDER BY `id`")
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var w internal.Webhook
+		if w, err = scanWebhook(rows); err != nil {
+			return
+		}
+		ws = append(ws, w)
+	}
+	err = rows.Err()
+	return
+}
+
+// GetOne returns a webhook by id
+func (r *WebhookSQLite) GetOne(ctx context.Context, id int) (w internal.Webhook, err error) {
+	row := r.db.QueryRowContext(ctx, "SELECT `id`, `url`, `events`, `secret`, `version` FROM `webhooks` WHERE `id` = ?", id)
+	w, err = scanWebhook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = internal.ErrWebhookNotFound
+	}
+	return
+}
+
+// Store saves a webhook
+func (r *WebhookSQLite) Store(ctx context.Context, w *internal.Webhook) (err error) {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO `webhooks` (`url`, `events`, `secret`, `version`) VALUES (?, ?, ?, 1)",
+		w.URL, joinEventTypes(w.Events), w.Secret,
+	)
+	if err != nil {
+		return
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return
+	}
+	w.ID = int(id)
+	w.Version = 1
+	return
+}
+
+// Update updates a webhook if its version matches the stored one, incrementing it
+func (r *WebhookSQLite) Update(ctx context.Context, w *internal.Webhook) (err error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE `webhooks` SET `url` = ?, `events` = ?, `secret` = ?, `version` = `version` + 1 WHERE `id` = ? AND `version` = ?",
+		w.URL, joinEventTypes(w.Events), w.Secret, w.ID, w.Version,
+	)
+	if err != nil {
+		return
+	}
+	err = webhookUpdated(ctx, result, w, r.GetOne)
+	return
+}
+
+// Delete deletes a webhook by id, its dead letters are deleted by the foreign key
+func (r *WebhookSQLite) Delete(ctx context.Context, id int) (err error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM `webhooks` WHERE `id` = ?", id)
+	if err != nil {
+		return
+	}
+	err = affected(result, internal.ErrWebhookNotFound)
+	return
+}
+
+// DeadLetters returns the dead letters of a webhook, the oldest first
+func (r *WebhookSQLite) DeadLetters(ctx context.Context, webhookID int) (ds []internal.DeadLetter, err error) {
+	if _, err = r.GetOne(ctx, webhookID); err != nil {
+		return
+	}
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT `id`, `id_webhook`, `event_id`, `event_type`, `event_payload`, `event_time`, `attempts`, `error`, `failed_at` "+
+			"FROM `webhook_dead_letters` WHERE `id_webhook` = ? ORDER BY `id`",
+		webhookID,
+	)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	ds, err = scanDeadLetters(rows)
+	return
+}
+
+// StoreDeadLetter saves a dead letter
+func (r *WebhookSQLite) StoreDeadLetter(ctx context.Context, d *internal.DeadLetter) (err error) {
+	if _, err = r.GetOne(ctx, d.WebhookID); err != nil {
+		return
+	}
+	m, err := internal.NewOutboxMessage(d.Event)
+	if err != nil {
+		return
+	}
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO `webhook_dead_letters` (`id_webhook`, `event_id`, `event_type`, `event_payload`, `event_time`, `attempts`, `error`, `failed_at`) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		d.WebhookID, d.Event.ID, m.Type, string(m.Payload), m.CreatedAt.UTC(), d.Attempts, d.Error, d.FailedAt.UTC(),
+	)
+	if err != nil {
+		return
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return
+	}
+	d.ID = int(id)
+	return
+}
+
+// DeleteDeadLetter deletes a dead letter of a webhook by id, returning it
+func (r *WebhookSQLite) DeleteDeadLetter(ctx context.Context, webhookID int, id int) (d internal.DeadLetter, err error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT `id`, `id_webhook`, `event_id`, `event_type`, `event_payload`, `event_time`, `attempts`, `error`, `failed_at` "+
+			"FROM `webhook_dead_letters` WHERE `id_webhook` = ? AND `id` = ?",
+		webhookID, id,
+	)
+	if err != nil {
+		return
+	}
+	ds, err := scanDeadLetters(rows)
+	rows.Close()
+	if err != nil {
+		return
+	}
+	if len(ds) == 0 {
+		err = internal.ErrDeadLetterNotFound
+		return
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM `webhook_dead_letters` WHERE `id_webhook` = ? AND `id` = ?", webhookID, id)
+	if err != nil {
+		return
+	}
+	if err = affected(result, internal.ErrDeadLetterNotFound); err != nil {
+		return
+	}
+	d = ds[0]
+	return
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// DefaultRelayInterval is the time between two passes of an OutboxRelay over the pending messages
+	DefaultRelayInterval = 500 * time.Millisecond
+	// relayBatch is the number of pending messages read by a pass
+	relayBatch = 100
+)
+
+// NewOutboxRelay returns a new instance of OutboxRelay, an interval of zero or less is DefaultRelayInterval
+func NewOutboxRelay(uow internal.UnitOfWork, interval time.Duration, sinks ...internal.EventSink) *OutboxRelay {
+	if interval <= 0 {
+		interval = DefaultRelayInterval
+	}
+	return &OutboxRelay{
+		uow:      uow,
+		interval: interval,
+		sinks:    sinks,
+	}
+}
+
+// OutboxRelay is a struct that delivers the messages pending in the outbox to the sinks, at least once:
+// a message is marked relayed once every sink accepted it, so a failure or a crash before makes it sent again.
+// The messages of an entity are delivered in order, the ones of different entities are not held back by each other
+type OutboxRelay struct {
+	// uow is the unit of work
+	uow internal.UnitOfWork
+	// interval is the time between two passes
+	interval time.Duration
+	// sinks are the destinations of the events
+	sinks []internal.EventSink
+}
+
+// Run relays the pending messages every interval until ctx is done. A pass that fails is tried again on the next one
+func (s *OutboxRelay) Run(ctx context.Context) {
+	tk := time.NewTicker(s.interval)
+	defer tk.Stop()
+	for {
+		s.Relay(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-tk.C:
+		}
+	}
+}
+
+// Relay makes a pass over the pending messages, the oldest first, and returns the number of them relayed.
+// When a message fails, the later ones of its entity wait for the next pass; err joins the errors of the sinks
+func (s *OutboxRelay) Relay(ctx context.Context) (relayed int, err error) {
+	// pending messages
+	var ms []internal.OutboxMessage
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		ms, err = r.Outbox.Pending(ctx, relayBatch)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	// deliver
+	var errs []error
+	held := make(map[string]bool)
+	for _, m := range ms {
+		if held[m.Entity] {
+			continue
+		}
+		if e := s.send(ctx, m); e != nil {
+			held[m.Entity] = true
+			errs = append(errs, e)
+			continue
+		}
+		err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) error {
+			return r.Outbox.MarkRelayed(ctx, m.ID)
+		})
+		if err != nil {
+			return
+		}
+		relayed++
+	}
+	err = errors.Join(errs...)
+	return
+}
+
+// send delivers the event of a message to every sink
+func (s *OutboxRelay) send(ctx context.Context, m internal.OutboxMessage) (err error) {
+	e, err := m.Event()
+	if err != nil {
+		return
+	}
+	for _, sk := range s.sinks {
+		if err = sk.Send(ctx, e); err != nil {
+			return
+		}
+	}
+	return
+}
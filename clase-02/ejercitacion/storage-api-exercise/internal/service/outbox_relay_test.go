@@ -0,0 +1,98 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sinkStub is an event sink that keeps the events it is sent, failing the ones of the products in fail
+type sinkStub struct {
+	events []internal.Event
+	fail   map[int]bool
+}
+
+func (s *sinkStub) Send(ctx context.Context, e internal.Event) (err error) {
+	if p, ok := e.Data.(internal.Product); ok && s.fail[p.ID] {
+		err = errors.New("sink unavailable")
+		return
+	}
+	s.events = append(s.events, e)
+	return
+}
+
+// appendEvents writes events of products to the outbox of a unit of work, one per id
+func appendEvents(t *testing.T, uow internal.UnitOfWork, ids ...int) {
+	t.Helper()
+	err := uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+		for _, id := range ids {
+			m, err := internal.NewOutboxMessage(internal.Event{Type: internal.EventProductUpdated, Time: time.Now(), Data: internal.Product{ID: id}})
+			if err != nil {
+				return err
+			}
+			if err = r.Outbox.Append(ctx, &m); err != nil {
+				return err
+			}
+		}
+		return
+	})
+	require.NoError(t, err)
+}
+
+// Tests for OutboxRelay.Relay
+func TestOutboxRelay_Relay(t *testing.T) {
+	t.Run("success - pending messages relayed once to every sink", func(t *testing.T) {
+		// arrange
+		uow := repository.NewUnitOfWorkMemory(repository.NewMemory())
+		appendEvents(t, uow, 1, 2, 1)
+		s1, s2 := &sinkStub{}, &sinkStub{}
+		rl := service.NewOutboxRelay(uow, time.Millisecond, s1, s2)
+
+		// act
+		relayed, err := rl.Relay(context.Background())
+		again, errAgain := rl.Relay(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 3, relayed)
+		require.NoError(t, errAgain)
+		require.Zero(t, again)
+		for _, s := range []*sinkStub{s1, s2} {
+			require.Len(t, s.events, 3)
+			for i, e := range s.events {
+				require.Equal(t, int64(i+1), e.ID)
+			}
+		}
+		require.Empty(t, pending(t, uow))
+	})
+
+	t.Run("failure - entity held back until its message is delivered", func(t *testing.T) {
+		// arrange
+		uow := repository.NewUnitOfWorkMemory(repository.NewMemory())
+		appendEvents(t, uow, 1, 2, 1)
+		sk := &sinkStub{fail: map[int]bool{1: true}}
+		rl := service.NewOutboxRelay(uow, time.Millisecond, sk)
+
+		// act
+		relayed, err := rl.Relay(context.Background())
+		sk.fail = nil
+		again, errAgain := rl.Relay(context.Background())
+
+		// assert
+		require.Error(t, err)
+		require.Equal(t, 1, relayed)
+		require.NoError(t, errAgain)
+		require.Equal(t, 2, again)
+		var ids []int64
+		for _, e := range sk.events {
+			ids = append(ids, e.ID)
+		}
+		require.Equal(t, []int64{2, 1, 3}, ids)
+	})
+}
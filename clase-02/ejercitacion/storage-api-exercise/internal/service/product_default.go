@@ -0,0 +1,416 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NewProductsDefault returns a new instance of ProductsDefault
+func NewProductsDefault(uow internal.UnitOfWork) *ProductsDefault {
+	return &ProductsDefault{
+		uow: uow,
+	}
+}
+
+// ProductsDefault is a struct that represents the default product service.
+// Each operation runs in a unit of work, so its checks and writes commit or roll back together
+type ProductsDefault struct {
+	// uow is the unit of work of the product and warehouse repositories
+	uow internal.UnitOfWork
+}
+
+// GetAll returns the products matching the filter
+func (s *ProductsDefault) GetAll(ctx context.Context, f internal.ProductFilter) (products []internal.Product, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		all, err := r.Products.GetAll(ctx)
+		if err != nil {
+			return
+		}
+
+		for _, p := range all {
+			if f.Match(p) {
+				products = append(products, p)
+			}
+		}
+		return
+	})
+	return
+}
+
+// GetOne returns a product by id
+func (s *ProductsDefault) GetOne(ctx context.Context, id int) (p internal.Product, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		p, err = r.Products.GetOne(ctx, id)
+		return
+	})
+	return
+}
+
+// GetAllWithWarehouse returns the products matching the filter with their warehouse
+func (s *ProductsDefault) GetAllWithWarehouse(ctx context.Context, f internal.ProductFilter) (products []internal.ProductWarehouse, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		all, err := r.Products.GetAllWithWarehouse(ctx)
+		if err != nil {
+			return
+		}
+
+		for _, p := range all {
+			if f.Match(p.Product) {
+				products = append(products, p)
+			}
+		}
+		return
+	})
+	return
+}
+
+// GetOneWithWarehouse returns a product by id with its warehouse
+func (s *ProductsDefault) GetOneWithWarehouse(ctx context.Context, id int) (p internal.ProductWarehouse, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		p, err = r.Products.GetOneWithWarehouse(ctx, id)
+		return
+	})
+	return
+}
+
+// Create validates and creates a product
+func (s *ProductsDefault) Create(ctx context.Context, p *internal.Product) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		err = s.create(ctx, r, p)
+		return
+	})
+	return
+}
+
+// Update validates and updates a product
+func (s *ProductsDefault) Update(ctx context.Context, p *internal.Product) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		err = s.update(ctx, r, p)
+		return
+	})
+	return
+}
+
+// Delete deletes a product by id if its version matches the stored one, whatever its version when it is 0
+func (s *ProductsDefault) Delete(ctx context.Context, id int, version int) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		err = r.Products.Delete(ctx, id, version)
+		return
+	})
+	return
+}
+
+// Import creates a product or updates the one with its code value
+func (s *ProductsDefault) Import(ctx context.Context, p *internal.Product) (created bool, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		current, err := r.Products.GetByCodeValue(ctx, p.CodeValue)
+		switch {
+		case err == nil:
+			p.ID = current.ID
+			p.Version = current.Version
+			err = s.update(ctx, r, p)
+		case errors.Is(err, internal.ErrProductNotFound):
+			err = s.create(ctx, r, p)
+			created = err == nil
+		}
+		return
+	})
+	return
+}
+
+// CreateBulk validates and creates products in a single transaction
+func (s *ProductsDefault) CreateBulk(ctx context.Context, ps []internal.Product, atomic bool) (errs []error, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		// rules
+		errs = make([]error, len(ps))
+		for i := range ps {
+			errs[i] = s.check(ctx, r, ps[i], nil)
+		}
+		// - room for every new product
+		err = s.checkCapacityBulk(ctx, r, ps, errs, func(i int) bool { return true })
+		if err != nil {
+			return
+		}
+
+		err = s.bulk(errs, atomic, func(idx []int) (errs []error, err error) {
+			valid := make([]internal.Product, len(idx))
+			for k, i := range idx {
+				valid[k] = ps[i]
+			}
+			errs, err = r.Products.StoreBulk(ctx, valid, atomic)
+			for k, i := range idx {
+				ps[i] = valid[k]
+			}
+			return
+		})
+		return
+	})
+	return
+}
+
+// UpdateBulk validates and updates products in a single transaction
+func (s *ProductsDefault) UpdateBulk(ctx context.Context, ps []internal.Product, atomic bool) (errs []error, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		// rules
+		errs = make([]error, len(ps))
+		moved := make([]bool, len(ps))
+		for i := range ps {
+			var current internal.Product
+			if current, errs[i] = r.Products.GetOne(ctx, ps[i].ID); errs[i] != nil {
+				if !errors.Is(errs[i], internal.ErrProductNotFound) {
+					err = errs[i]
+					return
+				}
+				continue
+			}
+			if errs[i] = s.check(ctx, r, ps[i], &current); errs[i] != nil {
+				continue
+			}
+			moved[i] = current.WarehouseId != ps[i].WarehouseId
+		}
+		// - room for the products that move to another warehouse
+		err = s.checkCapacityBulk(ctx, r, ps, errs, func(i int) bool { return moved[i] })
+		if err != nil {
+			return
+		}
+
+		err = s.bulk(errs, atomic, func(idx []int) (errs []error, err error) {
+			valid := make([]internal.Product, len(idx))
+			for k, i := range idx {
+				valid[k] = ps[i]
+			}
+			errs, err = r.Products.UpdateBulk(ctx, valid, atomic)
+			for k, i := range idx {
+				ps[i] = valid[k]
+			}
+			return
+		})
+		return
+	})
+	return
+}
+
+// DeleteBulk deletes products by id in a single transaction
+func (s *ProductsDefault) DeleteBulk(ctx context.Context, ids []int, atomic bool) (errs []error, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		errs, err = r.Products.DeleteBulk(ctx, ids, atomic)
+		return
+	})
+	return
+}
+
+// Search returns the products whose name or code value match a query, none for a blank query
+func (s *ProductsDefault) Search(ctx context.Context, query string, limit int) (ms []internal.ProductMatch, err error) {
+	// check query
+	if strings.TrimSpace(query) == "" {
+		return
+	}
+
+	// check limit
+	switch {
+	case limit <= 0:
+		limit = internal.ProductSearchLimit
+	case limit > internal.ProductSearchMaxLimit:
+		limit = internal.ProductSearchMaxLimit
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		ms, err = r.Products.Search(ctx, query, limit)
+		return
+	})
+	return
+}
+
+// create validates and stores a product
+func (s *ProductsDefault) create(ctx context.Context, r internal.Repositories, p *internal.Product) (err error) {
+	// rules
+	if err = s.check(ctx, r, *p, nil); err != nil {
+		return
+	}
+	// - room for one more product
+	if err = s.checkCapacity(ctx, r, p.WarehouseId, 1); err != nil {
+		return
+	}
+
+	err = r.Products.Store(ctx, p)
+	return
+}
+
+// update validates and updates a product
+func (s *ProductsDefault) update(ctx context.Context, r internal.Repositories, p *internal.Product) (err error) {
+	// rules
+	current, err := r.Products.GetOne(ctx, p.ID)
+	if err != nil {
+		return
+	}
+	if err = s.check(ctx, r, *p, &current); err != nil {
+		return
+	}
+	// - room for one more product when it moves to another warehouse
+	if current.WarehouseId != p.WarehouseId {
+		if err = s.checkCapacity(ctx, r, p.WarehouseId, 1); err != nil {
+			return
+		}
+	}
+
+	err = r.Products.Update(ctx, p)
+	return
+}
+
+// bulk runs the repository operation fn of a batch on its items without errors, given by index, merging their errors into errs.
+// An all-or-nothing batch with an item error is aborted without running it
+func (s *ProductsDefault) bulk(errs []error, atomic bool, fn func(idx []int) (errs []error, err error)) (err error) {
+	var idx []int
+	for i := range errs {
+		if errs[i] == nil {
+			idx = append(idx, i)
+			continue
+		}
+		if atomic {
+			err = fmt.Errorf("%w: %w", internal.ErrProductBulkAborted, errs[i])
+			return
+		}
+	}
+	if len(idx) == 0 {
+		return
+	}
+
+	validErrs, err := fn(idx)
+	if validErrs != nil {
+		for k, i := range idx {
+			errs[i] = validErrs[k]
+		}
+	}
+	return
+}
+
+// check validates a product and checks its code value is not used by another product.
+// current is the stored product of an update, nil on create
+func (s *ProductsDefault) check(ctx context.Context, r internal.Repositories, p internal.Product, current *internal.Product) (err error) {
+	if err = validateProduct(p, current); err != nil {
+		return
+	}
+
+	other, err := r.Products.GetByCodeValue(ctx, p.CodeValue)
+	switch {
+	case errors.Is(err, internal.ErrProductNotFound):
+		err = nil
+	case err != nil:
+	case other.ID != p.ID:
+		err = internal.ErrProductNotUnique
+	}
+	return
+}
+
+// checkCapacity checks a warehouse has room for n more products.
+// A warehouse that does not exist is a relation error, as in the repository
+func (s *ProductsDefault) checkCapacity(ctx context.Context, r internal.Repositories, warehouseId int, n int) (err error) {
+	left, err := capacityLeft(ctx, r, warehouseId)
+	if err != nil {
+		return
+	}
+	if left < n {
+		err = internal.ErrWarehouseCapacityExceeded
+	}
+	return
+}
+
+// checkCapacityBulk sets the error of the products of a batch that do not fit in their warehouse, in order.
+// added tells if the product at an index is added to its warehouse, err reports a failure to check it.
+// The warehouses are locked in order of id, so concurrent batches do not deadlock
+func (s *ProductsDefault) checkCapacityBulk(ctx context.Context, r internal.Repositories, ps []internal.Product, errs []error, added func(i int) bool) (err error) {
+	type room struct {
+		left int
+		err  error
+	}
+	// - room left in each warehouse
+	rooms := make(map[int]*room)
+	for i, p := range ps {
+		if errs[i] == nil && added(i) {
+			rooms[p.WarehouseId] = &room{}
+		}
+	}
+	ids := make([]int, 0, len(rooms))
+	for id := range rooms {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		rm := rooms[id]
+		rm.left, rm.err = capacityLeft(ctx, r, id)
+		if rm.err != nil && !errors.Is(rm.err, internal.ErrProductRelation) {
+			err = rm.err
+			return
+		}
+	}
+
+	// - products that fit, in order
+	for i, p := range ps {
+		if errs[i] != nil || !added(i) {
+			continue
+		}
+		rm := rooms[p.WarehouseId]
+		switch {
+		case rm.err != nil:
+			errs[i] = rm.err
+		case rm.left <= 0:
+			errs[i] = internal.ErrWarehouseCapacityExceeded
+		default:
+			rm.left--
+		}
+	}
+	return
+}
+
+// capacityLeft returns the number of products a warehouse has room for.
+// The warehouse stays locked until the unit of work ends, so no other write fills it after the count
+func capacityLeft(ctx context.Context, r internal.Repositories, warehouseId int) (left int, err error) {
+	w, err := r.Warehouses.GetOneForUpdate(ctx, warehouseId)
+	if err != nil {
+		if errors.Is(err, internal.ErrWarehouseNotFound) {
+			err = internal.ErrProductRelation
+		}
+		return
+	}
+	count, err := productCount(ctx, r.Warehouses, warehouseId)
+	if err != nil {
+		return
+	}
+
+	left = w.Capacity - count
+	return
+}
+
+// validateProduct returns a *internal.FieldError for the first field of a product that breaks a rule.
+// An expired product can not be published, on create or when an update publishes it; one already published can still be updated.
+// current is the stored product of an update, nil on create
+func validateProduct(p internal.Product, current *internal.Product) (err error) {
+	fail := func(field, code, message string) error {
+		return &internal.FieldError{Field: field, Code: code, Message: message, Err: internal.ErrProductInvalid}
+	}
+
+	y, m, d := time.Now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	switch {
+	case strings.TrimSpace(p.Name) == "":
+		err = fail("name", "required", "is required")
+	case p.Quantity < 0:
+		err = fail("quantity", "min", "must not be negative")
+	case strings.TrimSpace(p.CodeValue) == "":
+		err = fail("code_value", "required", "is required")
+	case p.Expiration.IsZero():
+		err = fail("expiration", "required", "is required")
+	case p.IsPublished && (current == nil || !current.IsPublished) && p.Expiration.Before(today):
+		err = fail("expiration", "expired", "has passed, an expired product can not be published")
+	case p.Price < 0:
+		err = fail("price", "min", "must not be negative")
+	case p.WarehouseId <= 0:
+		err = fail("warehouse_id", "gt", "must be positive")
+	}
+	return
+}
@@ -0,0 +1,388 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newProductService returns a product service over an in-memory database with a warehouse of the given capacity,
+// along with the product repository and the unit of work of the database
+func newProductService(t *testing.T, capacity int) (sv *service.ProductsDefault, rp internal.RepositoryProducts, uow internal.UnitOfWork, w internal.Warehouse) {
+	t.Helper()
+	db := repository.NewMemory()
+	rp = repository.NewProductsMemory(db)
+	w = internal.Warehouse{Name: "warehouse 1", Address: "address", Telephone: "telephone", Capacity: capacity}
+	require.NoError(t, repository.NewWarehouseMemory(db).Store(context.Background(), &w))
+	uow = repository.NewUnitOfWorkMemory(db)
+	sv = service.NewProductsDefault(uow)
+	return
+}
+
+// newProduct returns a valid product of a warehouse, not stored yet
+func newProduct(code string, warehouseId int) internal.Product {
+	return internal.Product{
+		Name:        "product " + code,
+		Quantity:    10,
+		CodeValue:   code,
+		IsPublished: true,
+		Expiration:  time.Now().AddDate(1, 0, 0),
+		Price:       9.5,
+		WarehouseId: warehouseId,
+	}
+}
+
+// Tests for ProductsDefault.Create
+func TestProductsDefault_Create(t *testing.T) {
+	t.Run("success - product created", func(t *testing.T) {
+		// arrange
+		sv, rp, _, w := newProductService(t, 10)
+		p := newProduct("A1", w.Id)
+
+		// act
+		err := sv.Create(context.Background(), &p)
+
+		// assert
+		require.NoError(t, err)
+		require.Positive(t, p.ID)
+		_, err = rp.GetOne(context.Background(), p.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("failure - invalid fields", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, 10)
+		cases := map[string]func(p *internal.Product){
+			"name":         func(p *internal.Product) { p.Name = " " },
+			"quantity":     func(p *internal.Product) { p.Quantity = -1 },
+			"code_value":   func(p *internal.Product) { p.CodeValue = "" },
+			"expiration":   func(p *internal.Product) { p.Expiration = time.Now().AddDate(0, 0, -2) },
+			"price":        func(p *internal.Product) { p.Price = -0.5 },
+			"warehouse_id": func(p *internal.Product) { p.WarehouseId = 0 },
+		}
+
+		for field, invalidate := range cases {
+			p := newProduct("A1", w.Id)
+			invalidate(&p)
+
+			// act
+			err := sv.Create(context.Background(), &p)
+
+			// assert
+			require.ErrorIs(t, err, internal.ErrProductInvalid, field)
+			var fieldErr *internal.FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			require.Equal(t, field, fieldErr.Field)
+		}
+	})
+
+	t.Run("success - an expired product is created unpublished", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, 10)
+		p := newProduct("A1", w.Id)
+		p.IsPublished = false
+		p.Expiration = time.Now().AddDate(0, 0, -2)
+
+		// act
+		err := sv.Create(context.Background(), &p)
+
+		// assert
+		require.NoError(t, err)
+	})
+
+	t.Run("failure - code value not unique", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, 10)
+		p, duplicate := newProduct("A1", w.Id), newProduct("A1", w.Id)
+		require.NoError(t, sv.Create(context.Background(), &p))
+
+		// act
+		err := sv.Create(context.Background(), &duplicate)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductNotUnique)
+	})
+
+	t.Run("failure - warehouse not found", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, 10)
+		p := newProduct("A1", w.Id+1)
+
+		// act
+		err := sv.Create(context.Background(), &p)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductRelation)
+	})
+
+	t.Run("failure - warehouse capacity exceeded", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, 1)
+		p1, p2 := newProduct("A1", w.Id), newProduct("A2", w.Id)
+		require.NoError(t, sv.Create(context.Background(), &p1))
+
+		// act
+		err := sv.Create(context.Background(), &p2)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrWarehouseCapacityExceeded)
+	})
+}
+
+// Tests for ProductsDefault.Update
+func TestProductsDefault_Update(t *testing.T) {
+	t.Run("success - product updated in a full warehouse", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, 1)
+		p := newProduct("A1", w.Id)
+		require.NoError(t, sv.Create(context.Background(), &p))
+		p.Name = "updated"
+
+		// act
+		err := sv.Update(context.Background(), &p)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 2, p.Version)
+	})
+
+	t.Run("failure - product moved to a full warehouse", func(t *testing.T) {
+		// arrange
+		db := repository.NewMemory()
+		rw := repository.NewWarehouseMemory(db)
+		w1 := internal.Warehouse{Name: "warehouse 1", Address: "address", Telephone: "telephone", Capacity: 1}
+		w2 := internal.Warehouse{Name: "warehouse 2", Address: "address", Telephone: "telephone", Capacity: 1}
+		require.NoError(t, rw.Store(context.Background(), &w1))
+		require.NoError(t, rw.Store(context.Background(), &w2))
+		sv := service.NewProductsDefault(repository.NewUnitOfWorkMemory(db))
+		p1, p2 := newProduct("A1", w1.Id), newProduct("A2", w2.Id)
+		require.NoError(t, sv.Create(context.Background(), &p1))
+		require.NoError(t, sv.Create(context.Background(), &p2))
+		p2.WarehouseId = w1.Id
+
+		// act
+		err := sv.Update(context.Background(), &p2)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrWarehouseCapacityExceeded)
+	})
+
+	t.Run("success - a published product updated after it expired", func(t *testing.T) {
+		// arrange
+		sv, rp, _, w := newProductService(t, 10)
+		p := newProduct("A1", w.Id)
+		p.Expiration = time.Now().AddDate(0, 0, -2)
+		require.NoError(t, rp.Store(context.Background(), &p))
+		p.Quantity = 0
+
+		// act
+		err := sv.Update(context.Background(), &p)
+
+		// assert
+		require.NoError(t, err)
+	})
+
+	t.Run("failure - an expired product published", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, 10)
+		p := newProduct("A1", w.Id)
+		p.IsPublished = false
+		p.Expiration = time.Now().AddDate(0, 0, -2)
+		require.NoError(t, sv.Create(context.Background(), &p))
+		p.IsPublished = true
+
+		// act
+		err := sv.Update(context.Background(), &p)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductInvalid)
+		var fieldErr *internal.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		require.Equal(t, "expiration", fieldErr.Field)
+	})
+
+	t.Run("failure - code value of another product", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, 10)
+		p1, p2 := newProduct("A1", w.Id), newProduct("A2", w.Id)
+		require.NoError(t, sv.Create(context.Background(), &p1))
+		require.NoError(t, sv.Create(context.Background(), &p2))
+		p2.CodeValue = "A1"
+
+		// act
+		err := sv.Update(context.Background(), &p2)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductNotUnique)
+	})
+
+	t.Run("failure - product not found", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, 10)
+		p := newProduct("A1", w.Id)
+		p.ID = 1
+
+		// act
+		err := sv.Update(context.Background(), &p)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+	})
+}
+
+// Tests for ProductsDefault.Import
+func TestProductsDefault_Import(t *testing.T) {
+	t.Run("success - created then updated by code value", func(t *testing.T) {
+		// arrange
+		sv, rp, _, w := newProductService(t, 10)
+		p, again := newProduct("A1", w.Id), newProduct("A1", w.Id)
+		again.Name = "updated"
+
+		// act
+		created, err := sv.Import(context.Background(), &p)
+		createdAgain, errAgain := sv.Import(context.Background(), &again)
+
+		// assert
+		require.NoError(t, err)
+		require.True(t, created)
+		require.NoError(t, errAgain)
+		require.False(t, createdAgain)
+		require.Equal(t, p.ID, again.ID)
+		stored, err := rp.GetOne(context.Background(), p.ID)
+		require.NoError(t, err)
+		require.Equal(t, "updated", stored.Name)
+	})
+}
+
+// Tests for ProductsDefault.GetAll
+func TestProductsDefault_GetAll(t *testing.T) {
+	t.Run("success - products matching the filter", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, 10)
+		p1, p2 := newProduct("A1", w.Id), newProduct("A2", w.Id)
+		p2.IsPublished = false
+		require.NoError(t, sv.Create(context.Background(), &p1))
+		require.NoError(t, sv.Create(context.Background(), &p2))
+		published := false
+
+		// act
+		ps, err := sv.GetAll(context.Background(), internal.ProductFilter{WarehouseId: w.Id, IsPublished: &published})
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, ps, 1)
+		require.Equal(t, p2.ID, ps[0].ID)
+	})
+}
+
+// Tests for ProductsDefault.GetAllWithWarehouse
+func TestProductsDefault_GetAllWithWarehouse(t *testing.T) {
+	t.Run("success - products matching the filter with their warehouse", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, 10)
+		p1, p2 := newProduct("A1", w.Id), newProduct("A2", w.Id)
+		p2.IsPublished = false
+		require.NoError(t, sv.Create(context.Background(), &p1))
+		require.NoError(t, sv.Create(context.Background(), &p2))
+		published := true
+
+		// act
+		ps, err := sv.GetAllWithWarehouse(context.Background(), internal.ProductFilter{IsPublished: &published})
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, ps, 1)
+		require.Equal(t, p1.ID, ps[0].ID)
+		require.Equal(t, w, ps[0].Warehouse)
+	})
+}
+
+// Tests for ProductsDefault.CreateBulk
+func TestProductsDefault_CreateBulk(t *testing.T) {
+	t.Run("success - best effort skips the products that break a rule", func(t *testing.T) {
+		// arrange
+		sv, rp, _, w := newProductService(t, 2)
+		ps := []internal.Product{newProduct("A1", w.Id), newProduct("A2", w.Id), newProduct("A3", w.Id), newProduct("A4", w.Id)}
+		ps[1].Name = ""
+
+		// act
+		errs, err := sv.CreateBulk(context.Background(), ps, false)
+
+		// assert
+		require.NoError(t, err)
+		require.NoError(t, errs[0])
+		require.ErrorIs(t, errs[1], internal.ErrProductInvalid)
+		require.NoError(t, errs[2])
+		require.ErrorIs(t, errs[3], internal.ErrWarehouseCapacityExceeded)
+		require.Positive(t, ps[0].ID)
+		require.Positive(t, ps[2].ID)
+		stored, err := rp.GetAll(context.Background())
+		require.NoError(t, err)
+		require.Len(t, stored, 2)
+	})
+
+	t.Run("failure - atomic batch with a product that breaks a rule", func(t *testing.T) {
+		// arrange
+		sv, rp, _, w := newProductService(t, 10)
+		ps := []internal.Product{newProduct("A1", w.Id), newProduct("A2", w.Id)}
+		ps[1].Quantity = -1
+
+		// act
+		errs, err := sv.CreateBulk(context.Background(), ps, true)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductBulkAborted)
+		require.ErrorIs(t, err, internal.ErrProductInvalid)
+		require.NoError(t, errs[0])
+		require.ErrorIs(t, errs[1], internal.ErrProductInvalid)
+		stored, err := rp.GetAll(context.Background())
+		require.NoError(t, err)
+		require.Empty(t, stored)
+	})
+}
+
+// Tests for ProductsDefault.Search
+func TestProductsDefault_Search(t *testing.T) {
+	t.Run("success - products matching the query up to the limit", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, internal.ProductSearchMaxLimit+1)
+		for i := 0; i <= internal.ProductSearchMaxLimit; i++ {
+			p := newProduct(fmt.Sprintf("A%d", i), w.Id)
+			p.Name = "Galletas de agua"
+			require.NoError(t, sv.Create(context.Background(), &p))
+		}
+
+		// act
+		limited, errLimited := sv.Search(context.Background(), "galletas", 2)
+		unset, errUnset := sv.Search(context.Background(), "galletas", 0)
+		most, errMost := sv.Search(context.Background(), "galletas", internal.ProductSearchMaxLimit+1)
+
+		// assert
+		require.NoError(t, errLimited)
+		require.Len(t, limited, 2)
+		require.NoError(t, errUnset)
+		require.Len(t, unset, internal.ProductSearchLimit)
+		require.NoError(t, errMost)
+		require.Len(t, most, internal.ProductSearchMaxLimit)
+	})
+
+	t.Run("success - none for a blank query", func(t *testing.T) {
+		// arrange
+		sv, _, _, w := newProductService(t, 10)
+		p := newProduct("A1", w.Id)
+		require.NoError(t, sv.Create(context.Background(), &p))
+
+		// act
+		ms, err := sv.Search(context.Background(), " ", 10)
+
+		// assert
+		require.NoError(t, err)
+		require.Empty(t, ms)
+	})
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"time"
+)
+
+// NewProductsOutbox returns a new instance of ProductsOutbox, uow must be the unit of work of sv so its changes join the same transaction
+func NewProductsOutbox(sv internal.ProductService, uow internal.UnitOfWork) *ProductsOutbox {
+	return &ProductsOutbox{
+		ProductService: sv,
+		uow:            uow,
+	}
+}
+
+// ProductsOutbox is a struct that represents a product service that writes the events of the changes it makes to the outbox,
+// with a stock event when the quantity of a product changes. The events are written in the transaction of the change,
+// so they are committed or rolled back with it, and the quantity before a change is read in it
+type ProductsOutbox struct {
+	internal.ProductService
+	// uow is the unit of work
+	uow internal.UnitOfWork
+}
+
+// append writes the event of a change of a product to the outbox, and its stock event when its quantity changed from previous
+func (s *ProductsOutbox) append(ctx context.Context, r internal.Repositories, t internal.EventType, p internal.Product, previous int) (err error) {
+	now := time.Now()
+	data := p
+	quantity := p.Quantity
+	if t == internal.EventProductDeleted {
+		data = internal.Product{ID: p.ID}
+		quantity = 0
+	}
+	events := []internal.Event{{Type: t, Time: now, Data: data}}
+	if quantity != previous {
+		events = append(events, internal.Event{Type: internal.EventProductStockChanged, Time: now, Data: internal.StockChange{
+			ProductID:   p.ID,
+			WarehouseId: p.WarehouseId,
+			Previous:    previous,
+			Quantity:    quantity,
+		}})
+	}
+
+	for _, e := range events {
+		var m internal.OutboxMessage
+		if m, err = internal.NewOutboxMessage(e); err != nil {
+			return
+		}
+		if err = r.Outbox.Append(ctx, &m); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// current returns the stored products of ids by id, before a change of several of them, without the ones not found
+func (s *ProductsOutbox) current(ctx context.Context, r internal.Repositories, ids []int) (ps map[int]internal.Product, err error) {
+	ps = make(map[int]internal.Product, len(ids))
+	for _, id := range ids {
+		var p internal.Product
+		p, err = r.Products.GetOne(ctx, id)
+		switch {
+		case err == nil:
+			ps[id] = p
+		case errors.Is(err, internal.ErrProductNotFound):
+			err = nil
+		default:
+			return
+		}
+	}
+	return
+}
+
+// Create creates a product and writes its event
+func (s *ProductsOutbox) Create(ctx context.Context, p *internal.Product) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		if err = s.ProductService.Create(ctx, p); err != nil {
+			return
+		}
+		err = s.append(ctx, r, internal.EventProductCreated, *p, 0)
+		return
+	})
+	return
+}
+
+// Update updates a product and writes its event
+func (s *ProductsOutbox) Update(ctx context.Context, p *internal.Product) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		before, err := r.Products.GetOne(ctx, p.ID)
+		if err != nil {
+			return
+		}
+		if err = s.ProductService.Update(ctx, p); err != nil {
+			return
+		}
+		err = s.append(ctx, r, internal.EventProductUpdated, *p, before.Quantity)
+		return
+	})
+	return
+}
+
+// Delete deletes a product by id and writes its event
+func (s *ProductsOutbox) Delete(ctx context.Context, id int, version int) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		before, err := r.Products.GetOne(ctx, id)
+		if err != nil {
+			return
+		}
+		if err = s.ProductService.Delete(ctx, id, version); err != nil {
+			return
+		}
+		err = s.append(ctx, r, internal.EventProductDeleted, before, before.Quantity)
+		return
+	})
+	return
+}
+
+// Import creates or updates a product and writes its event
+func (s *ProductsOutbox) Import(ctx context.Context, p *internal.Product) (created bool, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		// - the quantity of the product stored with the code value, if any
+		var previous int
+		before, err := r.Products.GetByCodeValue(ctx, p.CodeValue)
+		switch {
+		case err == nil:
+			previous = before.Quantity
+		case errors.Is(err, internal.ErrProductNotFound):
+			err = nil
+		default:
+			return
+		}
+		if created, err = s.ProductService.Import(ctx, p); err != nil {
+			return
+		}
+		if created {
+			err = s.append(ctx, r, internal.EventProductCreated, *p, 0)
+			return
+		}
+		err = s.append(ctx, r, internal.EventProductUpdated, *p, previous)
+		return
+	})
+	return
+}
+
+// CreateBulk creates products and writes the events of the ones created
+func (s *ProductsOutbox) CreateBulk(ctx context.Context, ps []internal.Product, atomic bool) (errs []error, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		if errs, err = s.ProductService.CreateBulk(ctx, ps, atomic); err != nil {
+			return
+		}
+		for i, p := range ps {
+			if errs[i] != nil {
+				continue
+			}
+			if err = s.append(ctx, r, internal.EventProductCreated, p, 0); err != nil {
+				return
+			}
+		}
+		return
+	})
+	return
+}
+
+// UpdateBulk updates products and writes the events of the ones updated
+func (s *ProductsOutbox) UpdateBulk(ctx context.Context, ps []internal.Product, atomic bool) (errs []error, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		ids := make([]int, 0, len(ps))
+		for _, p := range ps {
+			ids = append(ids, p.ID)
+		}
+		before, err := s.current(ctx, r, ids)
+		if err != nil {
+			return
+		}
+		if errs, err = s.ProductService.UpdateBulk(ctx, ps, atomic); err != nil {
+			return
+		}
+		for i, p := range ps {
+			if errs[i] != nil {
+				continue
+			}
+			if err = s.append(ctx, r, internal.EventProductUpdated, p, before[p.ID].Quantity); err != nil {
+				return
+			}
+		}
+		return
+	})
+	return
+}
+
+// DeleteBulk deletes products by id and writes the events of the ones deleted
+func (s *ProductsOutbox) DeleteBulk(ctx context.Context, ids []int, atomic bool) (errs []error, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		before, err := s.current(ctx, r, ids)
+		if err != nil {
+			return
+		}
+		if errs, err = s.ProductService.DeleteBulk(ctx, ids, atomic); err != nil {
+			return
+		}
+		for i, id := range ids {
+			if errs[i] != nil {
+				continue
+			}
+			p := before[id]
+			p.ID = id
+			if err = s.append(ctx, r, internal.EventProductDeleted, p, p.Quantity); err != nil {
+				return
+			}
+		}
+		return
+	})
+	return
+}
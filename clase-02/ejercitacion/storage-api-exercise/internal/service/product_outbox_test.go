@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/service"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pending returns the events pending in the outbox of a unit of work
+func pending(t *testing.T, uow internal.UnitOfWork) (events []internal.Event) {
+	t.Helper()
+	err := uow.Do(context.Background(), func(ctx context.Context, r internal.Repositories) (err error) {
+		ms, err := r.Outbox.Pending(ctx, 100)
+		for _, m := range ms {
+			var e internal.Event
+			if e, err = m.Event(); err != nil {
+				return
+			}
+			events = append(events, e)
+		}
+		return
+	})
+	require.NoError(t, err)
+	return
+}
+
+// Tests for ProductsOutbox
+func TestProductsOutbox(t *testing.T) {
+	t.Run("success - changes and stock written", func(t *testing.T) {
+		// arrange
+		sv, _, uow, w := newProductService(t, 10)
+		sp := service.NewProductsOutbox(sv, uow)
+		p := newProduct("A1", w.Id)
+
+		// act
+		require.NoError(t, sp.Create(context.Background(), &p))
+		p.Name = "product renamed"
+		require.NoError(t, sp.Update(context.Background(), &p))
+		p.Quantity = 4
+		require.NoError(t, sp.Update(context.Background(), &p))
+		require.NoError(t, sp.Delete(context.Background(), p.ID, 0))
+
+		// assert
+		events := pending(t, uow)
+		var got []internal.EventType
+		for i, e := range events {
+			require.Equal(t, int64(i+1), e.ID)
+			got = append(got, e.Type)
+		}
+		expected := []internal.EventType{
+			internal.EventProductCreated, internal.EventProductStockChanged,
+			internal.EventProductUpdated,
+			internal.EventProductUpdated, internal.EventProductStockChanged,
+			internal.EventProductDeleted, internal.EventProductStockChanged,
+		}
+		require.Equal(t, expected, got)
+		require.Equal(t, internal.StockChange{ProductID: p.ID, WarehouseId: w.Id, Previous: 10, Quantity: 4}, events[4].Data)
+		require.Equal(t, internal.Product{ID: p.ID}, events[5].Data)
+	})
+
+	t.Run("failure - change that fails not written", func(t *testing.T) {
+		// arrange
+		sv, _, uow, w := newProductService(t, 10)
+		sp := service.NewProductsOutbox(sv, uow)
+		p := newProduct("A1", w.Id)
+		p.Name = ""
+
+		// act
+		err := sp.Create(context.Background(), &p)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductInvalid)
+		require.Empty(t, pending(t, uow))
+	})
+
+	t.Run("failure - atomic batch that fails not written", func(t *testing.T) {
+		// arrange
+		sv, _, uow, w := newProductService(t, 10)
+		sp := service.NewProductsOutbox(sv, uow)
+		invalid := newProduct("A2", w.Id)
+		invalid.Name = ""
+
+		// act
+		_, err := sp.CreateBulk(context.Background(), []internal.Product{newProduct("A1", w.Id), invalid}, true)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductBulkAborted)
+		require.Empty(t, pending(t, uow))
+	})
+
+	t.Run("success - import reads the stock before by code value", func(t *testing.T) {
+		// arrange
+		sv, _, uow, w := newProductService(t, 10)
+		sp := service.NewProductsOutbox(sv, uow)
+		p := newProduct("A1", w.Id)
+		require.NoError(t, sv.Create(context.Background(), &p))
+		imported := newProduct("A1", w.Id)
+		imported.Quantity = 7
+
+		// act
+		created, err := sp.Import(context.Background(), &imported)
+
+		// assert
+		require.NoError(t, err)
+		require.False(t, created)
+		events := pending(t, uow)
+		require.Len(t, events, 2)
+		require.Equal(t, internal.EventProductUpdated, events[0].Type)
+		require.Equal(t, internal.StockChange{ProductID: p.ID, WarehouseId: w.Id, Previous: 10, Quantity: 7}, events[1].Data)
+	})
+
+	t.Run("failure - update and delete of a product not found not written", func(t *testing.T) {
+		// arrange
+		sv, _, uow, w := newProductService(t, 10)
+		sp := service.NewProductsOutbox(sv, uow)
+		p := newProduct("A1", w.Id)
+		p.ID = 99
+
+		// act
+		errUpdate := sp.Update(context.Background(), &p)
+		errDelete := sp.Delete(context.Background(), p.ID, 0)
+
+		// assert
+		require.ErrorIs(t, errUpdate, internal.ErrProductNotFound)
+		require.ErrorIs(t, errDelete, internal.ErrProductNotFound)
+		require.Empty(t, pending(t, uow))
+	})
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"strings"
+)
+
+// NewWarehouseDefault returns a new instance of WarehouseDefault
+func NewWarehouseDefault(uow internal.UnitOfWork) *WarehouseDefault {
+	return &WarehouseDefault{
+		uow: uow,
+	}
+}
+
+// WarehouseDefault is a struct that represents the default warehouse service.
+// Each operation runs in a unit of work, so its checks and writes commit or roll back together
+type WarehouseDefault struct {
+	// uow is the unit of work of the product and warehouse repositories
+	uow internal.UnitOfWork
+}
+
+// GetAll returns all warehouses
+func (s *WarehouseDefault) GetAll(ctx context.Context) (w []internal.Warehouse, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		w, err = r.Warehouses.GetAll(ctx)
+		return
+	})
+	return
+}
+
+// GetOne returns a warehouse by id
+func (s *WarehouseDefault) GetOne(ctx context.Context, id int) (w internal.Warehouse, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		w, err = r.Warehouses.GetOne(ctx, id)
+		return
+	})
+	return
+}
+
+// GetAllWithProducts returns all warehouses with their products
+func (s *WarehouseDefault) GetAllWithProducts(ctx context.Context) (w []internal.WarehouseProducts, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		w, err = r.Warehouses.GetAllWithProducts(ctx)
+		return
+	})
+	return
+}
+
+// GetOneWithProducts returns a warehouse by id with its products
+func (s *WarehouseDefault) GetOneWithProducts(ctx context.Context, id int) (w internal.WarehouseProducts, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		w, err = r.Warehouses.GetOneWithProducts(ctx, id)
+		return
+	})
+	return
+}
+
+// Create validates and creates a warehouse, moving the products with the given ids into it.
+// The warehouse is not created unless every product is moved
+func (s *WarehouseDefault) Create(ctx context.Context, w *internal.Warehouse, productIds ...int) (err error) {
+	// rules
+	if err = validateWarehouse(*w); err != nil {
+		return
+	}
+	// - room for the moved products
+	if len(productIds) > w.Capacity {
+		err = internal.ErrWarehouseCapacityExceeded
+		return
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		if err = r.Warehouses.Store(ctx, w); err != nil {
+			return
+		}
+
+		// move the products
+		for _, id := range productIds {
+			var p internal.Product
+			if p, err = r.Products.GetOne(ctx, id); err != nil {
+				return
+			}
+			if p.WarehouseId == w.Id {
+				continue
+			}
+			p.WarehouseId = w.Id
+			if err = r.Products.Update(ctx, &p); err != nil {
+				return
+			}
+		}
+		return
+	})
+	if err != nil {
+		// - the warehouse was rolled back
+		w.Id = 0
+		w.Version = 0
+	}
+	return
+}
+
+// Update validates and updates a warehouse, its capacity can not drop below the number of its products
+func (s *WarehouseDefault) Update(ctx context.Context, w *internal.Warehouse) (err error) {
+	if err = validateWarehouse(*w); err != nil {
+		return
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		// - locked, so no product is added after the count
+		if _, err = r.Warehouses.GetOneForUpdate(ctx, w.Id); err != nil {
+			return
+		}
+		count, err := productCount(ctx, r.Warehouses, w.Id)
+		if err != nil {
+			return
+		}
+		if count > w.Capacity {
+			err = internal.ErrWarehouseCapacityExceeded
+			return
+		}
+
+		err = r.Warehouses.Update(ctx, w)
+		return
+	})
+	return
+}
+
+// ReportProducts returns a report of products by warehouse
+func (s *WarehouseDefault) ReportProducts(ctx context.Context, id int) (rp []internal.ReportProduct, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		rp, err = r.Warehouses.ReportProducts(ctx, id)
+		return
+	})
+	return
+}
+
+// validateWarehouse returns a *internal.FieldError for the first field of a warehouse that breaks a rule
+func validateWarehouse(w internal.Warehouse) (err error) {
+	fail := func(field, code, message string) error {
+		return &internal.FieldError{Field: field, Code: code, Message: message, Err: internal.ErrWarehouseInvalid}
+	}
+
+	switch {
+	case strings.TrimSpace(w.Name) == "":
+		err = fail("name", "required", "is required")
+	case strings.TrimSpace(w.Address) == "":
+		err = fail("address", "required", "is required")
+	case strings.TrimSpace(w.Telephone) == "":
+		err = fail("telephone", "required", "is required")
+	case w.Capacity <= 0:
+		err = fail("capacity", "gt", "must be positive")
+	}
+	return
+}
+
+// productCount returns the number of products of a warehouse
+func productCount(ctx context.Context, rw internal.WarehouseRepository, id int) (count int, err error) {
+	report, err := rw.ReportProducts(ctx, id)
+	if err != nil {
+		return
+	}
+	for _, r := range report {
+		count += r.ProductCount
+	}
+	return
+}
@@ -0,0 +1,140 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for WarehouseDefault.Create
+func TestWarehouseDefault_Create(t *testing.T) {
+	t.Run("success - warehouse created", func(t *testing.T) {
+		// arrange
+		sv := service.NewWarehouseDefault(repository.NewUnitOfWorkMemory(repository.NewMemory()))
+		w := internal.Warehouse{Name: "warehouse 1", Address: "address", Telephone: "telephone", Capacity: 10}
+
+		// act
+		err := sv.Create(context.Background(), &w)
+
+		// assert
+		require.NoError(t, err)
+		require.Positive(t, w.Id)
+	})
+
+	t.Run("failure - capacity not positive", func(t *testing.T) {
+		// arrange
+		sv := service.NewWarehouseDefault(repository.NewUnitOfWorkMemory(repository.NewMemory()))
+		w := internal.Warehouse{Name: "warehouse 1", Address: "address", Telephone: "telephone"}
+
+		// act
+		err := sv.Create(context.Background(), &w)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrWarehouseInvalid)
+		var fieldErr *internal.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		require.Equal(t, "capacity", fieldErr.Field)
+	})
+
+	t.Run("success - products moved into the warehouse", func(t *testing.T) {
+		// arrange
+		sp, rp, uow, w1 := newProductService(t, 10)
+		p1, p2 := newProduct("A1", w1.Id), newProduct("A2", w1.Id)
+		require.NoError(t, sp.Create(context.Background(), &p1))
+		require.NoError(t, sp.Create(context.Background(), &p2))
+		sv := service.NewWarehouseDefault(uow)
+		w2 := internal.Warehouse{Name: "warehouse 2", Address: "address", Telephone: "telephone", Capacity: 10}
+
+		// act
+		err := sv.Create(context.Background(), &w2, p1.ID, p2.ID)
+
+		// assert
+		require.NoError(t, err)
+		require.Positive(t, w2.Id)
+		for _, id := range []int{p1.ID, p2.ID} {
+			p, err := rp.GetOne(context.Background(), id)
+			require.NoError(t, err)
+			require.Equal(t, w2.Id, p.WarehouseId)
+		}
+	})
+
+	t.Run("failure - product to move not found rolls back the warehouse", func(t *testing.T) {
+		// arrange
+		sp, rp, uow, w1 := newProductService(t, 10)
+		p := newProduct("A1", w1.Id)
+		require.NoError(t, sp.Create(context.Background(), &p))
+		sv := service.NewWarehouseDefault(uow)
+		w2 := internal.Warehouse{Name: "warehouse 2", Address: "address", Telephone: "telephone", Capacity: 10}
+
+		// act
+		err := sv.Create(context.Background(), &w2, p.ID, 99)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+		require.Zero(t, w2.Id)
+		ws, err := sv.GetAll(context.Background())
+		require.NoError(t, err)
+		require.Len(t, ws, 1)
+		stored, err := rp.GetOne(context.Background(), p.ID)
+		require.NoError(t, err)
+		require.Equal(t, w1.Id, stored.WarehouseId)
+	})
+
+	t.Run("failure - more products to move than capacity", func(t *testing.T) {
+		// arrange
+		sp, _, uow, w1 := newProductService(t, 10)
+		p1, p2 := newProduct("A1", w1.Id), newProduct("A2", w1.Id)
+		require.NoError(t, sp.Create(context.Background(), &p1))
+		require.NoError(t, sp.Create(context.Background(), &p2))
+		sv := service.NewWarehouseDefault(uow)
+		w2 := internal.Warehouse{Name: "warehouse 2", Address: "address", Telephone: "telephone", Capacity: 1}
+
+		// act
+		err := sv.Create(context.Background(), &w2, p1.ID, p2.ID)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrWarehouseCapacityExceeded)
+		ws, err := sv.GetAll(context.Background())
+		require.NoError(t, err)
+		require.Len(t, ws, 1)
+	})
+}
+
+// Tests for WarehouseDefault.Update
+func TestWarehouseDefault_Update(t *testing.T) {
+	t.Run("failure - capacity below the products of the warehouse", func(t *testing.T) {
+		// arrange
+		sp, _, uow, w := newProductService(t, 10)
+		p1, p2 := newProduct("A1", w.Id), newProduct("A2", w.Id)
+		require.NoError(t, sp.Create(context.Background(), &p1))
+		require.NoError(t, sp.Create(context.Background(), &p2))
+		sv := service.NewWarehouseDefault(uow)
+		w.Capacity = 1
+
+		// act
+		err := sv.Update(context.Background(), &w)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrWarehouseCapacityExceeded)
+	})
+
+	t.Run("success - capacity down to the products of the warehouse", func(t *testing.T) {
+		// arrange
+		sp, _, uow, w := newProductService(t, 10)
+		p := newProduct("A1", w.Id)
+		require.NoError(t, sp.Create(context.Background(), &p))
+		sv := service.NewWarehouseDefault(uow)
+		w.Capacity = 1
+
+		// act
+		err := sv.Update(context.Background(), &w)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 2, w.Version)
+	})
+}
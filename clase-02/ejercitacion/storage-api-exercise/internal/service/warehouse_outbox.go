@@ -0,0 +1,70 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"time"
+)
+
+// NewWarehousesOutbox returns a new instance of WarehousesOutbox, uow must be the unit of work of sv so its changes join the same transaction
+func NewWarehousesOutbox(sv internal.WarehouseService, uow internal.UnitOfWork) *WarehousesOutbox {
+	return &WarehousesOutbox{
+		WarehouseService: sv,
+		uow:              uow,
+	}
+}
+
+// WarehousesOutbox is a struct that represents a warehouse service that writes the events of the changes it makes to the outbox,
+// in the transaction of the change
+type WarehousesOutbox struct {
+	internal.WarehouseService
+	// uow is the unit of work
+	uow internal.UnitOfWork
+}
+
+// append writes events to the outbox, in order
+func (s *WarehousesOutbox) append(ctx context.Context, r internal.Repositories, events ...internal.Event) (err error) {
+	for _, e := range events {
+		var m internal.OutboxMessage
+		if m, err = internal.NewOutboxMessage(e); err != nil {
+			return
+		}
+		if err = r.Outbox.Append(ctx, &m); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Create creates a warehouse and writes its event, followed by the update of each product moved into it
+func (s *WarehousesOutbox) Create(ctx context.Context, w *internal.Warehouse, productIds ...int) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		if err = s.WarehouseService.Create(ctx, w, productIds...); err != nil {
+			return
+		}
+		now := time.Now()
+		events := []internal.Event{{Type: internal.EventWarehouseCreated, Time: now, Data: *w}}
+		for _, id := range productIds {
+			var p internal.Product
+			if p, err = r.Products.GetOne(ctx, id); err != nil {
+				return
+			}
+			events = append(events, internal.Event{Type: internal.EventProductUpdated, Time: now, Data: p})
+		}
+		err = s.append(ctx, r, events...)
+		return
+	})
+	return
+}
+
+// Update updates a warehouse and writes its event
+func (s *WarehousesOutbox) Update(ctx context.Context, w *internal.Warehouse) (err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context, r internal.Repositories) (err error) {
+		if err = s.WarehouseService.Update(ctx, w); err != nil {
+			return
+		}
+		err = s.append(ctx, r, internal.Event{Type: internal.EventWarehouseUpdated, Time: time.Now(), Data: *w})
+		return
+	})
+	return
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"slices"
+)
+
+// secretSize is the number of random bytes of a generated webhook secret
+const secretSize = 32
+
+// NewWebhookDefault returns a new instance of WebhookDefault
+func NewWebhookDefault(rp internal.WebhookRepository) *WebhookDefault {
+	return &WebhookDefault{
+		rp: rp,
+	}
+}
+
+// WebhookDefault is a struct that represents the default webhook service
+type WebhookDefault struct {
+	// rp is the webhook repository
+	rp internal.WebhookRepository
+}
+
+// GetAll returns all webhooks
+func (s *WebhookDefault) GetAll(ctx context.Context) (ws []internal.Webhook, err error) {
+	ws, err = s.rp.GetAll(ctx)
+	return
+}
+
+// GetOne returns a webhook by id
+func (s *WebhookDefault) GetOne(ctx context.Context, id int) (w internal.Webhook, err error) {
+	w, err = s.rp.GetOne(ctx, id)
+	return
+}
+
+// Create validates and creates a webhook, with a random secret when it has none
+func (s *WebhookDefault) Create(ctx context.Context, w *internal.Webhook) (err error) {
+	if err = validateWebhook(*w); err != nil {
+		return
+	}
+	if w.Secret == "" {
+		b := make([]byte, secretSize)
+		if _, err = rand.Read(b); err != nil {
+			return
+		}
+		w.Secret = hex.EncodeToString(b)
+	}
+
+	err = s.rp.Store(ctx, w)
+	return
+}
+
+// Update validates and updates a webhook, keeping its secret when it has none
+func (s *WebhookDefault) Update(ctx context.Context, w *internal.Webhook) (err error) {
+	if err = validateWebhook(*w); err != nil {
+		return
+	}
+	if w.Secret == "" {
+		var current internal.Webhook
+		if current, err = s.rp.GetOne(ctx, w.ID); err != nil {
+			return
+		}
+		w.Secret = current.Secret
+	}
+
+	err = s.rp.Update(ctx, w)
+	return
+}
+
+// Delete deletes a webhook by id, with its dead letters
+func (s *WebhookDefault) Delete(ctx context.Context, id int) (err error) {
+	err = s.rp.Delete(ctx, id)
+	return
+}
+
+// Subscribed returns the webhooks that receive the events of a type
+func (s *WebhookDefault) Subscribed(ctx context.Context, t internal.EventType) (ws []internal.Webhook, err error) {
+	all, err := s.rp.GetAll(ctx)
+	if err != nil {
+		return
+	}
+	for _, w := range all {
+		if w.Subscribed(t) {
+			ws = append(ws, w)
+		}
+	}
+	return
+}
+
+// DeadLetters returns the dead letters of a webhook, the oldest first
+func (s *WebhookDefault) DeadLetters(ctx context.Context, webhookID int) (ds []internal.DeadLetter, err error) {
+	ds, err = s.rp.DeadLetters(ctx, webhookID)
+	return
+}
+
+// AddDeadLetter saves an event that could not be delivered to a webhook
+func (s *WebhookDefault) AddDeadLetter(ctx context.Context, d *internal.DeadLetter) (err error) {
+	err = s.rp.StoreDeadLetter(ctx, d)
+	return
+}
+
+// TakeDeadLetter deletes a dead letter of a webhook and returns it, to deliver its event again
+func (s *WebhookDefault) TakeDeadLetter(ctx context.Context, webhookID int, id int) (d internal.DeadLetter, err error) {
+	d, err = s.rp.DeleteDeadLetter(ctx, webhookID, id)
+	return
+}
+
+// validateWebhook returns a *internal.FieldError for the first field of a webhook that breaks a rule
+func validateWebhook(w internal.Webhook) (err error) {
+	fail := func(field, code, message string) error {
+		return &internal.FieldError{Field: field, Code: code, Message: message, Err: internal.ErrWebhookInvalid}
+	}
+
+	u, parseErr := url.Parse(w.URL)
+	switch {
+	case w.URL == "":
+		err = fail("url", "required", "is required")
+	case parseErr != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+		err = fail("url", "url", "must be an http or https url")
+	}
+	if err != nil {
+		return
+	}
+	for _, t := range w.Events {
+		if !slices.Contains(internal.EventTypes, t) {
+			err = fail("events", "oneof", "must be known event types")
+			return
+		}
+	}
+	return
+}
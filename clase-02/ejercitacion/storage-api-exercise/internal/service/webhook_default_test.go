@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for WebhookDefault.Create
+func TestWebhookDefault_Create(t *testing.T) {
+	t.Run("success - webhook created with a secret", func(t *testing.T) {
+		// arrange
+		sv := service.NewWebhookDefault(repository.NewWebhookMemory())
+		w := internal.Webhook{URL: "http://localhost:9000/hook", Events: []internal.EventType{internal.EventProductCreated}}
+
+		// act
+		err := sv.Create(context.Background(), &w)
+
+		// assert
+		require.NoError(t, err)
+		require.Positive(t, w.ID)
+		require.Len(t, w.Secret, 64)
+	})
+
+	t.Run("failure - url not http", func(t *testing.T) {
+		// arrange
+		sv := service.NewWebhookDefault(repository.NewWebhookMemory())
+		w := internal.Webhook{URL: "ftp://localhost/hook"}
+
+		// act
+		err := sv.Create(context.Background(), &w)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrWebhookInvalid)
+		var fieldErr *internal.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		require.Equal(t, "url", fieldErr.Field)
+	})
+
+	t.Run("failure - unknown event type", func(t *testing.T) {
+		// arrange
+		sv := service.NewWebhookDefault(repository.NewWebhookMemory())
+		w := internal.Webhook{URL: "https://example.com/hook", Events: []internal.EventType{"product.sold"}}
+
+		// act
+		err := sv.Create(context.Background(), &w)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrWebhookInvalid)
+		var fieldErr *internal.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		require.Equal(t, "events", fieldErr.Field)
+	})
+}
+
+// Tests for WebhookDefault.Subscribed
+func TestWebhookDefault_Subscribed(t *testing.T) {
+	t.Run("success - webhooks of the type and of every type", func(t *testing.T) {
+		// arrange
+		sv := service.NewWebhookDefault(repository.NewWebhookMemory())
+		all := internal.Webhook{URL: "http://localhost/all"}
+		created := internal.Webhook{URL: "http://localhost/created", Events: []internal.EventType{internal.EventProductCreated}}
+		deleted := internal.Webhook{URL: "http://localhost/deleted", Events: []internal.EventType{internal.EventProductDeleted}}
+		for _, w := range []*internal.Webhook{&all, &created, &deleted} {
+			require.NoError(t, sv.Create(context.Background(), w))
+		}
+
+		// act
+		ws, err := sv.Subscribed(context.Background(), internal.EventProductCreated)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []internal.Webhook{all, created}, ws)
+	})
+}
+
+// Tests for WebhookDefault.TakeDeadLetter
+func TestWebhookDefault_TakeDeadLetter(t *testing.T) {
+	t.Run("success - dead letter taken once", func(t *testing.T) {
+		// arrange
+		sv := service.NewWebhookDefault(repository.NewWebhookMemory())
+		w := internal.Webhook{URL: "http://localhost/hook"}
+		require.NoError(t, sv.Create(context.Background(), &w))
+		d := internal.DeadLetter{WebhookID: w.ID, Event: internal.Event{ID: 1, Type: internal.EventProductCreated}, Attempts: 3}
+		require.NoError(t, sv.AddDeadLetter(context.Background(), &d))
+
+		// act
+		taken, err := sv.TakeDeadLetter(context.Background(), w.ID, d.ID)
+		_, errAgain := sv.TakeDeadLetter(context.Background(), w.ID, d.ID)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, d, taken)
+		require.ErrorIs(t, errAgain, internal.ErrDeadLetterNotFound)
+		ds, err := sv.DeadLetters(context.Background(), w.ID)
+		require.NoError(t, err)
+		require.Empty(t, ds)
+	})
+}
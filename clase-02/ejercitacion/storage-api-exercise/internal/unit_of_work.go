@@ -0,0 +1,21 @@
+package internal
+
+import "context"
+
+// Repositories is a struct that groups the repositories of a unit of work
+type Repositories struct {
+	// Products is the product repository
+	Products RepositoryProducts
+	// Warehouses is the warehouse repository
+	Warehouses WarehouseRepository
+	// Outbox is the outbox of the events of the changes
+	Outbox OutboxRepository
+}
+
+// UnitOfWork is an interface that represents a unit of work: repository calls that commit or roll back together
+type UnitOfWork interface {
+	// Do runs fn with repositories bound to a transaction, begun with ctx, that commits when fn returns nil
+	// and rolls back when it returns an error, panics or ctx is done.
+	// A Do called with the context fn receives joins the same transaction, which the outermost Do ends
+	Do(ctx context.Context, fn func(ctx context.Context, r Repositories) error) (err error)
+}
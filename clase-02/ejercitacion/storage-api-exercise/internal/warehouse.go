@@ -12,6 +12,15 @@ type Warehouse struct {
 	Telephone string
 	// Capacity is the capacity of the warehouse
 	Capacity int
+	// Version is the optimistic concurrency version of the warehouse
+	Version int
+}
+
+// WarehouseProducts is a struct that represents a warehouse with its products
+type WarehouseProducts struct {
+	Warehouse
+	// Products are the products of the warehouse sorted by id, none when it is empty
+	Products []Product
 }
 
 type ReportProduct struct {
@@ -1,21 +1,37 @@
 package internal
 
 import (
+	"context"
 	"errors"
 )
 
 var (
 	ErrWarehouseNotFound      = errors.New("repository: warehouse not found")
 	ErrWarehouseAlreadyExists = errors.New("repository: warehouse already exists")
+	// ErrWarehouseVersionConflict is an error that will be returned when a warehouse was modified by someone else
+	ErrWarehouseVersionConflict = errors.New("repository: warehouse version conflict")
 )
 
+// WarehouseRepository is an interface that represents a warehouse repository.
+// Its methods stop and return the error of ctx when it is canceled or its deadline passes
 type WarehouseRepository interface {
 	// GetAll returns all warehouses
-	GetAll() (w []Warehouse, err error)
+	GetAll(ctx context.Context) (w []Warehouse, err error)
 	// GetOne returns a warehouse by id
-	GetOne(id int) (w Warehouse, err error)
+	GetOne(ctx context.Context, id int) (w Warehouse, err error)
+	// GetOneForUpdate returns a warehouse by id, locking it until the unit of work ends,
+	// so the writes that check its capacity against its products run one at a time
+	GetOneForUpdate(ctx context.Context, id int) (w Warehouse, err error)
+	// GetByIds returns the warehouses of ids sorted by id, read together, without the ones that do not exist
+	GetByIds(ctx context.Context, ids []int) (w []Warehouse, err error)
+	// GetAllWithProducts returns all warehouses sorted by id with their products, read together
+	GetAllWithProducts(ctx context.Context) (w []WarehouseProducts, err error)
+	// GetOneWithProducts returns a warehouse by id with its products, read together
+	GetOneWithProducts(ctx context.Context, id int) (w WarehouseProducts, err error)
 	// Store saves a warehouse
-	Store(w *Warehouse) (err error)
+	Store(ctx context.Context, w *Warehouse) (err error)
+	// Update updates a warehouse if its version matches the stored one, incrementing it
+	Update(ctx context.Context, w *Warehouse) (err error)
 	// ReportProducts returns a report of products by warehouse
-	ReportProducts(id int) (rp []ReportProduct, err error)
+	ReportProducts(ctx context.Context, id int) (rp []ReportProduct, err error)
 }
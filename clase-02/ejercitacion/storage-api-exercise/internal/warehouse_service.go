@@ -0,0 +1,31 @@
+package internal
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrWarehouseInvalid is an error that will be returned when a warehouse breaks a validation rule, as a *FieldError
+	ErrWarehouseInvalid = errors.New("service: warehouse invalid")
+)
+
+// WarehouseService is an interface that represents the business rules of warehouses.
+// Writes validate the warehouse and keep its capacity above the number of its products
+type WarehouseService interface {
+	// GetAll returns all warehouses
+	GetAll(ctx context.Context) (w []Warehouse, err error)
+	// GetOne returns a warehouse by id
+	GetOne(ctx context.Context, id int) (w Warehouse, err error)
+	// GetAllWithProducts returns all warehouses with their products
+	GetAllWithProducts(ctx context.Context) (w []WarehouseProducts, err error)
+	// GetOneWithProducts returns a warehouse by id with its products
+	GetOneWithProducts(ctx context.Context, id int) (w WarehouseProducts, err error)
+	// Create creates a warehouse and moves the products with the given ids into it, all or nothing.
+	// It returns ErrProductNotFound if one of the products does not exist
+	Create(ctx context.Context, w *Warehouse, productIds ...int) (err error)
+	// Update updates a warehouse if its version matches the stored one
+	Update(ctx context.Context, w *Warehouse) (err error)
+	// ReportProducts returns a report of products by warehouse
+	ReportProducts(ctx context.Context, id int) (rp []ReportProduct, err error)
+}
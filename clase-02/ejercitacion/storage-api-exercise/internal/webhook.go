@@ -0,0 +1,46 @@
+package internal
+
+import "time"
+
+// Webhook is a struct that represents a subscription of an url to the events of the bus
+type Webhook struct {
+	// ID is the unique identifier of the webhook
+	ID int
+	// URL is where the events are posted
+	URL string
+	// Events are the types of the events posted, every type when it is empty
+	Events []EventType
+	// Secret is the key of the signature of the payloads, so the receiver can check they come from the api
+	Secret string
+	// Version is the optimistic concurrency version of the webhook
+	Version int
+}
+
+// Subscribed returns whether the webhook receives the events of a type
+func (w Webhook) Subscribed(t EventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// DeadLetter is a struct that represents an event that could not be delivered to a webhook once its attempts ran out
+type DeadLetter struct {
+	// ID is the unique identifier of the dead letter
+	ID int
+	// WebhookID is the id of the webhook
+	WebhookID int
+	// Event is the event not delivered
+	Event Event
+	// Attempts is the number of deliveries attempted
+	Attempts int
+	// Error is the error of the last attempt
+	Error string
+	// FailedAt is when the last attempt failed
+	FailedAt time.Time
+}
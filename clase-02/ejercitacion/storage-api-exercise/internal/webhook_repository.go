@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrWebhookNotFound is an error that will be returned when a webhook does not exist
+	ErrWebhookNotFound = errors.New("repository: webhook not found")
+	// ErrWebhookVersionConflict is an error that will be returned when a webhook was modified by someone else
+	ErrWebhookVersionConflict = errors.New("repository: webhook version conflict")
+	// ErrDeadLetterNotFound is an error that will be returned when a dead letter does not exist
+	ErrDeadLetterNotFound = errors.New("repository: dead letter not found")
+)
+
+// WebhookRepository is an interface that represents a repository of webhooks and their dead letters.
+// Its methods stop and return the error of ctx when it is canceled or its deadline passes
+type WebhookRepository interface {
+	// GetAll returns all webhooks
+	GetAll(ctx context.Context) (ws []Webhook, err error)
+	// GetOne returns a webhook by id
+	GetOne(ctx context.Context, id int) (w Webhook, err error)
+	// Store saves a webhook
+	Store(ctx context.Context, w *Webhook) (err error)
+	// Update updates a webhook if its version matches the stored one, incrementing it
+	Update(ctx context.Context, w *Webhook) (err error)
+	// Delete deletes a webhook by id, with its dead letters
+	Delete(ctx context.Context, id int) (err error)
+
+	// DeadLetters returns the dead letters of a webhook, the oldest first
+	DeadLetters(ctx context.Context, webhookID int) (ds []DeadLetter, err error)
+	// StoreDeadLetter saves a dead letter
+	StoreDeadLetter(ctx context.Context, d *DeadLetter) (err error)
+	// DeleteDeadLetter deletes a dead letter of a webhook by id, returning it
+	DeleteDeadLetter(ctx context.Context, webhookID int, id int) (d DeadLetter, err error)
+}
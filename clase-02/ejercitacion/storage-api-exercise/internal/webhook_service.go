@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrWebhookInvalid is an error that will be returned when a webhook breaks a validation rule, as a *FieldError
+	ErrWebhookInvalid = errors.New("service: webhook invalid")
+)
+
+// WebhookService is an interface that represents the business rules of webhooks.
+// Writes validate the webhook: an http or https url and known event types
+type WebhookService interface {
+	// GetAll returns all webhooks
+	GetAll(ctx context.Context) (ws []Webhook, err error)
+	// GetOne returns a webhook by id
+	GetOne(ctx context.Context, id int) (w Webhook, err error)
+	// Create creates a webhook, with a random secret when it has none
+	Create(ctx context.Context, w *Webhook) (err error)
+	// Update updates a webhook if its version matches the stored one
+	Update(ctx context.Context, w *Webhook) (err error)
+	// Delete deletes a webhook by id, with its dead letters
+	Delete(ctx context.Context, id int) (err error)
+	// Subscribed returns the webhooks that receive the events of a type
+	Subscribed(ctx context.Context, t EventType) (ws []Webhook, err error)
+
+	// DeadLetters returns the dead letters of a webhook, the oldest first
+	DeadLetters(ctx context.Context, webhookID int) (ds []DeadLetter, err error)
+	// AddDeadLetter saves an event that could not be delivered to a webhook
+	AddDeadLetter(ctx context.Context, d *DeadLetter) (err error)
+	// TakeDeadLetter deletes a dead letter of a webhook and returns it, to deliver its event again
+	TakeDeadLetter(ctx context.Context, webhookID int, id int) (d DeadLetter, err error)
+}
@@ -0,0 +1,69 @@
+// Package broadcast sends the values published in a process to every current subscriber.
+// It is an in-memory feed: a subscriber only sees the values published while it is subscribed
+package broadcast
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultBuffer is the number of values a subscriber can fall behind before it is dropped
+const DefaultBuffer = 64
+
+// New returns a new broadcaster of values of type T, its subscribers can fall behind buffer values
+func New[T any](buffer int) *Broadcaster[T] {
+	if buffer < 0 {
+		buffer = 0
+	}
+	return &Broadcaster[T]{
+		buffer: buffer,
+		subs:   make(map[chan T]struct{}),
+	}
+}
+
+// Broadcaster is a struct that sends the values it is published to its subscribers
+type Broadcaster[T any] struct {
+	// buffer is the size of the channel of each subscriber
+	buffer int
+	// mu guards subs
+	mu sync.Mutex
+	// subs are the channels of the subscribers
+	subs map[chan T]struct{}
+}
+
+// Publish sends a value to the current subscribers without blocking.
+// A subscriber whose channel is full is dropped: its channel is closed, so it knows it missed values
+func (b *Broadcaster[T]) Publish(v T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- v:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns the values published from now on, until ctx is done or the subscriber falls behind,
+// when the channel is closed
+func (b *Broadcaster[T]) Subscribe(ctx context.Context) <-chan T {
+	ch := make(chan T, b.buffer)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,84 @@
+package broadcast_test
+
+import (
+	"app/platform/broadcast"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Broadcaster
+func TestBroadcaster(t *testing.T) {
+	t.Run("case 1: every subscriber receives the published values", func(t *testing.T) {
+		// arrange
+		b := broadcast.New[int](broadcast.DefaultBuffer)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch1, ch2 := b.Subscribe(ctx), b.Subscribe(ctx)
+
+		// act
+		b.Publish(1)
+		b.Publish(2)
+
+		// assert
+		require.Equal(t, 1, <-ch1)
+		require.Equal(t, 2, <-ch1)
+		require.Equal(t, 1, <-ch2)
+		require.Equal(t, 2, <-ch2)
+	})
+
+	t.Run("case 2: the values published before subscribing are not received", func(t *testing.T) {
+		// arrange
+		b := broadcast.New[int](broadcast.DefaultBuffer)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// act
+		b.Publish(1)
+		ch := b.Subscribe(ctx)
+		b.Publish(2)
+
+		// assert
+		require.Equal(t, 2, <-ch)
+	})
+
+	t.Run("case 3: the channel is closed when the context is done", func(t *testing.T) {
+		// arrange
+		b := broadcast.New[int](broadcast.DefaultBuffer)
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := b.Subscribe(ctx)
+
+		// act
+		cancel()
+
+		// assert
+		select {
+		case _, ok := <-ch:
+			require.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("channel not closed")
+		}
+		b.Publish(1)
+	})
+
+	t.Run("case 4: a subscriber that falls behind is dropped", func(t *testing.T) {
+		// arrange
+		b := broadcast.New[int](1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := b.Subscribe(ctx)
+
+		// act
+		b.Publish(1)
+		b.Publish(2)
+
+		// assert
+		v, ok := <-ch
+		require.True(t, ok)
+		require.Equal(t, 1, v)
+		_, ok = <-ch
+		require.False(t, ok)
+	})
+}
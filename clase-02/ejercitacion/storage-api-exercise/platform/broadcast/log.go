@@ -0,0 +1,142 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultHistory is the number of values a Log keeps for its subscribers to resume from
+const DefaultHistory = 1024
+
+// ErrExpired is used when a subscriber resumes from a value the Log no longer keeps, or from one it never published
+// (e.g. one of a former process)
+var ErrExpired = errors.New("broadcast: resume point expired")
+
+// Numbered is the constraint of the values of a Log: Number returns the number of a value, zero when it has none,
+// and WithID returns the value with the number the Log gives it
+type Numbered[T any] interface {
+	Number() int64
+	WithID(id int64) T
+}
+
+// NewLog returns a new log of values of type T, its subscribers can fall behind buffer values
+// and resume from any of the last history values
+func NewLog[T Numbered[T]](buffer, history int) *Log[T] {
+	if history < 0 {
+		history = 0
+	}
+	return &Log[T]{
+		b:       New[T](buffer),
+		buffer:  buffer,
+		history: history,
+	}
+}
+
+// Log is a struct that sends the values it is published to its subscribers. A value keeps the number given by its source
+// (e.g. the id of its outbox message), one without it is numbered after the highest one published, from 1.
+// It keeps the last ones, so a subscriber that reconnects receives the values it missed in order of publication,
+// and a value published again while it is kept is not sent again
+type Log[T Numbered[T]] struct {
+	// b sends the values to the subscribers
+	b *Broadcaster[T]
+	// buffer is the size of the channel of each subscriber
+	buffer int
+	// history is the number of values kept
+	history int
+	// mu guards the numbers and kept, and makes a subscription and a publication happen one after the other
+	mu sync.Mutex
+	// last is the number of the last value published
+	last int64
+	// max is the highest number published
+	max int64
+	// dropped is the number of the last value no longer kept, the one published before the oldest kept
+	dropped int64
+	// kept are the last values published, the oldest first
+	kept []entry[T]
+}
+
+// entry is a value kept by a Log with its number
+type entry[T any] struct {
+	id int64
+	v  T
+}
+
+// Publish sends a value to the current subscribers without blocking, as Broadcaster.Publish does, numbering it when it has no number.
+// A value with the number of a kept one is not sent again. It returns the value with its number
+func (l *Log[T]) Publish(v T) T {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// number
+	id := v.Number()
+	if id == 0 {
+		id = l.max + 1
+		v = v.WithID(id)
+	}
+	if l.index(id) >= 0 {
+		return v
+	}
+	l.last, l.max = id, max(l.max, id)
+
+	// keep and send
+	if l.history > 0 {
+		if len(l.kept) == l.history {
+			l.dropped = l.kept[0].id
+			l.kept = append(l.kept[:0], l.kept[1:]...)
+		}
+		l.kept = append(l.kept, entry[T]{id: id, v: v})
+	}
+	l.b.Publish(v)
+	return v
+}
+
+// index returns the position of the kept value numbered id, -1 when it is not kept
+func (l *Log[T]) index(id int64) int {
+	for i := len(l.kept) - 1; i >= 0; i-- {
+		if l.kept[i].id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Subscribe returns the values published after the one numbered after, the ones kept first, and the ones published from now on,
+// until ctx is done or the subscriber falls behind, when the channel is closed. An after of zero receives only the values from now on.
+// It returns ErrExpired when the value numbered after is no longer kept
+func (l *Log[T]) Subscribe(ctx context.Context, after int64) (values <-chan T, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// missed values: the ones published after it, whatever their numbers
+	var missed []T
+	if after > 0 && after != l.last {
+		i := l.index(after)
+		if i < 0 && after != l.dropped {
+			err = ErrExpired
+			return
+		}
+		for _, e := range l.kept[i+1:] {
+			missed = append(missed, e.v)
+		}
+	}
+
+	// live values: subscribed before a publication can happen, so none is lost or repeated
+	live := l.b.Subscribe(ctx)
+	ch := make(chan T, len(missed)+l.buffer)
+	for _, v := range missed {
+		ch <- v
+	}
+	go func() {
+		defer close(ch)
+		for v := range live {
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	values = ch
+	return
+}
@@ -0,0 +1,124 @@
+package broadcast_test
+
+import (
+	"app/platform/broadcast"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// value is a value of the tests of Log
+type value struct {
+	id   int64
+	name string
+}
+
+// Number returns the number of the value
+func (v value) Number() int64 {
+	return v.id
+}
+
+// WithID returns the value with its number
+func (v value) WithID(id int64) value {
+	v.id = id
+	return v
+}
+
+// Tests for Log
+func TestLog(t *testing.T) {
+	t.Run("case 1: the values are numbered in order of publication", func(t *testing.T) {
+		// arrange
+		l := broadcast.NewLog[value](broadcast.DefaultBuffer, broadcast.DefaultHistory)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := l.Subscribe(ctx, 0)
+		require.NoError(t, err)
+
+		// act
+		a := l.Publish(value{name: "a"})
+		b := l.Publish(value{name: "b"})
+
+		// assert
+		require.Equal(t, value{id: 1, name: "a"}, a)
+		require.Equal(t, value{id: 2, name: "b"}, b)
+		require.Equal(t, a, <-ch)
+		require.Equal(t, b, <-ch)
+	})
+
+	t.Run("case 2: a subscriber resumes after the last value it received", func(t *testing.T) {
+		// arrange
+		l := broadcast.NewLog[value](broadcast.DefaultBuffer, broadcast.DefaultHistory)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		l.Publish(value{name: "a"})
+		l.Publish(value{name: "b"})
+		l.Publish(value{name: "c"})
+
+		// act
+		ch, err := l.Subscribe(ctx, 1)
+		require.NoError(t, err)
+		l.Publish(value{name: "d"})
+
+		// assert
+		require.Equal(t, "b", (<-ch).name)
+		require.Equal(t, "c", (<-ch).name)
+		require.Equal(t, "d", (<-ch).name)
+	})
+
+	t.Run("case 3: a subscriber can not resume from a value no longer kept", func(t *testing.T) {
+		// arrange
+		l := broadcast.NewLog[value](broadcast.DefaultBuffer, 2)
+		for _, name := range []string{"a", "b", "c", "d"} {
+			l.Publish(value{name: name})
+		}
+
+		// act
+		_, errOld := l.Subscribe(context.Background(), 1)
+		_, errFuture := l.Subscribe(context.Background(), 5)
+		ch, errKept := l.Subscribe(context.Background(), 2)
+
+		// assert
+		require.ErrorIs(t, errOld, broadcast.ErrExpired)
+		require.ErrorIs(t, errFuture, broadcast.ErrExpired)
+		require.NoError(t, errKept)
+		require.Equal(t, "c", (<-ch).name)
+		require.Equal(t, "d", (<-ch).name)
+	})
+
+	t.Run("case 4: the values numbered by their source keep their number and are resumed in order of publication", func(t *testing.T) {
+		// arrange
+		l := broadcast.NewLog[value](broadcast.DefaultBuffer, broadcast.DefaultHistory)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		l.Publish(value{id: 5, name: "a"})
+		l.Publish(value{id: 3, name: "b"})
+
+		// act
+		ch, err := l.Subscribe(ctx, 5)
+		require.NoError(t, err)
+		again := l.Publish(value{id: 3, name: "b"})
+		next := l.Publish(value{name: "c"})
+
+		// assert
+		require.Equal(t, value{id: 3, name: "b"}, again)
+		require.Equal(t, value{id: 6, name: "c"}, next)
+		require.Equal(t, value{id: 3, name: "b"}, <-ch)
+		require.Equal(t, value{id: 6, name: "c"}, <-ch)
+	})
+
+	t.Run("case 5: the channel is closed when the context is done", func(t *testing.T) {
+		// arrange
+		l := broadcast.NewLog[value](broadcast.DefaultBuffer, broadcast.DefaultHistory)
+		ctx, cancel := context.WithCancel(context.Background())
+		ch, err := l.Subscribe(ctx, 0)
+		require.NoError(t, err)
+
+		// act
+		cancel()
+
+		// assert
+		_, ok := <-ch
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,100 @@
+// Package i18n translates the messages of the api, written in English, to the language negotiated with the client.
+// The messages in English are the keys of the catalog of each language, a message without translation is kept in English
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// English is the language the messages are written in
+	English = "en"
+	// Spanish is the language of most of the clients
+	Spanish = "es"
+	// Default is the language used when the client accepts none of the supported ones
+	Default = English
+)
+
+var (
+	// mu guards catalogs
+	mu sync.RWMutex
+	// catalogs are the translations of the messages by language, keyed by the message in English
+	catalogs = map[string]map[string]string{
+		English: {},
+		Spanish: messagesES,
+	}
+)
+
+// Register adds the translations of messages to a language, replacing the ones already registered
+func Register(lang string, messages map[string]string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	c, ok := catalogs[lang]
+	if !ok {
+		c = make(map[string]string, len(messages))
+		catalogs[lang] = c
+	}
+	for k, v := range messages {
+		c[k] = v
+	}
+}
+
+// Translate returns a message translated to a language and formatted with args as fmt.Sprintf does
+func Translate(lang, message string, args ...any) string {
+	mu.RLock()
+	if t, ok := catalogs[lang][message]; ok {
+		message = t
+	}
+	mu.RUnlock()
+
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Negotiate returns the supported language that best matches an Accept-Language header (e.g. es-AR,es;q=0.9,en;q=0.8),
+// the default one when none does
+func Negotiate(header string) string {
+	// - languages by preference
+	type accepted struct {
+		lang string
+		q    float64
+	}
+	var langs []accepted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if tag == "" || q <= 0 {
+			continue
+		}
+		// - the primary subtag (es for es-AR)
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		langs = append(langs, accepted{lang: primary, q: q})
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	// - first supported
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, a := range langs {
+		if _, ok := catalogs[a.lang]; ok {
+			return a.lang
+		}
+		if a.lang == "*" {
+			break
+		}
+	}
+	return Default
+}
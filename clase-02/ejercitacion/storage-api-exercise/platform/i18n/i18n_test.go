@@ -0,0 +1,80 @@
+package i18n_test
+
+import (
+	"app/platform/i18n"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Translate
+func TestTranslate(t *testing.T) {
+	t.Run("case 1: message translated and formatted", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		message := i18n.Translate(i18n.Spanish, "%[1]s is required", "name")
+
+		// assert
+		require.Equal(t, "name es obligatorio", message)
+	})
+
+	t.Run("case 2: message without translation kept in English", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		message := i18n.Translate(i18n.Spanish, "message without translation")
+
+		// assert
+		require.Equal(t, "message without translation", message)
+	})
+
+	t.Run("case 3: registered message", func(t *testing.T) {
+		// arrange
+		i18n.Register(i18n.Spanish, map[string]string{"product created": "producto creado"})
+
+		// act
+		message := i18n.Translate(i18n.Spanish, "product created")
+
+		// assert
+		require.Equal(t, "producto creado", message)
+	})
+}
+
+// Tests for Negotiate
+func TestNegotiate(t *testing.T) {
+	t.Run("case 1: regional language matched by its primary subtag", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		lang := i18n.Negotiate("es-AR,es;q=0.9,en;q=0.8")
+
+		// assert
+		require.Equal(t, i18n.Spanish, lang)
+	})
+
+	t.Run("case 2: preferred language by quality", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		lang := i18n.Negotiate("es;q=0.5, en")
+
+		// assert
+		require.Equal(t, i18n.English, lang)
+	})
+
+	t.Run("case 3: unsupported languages fall back to English", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		lang := i18n.Negotiate("fr-FR, de;q=0.9")
+
+		// assert
+		require.Equal(t, i18n.English, lang)
+	})
+}
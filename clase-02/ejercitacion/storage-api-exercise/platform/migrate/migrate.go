@@ -0,0 +1,410 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMigrationInvalid is returned when a migration file is malformed or duplicated.
+	ErrMigrationInvalid = errors.New("migrate: invalid migration")
+	// ErrMigrationChecksum is returned when an applied migration file was modified afterwards.
+	ErrMigrationChecksum = errors.New("migrate: applied migration checksum mismatch")
+	// ErrMigrationUnknown is returned when an applied migration has no file.
+	ErrMigrationUnknown = errors.New("migrate: applied migration unknown")
+	// ErrMigrationIrreversible is returned when rolling back a migration without down file.
+	ErrMigrationIrreversible = errors.New("migrate: migration has no down file")
+	// ErrSchemaBehind is returned when there are pending migrations.
+	ErrSchemaBehind = errors.New("migrate: schema is behind")
+)
+
+// Migration is a versioned schema change
+type Migration struct {
+	// Version is the number that orders the migration
+	Version int
+	// Name is the description of the migration
+	Name string
+	// Up is the sql that applies the migration
+	Up string
+	// Down is the sql that rolls back the migration
+	Down string
+	// Checksum is the sha256 of the up sql
+	Checksum string
+}
+
+// fileName matches the name of a migration file
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load reads the migrations of fsys sorted by version.
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql, other files are ignored.
+func Load(fsys fs.FS) (ms []Migration, err error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		match := fileName.FindStringSubmatch(e.Name())
+		if e.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		var content []byte
+		content, err = fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			err = fmt.Errorf("%w: version %d has two names", ErrMigrationInvalid, version)
+			return
+		}
+		switch match[3] {
+		case "up":
+			m.Up = string(content)
+			m.Checksum = fmt.Sprintf("%x", sha256.Sum256(content))
+		case "down":
+			m.Down = string(content)
+		}
+	}
+
+	for _, m := range byVersion {
+		if m.Up == "" {
+			err = fmt.Errorf("%w: version %d has no up file", ErrMigrationInvalid, m.Version)
+			return
+		}
+		ms = append(ms, *m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+	return
+}
+
+// Statements splits the sql of a migration into its statements.
+// Statements end with a semicolon at the end of a line, comment lines are dropped.
+func Statements(script string) (stmts []string) {
+	var b strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(b.String()))
+			b.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(b.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return
+}
+
+// Placeholder is the style of the bind parameters of a database
+type Placeholder int
+
+const (
+	// PlaceholderQuestion is the ? style of mysql and sqlite
+	PlaceholderQuestion Placeholder = iota
+	// PlaceholderDollar is the $1 style of postgres
+	PlaceholderDollar
+)
+
+// Rebind rewrites the ? bind parameters of query into the style p
+func Rebind(p Placeholder, query string) string {
+	if p != PlaceholderDollar {
+		return query
+	}
+
+	var b strings.Builder
+	var n int
+	for _, r := range query {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+		n++
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+	return b.String()
+}
+
+// NewMigrator returns a new migrator of db, whose bind parameters are ?
+func NewMigrator(db *sql.DB, ms []Migration) *Migrator {
+	return NewMigratorPlaceholder(db, ms, PlaceholderQuestion)
+}
+
+// NewMigratorPlaceholder returns a new migrator of db, whose bind parameters have the style p
+func NewMigratorPlaceholder(db *sql.DB, ms []Migration, p Placeholder) *Migrator {
+	return &Migrator{
+		db: db,
+		ms: ms,
+		p:  p,
+	}
+}
+
+// NewMigratorTransactional returns a new migrator of db, whose bind parameters have the style p,
+// that applies or rolls back each migration and its schema_migrations row in a single transaction.
+// It is meant for databases whose DDL is transactional (postgres, sqlite): a failed migration leaves no change behind
+func NewMigratorTransactional(db *sql.DB, ms []Migration, p Placeholder) *Migrator {
+	return &Migrator{
+		db: db,
+		ms: ms,
+		p:  p,
+		tx: true,
+	}
+}
+
+// Migrator applies and rolls back migrations, keeping track of them in the schema_migrations table
+type Migrator struct {
+	// db is the database connection
+	db *sql.DB
+	// ms are the known migrations sorted by version
+	ms []Migration
+	// p is the style of the bind parameters
+	p Placeholder
+	// tx tells if each migration runs in a transaction
+	tx bool
+}
+
+// execer is the subset of *sql.DB and *sql.Tx used to run a migration
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// run runs the statements of the sql of a migration followed by the query that records it,
+// in a single transaction when the migrator is transactional
+func (m *Migrator) run(mg Migration, script string, record string, args ...any) (err error) {
+	var ex execer = m.db
+	var tx *sql.Tx
+	if m.tx {
+		if tx, err = m.db.Begin(); err != nil {
+			return
+		}
+		defer tx.Rollback()
+		ex = tx
+	}
+
+	for _, stmt := range Statements(script) {
+		if _, err = ex.Exec(stmt); err != nil {
+			err = fmt.Errorf("migration %d (%s): %w", mg.Version, mg.Name, err)
+			return
+		}
+	}
+	if _, err = ex.Exec(Rebind(m.p, record), args...); err != nil {
+		return
+	}
+
+	if tx != nil {
+		err = tx.Commit()
+	}
+	return
+}
+
+// Status is the state of a migration
+type Status struct {
+	Migration
+	// Applied tells if the migration was applied
+	Applied bool
+	// AppliedAt is the time the migration was applied
+	AppliedAt time.Time
+	// Modified tells if the migration file changed after being applied
+	Modified bool
+}
+
+// applied is a row of the schema_migrations table
+type applied struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// init creates the schema_migrations table
+func (m *Migrator) init() (err error) {
+	_, err = m.db.Exec(
+		"CREATE TABLE IF NOT EXISTS schema_migrations (" +
+			"version BIGINT NOT NULL PRIMARY KEY, " +
+			"name VARCHAR(255) NOT NULL, " +
+			"checksum CHAR(64) NOT NULL, " +
+			"applied_at TIMESTAMP NOT NULL)",
+	)
+	return
+}
+
+// applied returns the applied migrations by version
+func (m *Migrator) applied() (as map[int]applied, err error) {
+	if err = m.init(); err != nil {
+		return
+	}
+
+	rows, err := m.db.Query("SELECT version, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	as = make(map[int]applied)
+	for rows.Next() {
+		var version int
+		var a applied
+		if err = rows.Scan(&version, &a.checksum, &a.appliedAt); err != nil {
+			return
+		}
+		as[version] = a
+	}
+	err = rows.Err()
+	return
+}
+
+// Status returns the state of every known migration
+func (m *Migrator) Status() (st []Status, err error) {
+	as, err := m.applied()
+	if err != nil {
+		return
+	}
+
+	for _, mg := range m.ms {
+		s := Status{Migration: mg}
+		if a, ok := as[mg.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = a.appliedAt
+			s.Modified = a.checksum != mg.Checksum
+		}
+		st = append(st, s)
+	}
+	return
+}
+
+// Verify checks that every applied migration is known and was not modified afterwards
+func (m *Migrator) Verify() (err error) {
+	as, err := m.applied()
+	if err != nil {
+		return
+	}
+
+	known := make(map[int]Migration)
+	for _, mg := range m.ms {
+		known[mg.Version] = mg
+	}
+	versions := make([]int, 0, len(as))
+	for v := range as {
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	for _, v := range versions {
+		mg, ok := known[v]
+		if !ok {
+			err = fmt.Errorf("%w: version %d", ErrMigrationUnknown, v)
+			return
+		}
+		if as[v].checksum != mg.Checksum {
+			err = fmt.Errorf("%w: version %d (%s)", ErrMigrationChecksum, v, mg.Name)
+			return
+		}
+	}
+	return
+}
+
+// Pending returns the migrations not applied yet
+func (m *Migrator) Pending() (ms []Migration, err error) {
+	as, err := m.applied()
+	if err != nil {
+		return
+	}
+
+	for _, mg := range m.ms {
+		if _, ok := as[mg.Version]; !ok {
+			ms = append(ms, mg)
+		}
+	}
+	return
+}
+
+// CheckUpToDate returns ErrSchemaBehind when there are pending migrations, after verifying the applied ones
+func (m *Migrator) CheckUpToDate() (err error) {
+	if err = m.Verify(); err != nil {
+		return
+	}
+
+	pending, err := m.Pending()
+	if err != nil {
+		return
+	}
+	if len(pending) > 0 {
+		err = fmt.Errorf("%w: %d pending migrations, next is %d (%s)", ErrSchemaBehind, len(pending), pending[0].Version, pending[0].Name)
+		return
+	}
+	return
+}
+
+// Up applies up to steps pending migrations in order (all of them when steps is 0)
+func (m *Migrator) Up(steps int) (done []Migration, err error) {
+	if err = m.Verify(); err != nil {
+		return
+	}
+
+	pending, err := m.Pending()
+	if err != nil {
+		return
+	}
+	if steps > 0 && steps < len(pending) {
+		pending = pending[:steps]
+	}
+
+	for _, mg := range pending {
+		err = m.run(mg, mg.Up,
+			"INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)",
+			mg.Version, mg.Name, mg.Checksum, time.Now().UTC(),
+		)
+		if err != nil {
+			return
+		}
+		done = append(done, mg)
+	}
+	return
+}
+
+// Down rolls back the last steps applied migrations in reverse order (one when steps is 0)
+func (m *Migrator) Down(steps int) (done []Migration, err error) {
+	if err = m.Verify(); err != nil {
+		return
+	}
+	if steps <= 0 {
+		steps = 1
+	}
+
+	as, err := m.applied()
+	if err != nil {
+		return
+	}
+
+	for i := len(m.ms) - 1; i >= 0 && len(done) < steps; i-- {
+		mg := m.ms[i]
+		if _, ok := as[mg.Version]; !ok {
+			continue
+		}
+		if mg.Down == "" {
+			err = fmt.Errorf("%w: version %d (%s)", ErrMigrationIrreversible, mg.Version, mg.Name)
+			return
+		}
+		if err = m.run(mg, mg.Down, "DELETE FROM schema_migrations WHERE version = ?", mg.Version); err != nil {
+			return
+		}
+		done = append(done, mg)
+	}
+	return
+}
@@ -0,0 +1,150 @@
+package migrate_test
+
+import (
+	"app/platform/migrate"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// Tests for Load function
+func TestLoad(t *testing.T) {
+	t.Run("success - sorted by version", func(t *testing.T) {
+		// arrange
+		fsys := fstest.MapFS{
+			"0002_second.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+			"0001_first.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+			"0001_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+			"readme.md":            {Data: []byte("ignored")},
+			"0003_invalid-name.up": {Data: []byte("ignored")},
+		}
+
+		// act
+		ms, err := migrate.Load(fsys)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, ms, 2)
+		require.Equal(t, 1, ms[0].Version)
+		require.Equal(t, "first", ms[0].Name)
+		require.Equal(t, "DROP TABLE a;", ms[0].Down)
+		require.Len(t, ms[0].Checksum, 64)
+		require.Equal(t, 2, ms[1].Version)
+		require.Equal(t, "", ms[1].Down)
+	})
+
+	t.Run("error - down without up", func(t *testing.T) {
+		// arrange
+		fsys := fstest.MapFS{
+			"0001_first.down.sql": {Data: []byte("DROP TABLE a;")},
+		}
+
+		// act
+		_, err := migrate.Load(fsys)
+
+		// assert
+		require.ErrorIs(t, err, migrate.ErrMigrationInvalid)
+	})
+
+	t.Run("error - same version with two names", func(t *testing.T) {
+		// arrange
+		fsys := fstest.MapFS{
+			"0001_first.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+			"0001_other.up.sql": {Data: []byte("CREATE TABLE b (id INT);")},
+		}
+
+		// act
+		_, err := migrate.Load(fsys)
+
+		// assert
+		require.ErrorIs(t, err, migrate.ErrMigrationInvalid)
+	})
+}
+
+// Tests for Statements function
+func TestStatements(t *testing.T) {
+	t.Run("multiple statements and comments", func(t *testing.T) {
+		// arrange
+		script := "-- comment\nCREATE TABLE `a` (\n  `id` int\n);\n\nINSERT INTO `a` VALUES\n(1);\nDROP TABLE `b`"
+
+		// act
+		stmts := migrate.Statements(script)
+
+		// assert
+		expected := []string{"CREATE TABLE `a` (\n  `id` int\n);", "INSERT INTO `a` VALUES\n(1);", "DROP TABLE `b`"}
+		require.Equal(t, expected, stmts)
+	})
+}
+
+// Tests for Rebind function
+func TestRebind(t *testing.T) {
+	t.Run("question placeholders are kept", func(t *testing.T) {
+		// act
+		query := migrate.Rebind(migrate.PlaceholderQuestion, "DELETE FROM t WHERE a = ? AND b = ?")
+
+		// assert
+		require.Equal(t, "DELETE FROM t WHERE a = ? AND b = ?", query)
+	})
+
+	t.Run("dollar placeholders are numbered", func(t *testing.T) {
+		// act
+		query := migrate.Rebind(migrate.PlaceholderDollar, "INSERT INTO t (a, b, c) VALUES (?, ?, ?)")
+
+		// assert
+		require.Equal(t, "INSERT INTO t (a, b, c) VALUES ($1, $2, $3)", query)
+	})
+}
+
+// Tests for Migrator.Up function
+func TestMigrator_Up(t *testing.T) {
+	// newDB returns a new sqlite database, whose ddl is transactional
+	newDB := func(t *testing.T) *sql.DB {
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+	ms := []migrate.Migration{
+		{Version: 1, Name: "first", Up: "CREATE TABLE a (id INT);", Down: "DROP TABLE a;", Checksum: "1"},
+		{Version: 2, Name: "second", Up: "CREATE TABLE b (id INT);\nINSERT INTO missing VALUES (1);", Down: "DROP TABLE b;", Checksum: "2"},
+	}
+
+	t.Run("transactional - a failed migration leaves no change behind", func(t *testing.T) {
+		// arrange
+		db := newDB(t)
+		mg := migrate.NewMigratorTransactional(db, ms, migrate.PlaceholderQuestion)
+
+		// act
+		done, err := mg.Up(0)
+
+		// assert
+		require.Error(t, err)
+		require.Len(t, done, 1)
+		var tables int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('a', 'b')").Scan(&tables))
+		require.Equal(t, 1, tables)
+		pending, err := mg.Pending()
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		require.Equal(t, 2, pending[0].Version)
+	})
+
+	t.Run("not transactional - a failed migration keeps the statements run before", func(t *testing.T) {
+		// arrange
+		db := newDB(t)
+		mg := migrate.NewMigrator(db, ms)
+
+		// act
+		_, err := mg.Up(0)
+
+		// assert
+		require.Error(t, err)
+		var tables int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('a', 'b')").Scan(&tables))
+		require.Equal(t, 2, tables)
+	})
+}
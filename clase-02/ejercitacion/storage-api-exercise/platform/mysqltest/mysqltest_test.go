@@ -0,0 +1,86 @@
+package mysqltest_test
+
+import (
+	"app/platform/mysqltest"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Server
+func TestServer(t *testing.T) {
+	// arrange
+	s, err := mysqltest.NewServer()
+	require.NoError(t, err)
+	defer s.Close()
+
+	t.Run("databases are isolated", func(t *testing.T) {
+		// arrange
+		cfg1, err := s.NewDatabase()
+		require.NoError(t, err)
+		cfg2, err := s.NewDatabase()
+		require.NoError(t, err)
+		db1, err := sql.Open("mysql", cfg1.FormatDSN())
+		require.NoError(t, err)
+		defer db1.Close()
+		db2, err := sql.Open("mysql", cfg2.FormatDSN())
+		require.NoError(t, err)
+		defer db2.Close()
+
+		// act
+		_, err1 := db1.Exec("CREATE TABLE `t` (`id` int NOT NULL, PRIMARY KEY (`id`))")
+		_, err2 := db2.Exec("SELECT * FROM `t`")
+
+		// assert
+		require.NotEqual(t, cfg1.DBName, cfg2.DBName)
+		require.NoError(t, err1)
+		require.Error(t, err2)
+	})
+
+	t.Run("mysql error codes", func(t *testing.T) {
+		// arrange
+		cfg, err := s.NewDatabase()
+		require.NoError(t, err)
+		db, err := sql.Open("mysql", cfg.FormatDSN())
+		require.NoError(t, err)
+		defer db.Close()
+		_, err = db.Exec("CREATE TABLE `t` (`id` int NOT NULL, PRIMARY KEY (`id`))")
+		require.NoError(t, err)
+		_, err = db.Exec("INSERT INTO `t` (`id`) VALUES (1)")
+		require.NoError(t, err)
+
+		// act
+		_, err = db.Exec("INSERT INTO `t` (`id`) VALUES (1)")
+
+		// assert
+		var mysqlErr *mysql.MySQLError
+		require.True(t, errors.As(err, &mysqlErr))
+		require.Equal(t, uint16(1062), mysqlErr.Number)
+	})
+
+	t.Run("transactions roll back", func(t *testing.T) {
+		// arrange
+		cfg, err := s.NewDatabase()
+		require.NoError(t, err)
+		db, err := sql.Open("mysql", cfg.FormatDSN())
+		require.NoError(t, err)
+		defer db.Close()
+		_, err = db.Exec("CREATE TABLE `t` (`id` int NOT NULL, PRIMARY KEY (`id`))")
+		require.NoError(t, err)
+
+		// act
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		_, err = tx.Exec("INSERT INTO `t` (`id`) VALUES (1)")
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+
+		// assert
+		var n int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM `t`").Scan(&n))
+		require.Zero(t, n)
+	})
+}
@@ -0,0 +1,424 @@
+// Package search is a full-text index of short texts, such as names and codes.
+// It finds the documents with the terms of a query, also by a prefix of them or with a typo,
+// ranks them by relevance and tells the terms matched, so they can be highlighted
+package search
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// minPrefix is the number of letters of a term of a query from which it matches the longer terms it starts
+	minPrefix = 2
+	// prefixWeight is the weight of a term matched by a prefix, scaled by the part of the term the prefix covers
+	prefixWeight = 0.8
+	// typoWeight is the weight of a term matched with a typo
+	typoWeight = 0.5
+)
+
+// Token is a term of a text with its position in the text
+type Token struct {
+	// Term is the token lowercase and without accents
+	Term string
+	// Start and End are the byte offsets of the token in the text
+	Start, End int
+}
+
+// Tokens splits a text into its tokens: the runs of letters and digits
+func Tokens(text string) (ts []Token) {
+	var term strings.Builder
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			term.WriteRune(fold(r))
+			continue
+		}
+		if start >= 0 {
+			ts = append(ts, Token{Term: term.String(), Start: start, End: i})
+			term.Reset()
+			start = -1
+		}
+	}
+	if start >= 0 {
+		ts = append(ts, Token{Term: term.String(), Start: start, End: len(text)})
+	}
+	return
+}
+
+// Terms returns the distinct terms of a text, in order of appearance
+func Terms(text string) (terms []string) {
+	seen := make(map[string]bool)
+	for _, t := range Tokens(text) {
+		if !seen[t.Term] {
+			seen[t.Term] = true
+			terms = append(terms, t.Term)
+		}
+	}
+	return
+}
+
+// accents are the letters folded into the one without its accent
+var accents = map[rune]rune{
+	'á': 'a', 'à': 'a', 'ä': 'a', 'â': 'a', 'ã': 'a',
+	'é': 'e', 'è': 'e', 'ë': 'e', 'ê': 'e',
+	'í': 'i', 'ì': 'i', 'ï': 'i', 'î': 'i',
+	'ó': 'o', 'ò': 'o', 'ö': 'o', 'ô': 'o', 'õ': 'o',
+	'ú': 'u', 'ù': 'u', 'ü': 'u', 'û': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
+
+// fold returns a letter lowercase and without its accent, one rune for one so the offsets of a term map to its text
+func fold(r rune) rune {
+	r = unicode.ToLower(r)
+	if f, ok := accents[r]; ok {
+		return f
+	}
+	return r
+}
+
+// maxTypos returns the number of typos tolerated in a term of a query: none in the short ones, one up to 7 letters and two from 8
+func maxTypos(term string) int {
+	switch n := utf8.RuneCountInString(term); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// match returns the weight of a term of a document for a term of a query and the number of its leading letters matched:
+// all of them when it is the same term or a typo of it, the ones of the query when the query is its prefix.
+// ok is false when it does not match
+func match(query, term string) (weight float64, letters int, ok bool) {
+	switch {
+	case query == term:
+		return 1, utf8.RuneCountInString(term), true
+	case utf8.RuneCountInString(query) >= minPrefix && strings.HasPrefix(term, query):
+		letters = utf8.RuneCountInString(query)
+		return prefixWeight * float64(letters) / float64(utf8.RuneCountInString(term)), letters, true
+	}
+	if d := maxTypos(query); d > 0 && distance(query, term, d) <= d {
+		return typoWeight, utf8.RuneCountInString(term), true
+	}
+	return
+}
+
+// distance returns the number of edits (insertions, deletions, substitutions and transpositions of two adjacent letters)
+// between two terms, or limit+1 once it is over limit
+func distance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if n := len(ra) - len(rb); n > limit || -n > limit {
+		return limit + 1
+	}
+
+	// rows of the matrix of the distances between the prefixes of a and b
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		best := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			best = min(best, cur[j])
+		}
+		if best > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+// TypoKeys returns the keys a term of a document is looked up by for the typos of a query: the strings given by deleting
+// as many of its letters as the typos a term of a query of its length tolerates (one from 3 letters, two from 6).
+// A term of a query with a typo of it has one of them among its QueryTypoKeys, so an index of them finds it without
+// a scan of the terms; Match then drops the candidates a typo does not give
+func TypoKeys(term string) (keys []string) {
+	switch n := utf8.RuneCountInString(term); {
+	case n >= 6:
+		keys = deletions(term, 2)
+	case n >= 3:
+		keys = deletions(term, 1)
+	}
+	return
+}
+
+// QueryTypoKeys returns the keys the typos of a term of a query are looked up by: the term and the strings given by deleting
+// as many of its letters as the typos it tolerates, none when it tolerates none.
+// A term of a document is a candidate when it is one of them or one of its TypoKeys is
+func QueryTypoKeys(term string) (keys []string) {
+	d := maxTypos(term)
+	if d == 0 {
+		return
+	}
+	keys = append([]string{term}, deletions(term, d)...)
+	return
+}
+
+// deletions returns the distinct strings given by deleting from 1 up to n letters of a term, sorted
+func deletions(term string, n int) (ds []string) {
+	seen := make(map[string]bool)
+	level := []string{term}
+	for i := 0; i < n; i++ {
+		var next []string
+		for _, t := range level {
+			rs := []rune(t)
+			for j := range rs {
+				d := string(rs[:j]) + string(rs[j+1:])
+				if d == "" || seen[d] {
+					continue
+				}
+				seen[d] = true
+				next = append(next, d)
+			}
+		}
+		ds = append(ds, next...)
+		level = next
+	}
+	sort.Strings(ds)
+	return
+}
+
+// Hit is a document found by a search
+type Hit struct {
+	// ID is the id of the document
+	ID int
+	// Score is the relevance of the document to the query, the higher the more relevant
+	Score float64
+	// Matched are the terms of the document matched by the query, with the number of their leading letters matched
+	Matched map[string]int
+}
+
+// NewIndex returns a new empty index of documents with a text per field, weights are the weights of the fields in the score
+func NewIndex(weights ...float64) *Index {
+	return &Index{
+		weights:  weights,
+		docs:     make(map[int][]string),
+		postings: make(map[string]map[int]uint64),
+	}
+}
+
+// Index is an inverted index: the documents of each term, with the fields the term is in.
+// It is not safe for concurrent use
+type Index struct {
+	// weights are the weights of the fields
+	weights []float64
+	// docs are the texts of the documents by id
+	docs map[int][]string
+	// postings are the documents of each term by id, with the bits of the fields the term is in
+	postings map[string]map[int]uint64
+	// vocabulary are the terms sorted, nil when a term was added or removed since it was sorted
+	vocabulary []string
+	// corpus is the number of documents the rarity of a term is measured in, zero for the ones of the index
+	corpus int
+}
+
+// Len returns the number of documents
+func (x *Index) Len() int {
+	return len(x.docs)
+}
+
+// SetCorpus sets the number of documents the rarity of a term is measured in, for an index of only the documents
+// with the terms of a query: its ranking is then the one of an index of the whole collection
+func (x *Index) SetCorpus(n int) {
+	x.corpus = n
+}
+
+// Put adds a document with its texts, one per field, or replaces the texts of the one with its id
+func (x *Index) Put(id int, texts ...string) {
+	if current, ok := x.docs[id]; ok {
+		if slices.Equal(current, texts) {
+			return
+		}
+		x.Remove(id)
+	}
+	x.docs[id] = append([]string(nil), texts...)
+	for f, text := range texts {
+		for _, t := range Tokens(text) {
+			ids, ok := x.postings[t.Term]
+			if !ok {
+				ids = make(map[int]uint64)
+				x.postings[t.Term] = ids
+				x.vocabulary = nil
+			}
+			ids[id] |= 1 << f
+		}
+	}
+}
+
+// Remove removes a document by id
+func (x *Index) Remove(id int) {
+	texts, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	delete(x.docs, id)
+	for _, text := range texts {
+		for _, t := range Tokens(text) {
+			ids := x.postings[t.Term]
+			delete(ids, id)
+			if len(ids) == 0 {
+				delete(x.postings, t.Term)
+				x.vocabulary = nil
+			}
+		}
+	}
+}
+
+// Retain removes the documents whose id keep returns false for
+func (x *Index) Retain(keep func(id int) bool) {
+	for id := range x.docs {
+		if !keep(id) {
+			x.Remove(id)
+		}
+	}
+}
+
+// Search returns the documents matched by the terms of a query, the most relevant first and up to limit (all when it is zero or less).
+// A document matches a term of the query when one of its terms is the same, starts with it or is a typo of it;
+// its score adds up, for each term of the query, the best of those matches weighted by the rarity of the term and its field
+func (x *Index) Search(query string, limit int) (hits []Hit) {
+	docs := float64(len(x.docs))
+	if x.corpus > 0 {
+		docs = float64(x.corpus)
+	}
+	byId := make(map[int]*Hit)
+	for _, q := range Terms(query) {
+		// best score of each document for the term of the query
+		scores := make(map[int]float64)
+		for _, term := range x.candidates(q) {
+			weight, letters, ok := match(q, term)
+			if !ok {
+				continue
+			}
+			ids := x.postings[term]
+			idf := math.Log(1 + (docs-float64(len(ids))+0.5)/(float64(len(ids))+0.5))
+			for id, fields := range ids {
+				h, ok := byId[id]
+				if !ok {
+					h = &Hit{ID: id, Matched: make(map[string]int)}
+					byId[id] = h
+				}
+				h.Matched[term] = max(h.Matched[term], letters)
+				scores[id] = max(scores[id], weight*x.fieldWeight(fields)*idf)
+			}
+		}
+		for id, s := range scores {
+			byId[id].Score += s
+		}
+	}
+
+	// rank
+	for _, h := range byId {
+		hits = append(hits, *h)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return
+}
+
+// candidates returns the terms of the index a term of a query may match: the ones it starts, found in the sorted vocabulary,
+// and the ones of a length a typo could give
+func (x *Index) candidates(q string) (terms []string) {
+	if x.vocabulary == nil {
+		x.vocabulary = make([]string, 0, len(x.postings))
+		for t := range x.postings {
+			x.vocabulary = append(x.vocabulary, t)
+		}
+		sort.Strings(x.vocabulary)
+	}
+
+	// prefix
+	i := sort.SearchStrings(x.vocabulary, q)
+	for ; i < len(x.vocabulary) && strings.HasPrefix(x.vocabulary[i], q); i++ {
+		terms = append(terms, x.vocabulary[i])
+	}
+
+	// typos
+	if d := maxTypos(q); d > 0 {
+		n := utf8.RuneCountInString(q)
+		for _, t := range x.vocabulary {
+			if m := utf8.RuneCountInString(t); m >= n-d && m <= n+d && !strings.HasPrefix(t, q) {
+				terms = append(terms, t)
+			}
+		}
+	}
+	return
+}
+
+// fieldWeight returns the weight of the heaviest of the fields of a term, given by their bits
+func (x *Index) fieldWeight(fields uint64) (w float64) {
+	for f, fw := range x.weights {
+		if fields&(1<<f) != 0 {
+			w = max(w, fw)
+		}
+	}
+	return
+}
+
+// Match matches a query against the texts of a single document, as a search does without ranking it.
+// It returns the terms of the document matched, ok is false when none is
+func Match(query string, texts ...string) (matched map[string]int, ok bool) {
+	matched = make(map[string]int)
+	for _, q := range Terms(query) {
+		for _, text := range texts {
+			for _, t := range Tokens(text) {
+				if _, letters, ok := match(q, t.Term); ok {
+					matched[t.Term] = max(matched[t.Term], letters)
+				}
+			}
+		}
+	}
+	ok = len(matched) > 0
+	return
+}
+
+// Span is a part of a text, by its byte offsets
+type Span struct {
+	Start, End int
+}
+
+// Highlight returns the parts of a text matched by a search, in order: the matched letters of each of its terms matched
+func Highlight(text string, matched map[string]int) (spans []Span) {
+	for _, t := range Tokens(text) {
+		letters, ok := matched[t.Term]
+		if !ok {
+			continue
+		}
+		end := t.Start
+		for i := 0; i < letters && end < t.End; i++ {
+			_, size := utf8.DecodeRuneInString(text[end:])
+			end += size
+		}
+		spans = append(spans, Span{Start: t.Start, End: end})
+	}
+	return
+}
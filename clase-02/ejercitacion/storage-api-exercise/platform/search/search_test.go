@@ -0,0 +1,197 @@
+package search_test
+
+import (
+	"app/platform/search"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newIndex returns an index of products by name and code value, the code value weighing more
+func newIndex() (x *search.Index) {
+	x = search.NewIndex(1, 2)
+	x.Put(1, "Chocolate - Dark", "0009-1111")
+	x.Put(2, "Chocolate Bar - Smarties", "42957-002")
+	x.Put(3, "Cookies - Oreo", "54868-6276")
+	x.Put(4, "Café con Leche", "0009-2222")
+	return
+}
+
+// ids returns the ids of the hits of a search, in order
+func ids(hits []search.Hit) (ids []int) {
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+	return
+}
+
+// Tests for Tokens function
+func TestTokens(t *testing.T) {
+	t.Run("success - runs of letters and digits, lowercase and without accents", func(t *testing.T) {
+		// act
+		ts := search.Tokens("Café - Ñandú 55")
+
+		// assert
+		require.Equal(t, []search.Token{
+			{Term: "cafe", Start: 0, End: 5},
+			{Term: "nandu", Start: 8, End: 15},
+			{Term: "55", Start: 16, End: 18},
+		}, ts)
+	})
+}
+
+// Tests for Index.Search method
+func TestIndex_Search(t *testing.T) {
+	t.Run("success - every term matched ranks first", func(t *testing.T) {
+		// arrange
+		x := newIndex()
+
+		// act
+		hits := x.Search("dark chocolate", 0)
+
+		// assert
+		require.Equal(t, []int{1, 2}, ids(hits))
+		require.Equal(t, map[string]int{"dark": 4, "chocolate": 9}, hits[0].Matched)
+	})
+
+	t.Run("success - prefix and typo", func(t *testing.T) {
+		// arrange
+		x := newIndex()
+
+		// act
+		prefix := x.Search("choc", 0)
+		typo := x.Search("cokies", 0)
+
+		// assert
+		require.Equal(t, []int{1, 2}, ids(prefix))
+		require.Equal(t, map[string]int{"chocolate": 4}, prefix[0].Matched)
+		require.Equal(t, []int{3}, ids(typo))
+		require.Equal(t, map[string]int{"cookies": 7}, typo[0].Matched)
+	})
+
+	t.Run("success - code value weighs more than a name, accents ignored", func(t *testing.T) {
+		// arrange
+		x := newIndex()
+		x.Put(5, "Pasta 0009", "77777-777")
+
+		// act
+		code := x.Search("0009", 0)
+		accents := x.Search("cafe", 0)
+
+		// assert
+		require.Equal(t, []int{1, 4, 5}, ids(code))
+		require.Equal(t, []int{4}, ids(accents))
+	})
+
+	t.Run("success - replaced and removed documents, limit", func(t *testing.T) {
+		// arrange
+		x := newIndex()
+
+		// act
+		x.Put(1, "Vanilla", "0009-1111")
+		x.Remove(2)
+		x.Retain(func(id int) bool { return id != 4 })
+		hits := x.Search("chocolate vanilla cookies", 1)
+
+		// assert
+		require.Equal(t, 2, x.Len())
+		require.Len(t, hits, 1)
+		require.Empty(t, x.Search("smarties", 0))
+	})
+
+	t.Run("success - short terms match only whole terms", func(t *testing.T) {
+		// arrange
+		x := newIndex()
+
+		// act
+		hits := x.Search("c", 0)
+
+		// assert
+		require.Empty(t, hits)
+	})
+
+	t.Run("success - an index of the candidates of a query ranks as the whole collection", func(t *testing.T) {
+		// arrange
+		x := newIndex()
+		candidates := search.NewIndex(1, 2)
+		candidates.Put(1, "Chocolate - Dark", "0009-1111")
+		candidates.Put(2, "Chocolate Bar - Smarties", "42957-002")
+		candidates.SetCorpus(x.Len())
+
+		// act
+		hits := candidates.Search("chocolate", 0)
+
+		// assert
+		require.Equal(t, x.Search("chocolate", 0), hits)
+	})
+}
+
+// Tests for Match function
+func TestMatch(t *testing.T) {
+	t.Run("success - terms of the document matched", func(t *testing.T) {
+		// act
+		matched, ok := search.Match("choclate 42957", "Chocolate Bar - Smarties", "42957-002")
+
+		// assert
+		require.True(t, ok)
+		require.Equal(t, map[string]int{"chocolate": 9, "42957": 5}, matched)
+	})
+
+	t.Run("failure - nothing matched", func(t *testing.T) {
+		// act
+		_, ok := search.Match("vanilla", "Chocolate Bar - Smarties", "42957-002")
+
+		// assert
+		require.False(t, ok)
+	})
+}
+
+// Tests for TypoKeys and QueryTypoKeys functions
+func TestTypoKeys(t *testing.T) {
+	t.Run("success - a term of a document with a typo of a query shares a key with it", func(t *testing.T) {
+		// arrange
+		cases := map[string]string{
+			"cokies":     "cookies",
+			"choclate":   "chocolate",
+			"chocolaet":  "chocolate",
+			"chocolatte": "chocolate",
+			"chcolatte":  "chocolate",
+			"bard":       "bar",
+		}
+
+		for query, term := range cases {
+			// act
+			keys := append(search.TypoKeys(term), term)
+			queryKeys := search.QueryTypoKeys(query)
+
+			// assert
+			_, ok := search.Match(query, term)
+			require.True(t, ok, query)
+			require.True(t, slices.ContainsFunc(queryKeys, func(k string) bool { return slices.Contains(keys, k) }), query)
+		}
+	})
+
+	t.Run("success - keys of short terms", func(t *testing.T) {
+		// act
+		keys := search.TypoKeys("bar")
+		short := search.TypoKeys("ab")
+		queryKeys := search.QueryTypoKeys("bar")
+
+		// assert
+		require.Equal(t, []string{"ar", "ba", "br"}, keys)
+		require.Empty(t, short)
+		require.Empty(t, queryKeys)
+	})
+}
+
+// Tests for Highlight function
+func TestHighlight(t *testing.T) {
+	t.Run("success - matched letters of each term", func(t *testing.T) {
+		// act
+		spans := search.Highlight("Café con Leche", map[string]int{"cafe": 4, "leche": 2})
+
+		// assert
+		require.Equal(t, []search.Span{{Start: 0, End: 5}, {Start: 10, End: 12}}, spans)
+	})
+}
@@ -0,0 +1,100 @@
+// Package codec encodes and decodes the bodies of the api in the media types it supports: JSON, XML, MessagePack and CSV.
+// Every format names the fields after their json tag, so a body reads the same in all of them:
+//   - XML: an element per field in a response root element, the items of a list in item elements
+//   - MessagePack: a map per struct, as encoding/json would write it
+//   - CSV: only for lists of structs, a header row with the fields and a row per item
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"reflect"
+)
+
+const (
+	// JSON is the media type of json bodies
+	JSON = "application/json"
+	// XML is the media type of xml bodies, text/xml is an alias
+	XML = "application/xml"
+	// MessagePack is the media type of msgpack bodies, application/x-msgpack and application/vnd.msgpack are aliases
+	MessagePack = "application/msgpack"
+	// CSV is the media type of csv bodies
+	CSV = "text/csv"
+)
+
+// ErrUnsupported is used when a media type has no codec, or a value can not be written in it (e.g. a single resource in csv)
+var ErrUnsupported = errors.New("codec: unsupported media type")
+
+// aliases are the media types of each format other than its own
+var aliases = map[string]string{
+	"text/xml":                XML,
+	"application/x-msgpack":   MessagePack,
+	"application/vnd.msgpack": MessagePack,
+}
+
+// MediaType returns the media type of a format named by a content type header (e.g. application/json; charset=utf-8),
+// resolving its aliases. ok is false when the api has no codec for it
+func MediaType(contentType string) (mediaType string, ok bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return
+	}
+	if m, isAlias := aliases[mediaType]; isAlias {
+		mediaType = m
+	}
+	switch mediaType {
+	case JSON, XML, MessagePack, CSV:
+		ok = true
+	}
+	return
+}
+
+// Tabular reports whether a value can be written as csv: a slice or array of structs (or pointers to them)
+func Tabular(v any) bool {
+	t := reflect.TypeOf(v)
+	if t == nil || (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) {
+		return false
+	}
+	t = t.Elem()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// Encode writes a value to w in a media type
+func Encode(w io.Writer, mediaType string, v any) (err error) {
+	switch mediaType {
+	case JSON:
+		err = json.NewEncoder(w).Encode(v)
+	case XML:
+		err = encodeXML(w, v)
+	case MessagePack:
+		err = encodeMsgpack(w, v)
+	case CSV:
+		err = encodeCSV(w, v)
+	default:
+		err = ErrUnsupported
+	}
+	return
+}
+
+// Decode reads a body in a media type from r into the value pointed by ptr.
+// Fields missing from the body keep their value, so it can decode over a current one
+func Decode(r io.Reader, mediaType string, ptr any) (err error) {
+	switch mediaType {
+	case JSON:
+		err = json.NewDecoder(r).Decode(ptr)
+	case XML:
+		err = decodeXML(r, ptr)
+	case MessagePack:
+		err = decodeMsgpack(r, ptr)
+	case CSV:
+		err = decodeCSV(r, ptr)
+	default:
+		err = ErrUnsupported
+	}
+	return
+}
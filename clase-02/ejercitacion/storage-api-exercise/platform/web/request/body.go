@@ -0,0 +1,38 @@
+package request
+
+import (
+	"app/platform/web/codec"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrRequestContentTypeNotSupported is used when the request content type has no decoder, or can not hold the expected body (e.g. a single resource in csv).
+	ErrRequestContentTypeNotSupported = errors.New("request content type is not supported")
+	// ErrRequestBodyInvalid is used when the request body can not be decoded.
+	ErrRequestBodyInvalid = errors.New("request body invalid")
+)
+
+// Body decodes the request body to ptr in the format of its content type: json (the default when it is missing), xml, msgpack or csv
+func Body(r *http.Request, ptr any) (err error) {
+	// check content type
+	mediaType := codec.JSON
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		var ok bool
+		if mediaType, ok = codec.MediaType(ct); !ok {
+			err = ErrRequestContentTypeNotSupported
+			return
+		}
+	}
+
+	// get body
+	err = codec.Decode(r.Body, mediaType, ptr)
+	switch {
+	case errors.Is(err, codec.ErrUnsupported):
+		err = ErrRequestContentTypeNotSupported
+	case err != nil:
+		err = fmt.Errorf("%w. %v", ErrRequestBodyInvalid, err)
+	}
+	return
+}
@@ -0,0 +1,44 @@
+package request_test
+
+import (
+	"app/platform/web/request"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Deadline
+func TestDeadline(t *testing.T) {
+	t.Run("case 1: the context of the request expires", func(t *testing.T) {
+		// arrange
+		var err error
+		hd := request.Deadline(time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			err = r.Context().Err()
+		}))
+
+		// act
+		hd.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		// assert
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("case 2: no deadline", func(t *testing.T) {
+		// arrange
+		var ok bool
+		hd := request.Deadline(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, ok = r.Context().Deadline()
+		}))
+
+		// act
+		hd.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		// assert
+		require.False(t, ok)
+	})
+}
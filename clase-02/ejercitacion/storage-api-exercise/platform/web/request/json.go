@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 )
 
@@ -17,8 +18,9 @@ var (
 
 // JSON decodes json from request body to ptr
 func JSON(r *http.Request, ptr any) (err error) {
-	// check content type
-	if r.Header.Get("Content-Type") != "application/json" {
+	// check content type, its parameters (e.g. charset) aside
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
 		err = ErrRequestContentTypeNotJSON
 		return
 	}
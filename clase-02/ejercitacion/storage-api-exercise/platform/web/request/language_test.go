@@ -0,0 +1,40 @@
+package request_test
+
+import (
+	"app/platform/web/request"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Language
+func TestLanguage(t *testing.T) {
+	t.Run("case 1: language accepted by the client", func(t *testing.T) {
+		// arrange
+		hd := request.Language(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Language", "es-AR,es;q=0.9")
+
+		// act
+		res := httptest.NewRecorder()
+		hd.ServeHTTP(res, req)
+
+		// assert
+		require.Equal(t, "es", res.Header().Get("Content-Language"))
+		require.Equal(t, "Accept-Language", res.Header().Get("Vary"))
+	})
+
+	t.Run("case 2: no language accepted", func(t *testing.T) {
+		// arrange
+		hd := request.Language(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		// act
+		res := httptest.NewRecorder()
+		hd.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+
+		// assert
+		require.Equal(t, "en", res.Header().Get("Content-Language"))
+	})
+}
@@ -0,0 +1,467 @@
+package request
+
+import (
+	"app/platform/web/codec"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ContentTypeMergePatch is the media type of a JSON merge patch (RFC 7386)
+	ContentTypeMergePatch = "application/merge-patch+json"
+	// ContentTypeJSONPatch is the media type of a JSON patch (RFC 6902)
+	ContentTypeJSONPatch = "application/json-patch+json"
+)
+
+var (
+	// ErrRequestContentTypeNotPatch is used when the request content type is not a supported patch format.
+	ErrRequestContentTypeNotPatch = errors.New("request content type is not a supported patch format")
+	// ErrRequestPatchInvalid is used when the request patch document is invalid or cannot be applied.
+	ErrRequestPatchInvalid = errors.New("request patch invalid")
+	// ErrRequestPatchTestFailed is used when a test operation of a json patch fails.
+	ErrRequestPatchTestFailed = errors.New("request patch test failed")
+)
+
+// Patch applies the patch in the request body to the value pointed by ptr.
+// The format is selected by the content type: application/json, application/xml or application/msgpack
+// (fields present in the body overwrite ptr), application/merge-patch+json (RFC 7386) or application/json-patch+json (RFC 6902).
+func Patch(r *http.Request, ptr any) (err error) {
+	// check content type
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		err = ErrRequestContentTypeNotPatch
+		return
+	}
+
+	switch mediaType {
+	case "application/json":
+		// decode over the current value
+		err = json.NewDecoder(r.Body).Decode(ptr)
+		if err != nil {
+			err = fmt.Errorf("%w. %v", ErrRequestJSONInvalid, err)
+		}
+		return
+	case codec.XML, "text/xml", codec.MessagePack, "application/x-msgpack", "application/vnd.msgpack":
+		// decode over the current value
+		mediaType, _ = codec.MediaType(mediaType)
+		err = codec.Decode(r.Body, mediaType, ptr)
+		if err != nil {
+			err = fmt.Errorf("%w. %v", ErrRequestBodyInvalid, err)
+		}
+		return
+	case ContentTypeMergePatch, ContentTypeJSONPatch:
+	default:
+		err = ErrRequestContentTypeNotPatch
+		return
+	}
+
+	// get current document
+	doc, err := toDocument(ptr)
+	if err != nil {
+		return
+	}
+
+	// get patch
+	var patch any
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err = dec.Decode(&patch); err != nil {
+		err = fmt.Errorf("%w. %v", ErrRequestPatchInvalid, err)
+		return
+	}
+
+	// apply patch
+	switch mediaType {
+	case ContentTypeMergePatch:
+		doc = MergePatch(doc, patch)
+	case ContentTypeJSONPatch:
+		doc, err = JSONPatch(doc, patch)
+		if err != nil {
+			return
+		}
+	}
+
+	// decode patched document into ptr
+	b, err := json.Marshal(doc)
+	if err != nil {
+		err = fmt.Errorf("%w. %v", ErrRequestPatchInvalid, err)
+		return
+	}
+	rv := reflect.ValueOf(ptr).Elem()
+	rv.Set(reflect.Zero(rv.Type()))
+	if err = json.Unmarshal(b, ptr); err != nil {
+		err = fmt.Errorf("%w. %v", ErrRequestPatchInvalid, err)
+		return
+	}
+
+	return
+}
+
+// toDocument converts a value into a generic json document
+func toDocument(v any) (doc any, err error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err = dec.Decode(&doc)
+	return
+}
+
+// MergePatch applies a json merge patch (RFC 7386) to a generic json document
+func MergePatch(doc any, patch any) (result any) {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		result = patch
+		return
+	}
+
+	target, ok := doc.(map[string]any)
+	if !ok {
+		target = make(map[string]any)
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(target, k)
+			continue
+		}
+		target[k] = MergePatch(target[k], v)
+	}
+
+	result = target
+	return
+}
+
+// operation is a json patch operation
+type operation struct {
+	Op    string
+	Path  string
+	From  string
+	Value any
+	// hasValue tells if the value member was present
+	hasValue bool
+}
+
+// JSONPatch applies a json patch (RFC 6902) to a generic json document.
+// The operations are applied in order and the whole patch fails if any of them fails.
+func JSONPatch(doc any, patch any) (result any, err error) {
+	// parse operations
+	items, ok := patch.([]any)
+	if !ok {
+		err = fmt.Errorf("%w. patch must be an array of operations", ErrRequestPatchInvalid)
+		return
+	}
+	ops := make([]operation, 0, len(items))
+	for i, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			err = fmt.Errorf("%w. operation %d must be an object", ErrRequestPatchInvalid, i)
+			return
+		}
+		var op operation
+		op.Op, _ = m["op"].(string)
+		op.Path, ok = m["path"].(string)
+		if !ok {
+			err = fmt.Errorf("%w. operation %d has no path", ErrRequestPatchInvalid, i)
+			return
+		}
+		op.From, _ = m["from"].(string)
+		op.Value, op.hasValue = m["value"]
+		ops = append(ops, op)
+	}
+
+	// apply operations
+	result = doc
+	for i, op := range ops {
+		result, err = applyOperation(result, op)
+		if err != nil {
+			err = fmt.Errorf("operation %d: %w", i, err)
+			return
+		}
+	}
+
+	return
+}
+
+// applyOperation applies a single json patch operation to doc
+func applyOperation(doc any, op operation) (result any, err error) {
+	switch op.Op {
+	case "add":
+		if !op.hasValue {
+			err = fmt.Errorf("%w. add requires a value", ErrRequestPatchInvalid)
+			return
+		}
+		result, err = pointerAdd(doc, op.Path, op.Value)
+	case "remove":
+		result, _, err = pointerRemove(doc, op.Path)
+	case "replace":
+		if !op.hasValue {
+			err = fmt.Errorf("%w. replace requires a value", ErrRequestPatchInvalid)
+			return
+		}
+		if result, _, err = pointerRemove(doc, op.Path); err != nil {
+			return
+		}
+		result, err = pointerAdd(result, op.Path, op.Value)
+	case "move":
+		if op.Path == op.From {
+			result = doc
+			return
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			err = fmt.Errorf("%w. cannot move a value into one of its children", ErrRequestPatchInvalid)
+			return
+		}
+		var value any
+		if result, value, err = pointerRemove(doc, op.From); err != nil {
+			return
+		}
+		result, err = pointerAdd(result, op.Path, value)
+	case "copy":
+		var value any
+		if value, err = pointerGet(doc, op.From); err != nil {
+			return
+		}
+		if value, err = toDocument(value); err != nil {
+			return
+		}
+		result, err = pointerAdd(doc, op.Path, value)
+	case "test":
+		if !op.hasValue {
+			err = fmt.Errorf("%w. test requires a value", ErrRequestPatchInvalid)
+			return
+		}
+		var value any
+		if value, err = pointerGet(doc, op.Path); err != nil {
+			return
+		}
+		if !equalDocuments(value, op.Value) {
+			err = fmt.Errorf("%w. value at %s does not match", ErrRequestPatchTestFailed, op.Path)
+			return
+		}
+		result = doc
+	default:
+		err = fmt.Errorf("%w. unknown operation %q", ErrRequestPatchInvalid, op.Op)
+	}
+
+	return
+}
+
+// parsePointer splits a json pointer (RFC 6901) into its unescaped tokens
+func parsePointer(pointer string) (tokens []string, err error) {
+	if pointer == "" {
+		return
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		err = fmt.Errorf("%w. invalid pointer %q", ErrRequestPatchInvalid, pointer)
+		return
+	}
+	for _, t := range strings.Split(pointer[1:], "/") {
+		t = strings.ReplaceAll(t, "~1", "/")
+		t = strings.ReplaceAll(t, "~0", "~")
+		tokens = append(tokens, t)
+	}
+	return
+}
+
+// arrayIndex parses the index of an array token, allowing "-" (one past the end) when end is true
+func arrayIndex(token string, length int, end bool) (index int, err error) {
+	if end && token == "-" {
+		index = length
+		return
+	}
+	index, err = strconv.Atoi(token)
+	if err != nil || index < 0 || index > length || (!end && index == length) || (len(token) > 1 && token[0] == '0') {
+		err = fmt.Errorf("%w. invalid array index %q", ErrRequestPatchInvalid, token)
+		return
+	}
+	return
+}
+
+// pointerGet returns the value referenced by pointer
+func pointerGet(doc any, pointer string) (value any, err error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return
+	}
+
+	value = doc
+	for _, t := range tokens {
+		switch node := value.(type) {
+		case map[string]any:
+			var ok bool
+			if value, ok = node[t]; !ok {
+				err = fmt.Errorf("%w. path %s not found", ErrRequestPatchInvalid, pointer)
+				return
+			}
+		case []any:
+			var i int
+			if i, err = arrayIndex(t, len(node), false); err != nil {
+				return
+			}
+			value = node[i]
+		default:
+			err = fmt.Errorf("%w. path %s not found", ErrRequestPatchInvalid, pointer)
+			return
+		}
+	}
+	return
+}
+
+// pointerAdd adds value at pointer, returning the resulting document
+func pointerAdd(doc any, pointer string, value any) (result any, err error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return
+	}
+	result, err = add(doc, tokens, value, pointer)
+	return
+}
+
+func add(node any, tokens []string, value any, pointer string) (result any, err error) {
+	if len(tokens) == 0 {
+		result = value
+		return
+	}
+
+	t := tokens[0]
+	switch n := node.(type) {
+	case map[string]any:
+		if len(tokens) == 1 {
+			n[t] = value
+			result = n
+			return
+		}
+		child, ok := n[t]
+		if !ok {
+			err = fmt.Errorf("%w. path %s not found", ErrRequestPatchInvalid, pointer)
+			return
+		}
+		if n[t], err = add(child, tokens[1:], value, pointer); err != nil {
+			return
+		}
+		result = n
+	case []any:
+		if len(tokens) == 1 {
+			var i int
+			if i, err = arrayIndex(t, len(n), true); err != nil {
+				return
+			}
+			n = append(n, nil)
+			copy(n[i+1:], n[i:])
+			n[i] = value
+			result = n
+			return
+		}
+		var i int
+		if i, err = arrayIndex(t, len(n), false); err != nil {
+			return
+		}
+		if n[i], err = add(n[i], tokens[1:], value, pointer); err != nil {
+			return
+		}
+		result = n
+	default:
+		err = fmt.Errorf("%w. path %s not found", ErrRequestPatchInvalid, pointer)
+	}
+	return
+}
+
+// pointerRemove removes the value at pointer, returning the resulting document and the removed value
+func pointerRemove(doc any, pointer string) (result any, removed any, err error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return
+	}
+	if len(tokens) == 0 {
+		err = fmt.Errorf("%w. cannot remove the whole document", ErrRequestPatchInvalid)
+		return
+	}
+	result, removed, err = remove(doc, tokens, pointer)
+	return
+}
+
+func remove(node any, tokens []string, pointer string) (result any, removed any, err error) {
+	t := tokens[0]
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[t]
+		if !ok {
+			err = fmt.Errorf("%w. path %s not found", ErrRequestPatchInvalid, pointer)
+			return
+		}
+		if len(tokens) == 1 {
+			delete(n, t)
+			result, removed = n, child
+			return
+		}
+		if n[t], removed, err = remove(child, tokens[1:], pointer); err != nil {
+			return
+		}
+		result = n
+	case []any:
+		var i int
+		if i, err = arrayIndex(t, len(n), false); err != nil {
+			return
+		}
+		if len(tokens) == 1 {
+			removed = n[i]
+			result = append(n[:i:i], n[i+1:]...)
+			return
+		}
+		if n[i], removed, err = remove(n[i], tokens[1:], pointer); err != nil {
+			return
+		}
+		result = n
+	default:
+		err = fmt.Errorf("%w. path %s not found", ErrRequestPatchInvalid, pointer)
+	}
+	return
+}
+
+// equalDocuments compares two generic json documents, numbers by value
+func equalDocuments(a, b any) bool {
+	switch va := a.(type) {
+	case json.Number:
+		vb, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fa, errA := va.Float64()
+		fb, errB := vb.Float64()
+		return errA == nil && errB == nil && fa == fb
+	case map[string]any:
+		vb, ok := b.(map[string]any)
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for k, v := range va {
+			w, ok := vb[k]
+			if !ok || !equalDocuments(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		vb, ok := b.([]any)
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !equalDocuments(va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
@@ -0,0 +1,189 @@
+package request_test
+
+import (
+	"app/platform/web/request"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Patch function
+func TestRequestPatch(t *testing.T) {
+	type schema struct {
+		Name     string   `json:"name"`
+		Quantity int      `json:"quantity"`
+		Active   bool     `json:"active"`
+		Tags     []string `json:"tags"`
+	}
+
+	t.Run("success - json", func(t *testing.T) {
+		// arrange
+		inputSchema := schema{Name: "test", Quantity: 10, Active: true}
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"application/json"}},
+			Body:   io.NopCloser(strings.NewReader(`{"quantity":20}`)),
+		}
+
+		// act
+		err := request.Patch(&inputRequest, &inputSchema)
+
+		// assert
+		expectedSchema := schema{Name: "test", Quantity: 20, Active: true}
+		require.NoError(t, err)
+		require.Equal(t, expectedSchema, inputSchema)
+	})
+
+	t.Run("success - merge patch", func(t *testing.T) {
+		// arrange
+		inputSchema := schema{Name: "test", Quantity: 10, Active: true, Tags: []string{"a"}}
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"application/merge-patch+json; charset=utf-8"}},
+			Body:   io.NopCloser(strings.NewReader(`{"active":false,"tags":null}`)),
+		}
+
+		// act
+		err := request.Patch(&inputRequest, &inputSchema)
+
+		// assert
+		expectedSchema := schema{Name: "test", Quantity: 10, Active: false}
+		require.NoError(t, err)
+		require.Equal(t, expectedSchema, inputSchema)
+	})
+
+	t.Run("success - json patch", func(t *testing.T) {
+		// arrange
+		inputSchema := schema{Name: "test", Quantity: 10, Tags: []string{"a", "c"}}
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"application/json-patch+json"}},
+			Body: io.NopCloser(strings.NewReader(`[
+				{"op":"test","path":"/quantity","value":10.0},
+				{"op":"replace","path":"/quantity","value":5},
+				{"op":"add","path":"/tags/1","value":"b"},
+				{"op":"copy","from":"/name","path":"/tags/-"},
+				{"op":"move","from":"/tags/0","path":"/name"}
+			]`)),
+		}
+
+		// act
+		err := request.Patch(&inputRequest, &inputSchema)
+
+		// assert
+		expectedSchema := schema{Name: "a", Quantity: 5, Tags: []string{"b", "c", "test"}}
+		require.NoError(t, err)
+		require.Equal(t, expectedSchema, inputSchema)
+	})
+
+	t.Run("success - xml", func(t *testing.T) {
+		// arrange
+		inputSchema := schema{Name: "test", Quantity: 10, Active: true}
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"application/xml; charset=utf-8"}},
+			Body:   io.NopCloser(strings.NewReader(`<request><quantity>20</quantity><tags><item>a</item></tags></request>`)),
+		}
+
+		// act
+		err := request.Patch(&inputRequest, &inputSchema)
+
+		// assert
+		expectedSchema := schema{Name: "test", Quantity: 20, Active: true, Tags: []string{"a"}}
+		require.NoError(t, err)
+		require.Equal(t, expectedSchema, inputSchema)
+	})
+
+	t.Run("error - content-type", func(t *testing.T) {
+		// arrange
+		inputSchema := schema{Name: "test"}
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"text/plain"}},
+			Body:   io.NopCloser(strings.NewReader(`name=other`)),
+		}
+
+		// act
+		err := request.Patch(&inputRequest, &inputSchema)
+
+		// assert
+		expectedSchema := schema{Name: "test"}
+		require.ErrorIs(t, err, request.ErrRequestContentTypeNotPatch)
+		require.Equal(t, expectedSchema, inputSchema)
+	})
+
+	t.Run("error - json patch test failed", func(t *testing.T) {
+		// arrange
+		inputSchema := schema{Name: "test", Quantity: 10}
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"application/json-patch+json"}},
+			Body: io.NopCloser(strings.NewReader(`[
+				{"op":"replace","path":"/name","value":"other"},
+				{"op":"test","path":"/quantity","value":11}
+			]`)),
+		}
+
+		// act
+		err := request.Patch(&inputRequest, &inputSchema)
+
+		// assert
+		expectedSchema := schema{Name: "test", Quantity: 10}
+		require.ErrorIs(t, err, request.ErrRequestPatchTestFailed)
+		require.EqualError(t, err, "operation 1: request patch test failed. value at /quantity does not match")
+		require.Equal(t, expectedSchema, inputSchema)
+	})
+
+	t.Run("error - json patch path not found", func(t *testing.T) {
+		// arrange
+		inputSchema := schema{Name: "test"}
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"application/json-patch+json"}},
+			Body:   io.NopCloser(strings.NewReader(`[{"op":"remove","path":"/missing"}]`)),
+		}
+
+		// act
+		err := request.Patch(&inputRequest, &inputSchema)
+
+		// assert
+		expectedSchema := schema{Name: "test"}
+		require.ErrorIs(t, err, request.ErrRequestPatchInvalid)
+		require.Equal(t, expectedSchema, inputSchema)
+	})
+
+	t.Run("error - json patch not an array", func(t *testing.T) {
+		// arrange
+		inputSchema := schema{Name: "test"}
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"application/json-patch+json"}},
+			Body:   io.NopCloser(strings.NewReader(`{"op":"remove","path":"/name"}`)),
+		}
+
+		// act
+		err := request.Patch(&inputRequest, &inputSchema)
+
+		// assert
+		require.ErrorIs(t, err, request.ErrRequestPatchInvalid)
+	})
+}
+
+// Tests for MergePatch function
+func TestRequestMergePatch(t *testing.T) {
+	t.Run("nested objects", func(t *testing.T) {
+		// arrange
+		doc := map[string]any{"a": "b", "c": map[string]any{"d": "e", "f": "g"}}
+		patch := map[string]any{"a": "z", "c": map[string]any{"f": nil}}
+
+		// act
+		result := request.MergePatch(doc, patch)
+
+		// assert
+		expected := map[string]any{"a": "z", "c": map[string]any{"d": "e"}}
+		require.Equal(t, expected, result)
+	})
+
+	t.Run("non object patch replaces the document", func(t *testing.T) {
+		// act
+		result := request.MergePatch(map[string]any{"a": "b"}, []any{"c"})
+
+		// assert
+		require.Equal(t, []any{"c"}, result)
+	})
+}
@@ -0,0 +1,47 @@
+package request
+
+import (
+	"net/http"
+	"strings"
+)
+
+// IfMatch reports whether the request preconditions in the If-Match header are met by etag.
+// A missing header is considered a match.
+func IfMatch(r *http.Request, etag string) (ok bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		ok = true
+		return
+	}
+
+	// strong comparison
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || (tag == etag && !strings.HasPrefix(tag, "W/")) {
+			ok = true
+			return
+		}
+	}
+
+	return
+}
+
+// IfNoneMatch reports whether etag is listed in the If-None-Match header of the request.
+// A missing header is never a match.
+func IfNoneMatch(r *http.Request, etag string) (match bool) {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return
+	}
+
+	// weak comparison
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			match = true
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,23 @@
+package request
+
+import (
+	"net/http"
+	"strings"
+)
+
+// QueryList returns the items of a comma separated query parameter (e.g. ?fields=id,name), in order and without blanks nor repeated ones.
+// The parameter may also be repeated (e.g. ?fields=id&fields=name). It is empty when the parameter is missing
+func QueryList(r *http.Request, name string) (items []string) {
+	seen := make(map[string]bool)
+	for _, v := range r.URL.Query()[name] {
+		for _, item := range strings.Split(v, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" || seen[item] {
+				continue
+			}
+			seen[item] = true
+			items = append(items, item)
+		}
+	}
+	return
+}
@@ -0,0 +1,34 @@
+package request_test
+
+import (
+	"app/platform/web/request"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for QueryList
+func TestQueryList(t *testing.T) {
+	t.Run("case 1: items of a comma separated and repeated parameter", func(t *testing.T) {
+		// arrange
+		req := httptest.NewRequest("GET", "/?fields=id,%20name,,id&fields=price", nil)
+
+		// act
+		items := request.QueryList(req, "fields")
+
+		// assert
+		require.Equal(t, []string{"id", "name", "price"}, items)
+	})
+
+	t.Run("case 2: missing parameter", func(t *testing.T) {
+		// arrange
+		req := httptest.NewRequest("GET", "/?include=warehouse", nil)
+
+		// act
+		items := request.QueryList(req, "fields")
+
+		// assert
+		require.Empty(t, items)
+	})
+}
@@ -0,0 +1,21 @@
+package response
+
+import (
+	"encoding/csv"
+	"mime"
+	"net/http"
+)
+
+// CSV writes the headers of a csv attachment response and returns the writer of its rows.
+// The caller must flush the writer once all the rows are written.
+func CSV(w http.ResponseWriter, code int, filename string) (cw *csv.Writer) {
+	// set header
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+
+	// set status code
+	w.WriteHeader(code)
+
+	cw = csv.NewWriter(w)
+	return
+}
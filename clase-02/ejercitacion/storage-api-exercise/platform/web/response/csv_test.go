@@ -0,0 +1,36 @@
+package response_test
+
+import (
+	"app/platform/web/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for CSV function
+func TestCSV(t *testing.T) {
+	t.Run("200 - rows written", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		cw := response.CSV(rr, http.StatusOK, "products.csv")
+		cw.Write([]string{"name", "price"})
+		cw.Write([]string{"product, 1", "10.5"})
+		cw.Flush()
+
+		// assert
+		expectedHeader := http.Header{
+			"Content-Type":        []string{"text/csv; charset=utf-8"},
+			"Content-Disposition": []string{"attachment; filename=products.csv"},
+		}
+		expectedCode := http.StatusOK
+		expectedBody := "name,price\n\"product, 1\",10.5\n"
+		require.Equal(t, expectedHeader, rr.Header())
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+	})
+}
@@ -0,0 +1,135 @@
+package response
+
+import (
+	"app/platform/web/codec"
+	"bytes"
+	"net/http"
+)
+
+// Envelope is the body of every response of a versioned api: the data of a success or the errors of a failure,
+// with the metadata of the response and the links to the resource
+type Envelope struct {
+	// Data is the resource or list of resources of a success, null on failure
+	Data any `json:"data"`
+	// Meta is the metadata of the response: its request id, its message and e.g. the count of a list
+	Meta map[string]any `json:"meta"`
+	// Errors are the problems of a failure
+	Errors []Problem `json:"errors,omitempty"`
+	// Links are the uris of the resource (self) and the related ones
+	Links map[string]string `json:"links,omitempty"`
+}
+
+// Body is the body of a success response
+type Body struct {
+	// Message is a summary of the response in its language
+	Message string
+	// Key is the key of the data in the body of the unversioned routes, data by default
+	Key string
+	// Data is the resource or list of resources
+	Data any
+	// Meta is the metadata of the response besides its request id and message (e.g. count)
+	Meta map[string]any
+	// Links are the uris of the resource and the related ones
+	Links map[string]string
+}
+
+// Enveloped is a middleware that makes the next handler write its responses in an Envelope
+func Enveloped(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&envelopeWriter{ResponseWriter: w}, r)
+	})
+}
+
+// envelopeWriter is a response writer whose responses are written in an Envelope
+type envelopeWriter struct {
+	http.ResponseWriter
+}
+
+// Unwrap returns the response writer it wraps, as http.ResponseController expects
+func (w *envelopeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// enveloped reports whether the responses of w are written in an Envelope, following the writers it wraps
+func enveloped(w http.ResponseWriter) bool {
+	for {
+		switch u := w.(type) {
+		case *envelopeWriter:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = u.Unwrap()
+		default:
+			return false
+		}
+	}
+}
+
+// meta returns the metadata of an Envelope: the request id of the response and m
+func meta(w http.ResponseWriter, m map[string]any) (md map[string]any) {
+	md = make(map[string]any, len(m)+1)
+	for k, v := range m {
+		md[k] = v
+	}
+	if id := w.Header().Get(headerRequestID); id != "" {
+		md["request_id"] = id
+	}
+	return
+}
+
+// Success writes the body of a success: in an Envelope when the response is enveloped,
+// otherwise as {"message": message, key: data} as the unversioned routes do.
+// It is written in the negotiated media type, a list negotiated as csv is written without the message and metadata
+func Success(w http.ResponseWriter, code int, b Body) {
+	// csv
+	if mediaType, _ := negotiate(w, codec.Tabular(b.Data)); mediaType == codec.CSV {
+		Encode(w, code, b.Data)
+		return
+	}
+
+	// unversioned
+	if !enveloped(w) {
+		key := b.Key
+		if key == "" {
+			key = "data"
+		}
+		Encode(w, code, map[string]any{"message": b.Message, key: b.Data})
+		return
+	}
+
+	// envelope
+	m := meta(w, b.Meta)
+	if b.Message != "" {
+		m["message"] = b.Message
+	}
+	Encode(w, code, Envelope{Data: b.Data, Meta: m, Links: b.Links})
+}
+
+// writeProblemEnvelope writes a problem as the errors of an Envelope, in the media type negotiated for the response.
+// It is written in json when none is acceptable, as the 406 problem of Encode is
+func writeProblemEnvelope(w http.ResponseWriter, p Problem) {
+	// the request id is in the metadata
+	m := meta(w, nil)
+	p.RequestID = ""
+
+	// negotiate
+	mediaType, ok := negotiate(w, false)
+	if !ok {
+		mediaType = codec.JSON
+	}
+	contentType := "application/json"
+	if mediaType != codec.JSON {
+		contentType = contentTypes[mediaType]
+	}
+
+	// encode body
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, mediaType, Envelope{Meta: m, Errors: []Problem{p}}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// write response
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(p.Status)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,119 @@
+package response_test
+
+import (
+	"app/platform/web/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Success
+func TestSuccess(t *testing.T) {
+	t.Run("case 1: should return the message and the data under its key", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		rr.Header().Set("X-Request-Id", "id")
+		response.Success(rr, http.StatusOK, response.Body{
+			Message: "warehouse found",
+			Key:     "warehouse",
+			Data:    map[string]any{"id": 1},
+			Links:   map[string]string{"self": "/warehouses/1"},
+		})
+
+		// assert
+		expectedCode := http.StatusOK
+		expectedBody := `{"message":"warehouse found","warehouse":{"id":1}}`
+		require.Equal(t, expectedCode, rr.Code)
+		require.JSONEq(t, expectedBody, rr.Body.String())
+	})
+
+	t.Run("case 2: should return an envelope when the response is enveloped", func(t *testing.T) {
+		// arrange
+		hd := response.Enveloped(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response.Success(w, http.StatusOK, response.Body{
+				Message: "warehouses found",
+				Key:     "warehouses",
+				Data:    []int{1},
+				Meta:    map[string]any{"count": 1},
+				Links:   map[string]string{"self": "/api/v1/warehouses"},
+			})
+		}))
+
+		// act
+		rr := httptest.NewRecorder()
+		rr.Header().Set("X-Request-Id", "id")
+		hd.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/warehouses", nil))
+
+		// assert
+		expectedCode := http.StatusOK
+		expectedBody := `{"data":[1],"meta":{"count":1,"message":"warehouses found","request_id":"id"},"links":{"self":"/api/v1/warehouses"}}`
+		require.Equal(t, expectedCode, rr.Code)
+		require.JSONEq(t, expectedBody, rr.Body.String())
+	})
+
+	t.Run("case 3: should return an envelope when the enveloped writer is wrapped", func(t *testing.T) {
+		// arrange
+		hd := response.Enveloped(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			response.Success(ww, http.StatusOK, response.Body{Data: 1})
+		}))
+
+		// act
+		rr := httptest.NewRecorder()
+		hd.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+		// assert
+		expectedBody := `{"data":1,"meta":{}}`
+		require.JSONEq(t, expectedBody, rr.Body.String())
+	})
+}
+
+// Tests for WriteProblem in an envelope
+func TestWriteProblem_Enveloped(t *testing.T) {
+	t.Run("case 1: should return the problem as the errors of an envelope", func(t *testing.T) {
+		// arrange
+		hd := response.Enveloped(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response.WriteProblem(w, response.Problem{Status: http.StatusNotFound, Code: "product_not_found", Title: "Product not found"})
+		}))
+
+		// act
+		rr := httptest.NewRecorder()
+		rr.Header().Set("X-Request-Id", "id")
+		hd.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/products/1", nil))
+
+		// assert
+		expectedCode := http.StatusNotFound
+		expectedBody := `{"data":null,"meta":{"request_id":"id"},"errors":[{"type":"/problems/product_not_found","title":"Product not found","status":404,"code":"product_not_found"}]}`
+		require.Equal(t, expectedCode, rr.Code)
+		require.JSONEq(t, expectedBody, rr.Body.String())
+		require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	})
+
+	t.Run("404 - envelope of a problem in the negotiated media type", func(t *testing.T) {
+		// arrange
+		hd := response.Negotiated(response.Enveloped(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response.WriteProblem(w, response.Problem{Status: http.StatusNotFound, Code: "product_not_found", Title: "Product not found"})
+		})))
+
+		// act
+		rr := httptest.NewRecorder()
+		rr.Header().Set("X-Request-Id", "id")
+		req := httptest.NewRequest("GET", "/api/v1/products/1", nil)
+		req.Header.Set("Accept", "application/xml")
+		hd.ServeHTTP(rr, req)
+
+		// assert
+		expectedCode := http.StatusNotFound
+		expectedBody := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+			`<response><data nil="true"></data><meta><request_id>id</request_id></meta><errors><item><type>/problems/product_not_found</type><title>Product not found</title><status>404</status><code>product_not_found</code></item></errors></response>`
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+		require.Equal(t, "application/xml; charset=utf-8", rr.Header().Get("Content-Type"))
+	})
+}
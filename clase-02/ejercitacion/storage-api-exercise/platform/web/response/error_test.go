@@ -23,8 +23,8 @@ func TestError(t *testing.T) {
 
 		// assert
 		expectedCode := http.StatusInternalServerError
-		expectedBody := `{"status":"Internal Server Error","message":"error message"}`
-		expectedHeaders := http.Header{"Content-Type": []string{"application/json"}}
+		expectedBody := `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"error message","code":"internal_server_error"}`
+		expectedHeaders := http.Header{"Content-Type": []string{"application/problem+json"}}
 		require.Equal(t, expectedCode, rr.Code)
 		require.Equal(t, expectedBody, rr.Body.String())
 		require.Equal(t, expectedHeaders, rr.Header())
@@ -42,12 +42,29 @@ func TestError(t *testing.T) {
 
 		// assert
 		expectedCode := http.StatusBadRequest
-		expectedBody := `{"status":"Bad Request","message":"error message"}`
-		expectedHeaders := http.Header{"Content-Type": []string{"application/json"}}
+		expectedBody := `{"type":"about:blank","title":"Bad Request","status":400,"detail":"error message","code":"bad_request"}`
+		expectedHeaders := http.Header{"Content-Type": []string{"application/problem+json"}}
 		require.Equal(t, expectedCode, rr.Code)
 		require.Equal(t, expectedBody, rr.Body.String())
 		require.Equal(t, expectedHeaders, rr.Header())
 	})
+
+	t.Run("case 3: should return status code 499 - client closed request", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		code := response.StatusClientClosedRequest
+		message := "error message"
+		response.Error(rr, code, message)
+
+		// assert
+		expectedCode := response.StatusClientClosedRequest
+		expectedBody := `{"type":"about:blank","title":"Client Closed Request","status":499,"detail":"error message","code":"client_closed_request"}`
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+	})
 }
 
 // Tests for Errorf
@@ -65,8 +82,8 @@ func TestErrorf(t *testing.T) {
 
 		// assert
 		expectedCode := http.StatusInternalServerError
-		expectedBody := `{"status":"Internal Server Error","message":"error message arg"}`
-		expectedHeaders := http.Header{"Content-Type": []string{"application/json"}}
+		expectedBody := `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"error message arg","code":"internal_server_error"}`
+		expectedHeaders := http.Header{"Content-Type": []string{"application/problem+json"}}
 		require.Equal(t, expectedCode, rr.Code)
 		require.Equal(t, expectedBody, rr.Body.String())
 		require.Equal(t, expectedHeaders, rr.Header())
@@ -85,10 +102,33 @@ func TestErrorf(t *testing.T) {
 
 		// assert
 		expectedCode := http.StatusBadRequest
-		expectedBody := `{"status":"Bad Request","message":"error message arg"}`
-		expectedHeaders := http.Header{"Content-Type": []string{"application/json"}}
+		expectedBody := `{"type":"about:blank","title":"Bad Request","status":400,"detail":"error message arg","code":"bad_request"}`
+		expectedHeaders := http.Header{"Content-Type": []string{"application/problem+json"}}
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+		require.Equal(t, expectedHeaders, rr.Header())
+	})
+}
+
+// Tests for ErrorList
+func TestErrorList(t *testing.T) {
+	t.Run("case 1: should return status code 422 with the list of errors", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		code := http.StatusUnprocessableEntity
+		message := "error message"
+		errs := []map[string]string{{"field": "name"}}
+		response.ErrorList(rr, code, message, errs)
+
+		// assert
+		expectedCode := http.StatusUnprocessableEntity
+		expectedBody := `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"error message","code":"unprocessable_entity","errors":[{"field":"name"}]}`
+		expectedHeaders := http.Header{"Content-Type": []string{"application/problem+json"}}
 		require.Equal(t, expectedCode, rr.Code)
 		require.Equal(t, expectedBody, rr.Body.String())
 		require.Equal(t, expectedHeaders, rr.Header())
 	})
-}
\ No newline at end of file
+}
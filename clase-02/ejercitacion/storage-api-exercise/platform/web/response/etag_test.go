@@ -0,0 +1,46 @@
+package response_test
+
+import (
+	"app/platform/web/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ETag function
+func TestETag(t *testing.T) {
+	t.Run("etag header set", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		response.ETag(rr, `"1"`)
+
+		// assert
+		expectedHeader := http.Header{"Etag": []string{`"1"`}}
+		require.Equal(t, expectedHeader, rr.Header())
+	})
+}
+
+// Tests for NotModified function
+func TestNotModified(t *testing.T) {
+	t.Run("304 - status not modified", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		response.NotModified(rr, `"1"`)
+
+		// assert
+		expectedHeader := http.Header{"Etag": []string{`"1"`}}
+		expectedCode := http.StatusNotModified
+		expectedBody := ""
+		require.Equal(t, expectedHeader, rr.Header())
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+	})
+}
@@ -0,0 +1,130 @@
+package response
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldUnknownError is the error of a field asked for that is not a field of the data of a response
+type FieldUnknownError struct {
+	// Name is the name of the field
+	Name string
+}
+
+// Error returns the message of the error
+func (e *FieldUnknownError) Error() string {
+	return "response: unknown field " + e.Name
+}
+
+// field is a field of a struct written in a response, named by its json tag
+type field struct {
+	// name is the json name of the field
+	name string
+	// options are the options of its json tag after the name (e.g. ",omitempty")
+	options string
+	// index is the index of the field in the struct, through its embedded structs
+	index []int
+	// typ is the type of the field
+	typ reflect.Type
+}
+
+// fieldsOf returns the fields of a struct type in order, the fields of its embedded structs as its own as encoding/json does
+func fieldsOf(t reflect.Type, index []int) (fs []field) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if options != "" {
+			options = "," + options
+		}
+		idx := append(append([]int(nil), index...), i)
+
+		// - embedded struct
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			fs = append(fs, fieldsOf(sf.Type, idx)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fs = append(fs, field{name: name, options: options, index: idx, typ: sf.Type})
+	}
+	return
+}
+
+// Fields returns the data of a response with only the fields named, by their json name (e.g. the ones of ?fields=id,name).
+// data is a struct or a slice of structs, its fields are kept in their order whatever the order of names.
+// The value returned is a struct, or a slice of them, of a type of its own, so it is written in every media type as data would
+// (e.g. as the columns of a csv). It returns data as is when names is empty, and a *FieldUnknownError when a name is not a field
+func Fields(data any, names []string) (sparse any, err error) {
+	if len(names) == 0 {
+		sparse = data
+		return
+	}
+
+	v := reflect.ValueOf(data)
+	list := v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+	t := v.Type()
+	if list {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		err = fmt.Errorf("response: fields of a %s", t)
+		return
+	}
+
+	// fields named
+	all := fieldsOf(t, nil)
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		found := false
+		for _, f := range all {
+			found = found || f.name == name
+		}
+		if !found {
+			err = &FieldUnknownError{Name: name}
+			return
+		}
+		keep[name] = true
+	}
+	var fs []field
+	var sfs []reflect.StructField
+	for _, f := range all {
+		if !keep[f.name] {
+			continue
+		}
+		fs = append(fs, f)
+		sfs = append(sfs, reflect.StructField{
+			Name: fmt.Sprintf("F%d", len(sfs)),
+			Type: f.typ,
+			Tag:  reflect.StructTag(fmt.Sprintf("json:%q", f.name+f.options)),
+		})
+	}
+	st := reflect.StructOf(sfs)
+
+	// copy
+	project := func(item reflect.Value) reflect.Value {
+		s := reflect.New(st).Elem()
+		for i, f := range fs {
+			s.Field(i).Set(item.FieldByIndex(f.index))
+		}
+		return s
+	}
+	if !list {
+		sparse = project(v).Interface()
+		return
+	}
+	items := reflect.MakeSlice(reflect.SliceOf(st), v.Len(), v.Len())
+	for i := 0; i < v.Len(); i++ {
+		items.Index(i).Set(project(v.Index(i)))
+	}
+	sparse = items.Interface()
+	return
+}
@@ -0,0 +1,77 @@
+package response_test
+
+import (
+	"app/platform/web/response"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fieldsItem is an item of the tests of Fields, with an embedded struct
+type fieldsItem struct {
+	fieldsBase
+	Name  string   `json:"name"`
+	Price float64  `json:"price,omitempty"`
+	Tags  []string `json:"tags"`
+}
+
+type fieldsBase struct {
+	ID int `json:"id"`
+}
+
+// Tests for Fields function
+func TestFields(t *testing.T) {
+	t.Run("fields named of a struct, in the order of the struct", func(t *testing.T) {
+		// arrange
+		item := fieldsItem{fieldsBase: fieldsBase{ID: 1}, Name: "a", Tags: []string{"x"}}
+
+		// act
+		sparse, err := response.Fields(item, []string{"price", "id"})
+
+		// assert
+		require.NoError(t, err)
+		b, err := json.Marshal(sparse)
+		require.NoError(t, err)
+		require.Equal(t, `{"id":1}`, string(b))
+	})
+
+	t.Run("fields named of a list", func(t *testing.T) {
+		// arrange
+		items := []fieldsItem{{fieldsBase: fieldsBase{ID: 1}, Name: "a"}, {fieldsBase: fieldsBase{ID: 2}, Name: "b"}}
+
+		// act
+		sparse, err := response.Fields(items, []string{"name", "id"})
+
+		// assert
+		require.NoError(t, err)
+		b, err := json.Marshal(sparse)
+		require.NoError(t, err)
+		require.Equal(t, `[{"id":1,"name":"a"},{"id":2,"name":"b"}]`, string(b))
+	})
+
+	t.Run("no fields named", func(t *testing.T) {
+		// arrange
+		item := fieldsItem{Name: "a"}
+
+		// act
+		sparse, err := response.Fields(item, nil)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, item, sparse)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		// arrange
+		item := fieldsItem{Name: "a"}
+
+		// act
+		_, err := response.Fields(item, []string{"id", "fieldsBase"})
+
+		// assert
+		var errField *response.FieldUnknownError
+		require.ErrorAs(t, err, &errField)
+		require.Equal(t, "fieldsBase", errField.Name)
+	})
+}
@@ -0,0 +1,146 @@
+package response
+
+import (
+	"app/platform/web/codec"
+	"bytes"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// contentTypes are the content type headers of the media types of the responses
+var contentTypes = map[string]string{
+	codec.XML:         "application/xml; charset=utf-8",
+	codec.MessagePack: codec.MessagePack,
+	codec.CSV:         "text/csv; charset=utf-8",
+}
+
+// Negotiated is a middleware that makes the next handler write its responses in the media type preferred by the Accept header of the request:
+// json (the default), xml, msgpack or, for lists, csv. Problems are still written as application/problem+json, except in an Envelope
+func Negotiated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		next.ServeHTTP(&negotiatedWriter{ResponseWriter: w, accept: r.Header.Get("Accept")}, r)
+	})
+}
+
+// negotiatedWriter is a response writer whose responses are written in the media type preferred by accept
+type negotiatedWriter struct {
+	http.ResponseWriter
+	// accept is the Accept header of the request
+	accept string
+}
+
+// Unwrap returns the response writer it wraps, as http.ResponseController expects
+func (w *negotiatedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// acceptOf returns the Accept header of the request of w, following the writers it wraps. ok is false when the response is not negotiated
+func acceptOf(w http.ResponseWriter) (accept string, ok bool) {
+	for {
+		switch u := w.(type) {
+		case *negotiatedWriter:
+			return u.accept, true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = u.Unwrap()
+		default:
+			return
+		}
+	}
+}
+
+// negotiate returns the media type of the response of w: the one with the highest quality in the Accept header,
+// json on a tie or when the response is not negotiated. csv is only offered for lists. ok is false when no media type is acceptable
+func negotiate(w http.ResponseWriter, list bool) (mediaType string, ok bool) {
+	accept, negotiated := acceptOf(w)
+	if !negotiated || strings.TrimSpace(accept) == "" {
+		return codec.JSON, true
+	}
+
+	// offers in order of preference
+	offers := []string{codec.JSON, codec.XML, codec.MessagePack}
+	if list {
+		offers = append(offers, codec.CSV)
+	}
+
+	best := 0.0
+	for _, offer := range offers {
+		if q := quality(accept, offer); q > best {
+			mediaType, best = offer, q
+		}
+	}
+	ok = best > 0
+	return
+}
+
+// quality returns the quality of a media type in an Accept header, given by its most specific range (type/subtype, type/* or */*)
+func quality(accept, mediaType string) (q float64) {
+	specificity := -1
+	for _, rg := range strings.Split(accept, ",") {
+		name, params, err := mime.ParseMediaType(strings.TrimSpace(rg))
+		if err != nil {
+			continue
+		}
+
+		// match
+		s := -1
+		typ, _, _ := strings.Cut(mediaType, "/")
+		switch {
+		case name == "*/*":
+			s = 0
+		case strings.HasSuffix(name, "/*") && strings.TrimSuffix(name, "/*") == typ:
+			s = 1
+		default:
+			if m, ok := codec.MediaType(name); ok && m == mediaType {
+				s = 2
+			}
+		}
+		if s <= specificity {
+			continue
+		}
+
+		// quality
+		specificity, q = s, 1
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return
+}
+
+// Encode writes body in the media type negotiated for the response (json when it is not negotiated),
+// or a 406 Not Acceptable problem when none is acceptable
+func Encode(w http.ResponseWriter, code int, body any) {
+	// check body
+	if body == nil {
+		w.WriteHeader(code)
+		return
+	}
+
+	// negotiate
+	mediaType, ok := negotiate(w, codec.Tabular(body))
+	if !ok {
+		WriteProblem(w, Problem{Status: http.StatusNotAcceptable})
+		return
+	}
+	if mediaType == codec.JSON {
+		JSON(w, code, body)
+		return
+	}
+
+	// encode body
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, mediaType, body); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// write response
+	w.Header().Set("Content-Type", contentTypes[mediaType])
+	w.WriteHeader(code)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,82 @@
+package response_test
+
+import (
+	"app/platform/web/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Negotiated
+func TestNegotiated(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	// serve returns the response of a success with data, negotiated by the accept header
+	serve := func(accept string, data any) *httptest.ResponseRecorder {
+		hd := response.Negotiated(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response.Success(w, http.StatusOK, response.Body{Message: "items found", Data: data})
+		}))
+		req := httptest.NewRequest("GET", "/items", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rr := httptest.NewRecorder()
+		hd.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("case 1: should return json without an accept header", func(t *testing.T) {
+		// act
+		rr := serve("", []item{{ID: 1, Name: "a"}})
+
+		// assert
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		require.Equal(t, "Accept", rr.Header().Get("Vary"))
+		require.JSONEq(t, `{"message":"items found","data":[{"id":1,"name":"a"}]}`, rr.Body.String())
+	})
+
+	t.Run("case 2: should return xml when it is preferred", func(t *testing.T) {
+		// act
+		rr := serve("application/json;q=0.5, application/xml", item{ID: 1, Name: "a"})
+
+		// assert
+		expectedBody := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><data><id>1</id><name>a</name></data><message>items found</message></response>`
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "application/xml; charset=utf-8", rr.Header().Get("Content-Type"))
+		require.Equal(t, expectedBody, rr.Body.String())
+	})
+
+	t.Run("case 3: should return the rows of a list in csv", func(t *testing.T) {
+		// act
+		rr := serve("text/csv", []item{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}})
+
+		// assert
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		require.Equal(t, "id,name\n1,a\n2,b\n", rr.Body.String())
+	})
+
+	t.Run("case 4: should prefer json on a wildcard", func(t *testing.T) {
+		// act
+		rr := serve("text/*, */*;q=0.1", item{ID: 1})
+
+		// assert
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	})
+
+	t.Run("case 5: should return 406 when no media type is acceptable", func(t *testing.T) {
+		// act
+		rr := serve("text/csv, application/json;q=0", item{ID: 1})
+
+		// assert
+		require.Equal(t, http.StatusNotAcceptable, rr.Code)
+		require.Equal(t, response.ContentTypeProblem, rr.Header().Get("Content-Type"))
+		require.JSONEq(t, `{"type":"about:blank","title":"Not Acceptable","status":406,"code":"not_acceptable"}`, rr.Body.String())
+	})
+}
@@ -0,0 +1,88 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const (
+	// ContentTypeProblem is the media type of a problem details response (RFC 7807)
+	ContentTypeProblem = "application/problem+json"
+	// ProblemTypeBase is the base of the type uri of a problem, followed by its code
+	ProblemTypeBase = "/problems/"
+	// headerRequestID is the header of the response with the id of the request, set by the request.ID middleware
+	headerRequestID = "X-Request-Id"
+)
+
+// Problem is an error response with the details of a problem, as defined by RFC 7807
+type Problem struct {
+	// Type is the uri of the kind of problem, about:blank when the status is enough to describe it
+	Type string `json:"type"`
+	// Title is a short summary of the kind of problem
+	Title string `json:"title"`
+	// Status is the status code of the response
+	Status int `json:"status"`
+	// Detail is an explanation of this occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+	// Code is the stable machine-readable code of the kind of problem (e.g. product_not_found)
+	Code string `json:"code"`
+	// RequestID is the id of the request, to find it in the logs
+	RequestID string `json:"request_id,omitempty"`
+	// Errors is the list of errors that caused the problem (e.g. the fields of an invalid body)
+	Errors any `json:"errors,omitempty"`
+}
+
+// statusCode returns the code of a status (e.g. not_found)
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(statusText(status)), " ", "_")
+}
+
+// WriteProblem writes a problem as an application/problem+json response, with its title and detail in the language of the response.
+// A problem without a code is described by its status: the type is about:blank and the code is the status text (e.g. not_found).
+// An enveloped response writes it as the errors of an Envelope
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	// defaults
+	// - status
+	if p.Status < 400 || p.Status > 599 {
+		p.Status = http.StatusInternalServerError
+	}
+	// - type and code
+	switch {
+	case p.Type != "":
+	case p.Code != "":
+		p.Type = ProblemTypeBase + p.Code
+	default:
+		p.Type = "about:blank"
+	}
+	if p.Code == "" {
+		p.Code = statusCode(p.Status)
+	}
+	// - title
+	if p.Title == "" {
+		p.Title = statusText(p.Status)
+	}
+	// - language of the response
+	p.Title, p.Detail = Localize(w, p.Title), Localize(w, p.Detail)
+	// - request id
+	if p.RequestID == "" {
+		p.RequestID = w.Header().Get(headerRequestID)
+	}
+	// - versioned api
+	if enveloped(w) {
+		writeProblemEnvelope(w, p)
+		return
+	}
+
+	// marshal body
+	bytes, err := json.Marshal(p)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// write response
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(p.Status)
+	w.Write(bytes)
+}
@@ -0,0 +1,72 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Stream is a struct that writes a text/event-stream response (Server-Sent Events), flushing each event to the client
+type Stream struct {
+	// w is the response writer
+	w http.ResponseWriter
+	// rc flushes the response writer, following the writers it wraps
+	rc *http.ResponseController
+}
+
+// NewStream writes the headers of an event stream and returns it, retry is the delay the client should wait to reconnect
+// (zero leaves it to the client). It returns an error when the response can not be flushed
+func NewStream(w http.ResponseWriter, retry time.Duration) (s *Stream, err error) {
+	s = &Stream{w: w, rc: http.NewResponseController(w)}
+
+	// headers: proxies must not cache nor buffer it
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if retry > 0 {
+		if _, err = fmt.Fprintf(w, "retry: %d\n\n", retry.Milliseconds()); err != nil {
+			return
+		}
+	}
+	err = s.rc.Flush()
+	return
+}
+
+// Send writes an event with its id and name, data is written as json in a single line.
+// An empty id or event is not written
+func (s *Stream) Send(id, event string, data any) (err error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	var sb strings.Builder
+	if id != "" {
+		sb.WriteString("id: " + id + "\n")
+	}
+	if event != "" {
+		sb.WriteString("event: " + event + "\n")
+	}
+	sb.WriteString("data: ")
+	sb.Write(b)
+	sb.WriteString("\n\n")
+
+	if _, err = s.w.Write([]byte(sb.String())); err != nil {
+		return
+	}
+	err = s.rc.Flush()
+	return
+}
+
+// Comment writes a comment, ignored by the client, e.g. to keep the connection alive through proxies
+func (s *Stream) Comment(text string) (err error) {
+	if _, err = fmt.Fprintf(s.w, ": %s\n\n", text); err != nil {
+		return
+	}
+	err = s.rc.Flush()
+	return
+}
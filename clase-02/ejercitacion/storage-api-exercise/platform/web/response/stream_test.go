@@ -0,0 +1,51 @@
+package response_test
+
+import (
+	"app/platform/web/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Stream
+func TestStream(t *testing.T) {
+	t.Run("case 1: should write the headers and the retry delay", func(t *testing.T) {
+		// arrange
+		rr := httptest.NewRecorder()
+
+		// act
+		_, err := response.NewStream(rr, 3*time.Second)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+		require.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+		require.Equal(t, "retry: 3000\n\n", rr.Body.String())
+		require.True(t, rr.Flushed)
+	})
+
+	t.Run("case 2: should write events and comments", func(t *testing.T) {
+		// arrange
+		rr := httptest.NewRecorder()
+		s, err := response.NewStream(rr, 0)
+		require.NoError(t, err)
+
+		// act
+		errEvent := s.Send("7", "product.created", map[string]any{"id": 1})
+		errData := s.Send("", "", "only data")
+		errComment := s.Comment("keep-alive")
+
+		// assert
+		require.NoError(t, errEvent)
+		require.NoError(t, errData)
+		require.NoError(t, errComment)
+		expected := "id: 7\nevent: product.created\ndata: {\"id\":1}\n\n" +
+			"data: \"only data\"\n\n" +
+			": keep-alive\n\n"
+		require.Equal(t, expected, rr.Body.String())
+	})
+}